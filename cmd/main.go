@@ -14,12 +14,17 @@ import (
 	"japa/internal/app/http/handler"
 	"japa/internal/app/http/middleware"
 	"japa/internal/config"
+	"japa/internal/domain/entity"
 	"japa/internal/domain/repository"
 	"japa/internal/domain/usecase"
 	"japa/internal/infrastructure/db"
 	"japa/internal/infrastructure/logging"
 	"japa/internal/infrastructure/mail"
+	"japa/internal/infrastructure/payment"
+	"japa/internal/infrastructure/scheduler"
 	"japa/internal/infrastructure/scraper"
+	"japa/internal/infrastructure/storage"
+	"japa/internal/infrastructure/webhook"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -112,6 +117,16 @@ func main() { // Application entry point
 		},
 	}
 
+	// Initialize payment providers, tried in the configured order
+	zap.L().Debug("Initializing payment providers")
+	paymentProvider, err := payment.NewResponsivePaymentProvider(
+		cfg.PaymentConfig,
+		cfg.SiteConfig.SiteDomain+"/api/v1/payments/fake/checkout",
+	)
+	if err != nil {
+		zap.L().Fatal("Payment provider setup failed", zap.Error(err))
+	}
+
 	// Initialize scrapers
 	japacontentScraper := &scraper.JapaContentScraper{
 		Logger: logger,
@@ -134,16 +149,127 @@ func main() { // Application entry point
 	userRepo := repository.NewUserRepository(db)
 	visaRepo := repository.NewVisaRepository(db)
 	postRepo := repository.NewPostRepository(db)
+	eligibilityRepo := repository.NewEligibilityRepository(db)
+	appointmentRepo := repository.NewAppointmentRepository(db)
+	slaRepo := repository.NewSLARepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	fraudRepo := repository.NewFraudRepository(db)
+	retentionRepo := repository.NewRetentionRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	billingRepo := repository.NewBillingRepository(db)
+	subscriptionRepo := repository.NewSubscriptionRepository(db)
+	entitlementRepo := repository.NewEntitlementRepository(db)
+	planRepo := repository.NewPlanRepository(db)
+	couponRepo := repository.NewCouponRepository(db)
+	affiliateRepo := repository.NewAffiliateRepository(db)
+	invoiceRepo := repository.NewInvoiceRepository(db)
+	ledgerRepo := repository.NewLedgerRepository(db)
+	payoutRepo := repository.NewPayoutRepository(db)
 
 	zap.L().Debug("Initializing services")
-	userUsecase := usecase.NewUserUsecase(cfg.JWTConfig, userRepo, db, mailer)
-	visaUsecase := usecase.NewVisaUsecase(visaRepo, db)
+	ledgerUsecase := usecase.NewLedgerUsecase(cfg.LedgerConfig, ledgerRepo, paymentProvider, db)
+	affiliateUsecase := usecase.NewAffiliateUsecase(cfg.AffiliateConfig, cfg.SiteConfig, affiliateRepo, ledgerUsecase, db)
+	payoutUsecase := usecase.NewPayoutUsecase(cfg.AffiliateConfig, cfg.SiteConfig, payoutRepo, affiliateRepo, paymentProvider, ledgerUsecase, db)
+	userUsecase := usecase.NewUserUsecase(cfg.JWTConfig, userRepo, affiliateUsecase, db, mailer)
+	eligibilityUsecase := usecase.NewEligibilityUsecase(eligibilityRepo, db)
+	webhookSender := webhook.NewSender(cfg.WebhookConfig.Timeout, cfg.SiteConfig.SiteName+"-Webhooks/1.0")
+	webhookUsecase := usecase.NewWebhookUsecase(cfg.WebhookConfig, webhookRepo, webhookSender, db)
+	fraudUsecase := usecase.NewFraudUsecase(cfg.FraudConfig, fraudRepo, db)
+	paymentUsecase := usecase.NewPaymentUsecase(cfg.PaymentConfig, cfg.SiteConfig, paymentRepo, paymentProvider, db, mailer)
+	entitlementUsecase := usecase.NewEntitlementUsecase(cfg.SubscriptionConfig, entitlementRepo, db)
+	couponUsecase := usecase.NewCouponUsecase(cfg.BillingConfig, couponRepo, db)
+	billingUsecase := usecase.NewBillingUsecase(cfg.BillingConfig, billingRepo, visaRepo, paymentUsecase, entitlementUsecase, couponUsecase, db)
+	planUsecase := usecase.NewPlanUsecase(cfg.BillingConfig, planRepo, db)
+	subscriptionUsecase := usecase.NewSubscriptionUsecase(cfg.SubscriptionConfig, cfg.BillingConfig, subscriptionRepo, paymentUsecase, couponUsecase, db, mailer)
+	paymentUsecase.OnSettled(entity.PaymentPurposeApplication, billingUsecase.HandleSettlement)
+	paymentUsecase.OnSettled(entity.PaymentPurposeSubscription, subscriptionUsecase.HandleSettlement)
+	invoiceUsecase := usecase.NewInvoiceUsecase(cfg.BillingConfig, cfg.SiteConfig, invoiceRepo, couponRepo, db, mailer)
+	paymentUsecase.OnAnySettled(affiliateUsecase.HandleSettlement)
+	paymentUsecase.OnAnySettled(invoiceUsecase.HandleSettlement)
+	paymentUsecase.OnAnySettled(ledgerUsecase.HandleSettlement)
+	paymentUsecase.OnRefunded(entity.PaymentPurposeApplication, billingUsecase.HandleRefund)
+	paymentUsecase.OnRefunded(entity.PaymentPurposeSubscription, subscriptionUsecase.HandleRefund)
+	paymentUsecase.OnAnyRefunded(affiliateUsecase.HandleRefund)
+	paymentUsecase.OnAnyRefunded(ledgerUsecase.HandleRefund)
+	visaUsecase := usecase.NewVisaUsecase(cfg.SiteConfig, cfg.VisaValidation, visaRepo, db, mailer, eligibilityUsecase, webhookUsecase, fraudUsecase, billingUsecase)
 	postUsecase := usecase.NewPostUsecase(postRepo, db)
+	appointmentUsecase := usecase.NewAppointmentUsecase(cfg.AppointmentConfig, cfg.SiteConfig, appointmentRepo, visaRepo, db, mailer)
+	slaUsecase := usecase.NewSLAUsecase(cfg.SLAConfig, cfg.SiteConfig, slaRepo, visaRepo, fraudRepo, userRepo, db, mailer)
+	auditUsecase := usecase.NewAuditUsecase(auditRepo, db)
+	exportUsecase := usecase.NewExportUsecase(slaUsecase, visaRepo, auditUsecase)
+	retentionUsecase := usecase.NewRetentionUsecase(cfg.RetentionConfig, retentionRepo, visaRepo, auditUsecase, storage.NewLocalStore(cfg.ServerConfig.UploadsDir), db)
 
 	zap.L().Debug("Initializing handlers")
 	userHandler := handlers.NewUserHandler(Validator, userUsecase)
 	visaHandler := handlers.NewVisaHandler(Validator, visaUsecase)
 	postHandler := handlers.NewPostHandler(Validator, postUsecase)
+	eligibilityHandler := handlers.NewEligibilityHandler(Validator, eligibilityUsecase)
+	appointmentHandler := handlers.NewAppointmentHandler(Validator, appointmentUsecase)
+	slaHandler := handlers.NewSLAHandler(Validator, slaUsecase)
+	auditHandler := handlers.NewAuditHandler(Validator, auditUsecase, exportUsecase)
+	webhookHandler := handlers.NewWebhookHandler(Validator, webhookUsecase)
+	fraudHandler := handlers.NewFraudHandler(Validator, fraudUsecase)
+	retentionHandler := handlers.NewRetentionHandler(Validator, retentionUsecase)
+	paymentHandler := handlers.NewPaymentHandler(Validator, paymentUsecase)
+	billingHandler := handlers.NewBillingHandler(Validator, billingUsecase)
+	subscriptionHandler := handlers.NewSubscriptionHandler(Validator, subscriptionUsecase)
+	entitlementHandler := handlers.NewEntitlementHandler(Validator, entitlementUsecase)
+	couponHandler := handlers.NewCouponHandler(Validator, couponUsecase)
+	affiliateHandler := handlers.NewAffiliateHandler(Validator, affiliateUsecase)
+	invoiceHandler := handlers.NewInvoiceHandler(Validator, invoiceUsecase)
+	planHandler := handlers.NewPlanHandler(Validator, planUsecase)
+	ledgerHandler := handlers.NewLedgerHandler(Validator, ledgerUsecase)
+	payoutHandler := handlers.NewPayoutHandler(Validator, payoutUsecase)
+
+	// Start background jobs with
+	// the same context app uses
+	jobScheduler := &scheduler.Scheduler{
+		Tasks: []scheduler.Task{
+			{
+				Name:     "appointment-reminders",
+				Job:      scheduler.JobFunc(appointmentUsecase.SendDueReminders),
+				Interval: cfg.AppointmentConfig.ReminderInterval,
+			},
+			{
+				Name:     "sla-escalation",
+				Job:      scheduler.JobFunc(slaUsecase.Escalate),
+				Interval: cfg.SLAConfig.EscalationInterval,
+			},
+			{
+				Name:     "webhook-delivery",
+				Job:      scheduler.JobFunc(webhookUsecase.DeliverDue),
+				Interval: cfg.WebhookConfig.DeliveryInterval,
+			},
+			{
+				Name:     "retention-purge",
+				Job:      scheduler.JobFunc(retentionUsecase.Purge),
+				Interval: cfg.RetentionConfig.PurgeInterval,
+			},
+			{
+				Name:     "payment-reconciliation",
+				Job:      scheduler.JobFunc(paymentUsecase.Reconcile),
+				Interval: cfg.PaymentConfig.ReconcileInterval,
+			},
+			{
+				Name:     "subscription-renewals",
+				Job:      scheduler.JobFunc(subscriptionUsecase.Renew),
+				Interval: cfg.SubscriptionConfig.RenewalInterval,
+			},
+			{
+				Name:     "ledger-reconciliation",
+				Job:      scheduler.JobFunc(ledgerUsecase.Reconcile),
+				Interval: cfg.LedgerConfig.ReconcileInterval,
+			},
+			{
+				Name:     "partner-payouts",
+				Job:      scheduler.JobFunc(payoutUsecase.Process),
+				Interval: cfg.AffiliateConfig.PayoutInterval,
+			},
+		},
+		Logger: logger,
+	}
+	jobScheduler.Run(ctx)
 
 	// Initialize middleware
 	authMiddleware := middleware.NewAuthMiddleware(cfg.ServerConfig, cfg.JWTConfig, db).Handler()
@@ -161,9 +287,10 @@ func main() { // Application entry point
 		cors.New(
 			cors.Config{
 				AllowOrigins: "*",
-				AllowMethods: "GET, POST, DELETE",
+				AllowMethods: "GET, POST, PUT, PATCH, DELETE",
 			},
 		),
+		middleware.IPCountry(cfg.ServerConfig.CountryHeader),
 	)
 
 	zap.L().Debug("Linking http routes..")
@@ -183,8 +310,22 @@ func main() { // Application entry point
 	v1.Post("/auth/refresh", userHandler.RefreshToken)
 	v1.Get("/posts",     postHandler.FetchPosts) // api/v1/posts?page=2&limit=20
 	v1.Get("/posts/:post_id/:slug", postHandler.FetchPost)  // posts/01JXYZM4T8HR8PQKJS6E4X2C1Z/seo-tips-for-developers
+	v1.Post("/eligibility/check", eligibilityHandler.Check)
+	v1.Get("/plans", planHandler.FetchCatalogue) // api/v1/plans?currency=KES
+	v1.Get("/r/:code", affiliateHandler.TrackClick) // Referral links, redirect to signup
+	v1.Post("/payments/webhooks/paystack", paymentHandler.PaystackWebhook) // Signed by the provider
+	v1.Post("/payments/webhooks/flutterwave", paymentHandler.FlutterwaveWebhook)
+	if _, err := paymentProvider.Provider("fake"); err == nil {
+		v1.Get("/payments/fake/checkout", paymentHandler.CompleteFakePayment) // Local development only
+	}
 
 
+	// Visa tracking is a paid plan feature where applicants pay, see pricing.md
+	trackingGate := func(c *fiber.Ctx) error { return c.Next() }
+	if cfg.BillingConfig.RequirePayment {
+		trackingGate = middleware.RequireEntitlement(entitlementUsecase, entity.EntitlementVisaTracking)
+	}
+
 	// Authenticated routes
 	accountGroup := v1.Group("/account")
 	accountGroup.Use(authMiddleware)
@@ -192,12 +333,79 @@ func main() { // Application entry point
 	// Visa routes (authenticated)
 	visaGroup :=  accountGroup.Group("/visa")
 	visaGroup.Post("/apply", visaHandler.SubmitVisaApplication)
+	visaGroup.Get("/applications/:application_id/timeline", trackingGate, visaHandler.FetchTimeline)
+	visaGroup.Post("/drafts", visaHandler.CreateDraft)
+	visaGroup.Get("/drafts", visaHandler.FetchDrafts)
+	visaGroup.Get("/drafts/:application_id", visaHandler.FetchDraft)
+	visaGroup.Patch("/drafts/:application_id", visaHandler.AutosaveDraft)
+	visaGroup.Post("/drafts/:application_id/submit", visaHandler.SubmitDraft)
+	visaGroup.Post("/applications/:application_id/documents", visaHandler.AddDocument)
+	visaGroup.Post("/groups", visaHandler.CreateGroup)
+	visaGroup.Get("/groups/:group_id", visaHandler.FetchGroup)
+	visaGroup.Post("/groups/:group_id/dependents", visaHandler.AddDependent)
+	visaGroup.Get("/applications/:application_id/appointments", appointmentHandler.FetchForApplicant)
+	visaGroup.Get("/appointments/:appointment_id/ics", appointmentHandler.DownloadICS)
+	visaGroup.Get("/add-ons", billingHandler.FetchAddOns)
+	visaGroup.Get("/applications/:application_id/quote", billingHandler.Quote) // ?add_ons=express,cv_review
+	visaGroup.Post("/applications/:application_id/checkout", billingHandler.Checkout)
+
+	// Payment routes (authenticated)
+	accountGroup.Get("/payments", paymentHandler.FetchPayments)
+	accountGroup.Get("/payments/:reference", paymentHandler.VerifyPayment)
+	accountGroup.Get("/billing/invoices", invoiceHandler.FetchInvoices) // ?page=1&limit=20
+	accountGroup.Get("/billing/invoices/:invoice_id/pdf", invoiceHandler.DownloadPDF)
+	accountGroup.Post("/billing/invoices/:invoice_id/email", invoiceHandler.Resend)
+
+	// Subscription routes (authenticated)
+	accountGroup.Get("/subscription", subscriptionHandler.FetchSubscription)
+	accountGroup.Post("/subscription", subscriptionHandler.Subscribe)
+	accountGroup.Post("/subscription/cancel", subscriptionHandler.Cancel) // {"at_period_end": false} ends it now
+	accountGroup.Post("/subscription/resume", subscriptionHandler.Resume)
+	accountGroup.Get("/subscription/change", subscriptionHandler.PreviewChange) // ?plan_id=3, prorated amounts without changing anything
+	accountGroup.Post("/subscription/change", subscriptionHandler.ChangePlan)
+	accountGroup.Delete("/subscription/change", subscriptionHandler.CancelChange) // Drops a scheduled downgrade
+	accountGroup.Get("/usage", entitlementHandler.FetchUsage) // "2 of 3 used" per entitlement
 
 	// Agent routes (authenticated)
 	agentGroup := v1.Group("/agent")
-	agentGroup.Use(middleware.AgentOnly())
+	agentGroup.Use(authMiddleware, middleware.AgentOnly())
 
 	//agentGroup.Get("/dashboard", agentHandler.GetDashboard)
+	agentGroup.Get("/visa/applications", slaHandler.FetchQueue)
+	agentGroup.Get("/visa/applications/export", auditHandler.ExportApplications)
+	agentGroup.Post("/visa/applications/:application_id/status", visaHandler.UpdateApplicationStatus)
+	agentGroup.Post("/visa/applications/:application_id/request-documents", visaHandler.RequestDocuments)
+	agentGroup.Get("/visa/groups/:group_id", visaHandler.FetchGroupForAgent)
+	agentGroup.Post("/visa/applications/:application_id/appointments", appointmentHandler.CreateAppointment)
+	agentGroup.Get("/visa/applications/:application_id/appointments", appointmentHandler.FetchForAgent)
+	agentGroup.Put("/appointments/:appointment_id", appointmentHandler.UpdateAppointment)
+	agentGroup.Delete("/appointments/:appointment_id", appointmentHandler.CancelAppointment)
+	agentGroup.Put("/visa/applications/:application_id/referrer", visaHandler.SetReferrer)
+	agentGroup.Get("/visa/applications/:application_id/fraud-signals", fraudHandler.FetchSignals)
+	agentGroup.Post("/visa/applications/:application_id/fraud-signals/:signal_id/review", fraudHandler.ReviewSignal)
+
+	// Partner routes (authenticated)
+	partnerGroup := v1.Group("/partner")
+	partnerGroup.Use(authMiddleware, middleware.PartnerOnly())
+
+	partnerGroup.Get("/webhooks", webhookHandler.FetchEndpoints)
+	partnerGroup.Post("/webhooks", webhookHandler.CreateEndpoint)
+	partnerGroup.Put("/webhooks/:endpoint_id", webhookHandler.UpdateEndpoint)
+	partnerGroup.Delete("/webhooks/:endpoint_id", webhookHandler.DeleteEndpoint)
+	partnerGroup.Post("/webhooks/:endpoint_id/rotate-secret", webhookHandler.RotateSecret)
+	partnerGroup.Get("/webhooks/:endpoint_id/deliveries", webhookHandler.FetchDeliveries)
+	partnerGroup.Get("/webhooks/deliveries/:delivery_id", webhookHandler.FetchDelivery)
+	partnerGroup.Post("/webhooks/deliveries/:delivery_id/replay", webhookHandler.ReplayDelivery)
+	partnerGroup.Get("/referral-codes", affiliateHandler.FetchCodes)
+	partnerGroup.Post("/referral-codes", affiliateHandler.CreateCode) // {"code": "ADA2025"}, generated when empty
+	partnerGroup.Put("/referral-codes/:code_id", affiliateHandler.UpdateCode)
+	partnerGroup.Get("/stats", affiliateHandler.FetchStats) // Clicks, signups, conversions and earnings
+	partnerGroup.Get("/commissions", affiliateHandler.FetchCommissions) // ?page=1&limit=20
+	partnerGroup.Get("/payout-accounts", payoutHandler.FetchAccounts) // With payable balances
+	partnerGroup.Put("/payout-accounts", payoutHandler.SaveAccount) // Upserts by currency
+	partnerGroup.Delete("/payout-accounts/:currency", payoutHandler.DeleteAccount)
+	partnerGroup.Get("/payouts", payoutHandler.FetchPayouts) // ?page=1&limit=20
+	partnerGroup.Get("/statement", payoutHandler.FetchStatement) // ?from=2026-10-01&to=2026-10-31
 
 	// Author routes (authenticated)
 	authorGroup := v1.Group("/author")
@@ -212,12 +420,77 @@ func main() { // Application entry point
 	adminGroup.Use(middleware.AdminOnly())
 
 	//adminGroup.Post("/posts/create", postHandler.CreatePost)
+	adminGroup.Get("/eligibility/rules", eligibilityHandler.FetchRules)
+	adminGroup.Post("/eligibility/rules", eligibilityHandler.CreateRule)
+	adminGroup.Delete("/eligibility/rules/:rule_id", eligibilityHandler.DeleteRule)
+	adminGroup.Get("/sla/targets", slaHandler.FetchTargets)
+	adminGroup.Put("/sla/targets", slaHandler.SaveTarget)
+	adminGroup.Delete("/sla/targets/:target_id", slaHandler.DeleteTarget)
+	adminGroup.Get("/visa/applications/export", auditHandler.ExportApplications)
+	adminGroup.Get("/audit-logs", auditHandler.FetchLogs)
+	adminGroup.Get("/retention/policies", retentionHandler.FetchPolicies)
+	adminGroup.Put("/retention/policies", retentionHandler.SavePolicy)
+	adminGroup.Delete("/retention/policies/:policy_id", retentionHandler.DeletePolicy)
+	adminGroup.Get("/retention/legal-holds", retentionHandler.FetchHolds)
+	adminGroup.Post("/visa/applications/:application_id/legal-hold", retentionHandler.PlaceHold)
+	adminGroup.Delete("/visa/applications/:application_id/legal-hold", retentionHandler.ReleaseHold)
+	adminGroup.Get("/retention/purge-runs", retentionHandler.FetchRuns)
+	adminGroup.Post("/retention/purge-runs", retentionHandler.StartPurge)
+	adminGroup.Get("/retention/purge-runs/:run_id", retentionHandler.FetchRun)
+	adminGroup.Get("/webhooks", webhookHandler.FetchEndpoints)
+	adminGroup.Post("/webhooks", webhookHandler.CreateEndpoint)
+	adminGroup.Put("/webhooks/:endpoint_id", webhookHandler.UpdateEndpoint)
+	adminGroup.Delete("/webhooks/:endpoint_id", webhookHandler.DeleteEndpoint)
+	adminGroup.Post("/webhooks/:endpoint_id/rotate-secret", webhookHandler.RotateSecret)
+	adminGroup.Get("/webhooks/:endpoint_id/deliveries", webhookHandler.FetchDeliveries)
+	adminGroup.Get("/webhooks/deliveries/:delivery_id", webhookHandler.FetchDelivery)
+	adminGroup.Post("/webhooks/deliveries/:delivery_id/replay", webhookHandler.ReplayDelivery)
+	adminGroup.Post("/payments/:reference/reconcile", paymentHandler.ReconcilePayment)
+	adminGroup.Get("/payments/:reference/refunds", paymentHandler.FetchRefunds)
+	adminGroup.Post("/payments/:reference/refunds", paymentHandler.RefundPayment)
+	adminGroup.Get("/pricing/applications", billingHandler.FetchPrices)
+	adminGroup.Put("/pricing/applications", billingHandler.SavePrice)
+	adminGroup.Delete("/pricing/applications/:price_id", billingHandler.DeletePrice)
+	adminGroup.Get("/pricing/add-ons", billingHandler.FetchAllAddOns)
+	adminGroup.Put("/pricing/add-ons", billingHandler.SaveAddOn)
+	adminGroup.Get("/plans", planHandler.FetchPlans)
+	adminGroup.Post("/plans", planHandler.CreatePlan)
+	adminGroup.Put("/plans/order", planHandler.Reorder)
+	adminGroup.Put("/plans/:plan_id", planHandler.UpdatePlan)
+	adminGroup.Post("/plans/:plan_id/versions", planHandler.CreateVersion)
+	adminGroup.Post("/plans/:plan_id/archive", planHandler.Archive)
+	adminGroup.Post("/plans/:plan_id/restore", planHandler.Restore)
+	adminGroup.Post("/plans/:plan_id/features", planHandler.SaveFeature)
+	adminGroup.Put("/plans/:plan_id/features/order", planHandler.ReorderFeatures)
+	adminGroup.Put("/plans/:plan_id/features/:feature_id", planHandler.SaveFeature)
+	adminGroup.Delete("/plans/:plan_id/features/:feature_id", planHandler.DeleteFeature)
+	adminGroup.Put("/plans/:plan_id/prices", planHandler.SavePrice)
+	adminGroup.Delete("/plans/:plan_id/prices/:currency", planHandler.DeletePrice)
+	adminGroup.Get("/plans/:plan_id/entitlements", entitlementHandler.FetchPlanEntitlements)
+	adminGroup.Put("/plans/:plan_id/entitlements", entitlementHandler.SaveEntitlement)
+	adminGroup.Delete("/plans/:plan_id/entitlements/:key", entitlementHandler.DeleteEntitlement)
+	adminGroup.Get("/coupons", couponHandler.FetchCoupons)
+	adminGroup.Put("/coupons", couponHandler.SaveCoupon) // Upserts by code
+	adminGroup.Get("/coupons/:code/redemptions", couponHandler.FetchRedemptions) // ?page=1&limit=20
+	adminGroup.Get("/commission-rules", affiliateHandler.FetchRules)
+	adminGroup.Put("/commission-rules", affiliateHandler.SaveRule) // Upserts by product and plan code
+	adminGroup.Delete("/commission-rules/:rule_id", affiliateHandler.DeleteRule)
+	adminGroup.Get("/ledger/balances", ledgerHandler.FetchBalances) // ?currency=NGN&at=2026-10-01T00:00:00Z
+	adminGroup.Get("/ledger/accounts/:account/entries", ledgerHandler.FetchEntries) // ?currency=NGN&page=1&limit=50
+	adminGroup.Get("/ledger/reconciliations", ledgerHandler.FetchReconciliations)
+	adminGroup.Post("/ledger/reconciliations", ledgerHandler.Reconcile) // Reruns a day
+	adminGroup.Get("/payouts/batches", payoutHandler.FetchBatches)
+	adminGroup.Post("/payouts/batches", payoutHandler.RunBatch) // Runs one now
+	adminGroup.Get("/payouts/batches/:batch_id", payoutHandler.FetchBatch)
 
 	// SuperAdmin routes (authenticated)
 	superAdminGroup := accountGroup.Group("/superadmin")
 	superAdminGroup.Use(middleware.SuperadminOnly())
 
 	//superAdminGroup.Get("/dashboard", superAdminHandler.GetDashboard)
+	superAdminGroup.Get("/users/:user_id/permissions", auditHandler.FetchPermissions)
+	superAdminGroup.Post("/users/:user_id/permissions", auditHandler.GrantPermission)
+	superAdminGroup.Delete("/users/:user_id/permissions/:permission", auditHandler.RevokePermission)
 
 	// Initialize Fiber server in background
 	zap.S().Debugw("Starting server at port ", cfg.ServerConfig.ServerAddress, "...")
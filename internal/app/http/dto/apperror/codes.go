@@ -52,6 +52,11 @@ const (
 	ErrCodeDocNotFound           = "DOCUMENT_NOT_FOUND"
 	ErrCodeInvalidDocFormat      = "INVALID_DOC_FORMAT"
 	ErrCodePaymentFailed         = "PAYMENT_FAILED"
+	ErrCodePaymentRequired       = "PAYMENT_REQUIRED"
 	ErrCodePostLimitReached      = "POST_LIMIT_REACHED"
 	ErrCodePlanExpired           = "PLAN_EXPIRED"
+	ErrCodeUpgradeRequired       = "UPGRADE_REQUIRED" // Plan doesn't include the feature
+	ErrCodeQuotaExceeded         = "QUOTA_EXCEEDED"   // Plan's quota used up for the period
+	ErrCodeInvalidCoupon         = "INVALID_COUPON"
+	ErrCodeNotEligible           = "NOT_ELIGIBLE"
 )
\ No newline at end of file
@@ -1,5 +1,7 @@
 package apperror
 
+import "strings"
+
 
 type AppError struct {
 	Code    string `json:"code"`    // e.g., VALIDATION_ERROR, DB_ERROR
@@ -36,3 +38,26 @@ func NewServerErr(details string) *AppError {
 	return New(ErrCodeInternalServer, "Something went wrong", details)
 }
 
+
+// FieldError is a validation failure on a single request field
+type FieldError struct {
+	Field   string `json:"field"`   // JSON path, i.e "visa_form_input.personal_info.passport_expiry"
+	Code    string `json:"code"`    // e.g., INVALID_FORMAT, OUT_OF_RANGE
+	Message string `json:"message"` // user-friendly message
+}
+
+// ValidationErrors collects every failing field of a request
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Field + ": " + fieldErr.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Add records a failing field
+func (e *ValidationErrors) Add(field, code, message string) {
+	*e = append(*e, FieldError{Field: field, Code: code, Message: message})
+}
@@ -0,0 +1,98 @@
+package request
+
+import (
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Create a referral code, i.e {"code": "ADA2025", "label": "YouTube"}
+type CreateReferralCodeRequest struct {
+	PartnerID string `json:"-" validate:"required,ulid"`                   // From auth context
+	Code      string `json:"code" validate:"omitempty,min=4,max=40,alphanum"` // Generated when empty
+	Label     string `json:"label" validate:"max=100"`
+}
+
+// Bind parses and validates the request body
+func (req *CreateReferralCodeRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	if len(c.Body()) > 0 {
+		// Parse request body into req
+		if err := c.BodyParser(req); err != nil {
+			return err
+		}
+	}
+
+	req.PartnerID, _ = c.Locals("user_id").(string)
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+// Relabel, pause or resume a referral code
+type UpdateReferralCodeRequest struct {
+	PartnerID string `json:"-" validate:"required,ulid"`  // From auth context
+	CodeID    uint   `json:"-" validate:"required,min=1"` // From route params
+	Label     string `json:"label" validate:"max=100"`
+	Active    *bool  `json:"active" validate:"required"`
+}
+
+// Bind parses and validates the request body
+func (req *UpdateReferralCodeRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	req.PartnerID, _ = c.Locals("user_id").(string)
+	id, err := strconv.ParseUint(c.Params("code_id"), 10, 64)
+	if err != nil {
+		return err
+	}
+	req.CodeID = uint(id)
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+// Create or update the commission rule for a product, i.e {"product": "plan", "plan_code": "pro", "type": "percentage", "percent": 20}
+type SaveCommissionRuleRequest struct {
+	Product   string `json:"product" validate:"required,oneof=plan application"`
+	PlanCode  string `json:"plan_code" validate:"omitempty,max=40"` // Plan rules only, empty for every plan
+	Type      string `json:"type" validate:"required,oneof=percentage fixed"`
+	Percent   int    `json:"percent" validate:"required_if=Type percentage,omitempty,min=1,max=100"`
+	Amount    int64  `json:"amount" validate:"required_if=Type fixed,omitempty,min=1"` // Minor units, per payment or per application
+	Currency  string `json:"currency" validate:"required_if=Type fixed,omitempty,len=3,uppercase"`
+	Recurring bool   `json:"recurring"` // Plan rules: pay on renewals too
+	Active    *bool  `json:"active"`    // Defaults to true
+}
+
+// Bind parses and validates the request body
+func (req *SaveCommissionRuleRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	if req.Active == nil {
+		active := true
+		req.Active = &active
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
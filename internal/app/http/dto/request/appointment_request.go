@@ -0,0 +1,81 @@
+package request
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+
+type CreateAppointmentRequest struct {
+	ApplicationID   string  `json:"-" validate:"required,ulid"` // From route params
+	AgentID         string  `json:"-" validate:"required,ulid"` // From auth context
+	Type            string  `json:"type" validate:"required,oneof=embassy_interview biometrics medical document_drop_off"`
+	Location        string  `json:"location" validate:"required,max=150"`
+	Address         *string `json:"address" validate:"omitempty,max=500"`
+	ScheduledAt     string  `json:"scheduled_at" validate:"required,datetime=2006-01-02T15:04"` // Local time at the location
+	Timezone        string  `json:"timezone" validate:"required"`                                // IANA zone, i.e "Africa/Lagos"
+	DurationMinutes int     `json:"duration_minutes" validate:"omitempty,min=5,max=480"`
+	Notes           *string `json:"notes" validate:"omitempty,max=2000"`
+}
+
+// Bind parses and validates the request body
+func (req *CreateAppointmentRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	req.ApplicationID = c.Params("application_id")
+	req.AgentID, _ = c.Locals("user_id").(string)
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	if _, err := time.LoadLocation(req.Timezone); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+type UpdateAppointmentRequest struct {
+	AppointmentID   string  `json:"-" validate:"required,ulid"` // From route params
+	AgentID         string  `json:"-" validate:"required,ulid"` // From auth context
+	Type            *string `json:"type" validate:"omitempty,oneof=embassy_interview biometrics medical document_drop_off"`
+	Location        *string `json:"location" validate:"omitempty,max=150"`
+	Address         *string `json:"address" validate:"omitempty,max=500"`
+	ScheduledAt     *string `json:"scheduled_at" validate:"omitempty,datetime=2006-01-02T15:04"`
+	Timezone        *string `json:"timezone"`
+	DurationMinutes *int    `json:"duration_minutes" validate:"omitempty,min=5,max=480"`
+	Status          *string `json:"status" validate:"omitempty,oneof=scheduled completed missed"`
+	Notes           *string `json:"notes" validate:"omitempty,max=2000"`
+}
+
+// Bind parses and validates the request body
+func (req *UpdateAppointmentRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	req.AppointmentID = c.Params("appointment_id")
+	req.AgentID, _ = c.Locals("user_id").(string)
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package request
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+
+// Export takes the agent queue filters plus a format, i.e ?format=xlsx&status=submitted
+type ExportApplicationsRequest struct {
+	Filters   VisaQueueRequest
+	Format    string `validate:"required,oneof=csv xlsx"`
+	IPAddress string
+}
+
+// Bind parses and validates the query string
+func (req *ExportApplicationsRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	if err := req.Filters.Bind(c, v); err != nil {
+		return err
+	}
+
+	req.Format = c.Query("format", "csv")
+	req.IPAddress = c.IP()
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+type PermissionRequest struct {
+	UserID     string `json:"-" validate:"required,ulid"` // From route params
+	ActorID    string `json:"-" validate:"required,ulid"` // From auth context
+	Permission string `json:"permission" validate:"required,oneof=applications.export_sensitive"`
+	IPAddress  string `json:"-"`
+}
+
+// Bind parses and validates the request body, the permission may come from route params (revoke)
+func (req *PermissionRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	if len(c.Body()) > 0 {
+		// Parse request body into req
+		if err := c.BodyParser(req); err != nil {
+			return err
+		}
+	}
+
+	req.UserID = c.Params("user_id")
+	req.ActorID, _ = c.Locals("user_id").(string)
+	req.IPAddress = c.IP()
+	if permission := c.Params("permission"); permission != "" {
+		req.Permission = permission
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+// Audit log filters, i.e /admin/audit-logs?action=applications.export&page=2
+type FetchAuditLogsRequest struct {
+	ActorID string `query:"actor_id" validate:"omitempty,ulid"`
+	Action  string `query:"action" validate:"omitempty,max=60"`
+	Page    int    `query:"page" validate:"omitempty,min=1"`
+	Limit   int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// Bind parses and validates the query string
+func (req *FetchAuditLogsRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse query string into req
+	if err := c.QueryParser(req); err != nil {
+		return err
+	}
+
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package request
+
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Quote or checkout for an application, i.e ?add_ons=express,cv_review&coupon=STUDENT20 or {"add_ons": ["express"]}
+type ApplicationCheckoutRequest struct {
+	UserID        string   `json:"-" validate:"required,ulid"` // From auth context
+	ApplicationID string   `json:"-" validate:"required,ulid"` // From route params
+	AddOns        []string `json:"add_ons" validate:"omitempty,max=10,dive,required,max=30"`
+	Coupon        string   `json:"coupon" validate:"max=40"`
+	Currency      string   `json:"currency" validate:"omitempty,len=3,uppercase"` // Price book to pay from, defaults by country
+	Country       string   `json:"-"`                                             // Pricing country, from the user or their IP
+}
+
+// Bind parses and validates the request body, or the query string for quotes
+func (req *ApplicationCheckoutRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	if len(c.Body()) > 0 {
+		// Parse request body into req
+		if err := c.BodyParser(req); err != nil {
+			return err
+		}
+	} else {
+		if addOns := c.Query("add_ons"); addOns != "" {
+			req.AddOns = strings.Split(addOns, ",")
+		}
+		req.Coupon = c.Query("coupon")
+		req.Currency = c.Query("currency")
+	}
+
+	req.UserID, _ = c.Locals("user_id").(string)
+	req.ApplicationID = c.Params("application_id")
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+type SaveApplicationPriceRequest struct {
+	Destination string `json:"destination" validate:"required,max=100"` // "*" for every destination
+	VisaType    string `json:"visa_type" validate:"required,max=60"`    // "*" for every visa type
+	Currency    string `json:"currency" validate:"required,len=3,uppercase"`
+	Amount      int64  `json:"amount" validate:"required,min=1"` // Minor units
+}
+
+// Bind parses and validates the request body
+func (req *SaveApplicationPriceRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+type SaveAddOnRequest struct {
+	Code         string `json:"code" validate:"required,max=30,lowercase"`
+	Currency     string `json:"currency" validate:"required,len=3,uppercase"`
+	Name         string `json:"name" validate:"required,max=100"`
+	Amount       int64  `json:"amount" validate:"min=0"` // Minor units
+	PerApplicant bool   `json:"per_applicant"`
+	Active       *bool  `json:"active"` // Defaults to true
+}
+
+// Bind parses and validates the request body
+func (req *SaveAddOnRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	if req.Active == nil {
+		active := true
+		req.Active = &active
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package request
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Create or update a coupon by code, i.e {"code": "STUDENT20", "type": "percentage", "percent_off": 20}
+type SaveCouponRequest struct {
+	Code           string     `json:"code" validate:"required,max=40,alphanum"`
+	Description    string     `json:"description" validate:"max=255"`
+	Type           string     `json:"type" validate:"required,oneof=percentage fixed"`
+	PercentOff     int        `json:"percent_off" validate:"required_if=Type percentage,omitempty,min=1,max=100"`
+	AmountOff      int64      `json:"amount_off" validate:"required_if=Type fixed,omitempty,min=1"` // Minor units
+	Currency       string     `json:"currency" validate:"required_if=Type fixed,omitempty,len=3,uppercase"`
+	AppliesTo      string     `json:"applies_to" validate:"required,oneof=all applications subscriptions"`
+	PlanCodes      []string   `json:"plan_codes" validate:"omitempty,max=20,dive,required,max=40"` // Empty for every plan
+	MaxRedemptions *int       `json:"max_redemptions" validate:"omitempty,min=1"`                 // Null is unlimited
+	PerUserLimit   *int       `json:"per_user_limit" validate:"omitempty,min=0"`                  // Defaults to 1, 0 is unlimited
+	StartsAt       *time.Time `json:"starts_at"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	Active         *bool      `json:"active"` // Defaults to true
+}
+
+// Bind parses and validates the request body
+func (req *SaveCouponRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	if req.AppliesTo == "" {
+		req.AppliesTo = "all"
+	}
+	if req.Active == nil {
+		active := true
+		req.Active = &active
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	if req.PerUserLimit == nil {
+		limit := 1
+		req.PerUserLimit = &limit
+	} else if *req.PerUserLimit == 0 {
+		req.PerUserLimit = nil
+	}
+
+	return nil
+}
@@ -1,8 +1,35 @@
 package request
 
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
 
 type CreateDocumentRequest struct {
+	UserID            string `json:"-" validate:"omitempty,ulid"` // From auth context
 	VisaApplicationID string `json:"visa_application_id" validate:"required,len=26"`
 	FileType          string `json:"file_type" validate:"required,oneof=passport_photo international_passport bank_statement signed_form transcript cv sop"`
 	FilePath          string `json:"file_path" validate:"required,url"`
-}
\ No newline at end of file
+}
+
+// Bind parses and validates the request body,
+// the application id in the route takes precedence over the body
+func (req *CreateDocumentRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	if applicationID := c.Params("application_id"); applicationID != "" {
+		req.VisaApplicationID = applicationID
+	}
+	req.UserID, _ = c.Locals("user_id").(string)
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
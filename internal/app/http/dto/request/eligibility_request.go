@@ -0,0 +1,61 @@
+package request
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+
+type EligibilityCheckRequest struct {
+	Destination    string   `json:"destination" validate:"required,min=2"`
+	VisaType       string   `json:"visa_type" validate:"required"`
+	Nationality    string   `json:"nationality" validate:"omitempty"`
+	PassportExpiry string   `json:"passport_expiry" validate:"omitempty,datetime=2006-01-02"`
+	TravelDate     string   `json:"travel_date" validate:"omitempty,datetime=2006-01-02"`
+	Funds          *float64 `json:"funds" validate:"omitempty,gte=0"`
+	FundsCurrency  string   `json:"funds_currency" validate:"omitempty,len=3"`
+	EducationLevel string   `json:"education_level" validate:"omitempty,oneof=none secondary diploma bachelors masters doctorate"`
+	HasBeenDenied  *bool    `json:"has_been_denied"`
+}
+
+// Bind parses and validates the request body
+func (req *EligibilityCheckRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+type CreateEligibilityRuleRequest struct {
+	Country  string  `json:"country" validate:"required"` // "*" for every destination
+	VisaType string  `json:"visa_type"`                   // Empty or "*" for every visa type
+	RuleType string  `json:"rule_type" validate:"required,oneof=nationality_allowed nationality_blocked passport_validity min_funds min_education no_prior_denial"`
+	Value    string  `json:"value" validate:"required_unless=RuleType no_prior_denial,max=255"`
+	Currency *string `json:"currency" validate:"omitempty,len=3"`
+	Weight   int     `json:"weight" validate:"omitempty,min=1,max=100"`
+	Blocking bool    `json:"blocking"`
+	Message  string  `json:"message" validate:"required"`
+}
+
+// Bind parses and validates the request body
+func (req *CreateEligibilityRuleRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package request
+
+import (
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// i.e {"key": "visa_applications", "type": "quota", "limit": 3, "reset_period": "month"}
+type SavePlanEntitlementRequest struct {
+	PlanID      uint   `json:"-" validate:"required,min=1"` // From route params
+	Key         string `json:"key" validate:"required,max=60,lowercase"`
+	Type        string `json:"type" validate:"required,oneof=boolean quota limit"`
+	Enabled     *bool  `json:"enabled"`                                   // Defaults to true
+	Limit       *int64 `json:"limit" validate:"omitempty,min=0"`          // Quotas and limits, null is unlimited
+	ResetPeriod string `json:"reset_period" validate:"required_if=Type quota,omitempty,oneof=day week month year lifetime"`
+}
+
+// Bind parses and validates the request body
+func (req *SavePlanEntitlementRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	planID, err := strconv.ParseUint(c.Params("plan_id"), 10, 64)
+	if err != nil {
+		return err
+	}
+	req.PlanID = uint(planID)
+
+	if req.Enabled == nil {
+		enabled := true
+		req.Enabled = &enabled
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package request
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReviewFraudSignalRequest struct {
+	ApplicationID string  `json:"-" validate:"required,ulid"` // From route params
+	SignalID      uint    `json:"-" validate:"required"`      // From route params
+	AgentID       string  `json:"-" validate:"required,ulid"` // From auth context
+	Resolution    string  `json:"resolution" validate:"required,oneof=dismissed confirmed"`
+	Note          *string `json:"note" validate:"omitempty,max=1000"`
+}
+
+// Bind parses and validates the request body
+func (req *ReviewFraudSignalRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	signalID, err := c.ParamsInt("signal_id")
+	if err != nil {
+		return err
+	}
+	req.ApplicationID = c.Params("application_id")
+	req.SignalID = uint(signalID)
+	req.AgentID, _ = c.Locals("user_id").(string)
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package request
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type FetchLedgerBalancesRequest struct {
+	Currency string `query:"currency" validate:"omitempty,len=3,uppercase"`
+	At       string `query:"at" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"` // RFC 3339, defaults to now
+}
+
+// Bind parses and validates the query string
+func (req *FetchLedgerBalancesRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse query string into req
+	if err := c.QueryParser(req); err != nil {
+		return err
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+type ReconcileLedgerRequest struct {
+	Provider string `json:"provider" validate:"required,max=20"`
+	Day      string `json:"day" validate:"required,datetime=2006-01-02"` // UTC day
+}
+
+// Bind parses and validates the request body
+func (req *ReconcileLedgerRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package request
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type FetchPaymentsRequest struct {
+	UserID string `query:"-" validate:"required,ulid"` // From auth context
+	Page   int    `query:"page" validate:"omitempty,min=1"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// Bind parses and validates the query string
+func (req *FetchPaymentsRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse query string into req
+	if err := c.QueryParser(req); err != nil {
+		return err
+	}
+
+	req.UserID, _ = c.Locals("user_id").(string)
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+type CompleteFakePaymentRequest struct {
+	Reference string `query:"reference" validate:"required,max=60"`
+	Outcome   string `query:"outcome" validate:"omitempty,oneof=success failed"`
+}
+
+// Bind parses and validates the query string
+func (req *CompleteFakePaymentRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse query string into req
+	if err := c.QueryParser(req); err != nil {
+		return err
+	}
+
+	if req.Outcome == "" {
+		req.Outcome = "success"
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+type RefundPaymentRequest struct {
+	AdminID   string `json:"-" validate:"required,ulid"`         // From auth context
+	Reference string `json:"-" validate:"required,max=60"`       // From route params
+	Amount    int64  `json:"amount" validate:"min=0"`            // Minor units, 0 refunds what is left
+	Reason    string `json:"reason" validate:"required,max=255"` // Shown to the customer
+}
+
+// Bind parses and validates the request body
+func (req *RefundPaymentRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	req.AdminID, _ = c.Locals("user_id").(string)
+	req.Reference = c.Params("reference")
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
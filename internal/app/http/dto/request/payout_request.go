@@ -0,0 +1,56 @@
+package request
+
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Add or replace the partner's payout account for a currency, i.e {"currency": "NGN", "bank_code": "058", "account_number": "0123456789"}
+type SavePayoutAccountRequest struct {
+	PartnerID     string `json:"-" validate:"required,ulid"` // From auth context
+	Currency      string `json:"currency" validate:"required,len=3,uppercase"`
+	BankCode      string `json:"bank_code" validate:"required,max=20,alphanum"`
+	AccountNumber string `json:"account_number" validate:"required,min=6,max=20,numeric"` // Resolved with the bank, never stored
+}
+
+// Bind parses and validates the request body
+func (req *SavePayoutAccountRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	req.PartnerID, _ = c.Locals("user_id").(string)
+	req.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+// Statement of the partner's earnings and payouts, ?from=2026-10-01&to=2026-10-31
+type FetchPayoutStatementRequest struct {
+	From string `query:"from" validate:"omitempty,datetime=2006-01-02"` // Defaults to the start of this month
+	To   string `query:"to" validate:"omitempty,datetime=2006-01-02"`   // Inclusive, defaults to today
+}
+
+// Bind parses and validates the query string
+func (req *FetchPayoutStatementRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse query string into req
+	if err := c.QueryParser(req); err != nil {
+		return err
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
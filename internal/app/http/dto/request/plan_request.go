@@ -0,0 +1,204 @@
+package request
+
+import (
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PlanFeatureInput struct {
+	Label string `json:"label" validate:"required,max=255"` // i.e "Visa applications"
+	Value string `json:"value" validate:"required,max=255"` // i.e "3 per month"
+}
+
+type PlanPriceInput struct {
+	Currency string `json:"currency" validate:"required,len=3,uppercase"`
+	Amount   int64  `json:"amount" validate:"min=0"` // Minor units
+}
+
+
+type CreatePlanRequest struct {
+	Code         string             `json:"code" validate:"required,max=40,lowercase,excludesall= "` // Stable across versions, i.e "pro"
+	Name         string             `json:"name" validate:"required,max=60"`
+	Description  string             `json:"description" validate:"max=2000"`
+	Amount       int64              `json:"amount" validate:"min=0"`                          // Minor units of Currency
+	Currency     string             `json:"currency" validate:"omitempty,len=3,uppercase"`    // The plan's own price book, the base currency when empty
+	BillingCycle string             `json:"billing_cycle" validate:"required,oneof=weekly monthly quarterly biannually yearly"`
+	SortOrder    int                `json:"sort_order"`
+	TrialDays    int                `json:"trial_days" validate:"min=0,max=90"`
+	Features     []PlanFeatureInput `json:"features" validate:"omitempty,max=50,dive"`
+	Prices       []PlanPriceInput   `json:"prices" validate:"omitempty,max=20,dive"`
+}
+
+// Bind parses and validates the request body
+func (req *CreatePlanRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+// Changes that don't affect what subscribers pay, applied to the version in place
+type UpdatePlanRequest struct {
+	PlanID      uint   `json:"-" validate:"required,min=1"` // From route params
+	Name        string `json:"name" validate:"required,max=60"`
+	Description string `json:"description" validate:"max=2000"`
+}
+
+// Bind parses and validates the request body
+func (req *UpdatePlanRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	if err := bindPlanID(c, &req.PlanID); err != nil {
+		return err
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+// A new version replaces the plan for new subscribers, existing ones keep theirs.
+// Features and entitlements are copied, prices too unless given.
+type CreatePlanVersionRequest struct {
+	PlanID       uint             `json:"-" validate:"required,min=1"` // From route params
+	Name         string           `json:"name" validate:"max=60"`         // Empty keeps the current name
+	Description  *string          `json:"description" validate:"omitempty,max=2000"`
+	Amount       int64            `json:"amount" validate:"min=0"`                       // Minor units
+	Currency     string           `json:"currency" validate:"omitempty,len=3,uppercase"` // Empty keeps the current currency
+	BillingCycle string           `json:"billing_cycle" validate:"required,oneof=weekly monthly quarterly biannually yearly"`
+	TrialDays    *int             `json:"trial_days" validate:"omitempty,min=0,max=90"` // Null keeps the current trial
+	Prices       []PlanPriceInput `json:"prices" validate:"omitempty,max=20,dive"`
+}
+
+// Bind parses and validates the request body
+func (req *CreatePlanVersionRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	if err := bindPlanID(c, &req.PlanID); err != nil {
+		return err
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+// New order of plans, or of a plan's features, i.e {"ids": [3, 1, 2]}
+type ReorderRequest struct {
+	PlanID uint   `json:"-"` // From route params, features only
+	IDs    []uint `json:"ids" validate:"required,min=1,max=100,unique,dive,min=1"`
+}
+
+// Bind parses and validates the request body
+func (req *ReorderRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	if c.Params("plan_id") != "" {
+		if err := bindPlanID(c, &req.PlanID); err != nil {
+			return err
+		}
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+type SavePlanFeatureRequest struct {
+	PlanID    uint `json:"-" validate:"required,min=1"` // From route params
+	FeatureID uint `json:"-"`                           // From route params, 0 creates
+	PlanFeatureInput
+}
+
+// Bind parses and validates the request body
+func (req *SavePlanFeatureRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(&req.PlanFeatureInput); err != nil {
+		return err
+	}
+
+	if err := bindPlanID(c, &req.PlanID); err != nil {
+		return err
+	}
+	if featureID := c.Params("feature_id"); featureID != "" {
+		id, err := strconv.ParseUint(featureID, 10, 64)
+		if err != nil {
+			return err
+		}
+		req.FeatureID = uint(id)
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+type SavePlanPriceRequest struct {
+	PlanID uint `json:"-" validate:"required,min=1"` // From route params
+	PlanPriceInput
+}
+
+// Bind parses and validates the request body
+func (req *SavePlanPriceRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(&req.PlanPriceInput); err != nil {
+		return err
+	}
+
+	if err := bindPlanID(c, &req.PlanID); err != nil {
+		return err
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+// Plan ID from route params
+func bindPlanID(c *fiber.Ctx, planID *uint) error {
+	id, err := strconv.ParseUint(c.Params("plan_id"), 10, 64)
+	if err != nil {
+		return err
+	}
+	*planID = uint(id)
+	return nil
+}
@@ -0,0 +1,111 @@
+package request
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SaveRetentionPolicyRequest struct {
+	Status       string `json:"status" validate:"required,oneof=draft pending under_review documents_requested submitted approved rejected"`
+	DocumentDays *int   `json:"document_days" validate:"omitempty,min=1,max=3650"` // Null keeps files indefinitely
+	FormDays     *int   `json:"form_days" validate:"omitempty,min=1,max=3650"`     // Null keeps the form indefinitely
+}
+
+// Bind parses and validates the request body
+func (req *SaveRetentionPolicyRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+type LegalHoldRequest struct {
+	ApplicationID string `json:"-" validate:"required,ulid"` // From route params
+	ActorID       string `json:"-" validate:"required,ulid"` // From auth context
+	Reason        string `json:"reason" validate:"omitempty,max=255"` // Required when placing a hold
+	IPAddress     string `json:"-"`
+}
+
+// Bind parses and validates the request body, releasing a hold has no body
+func (req *LegalHoldRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	if len(c.Body()) > 0 {
+		// Parse request body into req
+		if err := c.BodyParser(req); err != nil {
+			return err
+		}
+	}
+
+	req.ApplicationID = c.Params("application_id")
+	req.ActorID, _ = c.Locals("user_id").(string)
+	req.IPAddress = c.IP()
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+type StartPurgeRequest struct {
+	ActorID string `json:"-" validate:"required,ulid"` // From auth context
+	DryRun  bool   `json:"dry_run"`                    // Report what would be purged without deleting
+}
+
+// Bind parses and validates the request body
+func (req *StartPurgeRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	if len(c.Body()) > 0 {
+		// Parse request body into req
+		if err := c.BodyParser(req); err != nil {
+			return err
+		}
+	}
+
+	req.ActorID, _ = c.Locals("user_id").(string)
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+type RetentionPageRequest struct {
+	RunID string `query:"-" validate:"omitempty,ulid"` // From route params, purge report only
+	Page  int    `query:"page" validate:"omitempty,min=1"`
+	Limit int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// Bind parses and validates the query string
+func (req *RetentionPageRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse query string into req
+	if err := c.QueryParser(req); err != nil {
+		return err
+	}
+
+	req.RunID = c.Params("run_id")
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
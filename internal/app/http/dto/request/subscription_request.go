@@ -0,0 +1,88 @@
+package request
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SubscribeRequest struct {
+	UserID    string `json:"-" validate:"required,ulid"` // From auth context
+	PlanID    uint   `json:"plan_id" validate:"required,min=1"`
+	Coupon    string `json:"coupon" validate:"max=40"`
+	SkipTrial bool   `json:"skip_trial"`                                    // Pay now on a plan with a trial
+	Currency  string `json:"currency" validate:"omitempty,len=3,uppercase"` // Price book to pay from, defaults by country
+	Country   string `json:"-"`                                             // Pricing country, from the user or their IP
+}
+
+// Bind parses and validates the request body
+func (req *SubscribeRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	req.UserID, _ = c.Locals("user_id").(string)
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+type CancelSubscriptionRequest struct {
+	UserID      string `json:"-" validate:"required,ulid"` // From auth context
+	AtPeriodEnd *bool  `json:"at_period_end"`              // Defaults to true, false ends the plan now
+}
+
+// Bind parses and validates the request body, which may be empty
+func (req *CancelSubscriptionRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	if len(c.Body()) > 0 {
+		// Parse request body into req
+		if err := c.BodyParser(req); err != nil {
+			return err
+		}
+	}
+
+	req.UserID, _ = c.Locals("user_id").(string)
+	if req.AtPeriodEnd == nil {
+		atPeriodEnd := true
+		req.AtPeriodEnd = &atPeriodEnd
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+type ChangePlanRequest struct {
+	UserID string `json:"-" query:"-" validate:"required,ulid"` // From auth context
+	PlanID uint   `json:"plan_id" query:"plan_id" validate:"required,min=1"`
+}
+
+// Bind parses and validates the request body, or the query string of a preview
+func (req *ChangePlanRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request into req
+	parse := c.BodyParser
+	if c.Method() == fiber.MethodGet {
+		parse = c.QueryParser
+	}
+	if err := parse(req); err != nil {
+		return err
+	}
+
+	req.UserID, _ = c.Locals("user_id").(string)
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -12,7 +12,9 @@ type CreateUserRequest struct {
 	Email     string `json:"email" validate:"required,email"`
 	Phone     string `json:"phone" validate:"required,e164"` // e164 complaint - +2348012345678, +447123456789 etc
 	Password  string `json:"password" validate:"required,min=8"` // plain password; hash before saving
-	Role      string `json:"role" validate:"required,oneof=admin user agent"` // customize roles as needed
+	Role      string `json:"role" validate:"required,oneof=admin user agent partner"` // customize roles as needed
+	ReferralCode string `json:"referral_code" validate:"max=40"` // Partner's code, the referral link cookie when empty
+	Country   string `json:"country" validate:"omitempty,iso3166_1_alpha2"` // Prices are shown in its currency, the IP's country when empty
 }
 
 
@@ -0,0 +1,99 @@
+package request
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+
+// Travel details shared by every member of a group
+type SharedTravelRequest struct {
+	Destination      string    `json:"destination" validate:"required,min=2"`
+	VisaType         string    `json:"visa_type" validate:"required"`
+	TravelDate       string    `json:"travel_date" validate:"required"`
+	DurationOfStay   string    `json:"duration_of_stay" validate:"required"`
+	Purpose          string    `json:"purpose" validate:"required"`
+}
+
+type DependentRequest struct {
+	Relationship     string                   `json:"relationship" validate:"required,oneof=spouse child parent sibling other"`
+	FullName         string                   `json:"full_name" validate:"required,min=2,max=100"`
+	HasBeenDenied    bool                     `json:"has_been_denied"`
+	PersonalInfo     PersonalInfoRequest      `json:"personal_info" validate:"required"`
+	EmergencyContact *EmergencyContactRequest `json:"emergency_contact" validate:"omitempty"`
+}
+
+type CreateVisaGroupRequest struct {
+	UserID                 string              `json:"-" validate:"required,ulid"` // From auth context
+	PrincipalApplicationID string              `json:"principal_application_id" validate:"required,ulid"`
+	Travel                 SharedTravelRequest `json:"travel" validate:"required"`
+	Dependents             []DependentRequest  `json:"dependents" validate:"omitempty,max=10,dive"`
+}
+
+func (req *CreateVisaGroupRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	req.UserID, _ = c.Locals("user_id").(string)
+
+	// Validate request struct (nested structs and dependents included)
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type AddDependentRequest struct {
+	GroupID   string           `json:"-" validate:"required,ulid"` // From route params
+	UserID    string           `json:"-" validate:"required,ulid"` // From auth context
+	Dependent DependentRequest `json:"dependent" validate:"required"`
+}
+
+func (req *AddDependentRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	req.GroupID = c.Params("group_id")
+	req.UserID, _ = c.Locals("user_id").(string)
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+/*
+{
+  "principal_application_id": "01JXYZM4T8HR8PQKJS6E4X2C1Z",
+  "travel": {
+	"destination": "Canada",
+	"visa_type": "Work",
+	"travel_date": "2026-01-15",
+	"duration_of_stay": "2 years",
+	"purpose": "Relocation"
+  },
+  "dependents": [
+	{
+	  "relationship": "spouse",
+	  "full_name": "Jane Doe",
+	  "has_been_denied": false,
+	  "personal_info": {
+		"passport_number": "B12345678",
+		"passport_expiry": "2030-05-31",
+		"residential_address": "123 Lagos Street, Abuja",
+		"nationality": "Nigerian",
+		"marital_status": "Married",
+		"date_of_birth": "1994-02-10"
+	  }
+	}
+  ]
+}
+*/
@@ -0,0 +1,64 @@
+package request
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+
+// Agent queue filters, i.e /agent/visa/applications?status=pending&sla=breached&page=2
+type VisaQueueRequest struct {
+	AgentID     string `query:"-" validate:"required,ulid"` // From auth context
+	Status      string `query:"status" validate:"omitempty,oneof=pending under_review documents_requested submitted approved rejected"`
+	Assigned    string `query:"assigned" validate:"omitempty,oneof=me unassigned any"`
+	Destination string `query:"destination" validate:"omitempty,max=60"`
+	SLA         string `query:"sla" validate:"omitempty,oneof=ok at_risk breached"`
+	Flagged     bool   `query:"flagged"` // Only applications with open fraud signals
+	Page        int    `query:"page" validate:"omitempty,min=1"`
+	Limit       int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// Bind parses and validates the query string
+func (req *VisaQueueRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse query string into req
+	if err := c.QueryParser(req); err != nil {
+		return err
+	}
+
+	req.AgentID, _ = c.Locals("user_id").(string)
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+type SaveSLATargetRequest struct {
+	PlanID      *uint  `json:"plan_id"` // Null for the default target
+	Status      string `json:"status" validate:"required,oneof=pending under_review documents_requested submitted"`
+	TargetHours int    `json:"target_hours" validate:"required,min=1,max=8760"`
+}
+
+// Bind parses and validates the request body
+func (req *SaveSLATargetRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -2,6 +2,8 @@ package request
 
 import (
 	"errors"
+	"encoding/json"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 )
@@ -38,7 +40,7 @@ type VisaFormInputRequest struct {
 }
 
 type CreateVisaApplicationRequest struct {
-	UserID  string    `json:"user_id" validate:"required,ulid"`
+	UserID  string    `json:"-" validate:"required,ulid"` // From auth context
 
 	// Optional fields
 	VisaFormInput *VisaFormInputRequest `json:"visa_form_input"`
@@ -52,6 +54,14 @@ func (req *CreateVisaApplicationRequest) Bind(c *fiber.Ctx, v *validator.Validat
 		return err
 	}
 
+	req.UserID, _ = c.Locals("user_id").(string)
+
+	return req.Validate(v)
+}
+
+// Validate applies the full submission rules.
+// Split from Bind so drafts can be validated on submit.
+func (req *CreateVisaApplicationRequest) Validate(v *validator.Validate) error {
 	// Validate top-level fields
 	if err := v.Struct(req); err != nil {
 		return err
@@ -80,6 +90,143 @@ func (req *CreateVisaApplicationRequest) Bind(c *fiber.Ctx, v *validator.Validat
 }
 
 
+// Drafts accept any partial form input, full validation happens on submit
+type CreateVisaDraftRequest struct {
+	UserID        string          `json:"-" validate:"required,ulid"` // From auth context
+	VisaFormInput json.RawMessage `json:"visa_form_input"`
+	VisaFormURL   *string         `json:"visa_form_url" validate:"omitempty,url"`
+}
+
+func (req *CreateVisaDraftRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	req.UserID, _ = c.Locals("user_id").(string)
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	// Partial input must still be a JSON object
+	if len(req.VisaFormInput) > 0 && !isJSONObject(req.VisaFormInput) {
+		return errors.New("visa_form_input must be a JSON object")
+	}
+
+	return nil
+}
+
+// Autosave payload, visa_form_input is a JSON merge patch (RFC 7386)
+type UpdateVisaDraftRequest struct {
+	ApplicationID string          `json:"-" validate:"required,ulid"` // From route params
+	UserID        string          `json:"-" validate:"required,ulid"` // From auth context
+	Version       uint            `json:"version" validate:"required"` // Version the client last saw
+	VisaFormInput json.RawMessage `json:"visa_form_input"`
+	VisaFormURL   *string         `json:"visa_form_url" validate:"omitempty,url"`
+}
+
+func (req *UpdateVisaDraftRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	req.ApplicationID = c.Params("application_id")
+	req.UserID, _ = c.Locals("user_id").(string)
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	if len(req.VisaFormInput) > 0 && !isJSONObject(req.VisaFormInput) {
+		return errors.New("visa_form_input must be a JSON merge patch object")
+	}
+
+	return nil
+}
+
+type SubmitVisaDraftRequest struct {
+	ApplicationID string `json:"-" validate:"required,ulid"` // From route params
+	UserID        string `json:"-" validate:"required,ulid"` // From auth context
+	Version       uint   `json:"version" validate:"required"`
+}
+
+func (req *SubmitVisaDraftRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	req.ApplicationID = c.Params("application_id")
+	req.UserID, _ = c.Locals("user_id").(string)
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func isJSONObject(raw json.RawMessage) bool {
+	var object map[string]any
+	return json.Unmarshal(raw, &object) == nil && object != nil
+}
+
+type UpdateVisaStatusRequest struct {
+	ApplicationID string  `json:"-" validate:"required,ulid"` // From route params
+	AgentID       string  `json:"-" validate:"required,ulid"` // From auth context
+	Status        string  `json:"status" validate:"required,oneof=under_review submitted approved rejected"`
+	Feedback      *string `json:"feedback" validate:"omitempty,max=2000"`
+}
+
+func (req *UpdateVisaStatusRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	// Route params and auth context
+	req.ApplicationID = c.Params("application_id")
+	req.AgentID, _ = c.Locals("user_id").(string)
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type RequestDocumentsRequest struct {
+	ApplicationID string   `json:"-" validate:"required,ulid"` // From route params
+	AgentID       string   `json:"-" validate:"required,ulid"` // From auth context
+	Documents     []string `json:"documents" validate:"required,min=1,dive,oneof=passport_photo international_passport bank_statement signed_form transcript cv sop"`
+	Message       *string  `json:"message" validate:"omitempty,max=2000"`
+}
+
+func (req *RequestDocumentsRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	// Route params and auth context
+	req.ApplicationID = c.Params("application_id")
+	req.AgentID, _ = c.Locals("user_id").(string)
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
 /*
 {
   "user_id": "user_id",
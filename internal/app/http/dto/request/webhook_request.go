@@ -0,0 +1,112 @@
+package request
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+
+type CreateWebhookEndpointRequest struct {
+	OwnerID     string   `json:"-"` // Partner from auth context, empty for platform endpoints
+	URL         string   `json:"url" validate:"required,http_url,max=500"`
+	Events      []string `json:"events" validate:"required,min=1,dive,required"` // Event types or "*"
+	Description *string  `json:"description" validate:"omitempty,max=255"`
+}
+
+// Bind parses and validates the request body
+func (req *CreateWebhookEndpointRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+type UpdateWebhookEndpointRequest struct {
+	EndpointID  string   `json:"-" validate:"required,ulid"` // From route params
+	OwnerID     string   `json:"-"`                          // Partner from auth context, empty for admins
+	URL         *string  `json:"url" validate:"omitempty,http_url,max=500"`
+	Events      []string `json:"events" validate:"omitempty,min=1,dive,required"`
+	Description *string  `json:"description" validate:"omitempty,max=255"`
+	Active      *bool    `json:"active"`
+}
+
+// Bind parses and validates the request body
+func (req *UpdateWebhookEndpointRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	req.EndpointID = c.Params("endpoint_id")
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+// Delivery log filters, i.e ?status=dead&page=2
+type FetchWebhookDeliveriesRequest struct {
+	EndpointID string `query:"-" validate:"required,ulid"` // From route params
+	OwnerID    string `query:"-"`                          // Partner from auth context, empty for admins
+	Status     string `query:"status" validate:"omitempty,oneof=pending succeeded dead"`
+	Page       int    `query:"page" validate:"omitempty,min=1"`
+	Limit      int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// Bind parses and validates the query string
+func (req *FetchWebhookDeliveriesRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse query string into req
+	if err := c.QueryParser(req); err != nil {
+		return err
+	}
+
+	req.EndpointID = c.Params("endpoint_id")
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
+type SetReferrerRequest struct {
+	ApplicationID string  `json:"-" validate:"required,ulid"` // From route params
+	ReferrerID    *string `json:"referrer_id" validate:"omitempty,ulid"` // Null clears the referrer
+}
+
+// Bind parses and validates the request body
+func (req *SetReferrerRequest) Bind(c *fiber.Ctx, v *validator.Validate) error {
+	// Parse request body into req
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	req.ApplicationID = c.Params("application_id")
+
+	// Validate request struct
+	if err := v.Struct(req); err != nil {
+		return err
+	}
+
+	return nil
+}
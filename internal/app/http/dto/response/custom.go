@@ -38,4 +38,54 @@ func Banned(c *fiber.Ctx, bannedUntil time.Time, banReason string) error {
 			},
 		},
 	})
-}
\ No newline at end of file
+}
+
+func NotEligible(c *fiber.Ctx, details string, result any) error {
+	return c.Status(fiber.StatusUnprocessableEntity).JSON(map[string]any{
+		"message": "Applicant is not eligible",
+		"status":  "failed",
+		"data":    result,
+		"error": map[string]any{
+			"code":    apperror.ErrCodeNotEligible,
+			"message": "Applicant is not eligible",
+			"details": details,
+		},
+	})
+}
+func InvalidFields(c *fiber.Ctx, fields apperror.ValidationErrors) error {
+	return c.Status(fiber.StatusUnprocessableEntity).JSON(map[string]any{
+		"message": "Invalid input",
+		"status":  "failed",
+		"data":    map[string]any{},
+		"error": map[string]any{
+			"code":    apperror.ErrCodeValidation,
+			"message": "Invalid input",
+			"details": fields.Error(),
+			"fields":  fields,
+		},
+	})
+}
+
+func PaymentFailed(c *fiber.Ctx, details string) error {
+	return c.Status(fiber.StatusPaymentRequired).JSON(map[string]any{
+		"message": "Payment failed",
+		"status":  "failed",
+		"error": map[string]any{
+			"code":    apperror.ErrCodePaymentFailed,
+			"message": "Payment failed",
+			"details": details,
+		},
+	})
+}
+
+func PaymentRequired(c *fiber.Ctx, details string) error {
+	return c.Status(fiber.StatusPaymentRequired).JSON(map[string]any{
+		"message": "Payment required",
+		"status":  "failed",
+		"error": map[string]any{
+			"code":    apperror.ErrCodePaymentRequired,
+			"message": "Payment required",
+			"details": details,
+		},
+	})
+}
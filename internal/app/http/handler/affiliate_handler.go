@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"time"
+	"context"
+	"errors"
+	"strconv"
+
+	"japa/internal/app/http/dto/apperror"
+	"japa/internal/app/http/dto/request"
+	"japa/internal/app/http/dto/response"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// TYPES
+
+// Affiliate handler
+type AffiliateHandler struct {
+	Validator *validator.Validate
+	Usecase   *usecase.AffiliateUsecase
+}
+
+// METHODS
+
+// Initialize Affiliate handler
+func NewAffiliateHandler(v *validator.Validate, uc *usecase.AffiliateUsecase) *AffiliateHandler {
+	return &AffiliateHandler{v, uc}
+}
+
+// Referral link, api/v1/r/ADA2025. Counts the visit, keeps the code in a cookie for registration
+// and redirects to the signup page. Unknown codes still land there, unattributed.
+func (ah *AffiliateHandler) TrackClick(c *fiber.Ctx) error {
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	referralCode, err := ah.Usecase.TrackClick(ctx, c.Params("code"))
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+		}
+		return c.Redirect(ah.Usecase.LandingURL(""), fiber.StatusFound)
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     ah.Usecase.Config.CookieName,
+		Value:    referralCode.Code,
+		Path:     "/",
+		Expires:  time.Now().Add(ah.Usecase.Config.CookieTTL),
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	return c.Redirect(ah.Usecase.LandingURL(referralCode.Code), fiber.StatusFound)
+}
+
+// Partner handler listing their referral codes
+func (ah *AffiliateHandler) FetchCodes(c *fiber.Ctx) error {
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	codes, signups, err := ah.Usecase.FetchCodes(ctx, affiliatePartner(c))
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	items := make([]map[string]any, len(codes))
+	for i := range codes {
+		items[i] = ah.referralCodePayload(&codes[i], signups[codes[i].ID])
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": items,
+	})
+}
+
+// Partner handler creating a referral code
+func (ah *AffiliateHandler) CreateCode(c *fiber.Ctx) error {
+	var reqBody request.CreateReferralCodeRequest
+	if err := reqBody.Bind(c, ah.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	referralCode, err := ah.Usecase.CreateCode(ctx, reqBody)
+	if err != nil {
+		return affiliateErrorResponse(c, err)
+	}
+
+	return response.Created(c, ah.referralCodePayload(referralCode, 0))
+}
+
+// Partner handler relabeling, pausing or resuming a referral code
+func (ah *AffiliateHandler) UpdateCode(c *fiber.Ctx) error {
+	var reqBody request.UpdateReferralCodeRequest
+	if err := reqBody.Bind(c, ah.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	referralCode, err := ah.Usecase.UpdateCode(ctx, reqBody)
+	if err != nil {
+		return affiliateErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Referral code updated", ah.referralCodePayload(referralCode, 0))
+}
+
+// Partner handler returning clicks, signups, conversions and earnings
+func (ah *AffiliateHandler) FetchStats(c *fiber.Ctx) error {
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stats, err := ah.Usecase.FetchStats(ctx, affiliatePartner(c))
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	codes := make([]map[string]any, len(stats.Codes))
+	for i := range stats.Codes {
+		codes[i] = ah.referralCodePayload(&stats.Codes[i], stats.CodeSignups[stats.Codes[i].ID])
+	}
+	earnings := make([]map[string]any, len(stats.Earnings))
+	for i, totals := range stats.Earnings {
+		earnings[i] = map[string]any{
+			"currency":  totals.Currency,
+			"earned":    totals.Earned,
+			"reversed":  totals.Reversed,
+			"pending":   totals.Pending,   // Still in the hold period
+			"available": totals.Available,
+		}
+	}
+
+	return response.Success(c, "", map[string]any{
+		"clicks":      stats.Clicks,
+		"signups":     stats.Signups,
+		"conversions": stats.Conversions,
+		"codes":       codes,
+		"earnings":    earnings,
+	})
+}
+
+// Partner handler listing their commission ledger, ?page=1&limit=20
+func (ah *AffiliateHandler) FetchCommissions(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	commissions, total, err := ah.Usecase.FetchCommissions(ctx, affiliatePartner(c), page, limit)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	items := make([]map[string]any, len(commissions))
+	for i, commission := range commissions {
+		items[i] = map[string]any{
+			"id":           commission.ID,
+			"kind":         commission.Kind,
+			"product":      commission.Product,
+			"payment_id":   commission.PaymentID,
+			"reversal_of":  commission.ReversalOf,
+			"base":         commission.Base,
+			"amount":       commission.Amount,
+			"currency":     commission.Currency,
+			"available_at": commission.AvailableAt,
+			"created_at":   commission.CreatedAt,
+		}
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": items,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// Admin handler listing commission rules
+func (ah *AffiliateHandler) FetchRules(c *fiber.Ctx) error {
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rules, err := ah.Usecase.FetchRules(ctx)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": rules,
+	})
+}
+
+// Admin handler to create or update the commission rule for a product
+func (ah *AffiliateHandler) SaveRule(c *fiber.Ctx) error {
+	var reqBody request.SaveCommissionRuleRequest
+	if err := reqBody.Bind(c, ah.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rule, err := ah.Usecase.SaveRule(ctx, reqBody)
+	if err != nil {
+		return affiliateErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Commission rule saved", map[string]any{
+		"item": rule,
+	})
+}
+
+// Admin handler to delete a commission rule
+func (ah *AffiliateHandler) DeleteRule(c *fiber.Ctx) error {
+	ruleID, err := strconv.ParseUint(c.Params("rule_id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid rule id",
+			err.Error(),
+		))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ah.Usecase.DeleteRule(ctx, uint(ruleID)); err != nil {
+		return affiliateErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Commission rule deleted")
+}
+
+// Referral code response shape, with its shareable link
+func (ah *AffiliateHandler) referralCodePayload(referralCode *entity.ReferralCode, signups int64) map[string]any {
+	return map[string]any{
+		"id":         referralCode.ID,
+		"code":       referralCode.Code,
+		"label":      referralCode.Label,
+		"active":     referralCode.Active,
+		"link":       ah.Usecase.SiteConfig.SiteDomain + "/api/v1/r/" + referralCode.Code,
+		"clicks":     referralCode.Clicks,
+		"signups":    signups,
+		"created_at": referralCode.CreatedAt,
+	}
+}
+
+// Partners see their own program, admins any partner's with ?partner_id=
+func affiliatePartner(c *fiber.Ctx) string {
+	userID, _ := c.Locals("user_id").(string)
+	if role, _ := c.Locals("role").(string); role != "partner" && c.Query("partner_id") != "" {
+		return c.Query("partner_id")
+	}
+	return userID
+}
+
+// Maps affiliate usecase errors to responses
+func affiliateErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, apperror.New(
+			apperror.ErrCodeRecordNotFound,
+			"Referral code or rule not found",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrReferralCodeTaken):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeAlreadyExists,
+			"This referral code is taken",
+			err.Error(),
+		))
+	default:
+		return response.InternalServerError(c, apperror.New(
+			apperror.ErrCodeDatabase,
+			"Failed to save",
+			err.Error(),
+		))
+	}
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"time"
+	"context"
+	"errors"
+
+	"japa/internal/app/http/dto/apperror"
+	"japa/internal/app/http/dto/request"
+	"japa/internal/app/http/dto/response"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// TYPES
+
+// Appointment handler
+type AppointmentHandler struct {
+	Validator *validator.Validate
+	Usecase   *usecase.AppointmentUsecase
+}
+
+// METHODS
+
+// Initialize Appointment handler
+func NewAppointmentHandler(v *validator.Validate, uc *usecase.AppointmentUsecase) *AppointmentHandler {
+	return &AppointmentHandler{v, uc}
+}
+
+// Agent handler to book an appointment for an application
+func (ah *AppointmentHandler) CreateAppointment(c *fiber.Ctx) error {
+	var reqBody request.CreateAppointmentRequest
+	if err := reqBody.Bind(c, ah.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	appointment, err := ah.Usecase.CreateAppointment(ctx, reqBody)
+	if err != nil {
+		return appointmentErrorResponse(c, err)
+	}
+
+	return response.Created(c, appointmentPayload(appointment))
+}
+
+// Agent handler to update or reschedule an appointment
+func (ah *AppointmentHandler) UpdateAppointment(c *fiber.Ctx) error {
+	var reqBody request.UpdateAppointmentRequest
+	if err := reqBody.Bind(c, ah.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	appointment, err := ah.Usecase.UpdateAppointment(ctx, reqBody)
+	if err != nil {
+		return appointmentErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Appointment updated", appointmentPayload(appointment))
+}
+
+// Agent handler to cancel an appointment
+func (ah *AppointmentHandler) CancelAppointment(c *fiber.Ctx) error {
+	appointmentID := c.Params("appointment_id")
+	if _, err := ulid.Parse(appointmentID); err != nil {
+		return response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid appointment id format",
+			err.Error(),
+		))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ah.Usecase.CancelAppointment(ctx, appointmentID); err != nil {
+		return appointmentErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Appointment cancelled")
+}
+
+// Agent handler listing an application's appointments
+func (ah *AppointmentHandler) FetchForAgent(c *fiber.Ctx) error {
+	return ah.fetchForApplication(c, "")
+}
+
+// Applicant handler listing their application's appointments
+func (ah *AppointmentHandler) FetchForApplicant(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	return ah.fetchForApplication(c, userID)
+}
+
+func (ah *AppointmentHandler) fetchForApplication(c *fiber.Ctx, userID string) error {
+	applicationID := c.Params("application_id")
+	if _, err := ulid.Parse(applicationID); err != nil {
+		return response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid application id format",
+			err.Error(),
+		))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	appointments, err := ah.Usecase.FetchForApplication(ctx, userID, applicationID)
+	if err != nil {
+		return visaErrorResponse(c, err)
+	}
+
+	payload := make([]map[string]any, len(appointments))
+	for i := range appointments {
+		payload[i] = appointmentPayload(&appointments[i])
+	}
+
+	return response.Success(c, "", map[string]any{
+		"appointments": payload,
+	})
+}
+
+// Applicant handler to download an appointment as an .ics file
+func (ah *AppointmentHandler) DownloadICS(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	appointmentID := c.Params("appointment_id")
+	if _, err := ulid.Parse(appointmentID); err != nil {
+		return response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid appointment id format",
+			err.Error(),
+		))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ics, err := ah.Usecase.FetchICS(ctx, userID, appointmentID)
+	if err != nil {
+		return appointmentErrorResponse(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="appointment.ics"`)
+	return c.Send(ics)
+}
+
+// Appointment response shape
+func appointmentPayload(appointment *entity.Appointment) map[string]any {
+	return map[string]any{
+		"id":                  appointment.ID,
+		"visa_application_id": appointment.VisaApplicationID,
+		"type":                appointment.Type,
+		"location":            appointment.Location,
+		"address":             appointment.Address,
+		"scheduled_at":        appointment.ScheduledAt,
+		"local_time":          appointment.LocalTime().Format("2006-01-02T15:04"),
+		"timezone":            appointment.Timezone,
+		"duration_minutes":    appointment.DurationMinutes,
+		"status":              appointment.Status,
+		"notes":               appointment.Notes,
+	}
+}
+
+// Maps appointment usecase errors to responses
+func appointmentErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, apperror.New(
+			apperror.ErrCodeRecordNotFound,
+			"Appointment not found",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrInvalidStatusTransition):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeInvalidApplication,
+			"Appointment has been cancelled",
+			err.Error(),
+		))
+	default:
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+}
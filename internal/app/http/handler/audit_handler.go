@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"time"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"japa/internal/app/http/dto/apperror"
+	"japa/internal/app/http/dto/request"
+	"japa/internal/app/http/dto/response"
+	"japa/internal/domain/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// TYPES
+
+// Audit handler (exports, audit logs and permissions)
+type AuditHandler struct {
+	Validator *validator.Validate
+	Usecase   *usecase.AuditUsecase
+	Export    *usecase.ExportUsecase
+}
+
+// METHODS
+
+// Initialize Audit handler
+func NewAuditHandler(v *validator.Validate, uc *usecase.AuditUsecase, export *usecase.ExportUsecase) *AuditHandler {
+	return &AuditHandler{v, uc, export}
+}
+
+// Agent/admin handler streaming the queue as CSV or XLSX
+// i.e /agent/visa/applications/export?format=xlsx&status=submitted
+func (ah *AuditHandler) ExportApplications(c *fiber.Ctx) error {
+	var reqBody request.ExportApplicationsRequest
+	if err := reqBody.Bind(c, ah.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Audited before a single row goes out
+	job, err := ah.Export.PrepareExport(ctx, reqBody)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	c.Set(fiber.HeaderContentType, job.ContentType)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+job.Filename+`"`)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ah.Export.StreamExport(job, w, 5*time.Minute)
+		w.Flush()
+	})
+
+	return nil
+}
+
+// Admin handler listing audit logs
+func (ah *AuditHandler) FetchLogs(c *fiber.Ctx) error {
+	var reqBody request.FetchAuditLogsRequest
+	if err := reqBody.Bind(c, ah.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	logs, total, err := ah.Usecase.FetchLogs(ctx, reqBody)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	items := make([]map[string]any, len(logs))
+	for i, log := range logs {
+		items[i] = map[string]any{
+			"id":            log.ID,
+			"actor_id":      log.ActorID,
+			"action":        log.Action,
+			"resource_type": log.ResourceType,
+			"resource_id":   log.ResourceID,
+			"metadata":      json.RawMessage(log.Metadata),
+			"ip_address":    log.IPAddress,
+			"created_at":    log.CreatedAt,
+		}
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": items,
+		"total": total,
+	})
+}
+
+// Superadmin handler listing a user's permissions
+func (ah *AuditHandler) FetchPermissions(c *fiber.Ctx) error {
+	userID := c.Params("user_id")
+	if _, err := ulid.Parse(userID); err != nil {
+		return response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid user id format",
+			err.Error(),
+		))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	permissions, err := ah.Usecase.FetchPermissions(ctx, userID)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": permissions,
+	})
+}
+
+// Superadmin handler granting a permission
+func (ah *AuditHandler) GrantPermission(c *fiber.Ctx) error {
+	var reqBody request.PermissionRequest
+	if err := reqBody.Bind(c, ah.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ah.Usecase.GrantPermission(ctx, reqBody); err != nil {
+		return permissionErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Permission granted")
+}
+
+// Superadmin handler revoking a permission
+func (ah *AuditHandler) RevokePermission(c *fiber.Ctx) error {
+	var reqBody request.PermissionRequest
+	if err := reqBody.Bind(c, ah.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ah.Usecase.RevokePermission(ctx, reqBody); err != nil {
+		return permissionErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Permission revoked")
+}
+
+// Maps permission usecase errors to responses
+func permissionErrorResponse(c *fiber.Ctx, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return response.NotFound(c, apperror.New(
+			apperror.ErrCodeRecordNotFound,
+			"User or permission not found",
+			err.Error(),
+		))
+	}
+	return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+}
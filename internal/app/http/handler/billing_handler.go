@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"time"
+	"context"
+	"errors"
+	"strconv"
+
+	"japa/internal/app/http/dto/apperror"
+	"japa/internal/app/http/dto/request"
+	"japa/internal/app/http/dto/response"
+	"japa/internal/domain/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// TYPES
+
+// Billing handler
+type BillingHandler struct {
+	Validator *validator.Validate
+	Usecase   *usecase.BillingUsecase
+}
+
+// METHODS
+
+// Initialize Billing handler
+func NewBillingHandler(v *validator.Validate, uc *usecase.BillingUsecase) *BillingHandler {
+	return &BillingHandler{v, uc}
+}
+
+// Handler pricing a draft before checkout
+func (bh *BillingHandler) Quote(c *fiber.Ctx) error {
+	var reqBody request.ApplicationCheckoutRequest
+	if err := reqBody.Bind(c, bh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+	reqBody.Country = pricingCountry(c)
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	quote, err := bh.Usecase.Quote(ctx, reqBody)
+	if err != nil {
+		return billingErrorResponse(c, err)
+	}
+
+	return response.Success(c, "", quotePayload(quote))
+}
+
+// Handler starting the checkout for a draft, the applicant pays on the returned authorization_url
+func (bh *BillingHandler) Checkout(c *fiber.Ctx) error {
+	var reqBody request.ApplicationCheckoutRequest
+	if err := reqBody.Bind(c, bh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+	reqBody.Country = pricingCountry(c)
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	quote, payment, err := bh.Usecase.Checkout(ctx, reqBody)
+	if err != nil {
+		return billingErrorResponse(c, err)
+	}
+
+	data := quotePayload(quote)
+	data["payment"] = paymentPayload(payment)
+	return response.Created(c, data)
+}
+
+// Handler listing the add-ons applicants can buy
+func (bh *BillingHandler) FetchAddOns(c *fiber.Ctx) error {
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	addOns, err := bh.Usecase.FetchAddOns(ctx, false, c.Query("currency"), pricingCountry(c))
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": addOns,
+	})
+}
+
+// Admin handler listing every add-on, inactive ones included
+func (bh *BillingHandler) FetchAllAddOns(c *fiber.Ctx) error {
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	addOns, err := bh.Usecase.FetchAddOns(ctx, true, "", "")
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": addOns,
+	})
+}
+
+// Admin handler to create or update an add-on
+func (bh *BillingHandler) SaveAddOn(c *fiber.Ctx) error {
+	var reqBody request.SaveAddOnRequest
+	if err := reqBody.Bind(c, bh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	addOn, err := bh.Usecase.SaveAddOn(ctx, reqBody)
+	if errors.Is(err, usecase.ErrUnsupportedCurrency) {
+		return response.Unprocessable(c, apperror.NewValidationErr(err.Error()))
+	}
+	if err != nil {
+		return response.InternalServerError(c, apperror.New(
+			apperror.ErrCodeDatabase,
+			"Failed to save add-on",
+			err.Error(),
+		))
+	}
+
+	return response.Success(c, "Add-on saved", map[string]any{
+		"item": addOn,
+	})
+}
+
+// Admin handler listing application prices
+func (bh *BillingHandler) FetchPrices(c *fiber.Ctx) error {
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	prices, err := bh.Usecase.FetchPrices(ctx)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": prices,
+	})
+}
+
+// Admin handler to create or update the price for a destination and visa type
+func (bh *BillingHandler) SavePrice(c *fiber.Ctx) error {
+	var reqBody request.SaveApplicationPriceRequest
+	if err := reqBody.Bind(c, bh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	price, err := bh.Usecase.SavePrice(ctx, reqBody)
+	if errors.Is(err, usecase.ErrUnsupportedCurrency) {
+		return response.Unprocessable(c, apperror.NewValidationErr(err.Error()))
+	}
+	if err != nil {
+		return response.InternalServerError(c, apperror.New(
+			apperror.ErrCodeDatabase,
+			"Failed to save price",
+			err.Error(),
+		))
+	}
+
+	return response.Success(c, "Price saved", map[string]any{
+		"item": price,
+	})
+}
+
+// Admin handler to delete a price
+func (bh *BillingHandler) DeletePrice(c *fiber.Ctx) error {
+	priceID, err := strconv.ParseUint(c.Params("price_id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid price id",
+			err.Error(),
+		))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := bh.Usecase.DeletePrice(ctx, uint(priceID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.NotFound(c, apperror.New(
+				apperror.ErrCodeRecordNotFound,
+				"Price not found",
+				err.Error(),
+			))
+		}
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	return response.Success(c, "Price deleted")
+}
+
+// Quote response shape
+func quotePayload(quote *usecase.Quote) map[string]any {
+	lines := make([]map[string]any, len(quote.Lines))
+	for i, line := range quote.Lines {
+		lines[i] = map[string]any{
+			"code":        line.Code,
+			"name":        line.Name,
+			"quantity":    line.Quantity,
+			"unit_amount": line.UnitAmount,
+			"amount":      line.Amount,
+		}
+	}
+
+	return map[string]any{
+		"application_ids": quote.ApplicationIDs,
+		"currency":        quote.Currency,
+		"lines":           lines,
+		"subtotal":        quote.Subtotal,
+		"coupon":          quote.Coupon,
+		"discount":        quote.Discount,
+		"total":           quote.Total,
+	}
+}
+
+// Maps billing usecase errors to responses
+func billingErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, apperror.New(
+			apperror.ErrCodeInvalidApplication,
+			"Application not found",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrNotDraft):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeInvalidApplication,
+			"Application is not a draft",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrAlreadyPaid):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeInvalidApplication,
+			"Application is already paid for",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrCheckoutPending):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeConstraintFailed,
+			"Another checkout for this application is in progress, try again",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrNoPrice):
+		return response.Unprocessable(c, apperror.New(
+			apperror.ErrCodeRecordNotFound,
+			"Application cannot be priced",
+			err.Error(),
+		))
+	case isCouponError(err):
+		return couponErrorResponse(c, err)
+	case errors.Is(err, usecase.ErrUnknownAddOn), errors.Is(err, usecase.ErrIncompleteApplication):
+		return response.Unprocessable(c, apperror.NewValidationErr(err.Error()))
+	case errors.Is(err, usecase.ErrPaymentProvider):
+		return response.PaymentFailed(c, err.Error())
+	default:
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"time"
+	"context"
+	"errors"
+
+	"japa/internal/app/http/dto/apperror"
+	"japa/internal/app/http/dto/request"
+	"japa/internal/app/http/dto/response"
+	"japa/internal/domain/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// TYPES
+
+// Coupon handler
+type CouponHandler struct {
+	Validator *validator.Validate
+	Usecase   *usecase.CouponUsecase
+}
+
+// METHODS
+
+// Initialize Coupon handler
+func NewCouponHandler(v *validator.Validate, uc *usecase.CouponUsecase) *CouponHandler {
+	return &CouponHandler{v, uc}
+}
+
+// Admin handler listing coupons with their redeemed counts
+func (ch *CouponHandler) FetchCoupons(c *fiber.Ctx) error {
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	coupons, redeemed, err := ch.Usecase.FetchCoupons(ctx)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	items := make([]map[string]any, len(coupons))
+	for i, coupon := range coupons {
+		items[i] = map[string]any{
+			"item":     coupon,
+			"redeemed": redeemed[coupon.ID],
+		}
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": items,
+	})
+}
+
+// Admin handler to create or update a coupon
+func (ch *CouponHandler) SaveCoupon(c *fiber.Ctx) error {
+	var reqBody request.SaveCouponRequest
+	if err := reqBody.Bind(c, ch.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	coupon, err := ch.Usecase.SaveCoupon(ctx, reqBody)
+	if err != nil {
+		return response.InternalServerError(c, apperror.New(
+			apperror.ErrCodeDatabase,
+			"Failed to save coupon",
+			err.Error(),
+		))
+	}
+
+	return response.Success(c, "Coupon saved", map[string]any{
+		"item": coupon,
+	})
+}
+
+// Admin handler listing a coupon's redemptions, ?page=1&limit=20
+func (ch *CouponHandler) FetchRedemptions(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	redemptions, total, err := ch.Usecase.FetchRedemptions(ctx, c.Params("code"), page, limit)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.NotFound(c, apperror.New(
+				apperror.ErrCodeRecordNotFound,
+				"Coupon not found",
+				err.Error(),
+			))
+		}
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": redemptions,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// Coupon errors from a checkout
+func isCouponError(err error) bool {
+	return errors.Is(err, usecase.ErrCouponInvalid) ||
+		errors.Is(err, usecase.ErrCouponNotApplicable) ||
+		errors.Is(err, usecase.ErrCouponExhausted) ||
+		errors.Is(err, usecase.ErrCouponUsed)
+}
+
+// Maps coupon errors from a checkout to responses
+func couponErrorResponse(c *fiber.Ctx, err error) error {
+	return response.Unprocessable(c, apperror.New(
+		apperror.ErrCodeInvalidCoupon,
+		"Coupon cannot be used",
+		err.Error(),
+	))
+}
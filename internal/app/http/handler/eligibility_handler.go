@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"time"
+	"context"
+	"errors"
+	"strconv"
+
+	"japa/internal/app/http/dto/apperror"
+	"japa/internal/app/http/dto/request"
+	"japa/internal/app/http/dto/response"
+	"japa/internal/domain/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// TYPES
+
+// Eligibility handler
+type EligibilityHandler struct {
+	Validator *validator.Validate
+	Usecase   *usecase.EligibilityUsecase
+}
+
+// METHODS
+
+// Initialize Eligibility handler
+func NewEligibilityHandler(v *validator.Validate, uc *usecase.EligibilityUsecase) *EligibilityHandler {
+	return &EligibilityHandler{v, uc}
+}
+
+// Public handler to check a profile before paying
+func (eh *EligibilityHandler) Check(c *fiber.Ctx) error {
+	var reqBody request.EligibilityCheckRequest
+	if err := reqBody.Bind(c, eh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	profile := usecase.EligibilityProfile{
+		Destination:    reqBody.Destination,
+		VisaType:       reqBody.VisaType,
+		Nationality:    reqBody.Nationality,
+		Funds:          reqBody.Funds,
+		FundsCurrency:  reqBody.FundsCurrency,
+		EducationLevel: reqBody.EducationLevel,
+		HasBeenDenied:  reqBody.HasBeenDenied,
+	}
+	// Dates were validated by the request binding
+	profile.PassportExpiry, _ = time.Parse("2006-01-02", reqBody.PassportExpiry)
+	profile.TravelDate, _ = time.Parse("2006-01-02", reqBody.TravelDate)
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := eh.Usecase.Evaluate(ctx, profile)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	return response.Success(c, "", map[string]any{
+		"result": result,
+	})
+}
+
+// Admin handler to list rules (?country=Germany)
+func (eh *EligibilityHandler) FetchRules(c *fiber.Ctx) error {
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rules, err := eh.Usecase.FetchRules(ctx, c.Query("country"))
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": rules,
+	})
+}
+
+// Admin handler to create a rule
+func (eh *EligibilityHandler) CreateRule(c *fiber.Ctx) error {
+	var reqBody request.CreateEligibilityRuleRequest
+	if err := reqBody.Bind(c, eh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rule, err := eh.Usecase.CreateRule(ctx, reqBody)
+	if err != nil {
+		return response.InternalServerError(c, apperror.New(
+			apperror.ErrCodeDatabase,
+			"Failed to create rule",
+			err.Error(),
+		))
+	}
+
+	return response.Created(c, map[string]any{
+		"item": rule,
+	})
+}
+
+// Admin handler to delete a rule
+func (eh *EligibilityHandler) DeleteRule(c *fiber.Ctx) error {
+	ruleID, err := strconv.ParseUint(c.Params("rule_id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid rule id",
+			err.Error(),
+		))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := eh.Usecase.DeleteRule(ctx, uint(ruleID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.NotFound(c, apperror.New(
+				apperror.ErrCodeRecordNotFound,
+				"Rule not found",
+				err.Error(),
+			))
+		}
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	return response.Success(c, "Rule deleted")
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"time"
+	"context"
+	"errors"
+	"strconv"
+
+	"japa/internal/app/http/dto/apperror"
+	"japa/internal/app/http/dto/request"
+	"japa/internal/app/http/dto/response"
+	"japa/internal/domain/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// TYPES
+
+// Entitlement handler
+type EntitlementHandler struct {
+	Validator *validator.Validate
+	Usecase   *usecase.EntitlementUsecase
+}
+
+// METHODS
+
+// Initialize Entitlement handler
+func NewEntitlementHandler(v *validator.Validate, uc *usecase.EntitlementUsecase) *EntitlementHandler {
+	return &EntitlementHandler{v, uc}
+}
+
+// Handler returning what the user's plan includes and how much of each quota is used
+func (eh *EntitlementHandler) FetchUsage(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	entitlements, summaries, err := eh.Usecase.Usage(ctx, userID)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	items := make([]map[string]any, len(summaries))
+	for i, summary := range summaries {
+		items[i] = map[string]any{
+			"key":       summary.Key,
+			"type":      summary.Type,
+			"enabled":   summary.Enabled,
+			"limit":     summary.Limit,
+			"used":      summary.Used,
+			"remaining": summary.Remaining,
+			"resets_at": summary.ResetsAt,
+			"summary":   summary.Summary,
+		}
+	}
+
+	return response.Success(c, "", map[string]any{
+		"plan": map[string]any{
+			"id":   entitlements.PlanID,
+			"name": entitlements.PlanName,
+		},
+		"items": items,
+	})
+}
+
+// Admin handler listing a plan's entitlements
+func (eh *EntitlementHandler) FetchPlanEntitlements(c *fiber.Ctx) error {
+	planID, err := strconv.ParseUint(c.Params("plan_id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid plan id",
+			err.Error(),
+		))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	entitlements, err := eh.Usecase.FetchPlanEntitlements(ctx, uint(planID))
+	if err != nil {
+		return entitlementErrorResponse(c, err)
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": entitlements,
+	})
+}
+
+// Admin handler to create or update a plan's entitlement
+func (eh *EntitlementHandler) SaveEntitlement(c *fiber.Ctx) error {
+	var reqBody request.SavePlanEntitlementRequest
+	if err := reqBody.Bind(c, eh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	entitlement, err := eh.Usecase.SaveEntitlement(ctx, reqBody)
+	if err != nil {
+		return entitlementErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Entitlement saved", map[string]any{
+		"item": entitlement,
+	})
+}
+
+// Admin handler to delete a plan's entitlement
+func (eh *EntitlementHandler) DeleteEntitlement(c *fiber.Ctx) error {
+	planID, err := strconv.ParseUint(c.Params("plan_id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid plan id",
+			err.Error(),
+		))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := eh.Usecase.DeleteEntitlement(ctx, uint(planID), c.Params("key")); err != nil {
+		return entitlementErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Entitlement deleted")
+}
+
+// Maps entitlement usecase errors to responses
+func entitlementErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, apperror.New(
+			apperror.ErrCodeRecordNotFound,
+			"Plan or entitlement not found",
+			err.Error(),
+		))
+	default:
+		return response.InternalServerError(c, apperror.New(
+			apperror.ErrCodeDatabase,
+			"Failed to save entitlement",
+			err.Error(),
+		))
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"time"
+	"context"
+	"errors"
+
+	"japa/internal/app/http/dto/apperror"
+	"japa/internal/app/http/dto/request"
+	"japa/internal/app/http/dto/response"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// TYPES
+
+// Fraud signal handler for agent review
+type FraudHandler struct {
+	Validator *validator.Validate
+	Usecase   *usecase.FraudUsecase
+}
+
+// METHODS
+
+// Initialize Fraud handler
+func NewFraudHandler(v *validator.Validate, uc *usecase.FraudUsecase) *FraudHandler {
+	return &FraudHandler{v, uc}
+}
+
+// Agent handler listing an application's signals, reviewed ones included
+func (fh *FraudHandler) FetchSignals(c *fiber.Ctx) error {
+	applicationID := c.Params("application_id")
+	if _, err := ulid.Parse(applicationID); err != nil {
+		return response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid application id format",
+			err.Error(),
+		))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	signals, err := fh.Usecase.FetchSignals(ctx, applicationID)
+	if err != nil {
+		return fraudErrorResponse(c, err)
+	}
+
+	items := make([]map[string]any, len(signals))
+	for i, signal := range signals {
+		items[i] = fraudSignalPayload(signal)
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": items,
+	})
+}
+
+// Agent handler to dismiss or confirm a signal
+func (fh *FraudHandler) ReviewSignal(c *fiber.Ctx) error {
+	var reqBody request.ReviewFraudSignalRequest
+	if err := reqBody.Bind(c, fh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	signal, err := fh.Usecase.ReviewSignal(ctx, reqBody)
+	if err != nil {
+		return fraudErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Fraud signal reviewed", fraudSignalPayload(*signal))
+}
+
+// Signal response shape, also used by the agent queue
+func fraudSignalPayload(signal entity.FraudSignal) map[string]any {
+	return map[string]any{
+		"id":                     signal.ID,
+		"kind":                   signal.Kind,
+		"severity":               signal.Severity,
+		"detail":                 signal.Detail,
+		"related_application_id": signal.RelatedApplicationID,
+		"resolution":             signal.Resolution,
+		"review_note":            signal.ReviewNote,
+		"reviewed_by":            signal.ReviewedBy,
+		"reviewed_at":            signal.ReviewedAt,
+		"created_at":             signal.CreatedAt,
+	}
+}
+
+// Maps fraud usecase errors to responses
+func fraudErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, apperror.New(
+			apperror.ErrCodeRecordNotFound,
+			"Fraud signal not found",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrSignalReviewed):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeAlreadyExists,
+			"Fraud signal already reviewed",
+			err.Error(),
+		))
+	default:
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"time"
+	"context"
+	"errors"
+
+	"japa/internal/app/http/dto/apperror"
+	"japa/internal/app/http/dto/response"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// TYPES
+
+// Invoice handler
+type InvoiceHandler struct {
+	Validator *validator.Validate
+	Usecase   *usecase.InvoiceUsecase
+}
+
+// METHODS
+
+// Initialize Invoice handler
+func NewInvoiceHandler(v *validator.Validate, uc *usecase.InvoiceUsecase) *InvoiceHandler {
+	return &InvoiceHandler{v, uc}
+}
+
+// Handler for the user's billing history, ?page=1&limit=20
+func (ih *InvoiceHandler) FetchInvoices(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	invoices, total, err := ih.Usecase.FetchInvoices(ctx, userID, page, limit)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	items := make([]map[string]any, len(invoices))
+	for i := range invoices {
+		items[i] = invoicePayload(&invoices[i])
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": items,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// Handler to download one of the user's invoices as a PDF
+func (ih *InvoiceHandler) DownloadPDF(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	invoiceID := c.Params("invoice_id")
+	if _, err := ulid.Parse(invoiceID); err != nil {
+		return response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid invoice id format",
+			err.Error(),
+		))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	invoice, pdf, err := ih.Usecase.FetchPDF(ctx, userID, invoiceID)
+	if err != nil {
+		return invoiceErrorResponse(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+invoice.Number+`.pdf"`)
+	return c.Send(pdf)
+}
+
+// Handler to email one of the user's invoices again
+func (ih *InvoiceHandler) Resend(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	invoiceID := c.Params("invoice_id")
+	if _, err := ulid.Parse(invoiceID); err != nil {
+		return response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid invoice id format",
+			err.Error(),
+		))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	invoice, err := ih.Usecase.Resend(ctx, userID, invoiceID)
+	if err != nil {
+		return invoiceErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Invoice sent to "+invoice.BillingEmail)
+}
+
+// Invoice response shape
+func invoicePayload(invoice *entity.Invoice) map[string]any {
+	lines, _ := invoice.LineItems()
+	items := make([]map[string]any, len(lines))
+	for i, line := range lines {
+		items[i] = map[string]any{
+			"description": line.Description,
+			"quantity":    line.Quantity,
+			"unit_amount": line.UnitAmount,
+			"amount":      line.Amount,
+		}
+	}
+
+	return map[string]any{
+		"id":         invoice.ID,
+		"number":     invoice.Number,
+		"payment_id": invoice.PaymentID,
+		"purpose":    invoice.Purpose,
+		"lines":      items,
+		"currency":   invoice.Currency,
+		"subtotal":   invoice.Subtotal,
+		"tax_name":   invoice.TaxName,
+		"tax_rate":   invoice.TaxRate, // Basis points
+		"tax":        invoice.Tax,
+		"total":      invoice.Total,
+		"paid_at":    invoice.PaidAt,
+		"emailed_at": invoice.EmailedAt,
+		"pdf_url":    "/api/v1/account/billing/invoices/" + invoice.ID + "/pdf",
+	}
+}
+
+// Maps invoice usecase errors to responses
+func invoiceErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, apperror.New(
+			apperror.ErrCodeRecordNotFound,
+			"Invoice not found",
+			err.Error(),
+		))
+	default:
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+}
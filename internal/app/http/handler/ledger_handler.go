@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"time"
+	"context"
+	"errors"
+	"strings"
+
+	"japa/internal/app/http/dto/apperror"
+	"japa/internal/app/http/dto/request"
+	"japa/internal/app/http/dto/response"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/usecase"
+	"japa/internal/infrastructure/payment"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TYPES
+
+// Ledger handler
+type LedgerHandler struct {
+	Validator *validator.Validate
+	Usecase   *usecase.LedgerUsecase
+}
+
+// METHODS
+
+// Initialize Ledger handler
+func NewLedgerHandler(v *validator.Validate, uc *usecase.LedgerUsecase) *LedgerHandler {
+	return &LedgerHandler{v, uc}
+}
+
+// Handler for account balances by currency, ?currency=NGN&at=2026-10-01T00:00:00Z
+func (lh *LedgerHandler) FetchBalances(c *fiber.Ctx) error {
+	var reqBody request.FetchLedgerBalancesRequest
+	if err := reqBody.Bind(c, lh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+	at := time.Now()
+	if reqBody.At != "" {
+		at, _ = time.Parse(time.RFC3339, reqBody.At) // Validated
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	balances, err := lh.Usecase.FetchBalances(ctx, at, reqBody.Currency)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	items := make([]map[string]any, len(balances))
+	for i, balance := range balances {
+		items[i] = map[string]any{
+			"account":  balance.Account,
+			"currency": balance.Currency,
+			"debits":   balance.Debits,
+			"credits":  balance.Credits,
+			"balance":  balance.Balance, // Debits less credits, revenue and liabilities are negative
+			"entries":  balance.Entries,
+		}
+	}
+
+	return response.Success(c, "", map[string]any{
+		"at":       at,
+		"balances": items,
+	})
+}
+
+// Handler for an account's entries, ?currency=NGN&page=1&limit=50
+func (lh *LedgerHandler) FetchEntries(c *fiber.Ctx) error {
+	account := c.Params("account")
+	currency := strings.ToUpper(c.Query("currency"))
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 50)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	entries, total, err := lh.Usecase.FetchEntries(ctx, account, currency, page, limit)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	items := make([]map[string]any, len(entries))
+	for i, entry := range entries {
+		items[i] = map[string]any{
+			"id":             entry.ID,
+			"transaction_id": entry.TransactionID,
+			"amount":         entry.Amount, // Debits positive, credits negative
+			"currency":       entry.Currency,
+			"occurred_at":    entry.OccurredAt,
+		}
+		if entry.Transaction != nil {
+			items[i]["kind"] = entry.Transaction.Kind
+			items[i]["source_id"] = entry.Transaction.SourceID
+			items[i]["payment_id"] = entry.Transaction.PaymentID
+			items[i]["description"] = entry.Transaction.Description
+		}
+	}
+
+	return response.Success(c, "", map[string]any{
+		"account": account,
+		"items":   items,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
+
+// Handler for daily reconciliation reports, ?provider=paystack&page=1&limit=30
+func (lh *LedgerHandler) FetchReconciliations(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 30)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 30
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	reports, total, err := lh.Usecase.FetchReconciliations(ctx, c.Query("provider"), page, limit)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	items := make([]map[string]any, len(reports))
+	for i := range reports {
+		items[i] = reconciliationPayload(&reports[i])
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": items,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// Handler for admins to run a day's reconciliation again, i.e after fixing a mismatch
+func (lh *LedgerHandler) Reconcile(c *fiber.Ctx) error {
+	var reqBody request.ReconcileLedgerRequest
+	if err := reqBody.Bind(c, lh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+	day, _ := time.Parse(time.DateOnly, reqBody.Day) // Validated
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	report, err := lh.Usecase.ReconcileDay(ctx, reqBody.Provider, day)
+	if err != nil {
+		return ledgerErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Reconciliation "+report.Status, reconciliationPayload(report))
+}
+
+// Reconciliation report response shape
+func reconciliationPayload(report *entity.LedgerReconciliation) map[string]any {
+	totals, _ := report.TotalsList()
+	totalItems := make([]map[string]any, len(totals))
+	for i, total := range totals {
+		totalItems[i] = map[string]any{
+			"currency":        total.Currency,
+			"provider_count":  total.ProviderCount,
+			"provider_amount": total.ProviderAmount,
+			"provider_fees":   total.ProviderFees,
+			"ledger_count":    total.LedgerCount,
+			"ledger_amount":   total.LedgerAmount,
+			"ledger_fees":     total.LedgerFees,
+		}
+	}
+
+	mismatches, _ := report.MismatchList()
+	mismatchItems := make([]map[string]any, len(mismatches))
+	for i, mismatch := range mismatches {
+		mismatchItems[i] = map[string]any{
+			"reference":       mismatch.Reference,
+			"issue":           mismatch.Issue,
+			"currency":        mismatch.Currency,
+			"provider_amount": mismatch.ProviderAmount,
+			"ledger_amount":   mismatch.LedgerAmount,
+			"provider_fee":    mismatch.ProviderFee,
+			"ledger_fee":      mismatch.LedgerFee,
+		}
+	}
+
+	return map[string]any{
+		"id":         report.ID,
+		"provider":   report.Provider,
+		"day":        report.Day,
+		"status":     report.Status,
+		"totals":     totalItems,
+		"mismatches": mismatchItems,
+		"error":      report.Error,
+		"run_at":     report.RunAt,
+	}
+}
+
+// Maps ledger usecase errors to responses
+func ledgerErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, payment.ErrProviderNotFound), errors.Is(err, payment.ErrSettlementsNotSupported):
+		return response.NotFound(c, apperror.New(
+			apperror.ErrCodeRecordNotFound,
+			"Provider not found or cannot report settlements",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrReconcileTooSoon):
+		return response.Unprocessable(c, apperror.New(
+			apperror.ErrCodeOutOfRange,
+			"Day has not settled yet",
+			err.Error(),
+		))
+	default:
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+}
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"time"
+	"context"
+	"errors"
+
+	"japa/internal/app/http/dto/apperror"
+	"japa/internal/app/http/dto/request"
+	"japa/internal/app/http/dto/response"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/usecase"
+	"japa/internal/infrastructure/payment"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// TYPES
+
+// Payment handler
+type PaymentHandler struct {
+	Validator *validator.Validate
+	Usecase   *usecase.PaymentUsecase
+}
+
+// METHODS
+
+// Initialize Payment handler
+func NewPaymentHandler(v *validator.Validate, uc *usecase.PaymentUsecase) *PaymentHandler {
+	return &PaymentHandler{v, uc}
+}
+
+// Handler listing the user's payments
+func (ph *PaymentHandler) FetchPayments(c *fiber.Ctx) error {
+	var reqBody request.FetchPaymentsRequest
+	if err := reqBody.Bind(c, ph.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	payments, total, err := ph.Usecase.FetchPayments(ctx, reqBody.UserID, reqBody.Page, reqBody.Limit)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	items := make([]map[string]any, len(payments))
+	for i := range payments {
+		items[i] = paymentPayload(&payments[i])
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": items,
+		"total": total,
+		"page":  reqBody.Page,
+		"limit": reqBody.Limit,
+	})
+}
+
+// Handler for the checkout return page, verifies a pending payment with the provider
+func (ph *PaymentHandler) VerifyPayment(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	payment, err := ph.Usecase.Verify(ctx, userID, c.Params("reference"))
+	if err != nil {
+		return paymentErrorResponse(c, err)
+	}
+	if payment.Status == entity.PaymentStatusFailed {
+		var reason string
+		if payment.FailureReason != nil {
+			reason = *payment.FailureReason
+		}
+		return response.PaymentFailed(c, reason)
+	}
+
+	return response.Success(c, "", paymentPayload(payment))
+}
+
+// Handler for the fake provider's checkout page, local development only
+func (ph *PaymentHandler) CompleteFakePayment(c *fiber.Ctx) error {
+	var reqBody request.CompleteFakePaymentRequest
+	if err := reqBody.Bind(c, ph.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	payment, err := ph.Usecase.CompleteFake(ctx, reqBody.Reference, reqBody.Outcome == "success")
+	if err != nil {
+		return paymentErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Fake payment completed", paymentPayload(payment))
+}
+
+// Handler for Paystack callbacks
+func (ph *PaymentHandler) PaystackWebhook(c *fiber.Ctx) error {
+	return ph.handleWebhook(c, "paystack")
+}
+
+// Handler for Flutterwave callbacks
+func (ph *PaymentHandler) FlutterwaveWebhook(c *fiber.Ctx) error {
+	return ph.handleWebhook(c, "flutterwave")
+}
+
+// Verifies and processes a provider callback. Anything but a 2xx makes the provider retry later.
+func (ph *PaymentHandler) handleWebhook(c *fiber.Ctx, provider string) error {
+	// Fiber reuses the request buffer, the raw body is stored as is
+	body := append([]byte(nil), c.Body()...)
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	event, err := ph.Usecase.HandleWebhook(ctx, provider, func(key string) string { return c.Get(key) }, body)
+	if err != nil {
+		return paymentWebhookErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Event received", map[string]any{
+		"id":     event.ID,
+		"status": event.Status,
+	})
+}
+
+// Handler for admins to re-verify a payment with its provider
+func (ph *PaymentHandler) ReconcilePayment(c *fiber.Ctx) error {
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	payment, err := ph.Usecase.Verify(ctx, "", c.Params("reference"))
+	if err != nil {
+		return paymentErrorResponse(c, err)
+	}
+
+	return response.Success(c, "", paymentPayload(payment))
+}
+
+// Handler for admins to refund part or all of a payment through its provider
+func (ph *PaymentHandler) RefundPayment(c *fiber.Ctx) error {
+	var reqBody request.RefundPaymentRequest
+	if err := reqBody.Bind(c, ph.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	refund, err := ph.Usecase.Refund(ctx, reqBody)
+	if err != nil {
+		return paymentErrorResponse(c, err)
+	}
+
+	return response.Created(c, refundPayload(refund))
+}
+
+// Handler for admins to see a payment's refunds and chargeback disputes
+func (ph *PaymentHandler) FetchRefunds(c *fiber.Ctx) error {
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	payment, refunds, disputes, err := ph.Usecase.FetchRefunds(ctx, c.Params("reference"))
+	if err != nil {
+		return paymentErrorResponse(c, err)
+	}
+
+	refundItems := make([]map[string]any, len(refunds))
+	for i := range refunds {
+		refundItems[i] = refundPayload(&refunds[i])
+	}
+	disputeItems := make([]map[string]any, len(disputes))
+	for i, dispute := range disputes {
+		disputeItems[i] = map[string]any{
+			"id":                  dispute.ID,
+			"provider_dispute_id": dispute.ProviderDisputeID,
+			"status":              dispute.Status,
+			"amount":              dispute.Amount,
+			"currency":            dispute.Currency,
+			"reason":              dispute.Reason,
+			"due_at":              dispute.DueAt,
+			"refund_id":           dispute.RefundID,
+			"resolved_at":         dispute.ResolvedAt,
+			"created_at":          dispute.CreatedAt,
+		}
+	}
+
+	return response.Success(c, "", map[string]any{
+		"payment":  paymentPayload(payment),
+		"refunds":  refundItems,
+		"disputes": disputeItems,
+	})
+}
+
+// Payment response shape
+func paymentPayload(payment *entity.Payment) map[string]any {
+	return map[string]any{
+		"id":                payment.ID,
+		"reference":         payment.Reference,
+		"provider":          payment.Provider,
+		"purpose":           payment.Purpose,
+		"amount":            payment.Amount,
+		"currency":          payment.Currency,
+		"status":            payment.Status,
+		"authorization_url": payment.AuthorizationURL,
+		"channel":           payment.Channel,
+		"failure_reason":    payment.FailureReason,
+		"refunded_amount":   payment.RefundedAmount,
+		"refund_status":     payment.RefundStatus,
+		"paid_at":           payment.PaidAt,
+		"created_at":        payment.CreatedAt,
+	}
+}
+
+// Refund response shape
+func refundPayload(refund *entity.Refund) map[string]any {
+	return map[string]any{
+		"id":                 refund.ID,
+		"payment_id":         refund.PaymentID,
+		"kind":               refund.Kind,
+		"provider_refund_id": refund.ProviderRefundID,
+		"amount":             refund.Amount,
+		"currency":           refund.Currency,
+		"reason":             refund.Reason,
+		"status":             refund.Status,
+		"failure_reason":     refund.FailureReason,
+		"initiated_by":       refund.InitiatedBy,
+		"processed_at":       refund.ProcessedAt,
+		"created_at":         refund.CreatedAt,
+	}
+}
+
+// Maps payment usecase errors to responses
+func paymentErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, apperror.New(
+			apperror.ErrCodeRecordNotFound,
+			"Payment not found",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrPaymentProvider), errors.Is(err, usecase.ErrPaymentMismatch):
+		return response.PaymentFailed(c, err.Error())
+	case errors.Is(err, usecase.ErrNotRefundable):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeConstraintFailed,
+			"Payment cannot be refunded",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrRefundExceeds):
+		return response.Unprocessable(c, apperror.New(
+			apperror.ErrCodeOutOfRange,
+			"Refund amount is out of range",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrNoFakeProvider):
+		return response.NotFound(c, apperror.New(
+			apperror.ErrCodeRecordNotFound,
+			"Not found",
+			err.Error(),
+		))
+	default:
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+}
+
+// Maps webhook errors to responses, only server errors ask the provider to redeliver
+func paymentWebhookErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, payment.ErrInvalidSignature):
+		return response.Unauthorized(c, apperror.New(
+			apperror.ErrCodeUnauthorized,
+			"Invalid signature",
+			err.Error(),
+		))
+	case errors.Is(err, payment.ErrProviderNotFound), errors.Is(err, payment.ErrWebhooksNotSupported):
+		return response.NotFound(c, apperror.New(
+			apperror.ErrCodeRecordNotFound,
+			"Not found",
+			err.Error(),
+		))
+	default:
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+}
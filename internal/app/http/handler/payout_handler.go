@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"time"
+	"context"
+	"errors"
+	"strings"
+
+	"japa/internal/app/http/dto/apperror"
+	"japa/internal/app/http/dto/request"
+	"japa/internal/app/http/dto/response"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/usecase"
+	"japa/internal/infrastructure/payment"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// TYPES
+
+// Payout handler
+type PayoutHandler struct {
+	Validator *validator.Validate
+	Usecase   *usecase.PayoutUsecase
+}
+
+// METHODS
+
+// Initialize Payout handler
+func NewPayoutHandler(v *validator.Validate, uc *usecase.PayoutUsecase) *PayoutHandler {
+	return &PayoutHandler{v, uc}
+}
+
+// Partner handler listing their payout accounts and what they can be paid
+func (ph *PayoutHandler) FetchAccounts(c *fiber.Ctx) error {
+	partnerID := affiliatePartner(c)
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	accounts, err := ph.Usecase.FetchAccounts(ctx, partnerID)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+	balances, err := ph.Usecase.FetchBalances(ctx, partnerID)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	items := make([]map[string]any, len(accounts))
+	for i := range accounts {
+		items[i] = payoutAccountPayload(&accounts[i])
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items":    items,
+		"balances": payoutBalancesPayload(balances),
+	})
+}
+
+// Partner handler to add or replace their payout account for a currency, the bank resolves it first
+func (ph *PayoutHandler) SaveAccount(c *fiber.Ctx) error {
+	var reqBody request.SavePayoutAccountRequest
+	if err := reqBody.Bind(c, ph.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	account, err := ph.Usecase.SaveAccount(ctx, reqBody)
+	if err != nil {
+		return payoutErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Payout account saved", payoutAccountPayload(account))
+}
+
+// Partner handler to remove their payout account for a currency
+func (ph *PayoutHandler) DeleteAccount(c *fiber.Ctx) error {
+	partnerID, _ := c.Locals("user_id").(string)
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ph.Usecase.DeleteAccount(ctx, partnerID, strings.ToUpper(c.Params("currency"))); err != nil {
+		return payoutErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Payout account removed")
+}
+
+// Partner handler listing their payouts, ?page=1&limit=20
+func (ph *PayoutHandler) FetchPayouts(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	payouts, total, err := ph.Usecase.FetchPayouts(ctx, affiliatePartner(c), page, limit)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	items := make([]map[string]any, len(payouts))
+	for i := range payouts {
+		items[i] = payoutPayload(&payouts[i])
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": items,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// Partner handler for their statement, ?from=2026-10-01&to=2026-10-31
+func (ph *PayoutHandler) FetchStatement(c *fiber.Ctx) error {
+	var reqBody request.FetchPayoutStatementRequest
+	if err := reqBody.Bind(c, ph.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if reqBody.From != "" {
+		from, _ = time.Parse(time.DateOnly, reqBody.From) // Validated
+	}
+	if reqBody.To != "" {
+		to, _ = time.Parse(time.DateOnly, reqBody.To) // Validated
+	}
+	to = to.AddDate(0, 0, 1) // Inclusive
+	if !from.Before(to) {
+		return response.BadRequest(c, apperror.NewValidationErr("from must not be after to"))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	statement, err := ph.Usecase.Statement(ctx, affiliatePartner(c), from, to)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	lines := make([]map[string]any, len(statement.Lines))
+	for i, line := range statement.Lines {
+		lines[i] = map[string]any{
+			"currency": line.Currency,
+			"opening":  line.Opening,
+			"earned":   line.Earned,
+			"reversed": line.Reversed,
+			"paid_out": line.PaidOut,
+			"closing":  line.Closing,
+		}
+	}
+	payouts := make([]map[string]any, len(statement.Payouts))
+	for i := range statement.Payouts {
+		payouts[i] = payoutPayload(&statement.Payouts[i])
+	}
+
+	return response.Success(c, "", map[string]any{
+		"from":     statement.From,
+		"to":       statement.To,
+		"lines":    lines,
+		"payouts":  payouts,
+		"balances": payoutBalancesPayload(statement.Balances),
+	})
+}
+
+// Admin handler listing payout batches, ?page=1&limit=20
+func (ph *PayoutHandler) FetchBatches(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	batches, total, err := ph.Usecase.FetchBatches(ctx, page, limit)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	items := make([]map[string]any, len(batches))
+	for i := range batches {
+		items[i] = payoutBatchPayload(&batches[i])
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": items,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// Admin handler for a batch and its payouts
+func (ph *PayoutHandler) FetchBatch(c *fiber.Ctx) error {
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	batch, payouts, err := ph.Usecase.FetchBatch(ctx, c.Params("batch_id"))
+	if err != nil {
+		return payoutErrorResponse(c, err)
+	}
+
+	items := make([]map[string]any, len(payouts))
+	for i := range payouts {
+		items[i] = payoutPayload(&payouts[i])
+		items[i]["partner_id"] = payouts[i].PartnerID
+	}
+	payload := payoutBatchPayload(batch)
+	payload["payouts"] = items
+
+	return response.Success(c, "", payload)
+}
+
+// Admin handler to run a payout batch now instead of waiting for the schedule
+func (ph *PayoutHandler) RunBatch(c *fiber.Ctx) error {
+	adminID, _ := c.Locals("user_id").(string)
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	batch, err := ph.Usecase.RunBatch(ctx, &adminID)
+	if err != nil {
+		return payoutErrorResponse(c, err)
+	}
+
+	return response.Created(c, payoutBatchPayload(batch))
+}
+
+// Payout account response shape, the account number is only ever shown by its last digits
+func payoutAccountPayload(account *entity.PayoutAccount) map[string]any {
+	return map[string]any{
+		"id":            account.ID,
+		"currency":      account.Currency,
+		"provider":      account.Provider,
+		"bank_code":     account.BankCode,
+		"account_last4": account.AccountLast4,
+		"account_name":  account.AccountName,
+		"verified_at":   account.VerifiedAt,
+		"updated_at":    account.UpdatedAt,
+	}
+}
+
+// Payable balances response shape
+func payoutBalancesPayload(balances []usecase.PayoutBalance) []map[string]any {
+	items := make([]map[string]any, len(balances))
+	for i, balance := range balances {
+		items[i] = map[string]any{
+			"currency":  balance.Currency,
+			"available": balance.Available,
+			"paid_out":  balance.PaidOut,
+			"payable":   balance.Payable,
+			"minimum":   balance.Minimum,
+		}
+	}
+	return items
+}
+
+// Payout response shape
+func payoutPayload(payout *entity.Payout) map[string]any {
+	return map[string]any{
+		"id":             payout.ID,
+		"batch_id":       payout.BatchID,
+		"reference":      payout.Reference,
+		"provider":       payout.Provider,
+		"amount":         payout.Amount,
+		"currency":       payout.Currency,
+		"fee":            payout.Fee,
+		"status":         payout.Status,
+		"failure_reason": payout.FailureReason,
+		"paid_at":        payout.PaidAt,
+		"created_at":     payout.CreatedAt,
+	}
+}
+
+// Payout batch response shape
+func payoutBatchPayload(batch *entity.PayoutBatch) map[string]any {
+	return map[string]any{
+		"id":           batch.ID,
+		"status":       batch.Status,
+		"started_by":   batch.StartedBy, // Null for scheduled batches
+		"count":        batch.Count,
+		"completed_at": batch.CompletedAt,
+		"created_at":   batch.CreatedAt,
+	}
+}
+
+// Maps payout usecase errors to responses
+func payoutErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, apperror.New(
+			apperror.ErrCodeRecordNotFound,
+			"Payout account or batch not found",
+			err.Error(),
+		))
+	case errors.Is(err, payment.ErrTransfersNotSupported):
+		return response.Unprocessable(c, apperror.New(
+			apperror.ErrCodeConstraintFailed,
+			"Payouts are not available in this currency",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrPayoutAccountInvalid):
+		return response.Unprocessable(c, apperror.New(
+			apperror.ErrCodeConstraintFailed,
+			"The bank could not find this account",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrPayoutInProgress):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeConstraintFailed,
+			"Wait for payouts in progress to finish",
+			err.Error(),
+		))
+	case errors.As(err, new(*payment.ProviderError)):
+		return response.InternalServerError(c, apperror.New(
+			apperror.ErrCodeDatabase,
+			"Payment provider is unavailable, try again later",
+			err.Error(),
+		))
+	default:
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+}
@@ -0,0 +1,405 @@
+package handlers
+
+import (
+	"time"
+	"context"
+	"errors"
+	"strconv"
+
+	"japa/internal/app/http/dto/apperror"
+	"japa/internal/app/http/dto/request"
+	"japa/internal/app/http/dto/response"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// TYPES
+
+// Plan handler
+type PlanHandler struct {
+	Validator *validator.Validate
+	Usecase   *usecase.PlanUsecase
+}
+
+// METHODS
+
+// Initialize Plan handler
+func NewPlanHandler(v *validator.Validate, uc *usecase.PlanUsecase) *PlanHandler {
+	return &PlanHandler{v, uc}
+}
+
+// Handler returning the plans on sale for the pricing page, api/v1/plans?currency=GHS
+func (ph *PlanHandler) FetchCatalogue(c *fiber.Ctx) error {
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	plans, err := ph.Usecase.Catalogue(ctx, c.Query("currency"), pricingCountry(c))
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	items := make([]map[string]any, len(plans))
+	for i, priced := range plans {
+		items[i] = map[string]any{
+			"id":            priced.Plan.ID,
+			"code":          priced.Plan.Code,
+			"name":          priced.Plan.Name,
+			"description":   priced.Plan.Description,
+			"billing_cycle": priced.Plan.BillingCycle,
+			"trial_days":    priced.Plan.TrialDays,
+			"features":      planFeaturesPayload(priced.Plan.PlanFeatures),
+			"price": map[string]any{
+				"currency": priced.Currency,
+				"amount":   priced.Amount,
+				"display":  priced.Display,
+			},
+		}
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": items,
+	})
+}
+
+// Admin handler listing every plan version, api/v1/account/admin/plans?status=archived
+func (ph *PlanHandler) FetchPlans(c *fiber.Ctx) error {
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	plans, subscribers, err := ph.Usecase.FetchPlans(ctx, c.Query("status"))
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	items := make([]map[string]any, len(plans))
+	for i := range plans {
+		items[i] = planPayload(&plans[i])
+		items[i]["subscribers"] = subscribers[plans[i].ID]
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": items,
+	})
+}
+
+// Admin handler to create a plan
+func (ph *PlanHandler) CreatePlan(c *fiber.Ctx) error {
+	var reqBody request.CreatePlanRequest
+	if err := reqBody.Bind(c, ph.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	plan, err := ph.Usecase.CreatePlan(ctx, reqBody)
+	if err != nil {
+		return planErrorResponse(c, err)
+	}
+
+	return response.Created(c, planPayload(plan))
+}
+
+// Admin handler to rename or re-describe a plan version
+func (ph *PlanHandler) UpdatePlan(c *fiber.Ctx) error {
+	var reqBody request.UpdatePlanRequest
+	if err := reqBody.Bind(c, ph.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	plan, err := ph.Usecase.UpdatePlan(ctx, reqBody)
+	if err != nil {
+		return planErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Plan updated", planPayload(plan))
+}
+
+// Admin handler creating a new version of a plan, existing subscribers keep theirs
+func (ph *PlanHandler) CreateVersion(c *fiber.Ctx) error {
+	var reqBody request.CreatePlanVersionRequest
+	if err := reqBody.Bind(c, ph.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	plan, err := ph.Usecase.CreateVersion(ctx, reqBody)
+	if err != nil {
+		return planErrorResponse(c, err)
+	}
+
+	return response.Created(c, planPayload(plan))
+}
+
+// Admin handler withdrawing a plan from sale
+func (ph *PlanHandler) Archive(c *fiber.Ctx) error {
+	return ph.setStatus(c, ph.Usecase.Archive, "Plan archived")
+}
+
+// Admin handler putting an archived plan back on sale
+func (ph *PlanHandler) Restore(c *fiber.Ctx) error {
+	return ph.setStatus(c, ph.Usecase.Restore, "Plan restored")
+}
+
+// Admin handler setting the pricing page order of plans
+func (ph *PlanHandler) Reorder(c *fiber.Ctx) error {
+	var reqBody request.ReorderRequest
+	if err := reqBody.Bind(c, ph.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ph.Usecase.Reorder(ctx, reqBody); err != nil {
+		return planErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Plans reordered")
+}
+
+// Admin handler to add a feature to a plan, or edit one when feature_id is in the route
+func (ph *PlanHandler) SaveFeature(c *fiber.Ctx) error {
+	var reqBody request.SavePlanFeatureRequest
+	if err := reqBody.Bind(c, ph.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	feature, err := ph.Usecase.SaveFeature(ctx, reqBody)
+	if err != nil {
+		return planErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Feature saved", map[string]any{
+		"item": planFeaturesPayload([]entity.PlanFeature{*feature})[0],
+	})
+}
+
+// Admin handler to delete a plan's feature
+func (ph *PlanHandler) DeleteFeature(c *fiber.Ctx) error {
+	planID, err := strconv.ParseUint(c.Params("plan_id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid plan id",
+			err.Error(),
+		))
+	}
+	featureID, err := strconv.ParseUint(c.Params("feature_id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid feature id",
+			err.Error(),
+		))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ph.Usecase.DeleteFeature(ctx, uint(planID), uint(featureID)); err != nil {
+		return planErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Feature deleted")
+}
+
+// Admin handler setting the order of a plan's features
+func (ph *PlanHandler) ReorderFeatures(c *fiber.Ctx) error {
+	var reqBody request.ReorderRequest
+	if err := reqBody.Bind(c, ph.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ph.Usecase.ReorderFeatures(ctx, reqBody); err != nil {
+		return planErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Features reordered")
+}
+
+// Admin handler to set a plan's price in a currency
+func (ph *PlanHandler) SavePrice(c *fiber.Ctx) error {
+	var reqBody request.SavePlanPriceRequest
+	if err := reqBody.Bind(c, ph.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	price, err := ph.Usecase.SavePrice(ctx, reqBody)
+	if err != nil {
+		return planErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Price saved", map[string]any{
+		"item": map[string]any{
+			"currency": price.Currency,
+			"amount":   price.Amount,
+		},
+	})
+}
+
+// Admin handler to delete a plan's price in a currency
+func (ph *PlanHandler) DeletePrice(c *fiber.Ctx) error {
+	planID, err := strconv.ParseUint(c.Params("plan_id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid plan id",
+			err.Error(),
+		))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := ph.Usecase.DeletePrice(ctx, uint(planID), c.Params("currency")); err != nil {
+		return planErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Price deleted")
+}
+
+// Shared by Archive and Restore
+func (ph *PlanHandler) setStatus(c *fiber.Ctx, action func(context.Context, uint) (*entity.Plan, error), message string) error {
+	planID, err := strconv.ParseUint(c.Params("plan_id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid plan id",
+			err.Error(),
+		))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	plan, err := action(ctx, uint(planID))
+	if err != nil {
+		return planErrorResponse(c, err)
+	}
+
+	return response.Success(c, message, planPayload(plan))
+}
+
+// Admin plan response shape
+func planPayload(plan *entity.Plan) map[string]any {
+	prices := make([]map[string]any, len(plan.PlanPrices))
+	for i, price := range plan.PlanPrices {
+		prices[i] = map[string]any{
+			"currency": price.Currency,
+			"amount":   price.Amount,
+		}
+	}
+
+	return map[string]any{
+		"id":            plan.ID,
+		"code":          plan.Code,
+		"version":       plan.Version,
+		"name":          plan.Name,
+		"description":   plan.Description,
+		"amount":        plan.Amount, // Minor units
+		"currency":      plan.Currency,
+		"billing_cycle": plan.BillingCycle,
+		"status":        plan.Status,
+		"sort_order":    plan.SortOrder,
+		"trial_days":    plan.TrialDays,
+		"features":      planFeaturesPayload(plan.PlanFeatures),
+		"prices":        prices,
+	}
+}
+
+// Country the customer is priced for: the user's when signed in and known, else the visitor's by IP
+func pricingCountry(c *fiber.Ctx) string {
+	if country, _ := c.Locals("country").(string); country != "" {
+		return country
+	}
+	country, _ := c.Locals("ip_country").(string)
+	return country
+}
+
+// Plan features response shape
+func planFeaturesPayload(features []entity.PlanFeature) []map[string]any {
+	items := make([]map[string]any, len(features))
+	for i, feature := range features {
+		items[i] = map[string]any{
+			"id":    feature.ID,
+			"label": feature.FeatureLabel,
+			"value": feature.FeatureValue,
+		}
+	}
+	return items
+}
+
+// Maps plan usecase errors to responses
+func planErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, apperror.New(
+			apperror.ErrCodeRecordNotFound,
+			"Plan, feature or price not found",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrPlanCodeTaken):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeAlreadyExists,
+			"A plan with this code already exists",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrNotLatestVersion),
+		errors.Is(err, usecase.ErrPlanNotActive),
+		errors.Is(err, usecase.ErrPlanNotArchived):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeConstraintFailed,
+			"Plan cannot be changed this way",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrPriceInUse):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeConstraintFailed,
+			"Subscribers pay this price, create a new plan version to change it",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrUnsupportedCurrency):
+		return response.Unprocessable(c, apperror.New(
+			apperror.ErrCodeValidation,
+			"Currency has no price book",
+			err.Error(),
+		))
+	default:
+		return response.InternalServerError(c, apperror.New(
+			apperror.ErrCodeDatabase,
+			"Failed to save plan",
+			err.Error(),
+		))
+	}
+}
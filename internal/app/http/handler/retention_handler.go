@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"time"
+	"context"
+	"errors"
+	"strconv"
+
+	"japa/internal/app/http/dto/apperror"
+	"japa/internal/app/http/dto/request"
+	"japa/internal/app/http/dto/response"
+	"japa/internal/domain/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// TYPES
+
+// Retention handler for policies, legal holds and purge reports
+type RetentionHandler struct {
+	Validator *validator.Validate
+	Usecase   *usecase.RetentionUsecase
+}
+
+// METHODS
+
+// Initialize Retention handler
+func NewRetentionHandler(v *validator.Validate, uc *usecase.RetentionUsecase) *RetentionHandler {
+	return &RetentionHandler{v, uc}
+}
+
+// Admin handler listing retention policies
+func (rh *RetentionHandler) FetchPolicies(c *fiber.Ctx) error {
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	policies, err := rh.Usecase.FetchPolicies(ctx)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": policies,
+	})
+}
+
+// Admin handler to create or update the policy for a status
+func (rh *RetentionHandler) SavePolicy(c *fiber.Ctx) error {
+	var reqBody request.SaveRetentionPolicyRequest
+	if err := reqBody.Bind(c, rh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	policy, err := rh.Usecase.SavePolicy(ctx, reqBody)
+	if err != nil {
+		return response.InternalServerError(c, apperror.New(
+			apperror.ErrCodeDatabase,
+			"Failed to save retention policy",
+			err.Error(),
+		))
+	}
+
+	return response.Success(c, "Retention policy saved", map[string]any{
+		"item": policy,
+	})
+}
+
+// Admin handler to delete a retention policy
+func (rh *RetentionHandler) DeletePolicy(c *fiber.Ctx) error {
+	policyID, err := strconv.ParseUint(c.Params("policy_id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid policy id",
+			err.Error(),
+		))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := rh.Usecase.DeletePolicy(ctx, uint(policyID)); err != nil {
+		return retentionErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Retention policy deleted")
+}
+
+// Admin handler to place a legal hold on an application
+func (rh *RetentionHandler) PlaceHold(c *fiber.Ctx) error {
+	var reqBody request.LegalHoldRequest
+	if err := reqBody.Bind(c, rh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	hold, err := rh.Usecase.PlaceHold(ctx, reqBody)
+	if err != nil {
+		return retentionErrorResponse(c, err)
+	}
+
+	return response.Created(c, map[string]any{
+		"item": hold,
+	})
+}
+
+// Admin handler to release an application's legal hold
+func (rh *RetentionHandler) ReleaseHold(c *fiber.Ctx) error {
+	var reqBody request.LegalHoldRequest
+	if err := reqBody.Bind(c, rh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	hold, err := rh.Usecase.ReleaseHold(ctx, reqBody)
+	if err != nil {
+		return retentionErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Legal hold released", map[string]any{
+		"item": hold,
+	})
+}
+
+// Admin handler listing active legal holds
+func (rh *RetentionHandler) FetchHolds(c *fiber.Ctx) error {
+	var reqBody request.RetentionPageRequest
+	if err := reqBody.Bind(c, rh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	holds, total, err := rh.Usecase.FetchHolds(ctx, reqBody)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": holds,
+		"total": total,
+		"page":  reqBody.Page,
+		"limit": reqBody.Limit,
+	})
+}
+
+// Admin handler to start a purge now, optionally as a dry run
+func (rh *RetentionHandler) StartPurge(c *fiber.Ctx) error {
+	var reqBody request.StartPurgeRequest
+	if err := reqBody.Bind(c, rh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	run, err := rh.Usecase.StartPurge(ctx, reqBody)
+	if err != nil {
+		return retentionErrorResponse(c, err)
+	}
+
+	return response.Created(c, map[string]any{
+		"item": run,
+	})
+}
+
+// Admin handler listing purge runs
+func (rh *RetentionHandler) FetchRuns(c *fiber.Ctx) error {
+	var reqBody request.RetentionPageRequest
+	if err := reqBody.Bind(c, rh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	runs, total, err := rh.Usecase.FetchRuns(ctx, reqBody)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": runs,
+		"total": total,
+		"page":  reqBody.Page,
+		"limit": reqBody.Limit,
+	})
+}
+
+// Admin handler for a purge report, a run with a page of its items
+func (rh *RetentionHandler) FetchRun(c *fiber.Ctx) error {
+	var reqBody request.RetentionPageRequest
+	if err := reqBody.Bind(c, rh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	run, items, total, err := rh.Usecase.FetchRun(ctx, reqBody)
+	if err != nil {
+		return retentionErrorResponse(c, err)
+	}
+
+	return response.Success(c, "", map[string]any{
+		"run":   run,
+		"items": items,
+		"total": total,
+		"page":  reqBody.Page,
+		"limit": reqBody.Limit,
+	})
+}
+
+// Maps retention usecase errors to responses
+func retentionErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, apperror.New(
+			apperror.ErrCodeRecordNotFound,
+			"Record not found",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrHoldReasonRequired):
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	case errors.Is(err, usecase.ErrOnLegalHold):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeAlreadyExists,
+			"Application is already on legal hold",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrPurgeRunning):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeAlreadyExists,
+			"A purge is already running",
+			err.Error(),
+		))
+	default:
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"time"
+	"context"
+	"errors"
+	"strconv"
+
+	"japa/internal/app/http/dto/apperror"
+	"japa/internal/app/http/dto/request"
+	"japa/internal/app/http/dto/response"
+	"japa/internal/domain/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// TYPES
+
+// SLA handler
+type SLAHandler struct {
+	Validator *validator.Validate
+	Usecase   *usecase.SLAUsecase
+}
+
+// METHODS
+
+// Initialize SLA handler
+func NewSLAHandler(v *validator.Validate, uc *usecase.SLAUsecase) *SLAHandler {
+	return &SLAHandler{v, uc}
+}
+
+// Agent queue with time-in-status and SLA flags
+// i.e /agent/visa/applications?assigned=me&sla=breached
+func (sh *SLAHandler) FetchQueue(c *fiber.Ctx) error {
+	var reqBody request.VisaQueueRequest
+	if err := reqBody.Bind(c, sh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	items, total, err := sh.Usecase.FetchQueue(ctx, reqBody)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	payload := make([]map[string]any, len(items))
+	for i, item := range items {
+		payload[i] = queueItemPayload(item)
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": payload,
+		"total": total,
+		"page":  reqBody.Page,
+		"limit": reqBody.Limit,
+	})
+}
+
+// Admin handler listing SLA targets
+func (sh *SLAHandler) FetchTargets(c *fiber.Ctx) error {
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	targets, err := sh.Usecase.FetchTargets(ctx)
+	if err != nil {
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": targets,
+	})
+}
+
+// Admin handler to create or update the target for a plan and status
+func (sh *SLAHandler) SaveTarget(c *fiber.Ctx) error {
+	var reqBody request.SaveSLATargetRequest
+	if err := reqBody.Bind(c, sh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	target, err := sh.Usecase.SaveTarget(ctx, reqBody)
+	if err != nil {
+		return response.InternalServerError(c, apperror.New(
+			apperror.ErrCodeDatabase,
+			"Failed to save SLA target",
+			err.Error(),
+		))
+	}
+
+	return response.Success(c, "SLA target saved", map[string]any{
+		"item": target,
+	})
+}
+
+// Admin handler to delete an SLA target
+func (sh *SLAHandler) DeleteTarget(c *fiber.Ctx) error {
+	targetID, err := strconv.ParseUint(c.Params("target_id"), 10, 64)
+	if err != nil {
+		return response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid target id",
+			err.Error(),
+		))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := sh.Usecase.DeleteTarget(ctx, uint(targetID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.NotFound(c, apperror.New(
+				apperror.ErrCodeRecordNotFound,
+				"SLA target not found",
+				err.Error(),
+			))
+		}
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+
+	return response.Success(c, "SLA target deleted")
+}
+
+// Queue item response shape
+func queueItemPayload(item usecase.QueueItem) map[string]any {
+	application := item.Application
+
+	var agent map[string]any
+	if application.Agent != nil {
+		agent = map[string]any{
+			"id":        application.Agent.ID,
+			"full_name": application.Agent.FullName,
+		}
+	}
+
+	sla := map[string]any{
+		"state":                  item.SLA.State,
+		"entered_status_at":      item.SLA.EnteredAt,
+		"time_in_status_minutes":  int(item.SLA.TimeInStatus.Minutes()),
+	}
+	if item.SLA.DueAt != nil {
+		sla["target_hours"] = int(item.SLA.Target.Hours())
+		sla["due_at"] = item.SLA.DueAt
+	}
+
+	signals := make([]map[string]any, len(item.Signals))
+	for i, signal := range item.Signals {
+		signals[i] = fraudSignalPayload(signal)
+	}
+
+	return map[string]any{
+		"id":             application.ID,
+		"applicant":      application.User.FullName,
+		"applicant_name": application.ApplicantName,
+		"destination":    item.Destination,
+		"visa_type":      item.VisaType,
+		"status":         application.Status,
+		"group_id":       application.GroupID,
+		"agent":          agent,
+		"created_at":     application.CreatedAt,
+		"sla":            sla,
+		"flagged":        len(signals) > 0,
+		"fraud_signals":  signals,
+	}
+}
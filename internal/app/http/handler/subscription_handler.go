@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"time"
+	"context"
+	"errors"
+
+	"japa/internal/app/http/dto/apperror"
+	"japa/internal/app/http/dto/request"
+	"japa/internal/app/http/dto/response"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// TYPES
+
+// Subscription handler
+type SubscriptionHandler struct {
+	Validator *validator.Validate
+	Usecase   *usecase.SubscriptionUsecase
+}
+
+// METHODS
+
+// Initialize Subscription handler
+func NewSubscriptionHandler(v *validator.Validate, uc *usecase.SubscriptionUsecase) *SubscriptionHandler {
+	return &SubscriptionHandler{v, uc}
+}
+
+// Handler returning the user's current subscription
+func (sh *SubscriptionHandler) FetchSubscription(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	subscription, err := sh.Usecase.FetchCurrent(ctx, userID)
+	if err != nil {
+		return subscriptionErrorResponse(c, err)
+	}
+
+	return response.Success(c, "", map[string]any{
+		"subscription": subscriptionPayload(subscription),
+	})
+}
+
+// Handler starting a subscription checkout, the user pays on the returned authorization_url
+func (sh *SubscriptionHandler) Subscribe(c *fiber.Ctx) error {
+	var reqBody request.SubscribeRequest
+	if err := reqBody.Bind(c, sh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+	reqBody.Country = pricingCountry(c)
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	subscription, payment, err := sh.Usecase.Subscribe(ctx, reqBody)
+	if err != nil {
+		return subscriptionErrorResponse(c, err)
+	}
+
+	// Trials start without a payment
+	var paymentData map[string]any
+	if payment != nil {
+		paymentData = paymentPayload(payment)
+	}
+
+	return response.Created(c, map[string]any{
+		"subscription": subscriptionPayload(subscription),
+		"payment":      paymentData,
+	})
+}
+
+// Handler canceling the subscription, at period end unless {"at_period_end": false}
+func (sh *SubscriptionHandler) Cancel(c *fiber.Ctx) error {
+	var reqBody request.CancelSubscriptionRequest
+	if err := reqBody.Bind(c, sh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	subscription, err := sh.Usecase.Cancel(ctx, reqBody)
+	if err != nil {
+		return subscriptionErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Subscription canceled", map[string]any{
+		"subscription": subscriptionPayload(subscription),
+	})
+}
+
+// Handler undoing a cancellation at period end
+func (sh *SubscriptionHandler) Resume(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	subscription, err := sh.Usecase.Resume(ctx, userID)
+	if err != nil {
+		return subscriptionErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Subscription resumed", map[string]any{
+		"subscription": subscriptionPayload(subscription),
+	})
+}
+
+// Handler pricing a plan change, api/v1/account/subscription/change?plan_id=3
+func (sh *SubscriptionHandler) PreviewChange(c *fiber.Ctx) error {
+	var reqBody request.ChangePlanRequest
+	if err := reqBody.Bind(c, sh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	subscription, plan, proration, err := sh.Usecase.PreviewChange(ctx, reqBody)
+	if err != nil {
+		return subscriptionErrorResponse(c, err)
+	}
+
+	return response.Success(c, "", map[string]any{
+		"subscription": subscriptionPayload(subscription),
+		"plan":         subscriptionPlanPayload(plan, subscription.Currency),
+		"proration":    prorationPayload(proration, subscription.Currency),
+	})
+}
+
+// Handler changing plan: upgrades apply once the prorated difference is paid, downgrades at period end.
+// A payment with an authorization_url still has to be paid there.
+func (sh *SubscriptionHandler) ChangePlan(c *fiber.Ctx) error {
+	var reqBody request.ChangePlanRequest
+	if err := reqBody.Bind(c, sh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	subscription, proration, payment, err := sh.Usecase.ChangePlan(ctx, reqBody)
+	if err != nil {
+		return subscriptionErrorResponse(c, err)
+	}
+
+	data := map[string]any{
+		"subscription": subscriptionPayload(subscription),
+		"proration":    prorationPayload(proration, subscription.Currency),
+	}
+	if payment != nil {
+		data["payment"] = paymentPayload(payment)
+	}
+	return response.Success(c, "", data)
+}
+
+// Handler dropping a downgrade scheduled for period end
+func (sh *SubscriptionHandler) CancelChange(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	subscription, err := sh.Usecase.CancelChange(ctx, userID)
+	if err != nil {
+		return subscriptionErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Plan change canceled", map[string]any{
+		"subscription": subscriptionPayload(subscription),
+	})
+}
+
+// Subscription response shape
+func subscriptionPayload(subscription *entity.Subscription) map[string]any {
+	return map[string]any{
+		"id":                   subscription.ID,
+		"plan":                 subscriptionPlanPayload(&subscription.Plan, subscription.Currency),
+		"pending_plan":         subscriptionPlanPayload(subscription.PendingPlan, subscription.Currency),
+		"currency":             subscription.Currency,               // Price book the subscription renews in
+		"plan_change_pending":  subscription.ChangePaymentID != nil, // Upgrade waiting for its payment
+		"credit_balance":       subscription.CreditBalance,
+		"status":               subscription.Status,
+		"started_at":           subscription.StartedAt,
+		"expires_at":           subscription.ExpiresAt,
+		"cancel_at_period_end": subscription.CancelAtPeriodEnd,
+		"canceled_at":          subscription.CanceledAt,
+		"grace_ends_at":        subscription.GraceEndsAt,
+		"next_renewal_at":      subscription.NextRenewalAt,
+		"in_trial":             subscription.InTrial(),
+		"trial_ends_at":        subscription.TrialEndsAt,
+		"coupon_code":          subscription.CouponCode, // Taken off the first charge after the trial
+		"in_good_standing":     subscription.InGoodStanding(time.Now()),
+	}
+}
+
+// Plan as shown on a subscription, priced in the subscription's currency, null for no plan
+func subscriptionPlanPayload(plan *entity.Plan, currency string) map[string]any {
+	if plan == nil {
+		return nil
+	}
+	amount, _ := plan.PriceIn(currency)
+	return map[string]any{
+		"id":            plan.ID,
+		"name":          plan.Name,
+		"amount":        amount, // Minor units
+		"currency":      currency,
+		"billing_cycle": plan.BillingCycle,
+	}
+}
+
+// Proration response shape, amounts in minor units
+func prorationPayload(proration *usecase.Proration, currency string) map[string]any {
+	return map[string]any{
+		"downgrade":  proration.Downgrade, // Applies at period end, nothing is due
+		"currency":   currency,
+		"credit":     proration.Credit,
+		"charge":     proration.Charge,
+		"due":        proration.Due,
+		"remainder":  proration.Remainder,
+		"expires_at": proration.ExpiresAt,
+	}
+}
+
+// Maps subscription usecase errors to responses
+func subscriptionErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, apperror.New(
+			apperror.ErrCodeRecordNotFound,
+			"Subscription or plan not found",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrAlreadySubscribed):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeAlreadyExists,
+			"You already have a subscription",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrSubscriptionPending):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeConstraintFailed,
+			"Another subscription checkout is in progress, try again",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrPlanUnavailable):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeConstraintFailed,
+			"This plan is no longer offered",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrSamePlan):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeConstraintFailed,
+			"You are already on this plan",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrNotActive):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeConstraintFailed,
+			"Renew your subscription before changing plan",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrInTrial):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeConstraintFailed,
+			"Plans can't be changed during a free trial",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrPlanNotPriced):
+		return response.Unprocessable(c, apperror.New(
+			apperror.ErrCodeConstraintFailed,
+			"This plan is not offered in your currency",
+			err.Error(),
+		))
+	case isCouponError(err):
+		return couponErrorResponse(c, err)
+	case errors.Is(err, usecase.ErrNoPendingChange):
+		return response.NotFound(c, apperror.New(
+			apperror.ErrCodeRecordNotFound,
+			"No plan change is scheduled",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrNotCanceling),
+		errors.Is(err, usecase.ErrRenewalInProgress),
+		errors.Is(err, usecase.ErrPlanChangeInProgress),
+		errors.Is(err, usecase.ErrSubscriptionChanged):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeConstraintFailed,
+			"Subscription cannot be changed right now",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrPaymentProvider):
+		return response.PaymentFailed(c, err.Error())
+	default:
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+}
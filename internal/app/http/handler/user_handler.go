@@ -45,6 +45,14 @@ func (uh *UserHandler) Register(c *fiber.Ctx) error {
 		))
 	}
 
+	// Referral link visits leave the code in a cookie
+	if reqBody.ReferralCode == "" {
+		reqBody.ReferralCode = c.Cookies(uh.Usecase.Affiliates.Config.CookieName)
+	}
+	if reqBody.Country == "" {
+		reqBody.Country, _ = c.Locals("ip_country").(string)
+	}
+
 	// Registering user
 	err := uh.Usecase.RegisterUser(c.Context(), reqBody)
 	if err != nil {
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"time"
+	"context"
+	"errors"
+
+	"japa/internal/app/http/dto/apperror"
+	"japa/internal/app/http/dto/request"
+	"japa/internal/app/http/dto/response"
+	"japa/internal/domain/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/oklog/ulid/v2"
+)
+
+// Handler to create a family/group application around the principal's application
+func (vh *VisaHandler) CreateGroup(c *fiber.Ctx) error {
+	var reqBody request.CreateVisaGroupRequest
+	if err := reqBody.Bind(c, vh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	summary, err := vh.Usecase.CreateGroup(ctx, reqBody)
+	if err != nil {
+		return visaGroupErrorResponse(c, err)
+	}
+
+	return response.Created(c, groupPayload(summary))
+}
+
+// Handler to add a dependent to a group
+func (vh *VisaHandler) AddDependent(c *fiber.Ctx) error {
+	var reqBody request.AddDependentRequest
+	if err := reqBody.Bind(c, vh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	application, err := vh.Usecase.AddDependent(ctx, reqBody)
+	if err != nil {
+		return visaGroupErrorResponse(c, err)
+	}
+
+	return response.Created(c, map[string]any{
+		"application_id": application.ID,
+		"relationship":   application.Relationship,
+		"applicant_name": application.ApplicantName,
+		"status":         application.Status,
+	})
+}
+
+// Handler for the applicant's group view
+func (vh *VisaHandler) FetchGroup(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	return vh.fetchGroup(c, userID)
+}
+
+// Handler for the agent's group view with status roll-up
+func (vh *VisaHandler) FetchGroupForAgent(c *fiber.Ctx) error {
+	return vh.fetchGroup(c, "")
+}
+
+func (vh *VisaHandler) fetchGroup(c *fiber.Ctx, userID string) error {
+	groupID := c.Params("group_id")
+	if _, err := ulid.Parse(groupID); err != nil {
+		return response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid group id format",
+			err.Error(),
+		))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	summary, err := vh.Usecase.FetchGroup(ctx, userID, groupID)
+	if err != nil {
+		return visaGroupErrorResponse(c, err)
+	}
+
+	return response.Success(c, "", groupPayload(summary))
+}
+
+// Handler to upload a supporting document for any application the user owns
+func (vh *VisaHandler) AddDocument(c *fiber.Ctx) error {
+	var reqBody request.CreateDocumentRequest
+	if err := reqBody.Bind(c, vh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	document, err := vh.Usecase.AddDocument(ctx, reqBody)
+	if err != nil {
+		return visaErrorResponse(c, err)
+	}
+
+	return response.Created(c, map[string]any{
+		"id":                  document.ID,
+		"visa_application_id": document.VisaApplicationID,
+		"file_type":           document.FileType,
+		"file_path":           document.FilePath,
+	})
+}
+
+// Group response shape
+func groupPayload(summary *usecase.VisaGroupSummary) map[string]any {
+	members := make([]map[string]any, len(summary.Group.Members))
+	for i, member := range summary.Group.Members {
+		members[i] = map[string]any{
+			"application_id": member.ID,
+			"relationship":   member.Relationship,
+			"applicant_name": member.ApplicantName,
+			"status":         member.Status,
+			"version":        member.Version, // Drafts change when the group's travel does
+			"agent_id":       member.AgentID,
+			"updated_at":     member.UpdatedAt,
+		}
+	}
+
+	return map[string]any{
+		"id":                       summary.Group.ID,
+		"principal_application_id": summary.Group.PrincipalApplicationID,
+		"travel": map[string]any{
+			"destination":      summary.Group.Destination,
+			"visa_type":        summary.Group.VisaType,
+			"travel_date":      summary.Group.TravelDate.Format("2006-01-02"),
+			"duration_of_stay": summary.Group.DurationOfStay,
+			"purpose":          summary.Group.Purpose,
+		},
+		"status":        summary.Status,
+		"status_counts": summary.StatusCounts,
+		"members":       members,
+	}
+}
+
+// Maps group usecase errors to responses
+func visaGroupErrorResponse(c *fiber.Ctx, err error) error {
+	if errors.Is(err, usecase.ErrAlreadyInGroup) {
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeAlreadyExists,
+			"Application already belongs to a group",
+			err.Error(),
+		))
+	}
+	if errors.Is(err, usecase.ErrGroupDecided) {
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeInvalidApplication,
+			"This group already has a decision, start a new application",
+			err.Error(),
+		))
+	}
+	if errors.Is(err, usecase.ErrLimitExceeded) || errors.Is(err, usecase.ErrNotEntitled) {
+		return response.Forbidden(c, apperror.New(
+			apperror.ErrCodeUpgradeRequired,
+			"Your plan does not allow this many applicants in a group",
+			err.Error(),
+		))
+	}
+	return visaErrorResponse(c, err)
+}
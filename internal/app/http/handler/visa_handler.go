@@ -1,14 +1,21 @@
 package handlers
 
 import (
+	"time"
+	"context"
+	"errors"
+	"encoding/json"
+
 	"japa/internal/app/http/dto/apperror"
 	"japa/internal/app/http/dto/request"
 	"japa/internal/app/http/dto/response"
-	//"japa/internal/domain/entity"
+	"japa/internal/domain/entity"
 	"japa/internal/domain/usecase"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
 	//"go.uber.org/zap"
 )
 
@@ -36,9 +43,251 @@ func (vh *VisaHandler) SubmitVisaApplication(c *fiber.Ctx) error {
 
 	// Pass to usecase layer
 	if err := vh.Usecase.CreateVisaApplication(c.Context(), reqBody); err != nil {
-		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+		return visaErrorResponse(c, err)
 	}
 
 	// If application successful
 	return response.Success(c, "Visa application was successful")
 }
+
+// Handler for agents to move an application to a new status
+func (vh *VisaHandler) UpdateApplicationStatus(c *fiber.Ctx) error {
+	var reqBody request.UpdateVisaStatusRequest
+	if err := reqBody.Bind(c, vh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := vh.Usecase.UpdateApplicationStatus(ctx, reqBody); err != nil {
+		return visaErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Application status updated")
+}
+
+// Handler for agents to request more documents from the applicant
+func (vh *VisaHandler) RequestDocuments(c *fiber.Ctx) error {
+	var reqBody request.RequestDocumentsRequest
+	if err := reqBody.Bind(c, vh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := vh.Usecase.RequestDocuments(ctx, reqBody); err != nil {
+		return visaErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Documents requested from applicant")
+}
+
+// Handler for agents to attribute an application to a partner
+func (vh *VisaHandler) SetReferrer(c *fiber.Ctx) error {
+	var reqBody request.SetReferrerRequest
+	if err := reqBody.Bind(c, vh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := vh.Usecase.SetReferrer(ctx, reqBody); err != nil {
+		return visaErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Application referrer updated")
+}
+
+// Handler for the applicant's application timeline
+func (vh *VisaHandler) FetchTimeline(c *fiber.Ctx) error {
+	applicationID := c.Params("application_id")
+	if _, err := ulid.Parse(applicationID); err != nil {
+		return response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid application id format",
+			err.Error(),
+		))
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	timeline, err := vh.Usecase.FetchTimeline(ctx, userID, applicationID)
+	if err != nil {
+		return visaErrorResponse(c, err)
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": timeline,
+	})
+}
+
+// Handler to start a draft application
+func (vh *VisaHandler) CreateDraft(c *fiber.Ctx) error {
+	var reqBody request.CreateVisaDraftRequest
+	if err := reqBody.Bind(c, vh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	draft, err := vh.Usecase.CreateDraft(ctx, reqBody)
+	if err != nil {
+		return visaErrorResponse(c, err)
+	}
+
+	return response.Created(c, draftPayload(draft))
+}
+
+// Handler to list the user's drafts
+func (vh *VisaHandler) FetchDrafts(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	drafts, err := vh.Usecase.FetchDrafts(ctx, userID)
+	if err != nil {
+		return visaErrorResponse(c, err)
+	}
+
+	items := make([]map[string]any, len(drafts))
+	for i := range drafts {
+		items[i] = draftPayload(&drafts[i])
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": items,
+	})
+}
+
+// Handler to resume a draft
+func (vh *VisaHandler) FetchDraft(c *fiber.Ctx) error {
+	applicationID := c.Params("application_id")
+	if _, err := ulid.Parse(applicationID); err != nil {
+		return response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid application id format",
+			err.Error(),
+		))
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	draft, err := vh.Usecase.FetchDraft(ctx, userID, applicationID)
+	if err != nil {
+		return visaErrorResponse(c, err)
+	}
+
+	return response.Success(c, "", draftPayload(draft))
+}
+
+// Handler for draft autosave (PATCH with JSON merge semantics)
+func (vh *VisaHandler) AutosaveDraft(c *fiber.Ctx) error {
+	var reqBody request.UpdateVisaDraftRequest
+	if err := reqBody.Bind(c, vh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	draft, err := vh.Usecase.AutosaveDraft(ctx, reqBody)
+	if err != nil {
+		return visaErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Draft saved", draftPayload(draft))
+}
+
+// Handler to submit a completed draft
+func (vh *VisaHandler) SubmitDraft(c *fiber.Ctx) error {
+	var reqBody request.SubmitVisaDraftRequest
+	if err := reqBody.Bind(c, vh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := vh.Usecase.SubmitDraft(ctx, reqBody, vh.Validator); err != nil {
+		return visaErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Visa application was successful")
+}
+
+// Draft response shape, keeps the stored form input as raw JSON
+func draftPayload(draft *entity.VisaApplication) map[string]any {
+	return map[string]any{
+		"id":              draft.ID,
+		"status":          draft.Status,
+		"version":         draft.Version,
+		"visa_form_input": json.RawMessage(draft.VisaFormInput),
+		"visa_form_url":   draft.VisaFormURL,
+		"updated_at":      draft.UpdatedAt,
+	}
+}
+
+// Maps visa usecase errors to responses
+func visaErrorResponse(c *fiber.Ctx, err error) error {
+	var eligibilityErr *usecase.EligibilityError
+	var fieldErrs apperror.ValidationErrors
+
+	switch {
+	case errors.As(err, &fieldErrs):
+		return response.InvalidFields(c, fieldErrs)
+	case errors.As(err, &eligibilityErr):
+		return response.NotEligible(c, err.Error(), eligibilityErr.Result)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, apperror.New(
+			apperror.ErrCodeInvalidApplication,
+			"Application not found",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrVersionConflict):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeInvalidApplication,
+			"Draft is out of date",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrNotDraft):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeInvalidApplication,
+			"Application is not a draft",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrPaymentRequired):
+		return response.PaymentRequired(c, err.Error())
+	case errors.Is(err, usecase.ErrNotPartner):
+		return response.Unprocessable(c, apperror.NewValidationErr(err.Error()))
+	case errors.Is(err, usecase.ErrIncompleteApplication):
+		return response.Unprocessable(c, apperror.NewValidationErr(err.Error()))
+	case errors.Is(err, usecase.ErrInvalidStatusTransition):
+		return response.Conflict(c, apperror.New(
+			apperror.ErrCodeInvalidApplication,
+			"Invalid status change",
+			err.Error(),
+		))
+	default:
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+}
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"time"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"japa/internal/app/http/dto/apperror"
+	"japa/internal/app/http/dto/request"
+	"japa/internal/app/http/dto/response"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/usecase"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// TYPES
+
+// Webhook handler, shared by the partner and admin routes
+type WebhookHandler struct {
+	Validator *validator.Validate
+	Usecase   *usecase.WebhookUsecase
+}
+
+// METHODS
+
+// Initialize Webhook handler
+func NewWebhookHandler(v *validator.Validate, uc *usecase.WebhookUsecase) *WebhookHandler {
+	return &WebhookHandler{v, uc}
+}
+
+// Handler to register an endpoint, the response holds the only copy of the secret
+func (wh *WebhookHandler) CreateEndpoint(c *fiber.Ctx) error {
+	var reqBody request.CreateWebhookEndpointRequest
+	if err := reqBody.Bind(c, wh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+	reqBody.OwnerID = webhookOwner(c)
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	endpoint, err := wh.Usecase.CreateEndpoint(ctx, reqBody)
+	if err != nil {
+		return webhookErrorResponse(c, err)
+	}
+
+	payload := endpointPayload(endpoint)
+	payload["secret"] = endpoint.Secret
+	return response.Created(c, payload)
+}
+
+// Handler listing endpoints
+func (wh *WebhookHandler) FetchEndpoints(c *fiber.Ctx) error {
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	endpoints, err := wh.Usecase.FetchEndpoints(ctx, webhookOwner(c))
+	if err != nil {
+		return webhookErrorResponse(c, err)
+	}
+
+	items := make([]map[string]any, len(endpoints))
+	for i := range endpoints {
+		items[i] = endpointPayload(&endpoints[i])
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items":       items,
+		"event_types": entity.WebhookEventTypes,
+	})
+}
+
+// Handler to update an endpoint
+func (wh *WebhookHandler) UpdateEndpoint(c *fiber.Ctx) error {
+	var reqBody request.UpdateWebhookEndpointRequest
+	if err := reqBody.Bind(c, wh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+	reqBody.OwnerID = webhookOwner(c)
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	endpoint, err := wh.Usecase.UpdateEndpoint(ctx, reqBody)
+	if err != nil {
+		return webhookErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Webhook endpoint updated", endpointPayload(endpoint))
+}
+
+// Handler to rotate an endpoint's signing secret
+func (wh *WebhookHandler) RotateSecret(c *fiber.Ctx) error {
+	endpointID, err := webhookParam(c, "endpoint_id")
+	if err != nil {
+		return err
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	endpoint, err := wh.Usecase.RotateSecret(ctx, webhookOwner(c), endpointID)
+	if err != nil {
+		return webhookErrorResponse(c, err)
+	}
+
+	payload := endpointPayload(endpoint)
+	payload["secret"] = endpoint.Secret
+	return response.Success(c, "Webhook secret rotated", payload)
+}
+
+// Handler to delete an endpoint
+func (wh *WebhookHandler) DeleteEndpoint(c *fiber.Ctx) error {
+	endpointID, err := webhookParam(c, "endpoint_id")
+	if err != nil {
+		return err
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := wh.Usecase.DeleteEndpoint(ctx, webhookOwner(c), endpointID); err != nil {
+		return webhookErrorResponse(c, err)
+	}
+
+	return response.Success(c, "Webhook endpoint deleted")
+}
+
+// Handler for an endpoint's delivery log
+func (wh *WebhookHandler) FetchDeliveries(c *fiber.Ctx) error {
+	var reqBody request.FetchWebhookDeliveriesRequest
+	if err := reqBody.Bind(c, wh.Validator); err != nil {
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	}
+	reqBody.OwnerID = webhookOwner(c)
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	deliveries, total, err := wh.Usecase.FetchDeliveries(ctx, reqBody)
+	if err != nil {
+		return webhookErrorResponse(c, err)
+	}
+
+	items := make([]map[string]any, len(deliveries))
+	for i := range deliveries {
+		items[i] = deliveryPayload(&deliveries[i])
+	}
+
+	return response.Success(c, "", map[string]any{
+		"items": items,
+		"total": total,
+	})
+}
+
+// Handler for a single delivery with its payload and attempts
+func (wh *WebhookHandler) FetchDelivery(c *fiber.Ctx) error {
+	deliveryID, err := webhookParam(c, "delivery_id")
+	if err != nil {
+		return err
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	delivery, attempts, err := wh.Usecase.FetchDelivery(ctx, webhookOwner(c), deliveryID)
+	if err != nil {
+		return webhookErrorResponse(c, err)
+	}
+
+	payload := deliveryPayload(delivery)
+	payload["payload"] = json.RawMessage(delivery.Payload)
+	payload["attempts"] = attempts
+	return response.Success(c, "", payload)
+}
+
+// Handler to send a delivery again
+func (wh *WebhookHandler) ReplayDelivery(c *fiber.Ctx) error {
+	deliveryID, err := webhookParam(c, "delivery_id")
+	if err != nil {
+		return err
+	}
+
+	// Context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	replay, err := wh.Usecase.Replay(ctx, webhookOwner(c), deliveryID)
+	if err != nil {
+		return webhookErrorResponse(c, err)
+	}
+
+	return response.Created(c, deliveryPayload(replay))
+}
+
+// Partners manage their own endpoints, admins manage all of them
+func webhookOwner(c *fiber.Ctx) string {
+	if role, _ := c.Locals("role").(string); role == "partner" {
+		userID, _ := c.Locals("user_id").(string)
+		return userID
+	}
+	return ""
+}
+
+// Reads a ULID route param, writing the bad request response on failure
+func webhookParam(c *fiber.Ctx, name string) (string, error) {
+	value := c.Params(name)
+	if _, err := ulid.Parse(value); err != nil {
+		return "", response.BadRequest(c, apperror.New(
+			apperror.ErrCodeInvalidID,
+			"invalid "+name+" format",
+			err.Error(),
+		))
+	}
+	return value, nil
+}
+
+// Endpoint response shape, without the secret
+func endpointPayload(endpoint *entity.WebhookEndpoint) map[string]any {
+	return map[string]any{
+		"id":          endpoint.ID,
+		"owner_id":    endpoint.OwnerID,
+		"url":         endpoint.URL,
+		"events":      endpoint.Events,
+		"description": endpoint.Description,
+		"active":      endpoint.Active,
+		"created_at":  endpoint.CreatedAt,
+		"updated_at":  endpoint.UpdatedAt,
+	}
+}
+
+// Delivery response shape
+func deliveryPayload(delivery *entity.WebhookDelivery) map[string]any {
+	return map[string]any{
+		"id":               delivery.ID,
+		"endpoint_id":      delivery.EndpointID,
+		"event_id":         delivery.EventID,
+		"event_type":       delivery.EventType,
+		"replay_of_id":     delivery.ReplayOfID,
+		"status":           delivery.Status,
+		"attempts":         delivery.Attempts,
+		"next_attempt_at":  delivery.NextAttemptAt,
+		"last_status_code": delivery.LastStatusCode,
+		"last_error":       delivery.LastError,
+		"delivered_at":     delivery.DeliveredAt,
+		"created_at":       delivery.CreatedAt,
+	}
+}
+
+// Maps webhook usecase errors to responses
+func webhookErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, apperror.New(
+			apperror.ErrCodeRecordNotFound,
+			"Webhook not found",
+			err.Error(),
+		))
+	case errors.Is(err, usecase.ErrInsecureWebhookURL), errors.Is(err, usecase.ErrUnknownWebhookEvent):
+		return response.BadRequest(c, apperror.NewValidationErr(err.Error()))
+	default:
+		return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+	}
+}
@@ -70,7 +70,7 @@ func (middleware *AuthMiddleware) Handler() fiber.Handler {
 		
 		// Fetch user basic info
 		if err := middleware.DB.
-			Select("id", "full_name", "username", "role", "banned_until", "ban_reason").
+			Select("id", "full_name", "username", "role", "banned_until", "ban_reason", "country").
 			First(&user, "id = ?", userID).Error; err != nil {
 			return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
 		}
@@ -82,43 +82,26 @@ func (middleware *AuthMiddleware) Handler() fiber.Handler {
 			}
 		}
 
-		////// SUBSCRIPTION LOGIC & CANCELATION ///////
+		////// SUBSCRIPTION LOGIC ///////
 
 		var userSubscriptions []entity.Subscription
 
-		// Fetch all subscriptions for this user
+		// Fetch the user's live subscriptions, the renewal job moves them between states
 		if err := middleware.DB.
 			Where("user_id = ?", user.ID).
-			//Where("status = ?", "active").
-			//Where("expires_at > ?", time.Now()).
+			Where("status IN ?", []string{entity.SubscriptionStatusActive, entity.SubscriptionStatusPastDue}).
 			Order("started_at DESC").
 			Find(&userSubscriptions).Error; err != nil {
 			return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
 		}
 
-		// User active subscription
+		// User active subscription, the latest one in good standing
 		var userActiveSubscription *entity.Subscription
-
-		// Loop through subscriptions to expire outdated ones
-		// and pick the latest active
+		now := time.Now()
 		for i := range userSubscriptions {
-			s := &userSubscriptions[i]
-
-			// Check if expired and status still active
-			if s.ExpiresAt.Before(time.Now()) && s.Status == "active" {
-				s.Status = "expired"
-				if err := middleware.DB.Model(s).Update("status", "expired").Error; err != nil {
-					// Log error
-					zap.L().Error(
-						"Failure updating subscription status",
-						zap.String("subscriptionID", s.ID),
-					)
-				}
-			}
-
-			// If still active, pick as userActiveSubscription
-			if s.Status == "active" && s.ExpiresAt.After(time.Now()) && userActiveSubscription == nil {
-				userActiveSubscription = s
+			if userSubscriptions[i].InGoodStanding(now) {
+				userActiveSubscription = &userSubscriptions[i]
+				break
 			}
 		}
 
@@ -128,6 +111,9 @@ func (middleware *AuthMiddleware) Handler() fiber.Handler {
 		c.Locals("username", user.Username)
 		c.Locals("role", user.Role)
 		c.Locals("subscription", userActiveSubscription)
+		if user.Country != nil {
+			c.Locals("country", *user.Country)
+		}
 
 		// Continue to next middleware/handler
 		return c.Next()
@@ -171,6 +157,12 @@ func AuthorOnly() fiber.Handler {
 }
 
 
+// PartnerOnly returns middleware for partners (referring consultancies) and admins only
+func PartnerOnly() fiber.Handler {
+	return RoleRequired("partner", "admin", "superadmin")
+}
+
+
 // AdminOnly returns middleware for admins only
 func AdminOnly() fiber.Handler {
 	return RoleRequired("admin", "superadmin")
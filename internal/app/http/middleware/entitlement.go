@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"japa/internal/app/http/dto/apperror"
+	"japa/internal/app/http/dto/response"
+	"japa/internal/domain/usecase"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireEntitlement returns a middleware that lets the request through only when the user's plan includes key.
+// Must run after the auth middleware. Example usage: RequireEntitlement(entitlementUsecase, entity.EntitlementAIAdvisor)
+func RequireEntitlement(entitlements *usecase.EntitlementUsecase, key string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, ok := c.Locals("user_id").(string)
+		if !ok || userID == "" {
+			return response.Unauthorized(c, apperror.NewUnauthorizedErr("User cannot be identified"))
+		}
+
+		// Context with timeout
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := entitlements.RequireEntitlement(ctx, entitlements.DB, userID, key); err != nil {
+			if errors.Is(err, usecase.ErrNotEntitled) {
+				return response.Forbidden(c, apperror.New(
+					apperror.ErrCodeUpgradeRequired,
+					"Your plan does not include this feature",
+					err.Error(),
+				))
+			}
+			return response.InternalServerError(c, apperror.NewServerErr(err.Error()))
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// IPCountry returns a middleware that keeps the visitor's country from header, set by the CDN or proxy
+// in front of the app, in the "ip_country" local. Unknown ("XX") and Tor ("T1") countries are left out.
+// Example usage: IPCountry("CF-IPCountry")
+func IPCountry(header string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		country := strings.ToUpper(strings.TrimSpace(c.Get(header)))
+		if len(country) == 2 && country != "XX" && country != "T1" {
+			c.Locals("ip_country", country)
+		}
+		return c.Next()
+	}
+}
@@ -66,8 +66,10 @@ type ServerConfig struct {
 	ServerAddress           string
 	ServerPort              string
 	AuthorizationHeaderPath string
+	CountryHeader           string // Visitor's country set by the CDN or proxy, i.e Cloudflare's "CF-IPCountry"
 	TemplatesDir            string
 	AssetsDir               string
+	UploadsDir              string // Local uploads, the only files the purge job may delete
 }
 
 type SiteConfig struct {
@@ -77,13 +79,115 @@ type SiteConfig struct {
 	LogoURL    string
 }
 
+type AppointmentConfig struct {
+	ReminderOffsets  []time.Duration // How long before an appointment reminders go out
+	ReminderInterval time.Duration   // How often the reminder job runs
+}
+
+type SLAConfig struct {
+	WarningPercent     int           // Share of the target elapsed before an application is "at risk"
+	EscalationInterval time.Duration // How often the escalation job runs
+}
+
+type VisaValidationConfig struct {
+	PassportValidityMonths        int            // Months a passport must stay valid after travel
+	PassportValidityByDestination map[string]int // Per destination overrides, keys lowercased
+	MaxTravelAheadMonths          int            // How far ahead a travel date may be
+	MinApplicantAge               int
+	MaxApplicantAge               int
+}
+
+type WebhookConfig struct {
+	MaxAttempts      int           // Deliveries are dead-lettered after this many attempts
+	BaseBackoff      time.Duration // Delay before the first retry, doubled on every attempt
+	MaxBackoff       time.Duration
+	Timeout          time.Duration // Per request
+	DeliveryInterval time.Duration // How often the delivery job runs
+	BatchSize        int           // Deliveries per run
+	AllowInsecureURL bool          // Accept http:// endpoints (local development only)
+}
+
+type FraudConfig struct {
+	HashKey           string        // HMAC key for fingerprints, changing it orphans existing ones
+	RapidRepeatWindow time.Duration
+	RapidRepeatCount  int           // Submissions within the window that raise a signal
+}
+
+type RetentionConfig struct {
+	PurgeInterval time.Duration // How often the purge job runs
+	BatchSize     int           // Applications loaded per query
+}
+
+type PaymentConfig struct {
+	Providers              []string // Priority order, i.e "paystack,flutterwave" or "fake" locally
+	Timeout                time.Duration
+	PaystackBaseURL        string
+	PaystackSecretKey      string
+	FlutterwaveBaseURL     string
+	FlutterwaveSecretKey   string
+	FlutterwaveWebhookHash string        // Secret hash set on the Flutterwave dashboard
+	FakeAutoSucceed        bool          // Fake payments succeed on verify without a checkout
+	ReconcileInterval      time.Duration // How often pending payments are re-verified
+	ReconcileAfter         time.Duration // Pending payments younger than this are left to webhooks
+	ReconcileWindow        time.Duration // Pending payments older than this are abandoned checkouts
+}
+
+type BillingConfig struct {
+	RequirePayment   bool          // Applications leave draft only once paid or covered by a subscription
+	Currency         string        // Base currency, prices fall back to it
+	Currencies       []string      // Price books customers can be priced in, lower case
+	FallbackCurrency string        // Price book for customers from countries without one of their own
+	CouponHold       time.Duration // How long an unpaid checkout holds a coupon redemption
+	InvoicePrefix    string        // Invoice numbers are "<prefix>-<year>-<sequence>"
+	TaxName          string        // Tax line on invoices, i.e "VAT"
+	TaxRate          int           // Basis points, prices include it. 0 leaves the tax line off
+	TaxNumber        string        // Our tax registration number printed on invoices, optional
+	Address          string        // Our address printed on invoices, optional
+}
+
+type AffiliateConfig struct {
+	CookieName        string         // Referral link cookie read at registration
+	CookieTTL         time.Duration
+	AttributionWindow time.Duration  // Referred users' plan payments earn commission this long after signing up
+	HoldPeriod        time.Duration  // Earnings become available after this, refunds before it reverse them
+	PayoutMinimums    map[string]int // Minor units by lower case currency, smaller balances wait for a later batch
+	PayoutEvery       time.Duration  // Between scheduled payout batches
+	PayoutInterval    time.Duration  // How often the payout job runs, it also checks on pending transfers
+}
+
+type LedgerConfig struct {
+	ReconcileInterval time.Duration // How often the reconciliation job looks for days to report on
+	ReconcileDays     int           // Days back that are reported on, and rerun until they match
+	SettleAfter       time.Duration // A day is reported on this long after it ends, for late webhooks
+}
+
+type SubscriptionConfig struct {
+	RenewalInterval    time.Duration // How often the lifecycle job runs
+	GracePeriod        time.Duration // Past due subscriptions keep their plan this long
+	RetryInterval      time.Duration // Between failed renewal charges
+	MaxRenewalAttempts int           // Charges per period before waiting out the grace period
+	ReminderBefore     time.Duration // Upcoming renewal email lead time
+	FreePlan           string        // Plan whose entitlements users without a subscription get
+}
+
 type Config struct {
-	SiteConfig       SiteConfig
-	ServerConfig     ServerConfig
-	DBConfig         DBConfig
-	EmailConfig      EmailConfig
-	JWTConfig        JWTConfig
-	LoggingConfig    LoggingConfig
+	SiteConfig         SiteConfig
+	ServerConfig       ServerConfig
+	DBConfig           DBConfig
+	EmailConfig        EmailConfig
+	JWTConfig          JWTConfig
+	LoggingConfig      LoggingConfig
+	AppointmentConfig  AppointmentConfig
+	SLAConfig          SLAConfig
+	VisaValidation     VisaValidationConfig
+	WebhookConfig      WebhookConfig
+	FraudConfig        FraudConfig
+	RetentionConfig    RetentionConfig
+	PaymentConfig      PaymentConfig
+	BillingConfig      BillingConfig
+	SubscriptionConfig SubscriptionConfig
+	AffiliateConfig    AffiliateConfig
+	LedgerConfig       LedgerConfig
 }
 
 // Initialize configurations
@@ -99,8 +203,10 @@ func InitConfig(envFilePath string) *Config {
 		ServerConfig: ServerConfig{
 			ServerAddress:           getEnv("SERVER_ADDRESS", ":8080"),
 			AuthorizationHeaderPath: "Authorization",
+			CountryHeader:           getEnv("COUNTRY_HEADER", "CF-IPCountry"),
 			TemplatesDir:            getEnv("TEMPLATE_DIR", "templates"),
 			AssetsDir:               getEnv("ASSET_DIR", "assets"),
+			UploadsDir:              getEnv("UPLOAD_DIR", "uploads"),
 		},
 		DBConfig: DBConfig{
 			DBURL:        getEnv("DBURL", ""),
@@ -128,6 +234,86 @@ func InitConfig(envFilePath string) *Config {
 			LogFilePath:      getEnv("LOG_FILE_PATH", "./logs/japa.log"),
 			ErrorLogFilePath: getEnv("ERROR_LOG_FILE_PATH", "./logs/japa-errors.log"),
 		},
+		AppointmentConfig: AppointmentConfig{
+			ReminderOffsets:  getEnvDurations("APPOINTMENT_REMINDER_OFFSETS", "72h,24h,2h"),
+			ReminderInterval: getEnvDuration("APPOINTMENT_REMINDER_INTERVAL", "5m"),
+		},
+		SLAConfig: SLAConfig{
+			WarningPercent:     getEnvInt("SLA_WARNING_PERCENT", 80),
+			EscalationInterval: getEnvDuration("SLA_ESCALATION_INTERVAL", "15m"),
+		},
+		VisaValidation: VisaValidationConfig{
+			PassportValidityMonths:        getEnvInt("PASSPORT_VALIDITY_MONTHS", 6),
+			PassportValidityByDestination: getEnvIntMap("PASSPORT_VALIDITY_BY_DESTINATION", "united kingdom:0,ireland:0,germany:3,france:3,netherlands:3,italy:3,spain:3"),
+			MaxTravelAheadMonths:          getEnvInt("MAX_TRAVEL_AHEAD_MONTHS", 24),
+			MinApplicantAge:               getEnvInt("MIN_APPLICANT_AGE", 0),
+			MaxApplicantAge:               getEnvInt("MAX_APPLICANT_AGE", 100),
+		},
+		WebhookConfig: WebhookConfig{
+			MaxAttempts:      getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			BaseBackoff:      getEnvDuration("WEBHOOK_BASE_BACKOFF", "30s"),
+			MaxBackoff:       getEnvDuration("WEBHOOK_MAX_BACKOFF", "6h"),
+			Timeout:          getEnvDuration("WEBHOOK_TIMEOUT", "10s"),
+			DeliveryInterval: getEnvDuration("WEBHOOK_DELIVERY_INTERVAL", "30s"),
+			BatchSize:        getEnvInt("WEBHOOK_BATCH_SIZE", 50),
+			AllowInsecureURL: getEnvBool("WEBHOOK_ALLOW_INSECURE_URL", false),
+		},
+		FraudConfig: FraudConfig{
+			HashKey:           getEnv("FRAUD_HASH_KEY", ""),
+			RapidRepeatWindow: getEnvDuration("FRAUD_RAPID_REPEAT_WINDOW", "24h"),
+			RapidRepeatCount:  getEnvInt("FRAUD_RAPID_REPEAT_COUNT", 3),
+		},
+		RetentionConfig: RetentionConfig{
+			PurgeInterval: getEnvDuration("RETENTION_PURGE_INTERVAL", "24h"),
+			BatchSize:     getEnvInt("RETENTION_BATCH_SIZE", 200),
+		},
+		PaymentConfig: PaymentConfig{
+			Providers:              getEnvList("PAYMENT_PROVIDERS", ""),
+			Timeout:                getEnvDuration("PAYMENT_TIMEOUT", "15s"),
+			PaystackBaseURL:        getEnv("PAYSTACK_BASE_URL", "https://api.paystack.co"),
+			PaystackSecretKey:      getEnvOptional("PAYSTACK_SECRET_KEY"),
+			FlutterwaveBaseURL:     getEnv("FLUTTERWAVE_BASE_URL", "https://api.flutterwave.com/v3"),
+			FlutterwaveSecretKey:   getEnvOptional("FLUTTERWAVE_SECRET_KEY"),
+			FlutterwaveWebhookHash: getEnvOptional("FLUTTERWAVE_WEBHOOK_HASH"),
+			FakeAutoSucceed:        getEnvBool("PAYMENT_FAKE_AUTO_SUCCEED", true),
+			ReconcileInterval:      getEnvDuration("PAYMENT_RECONCILE_INTERVAL", "15m"),
+			ReconcileAfter:         getEnvDuration("PAYMENT_RECONCILE_AFTER", "10m"),
+			ReconcileWindow:        getEnvDuration("PAYMENT_RECONCILE_WINDOW", "72h"),
+		},
+		BillingConfig: BillingConfig{
+			RequirePayment:   getEnvBool("BILLING_REQUIRE_PAYMENT", true),
+			Currency:         getEnv("BILLING_CURRENCY", "NGN"), // ISO 4217, upper case
+			Currencies:       getEnvList("BILLING_CURRENCIES", "NGN,GHS,KES,USD,GBP"),
+			FallbackCurrency: getEnv("BILLING_FALLBACK_CURRENCY", "USD"),
+			CouponHold:       getEnvDuration("BILLING_COUPON_HOLD", "1h"),
+			InvoicePrefix:    getEnv("BILLING_INVOICE_PREFIX", "INV"),
+			TaxName:          getEnv("BILLING_TAX_NAME", "VAT"),
+			TaxRate:          getEnvInt("BILLING_TAX_RATE_BPS", 750), // 7.5%
+			TaxNumber:        getEnvOptional("BILLING_TAX_NUMBER"),
+			Address:          getEnvOptional("BILLING_ADDRESS"),
+		},
+		SubscriptionConfig: SubscriptionConfig{
+			RenewalInterval:    getEnvDuration("SUBSCRIPTION_RENEWAL_INTERVAL", "1h"),
+			GracePeriod:        getEnvDuration("SUBSCRIPTION_GRACE_PERIOD", "72h"),
+			RetryInterval:      getEnvDuration("SUBSCRIPTION_RETRY_INTERVAL", "24h"),
+			MaxRenewalAttempts: getEnvInt("SUBSCRIPTION_MAX_RENEWAL_ATTEMPTS", 3),
+			ReminderBefore:     getEnvDuration("SUBSCRIPTION_REMINDER_BEFORE", "72h"),
+			FreePlan:           getEnv("SUBSCRIPTION_FREE_PLAN", "free"), // Plan code
+		},
+		AffiliateConfig: AffiliateConfig{
+			CookieName:        getEnv("AFFILIATE_COOKIE_NAME", "japa_ref"),
+			CookieTTL:         getEnvDuration("AFFILIATE_COOKIE_TTL", "720h"),
+			AttributionWindow: getEnvDuration("AFFILIATE_ATTRIBUTION_WINDOW", "8760h"),
+			HoldPeriod:        getEnvDuration("AFFILIATE_HOLD_PERIOD", "336h"),
+			PayoutMinimums:    getEnvIntMap("AFFILIATE_PAYOUT_MINIMUMS", "ngn:500000,ghs:50000,kes:100000,usd:2000,gbp:2000"),
+			PayoutEvery:       getEnvDuration("AFFILIATE_PAYOUT_EVERY", "168h"), // Weekly
+			PayoutInterval:    getEnvDuration("AFFILIATE_PAYOUT_INTERVAL", "1h"),
+		},
+		LedgerConfig: LedgerConfig{
+			ReconcileInterval: getEnvDuration("LEDGER_RECONCILE_INTERVAL", "1h"),
+			ReconcileDays:     getEnvInt("LEDGER_RECONCILE_DAYS", 7),
+			SettleAfter:       getEnvDuration("LEDGER_SETTLE_AFTER", "2h"),
+		},
 	}
 	Settings = *cfg
 	return cfg
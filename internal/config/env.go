@@ -3,7 +3,9 @@ package config
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -18,6 +20,23 @@ func getEnv(key string, ifNotFound string) string {
 	return value
 }
 
+// Like getEnv but a missing key is allowed, for secrets of optional integrations
+func getEnvOptional(key string) string {
+	value, _ := os.LookupEnv(key)
+	return value
+}
+
+// Comma separated values, trimmed and lowercased i.e "paystack, flutterwave"
+func getEnvList(key string, defaultVal string) []string {
+	var values []string
+	for _, val := range strings.Split(getEnv(key, defaultVal), ",") {
+		if val = strings.ToLower(strings.TrimSpace(val)); val != "" {
+			values = append(values, val)
+		}
+	}
+	return values
+}
+
 func getEnvInt(key string, defaultVal int) int {
 	valStr, ok := os.LookupEnv(key)
 	if !ok {
@@ -30,6 +49,56 @@ func getEnvInt(key string, defaultVal int) int {
 	return val
 }
 
+func getEnvBool(key string, defaultVal bool) bool {
+	valStr, ok := os.LookupEnv(key)
+	if !ok {
+		return defaultVal
+	}
+	val, err := strconv.ParseBool(valStr)
+	if err != nil {
+		panic(fmt.Sprintf("Invalid bool value for '%s': %s", key, valStr))
+	}
+	return val
+}
+
+func getEnvDuration(key string, defaultVal string) time.Duration {
+	return mustParseDuration(getEnv(key, defaultVal))
+}
+
+// Comma separated durations i.e "72h,24h,2h"
+func getEnvDurations(key string, defaultVal string) []time.Duration {
+	var durations []time.Duration
+	for _, val := range strings.Split(getEnv(key, defaultVal), ",") {
+		if val = strings.TrimSpace(val); val != "" {
+			durations = append(durations, mustParseDuration(val))
+		}
+	}
+	return durations
+}
+
+// Comma separated key:int pairs, keys lowercased i.e "germany:3,united kingdom:0".
+// Unlike getEnv an empty default is allowed.
+func getEnvIntMap(key string, defaultVal string) map[string]int {
+	valStr, ok := os.LookupEnv(key)
+	if !ok {
+		valStr = defaultVal
+	}
+
+	values := make(map[string]int)
+	for _, pair := range strings.Split(valStr, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, num, found := strings.Cut(pair, ":")
+		val, err := strconv.Atoi(strings.TrimSpace(num))
+		if !found || err != nil {
+			panic(fmt.Sprintf("Invalid key:int pair for '%s': %s", key, pair))
+		}
+		values[strings.ToLower(strings.TrimSpace(name))] = val
+	}
+	return values
+}
+
 func loadEnvFile(envPath string) {
 	if err := godotenv.Load(envPath); err != nil {
 		fmt.Printf("Error loading .env file: %s\n", envPath)
@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"time"
+)
+
+// What a commission rule pays on
+const (
+	CommissionProductPlan        = "plan"        // Subscription payments
+	CommissionProductApplication = "application" // Application checkouts, per application
+)
+
+// Commission ledger entry kinds
+const (
+	CommissionKindEarning  = "earning"
+	CommissionKindReversal = "reversal" // Negative, the earning's payment was refunded
+)
+
+// ReferralCode is a partner's code, shared as is or as a referral link
+type ReferralCode struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+
+	PartnerID string    `gorm:"column:partner_id;type:varchar(60);not null;index"` // FK to User
+	Partner   User      `gorm:"foreignKey:PartnerID"`
+
+	Code      string    `gorm:"column:code;type:varchar(40);not null;uniqueIndex"` // Upper case, i.e "ADA2025"
+	Label     string    `gorm:"column:label;type:varchar(100)"`                   // Partner's own note, i.e "YouTube"
+	Active    bool      `gorm:"column:active;not null;default:true"`
+	Clicks    int64     `gorm:"column:clicks;not null;default:0"` // Referral link visits
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// CommissionRule is what partners earn on a product. PlanCode narrows a plan rule to one plan,
+// empty matches every plan. Application rules ignore it.
+type CommissionRule struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+
+	Product   string    `gorm:"column:product;type:varchar(20);not null;uniqueIndex:idx_commission_rule"` // "plan", "application"
+	PlanCode  string    `gorm:"column:plan_code;type:varchar(40);not null;default:'';uniqueIndex:idx_commission_rule"`
+
+	Type      string    `gorm:"column:type;type:varchar(20);not null"` // "percentage", "fixed" (coupon types)
+	Percent   int       `gorm:"column:percent;not null;default:0"`     // 1-100 of what was paid, percentage rules
+	Amount    int64     `gorm:"column:amount;not null;default:0"`      // Minor units, fixed rules
+	Currency  *string   `gorm:"column:currency;type:char(3);null"`     // Fixed rules only pay on payments in their currency
+	Recurring bool      `gorm:"column:recurring;not null;default:false"` // Plan rules: every payment, not just the first
+	Active    bool      `gorm:"column:active;not null;default:true"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Commission is an entry in the partners' commission ledger. Entries are never edited,
+// a refund adds a negative reversal pointing at the earning.
+type Commission struct {
+	ID             string    `gorm:"type:varchar(60);primaryKey"`
+
+	PartnerID      string    `gorm:"column:partner_id;type:varchar(60);not null;index"`
+	ReferredUserID string    `gorm:"column:referred_user_id;type:varchar(60);not null;index"`
+	PaymentID      string    `gorm:"column:payment_id;type:varchar(60);not null;index"`
+	RuleID         *uint     `gorm:"column:rule_id;null"`
+	ReversalOf     *string   `gorm:"column:reversal_of;type:varchar(60);null;index"` // Earning a reversal takes back
+
+	Kind           string    `gorm:"column:kind;type:varchar(20);not null"`    // "earning", "reversal"
+	Product        string    `gorm:"column:product;type:varchar(20);not null"` // "plan", "application"
+	Base           int64     `gorm:"column:base;not null"`                     // Minor units the commission was worked out on
+	Amount         int64     `gorm:"column:amount;not null"`                   // Minor units, negative for reversals
+	Currency       string    `gorm:"column:currency;type:char(3);not null"`
+	AvailableAt    time.Time `gorm:"column:available_at;not null"` // Earnings are held until refunds are unlikely
+
+	CreatedAt      time.Time
+}
@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"time"
+)
+
+// Appointment types
+const (
+	AppointmentTypeEmbassyInterview = "embassy_interview"
+	AppointmentTypeBiometrics       = "biometrics"
+	AppointmentTypeMedical          = "medical"
+	AppointmentTypeDocumentDropOff  = "document_drop_off"
+)
+
+// Appointment statuses
+const (
+	AppointmentStatusScheduled = "scheduled"
+	AppointmentStatusCompleted = "completed"
+	AppointmentStatusCancelled = "cancelled"
+	AppointmentStatusMissed    = "missed"
+)
+
+// Appointment is an embassy/biometrics (etc.) appointment booked for an application
+type Appointment struct {
+	ID                string          `gorm:"type:varchar(60);primaryKey"`
+	VisaApplicationID string          `gorm:"column:visa_application_id;type:varchar(60);not null;index"`
+	VisaApplication   VisaApplication `gorm:"foreignKey:VisaApplicationID"`
+
+	Type              string          `gorm:"column:type;type:varchar(30);not null"`
+	Location          string          `gorm:"column:location;type:varchar(150);not null"` // i.e "VFS Global, Lagos"
+	Address           *string         `gorm:"column:address;type:text;null"`
+
+	ScheduledAt       time.Time       `gorm:"column:scheduled_at;not null;index"` // Stored in UTC
+	Timezone          string          `gorm:"column:timezone;type:varchar(60);not null"` // IANA zone of the location, i.e "Africa/Lagos"
+	DurationMinutes   int             `gorm:"column:duration_minutes;not null;default:30"`
+
+	Status            string          `gorm:"column:status;type:varchar(20);not null;default:'scheduled';index"`
+	Notes             *string         `gorm:"column:notes;type:text;null"` // Instructions for the applicant
+	Sequence          int             `gorm:"column:sequence;not null;default:0"` // iCalendar SEQUENCE, bumped on every change
+
+	CreatedByID       string          `gorm:"column:created_by_id;type:varchar(60);not null"` // Agent who booked it
+
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// AppointmentReminder records reminders already sent, one per configured offset
+type AppointmentReminder struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement"`
+	AppointmentID string    `gorm:"column:appointment_id;type:varchar(60);not null;uniqueIndex:idx_appointment_offset"`
+	OffsetMinutes int       `gorm:"column:offset_minutes;not null;uniqueIndex:idx_appointment_offset"`
+	SentAt        time.Time `gorm:"column:sent_at;not null"`
+}
+
+// LocalTime returns the appointment time in the location's timezone
+func (a *Appointment) LocalTime() time.Time {
+	location, err := time.LoadLocation(a.Timezone)
+	if err != nil {
+		return a.ScheduledAt.UTC()
+	}
+	return a.ScheduledAt.In(location)
+}
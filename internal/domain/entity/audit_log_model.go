@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"time"
+)
+
+// Audited actions
+const (
+	AuditActionApplicationsExport = "applications.export"
+	AuditActionPermissionGrant    = "permissions.grant"
+	AuditActionPermissionRevoke   = "permissions.revoke"
+	AuditActionLegalHoldPlace     = "legal_hold.place"
+	AuditActionLegalHoldRelease   = "legal_hold.release"
+)
+
+// AuditLog is an append-only record of sensitive actions
+type AuditLog struct {
+	ID           string    `gorm:"type:varchar(60);primaryKey"`
+	ActorID      string    `gorm:"column:actor_id;type:varchar(60);not null;index"`
+	Action       string    `gorm:"column:action;type:varchar(60);not null;index"`
+	ResourceType string    `gorm:"column:resource_type;type:varchar(60);not null"` // i.e "visa_application", "user"
+	ResourceID   *string   `gorm:"column:resource_id;type:varchar(60);null"`
+	Metadata     []byte    `gorm:"column:metadata;type:json"` // Filters, format, row counts etc.
+	IPAddress    string    `gorm:"column:ip_address;type:varchar(45)"`
+	CreatedAt    time.Time `gorm:"index"`
+}
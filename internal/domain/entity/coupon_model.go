@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"math"
+	"time"
+)
+
+// Coupon types
+const (
+	CouponTypePercentage = "percentage"
+	CouponTypeFixed      = "fixed"
+)
+
+// What a coupon can be redeemed on
+const (
+	CouponAppliesToAll           = "all"
+	CouponAppliesToApplications  = "applications"  // Application checkouts (purchases)
+	CouponAppliesToSubscriptions = "subscriptions" // First subscription payment
+)
+
+// Coupon redemption statuses
+const (
+	CouponRedemptionPending  = "pending"  // Checkout in flight, holds a redemption for a while
+	CouponRedemptionRedeemed = "redeemed"
+	CouponRedemptionVoid     = "void"     // Payment failed
+)
+
+// Coupon is a promo code taking a percentage or fixed amount off a checkout
+type Coupon struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement"`
+	Code           string     `gorm:"column:code;type:varchar(40);not null;uniqueIndex"` // Upper case, i.e "STUDENT20"
+	Description    string     `gorm:"column:description;type:varchar(255)"`
+
+	Type           string     `gorm:"column:type;type:varchar(20);not null"` // "percentage", "fixed"
+	PercentOff     int        `gorm:"column:percent_off;not null;default:0"` // 1-100, percentage coupons
+	AmountOff      int64      `gorm:"column:amount_off;not null;default:0"`  // Minor units, fixed coupons
+	Currency       *string    `gorm:"column:currency;type:char(3);null"`      // Fixed coupons only apply in their currency
+
+	AppliesTo      string     `gorm:"column:applies_to;type:varchar(20);not null;default:'all'"` // "all", "applications", "subscriptions"
+	PlanCodes      []byte     `gorm:"column:plan_codes;type:json"`                              // Subscription plans it is limited to, empty for every plan
+
+	MaxRedemptions *int       `gorm:"column:max_redemptions;null"`                // Null is unlimited
+	PerUserLimit   *int       `gorm:"column:per_user_limit;null;default:1"`        // Null is unlimited
+	StartsAt       *time.Time `gorm:"column:starts_at;null"`
+	ExpiresAt      *time.Time `gorm:"column:expires_at;null"`
+	Active         bool       `gorm:"column:active;not null;default:true"`
+
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Discount the coupon takes off amount, never more than amount
+func (c *Coupon) Discount(amount int64) int64 {
+	discount := c.AmountOff
+	if c.Type == CouponTypePercentage {
+		discount = int64(math.Round(float64(amount) * float64(c.PercentOff) / 100))
+	}
+	return min(max(discount, 0), amount)
+}
+
+// CouponRedemption is one use of a coupon, tied to the payment it discounted
+type CouponRedemption struct {
+	ID             string    `gorm:"type:varchar(60);primaryKey"`
+
+	CouponID       uint      `gorm:"column:coupon_id;not null;index"` // FK to Coupon
+	Coupon         Coupon    `gorm:"foreignKey:CouponID"`
+
+	UserID         string    `gorm:"column:user_id;type:varchar(60);not null;index"`
+	PaymentID      string    `gorm:"column:payment_id;type:varchar(60);not null;index"`
+	SubscriptionID *string   `gorm:"column:subscription_id;type:varchar(60);null"` // Subscription payments only
+
+	Status         string    `gorm:"column:status;type:varchar(20);not null;default:'pending'"` // "pending", "redeemed", "void"
+	Discount       int64     `gorm:"column:discount;not null"`                                  // Minor units
+	Currency       string    `gorm:"column:currency;type:char(3);not null"`
+
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
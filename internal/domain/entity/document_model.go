@@ -14,6 +14,7 @@ type Document struct {
 	VisaApplication   VisaApplication `gorm:"foreignKey:VisaApplicationID"`
 
 	FileType          string    `gorm:"type:varchar(60);not null"` // e.g. "passport_photo", "bank_statement", "signed_form"
-	FilePath          string    `gorm:"type:text;not null"` // where it's stored locally or cloud URL
+	FilePath          string    `gorm:"type:text;not null"` // where it's stored locally or cloud URL, emptied when purged
 	UploadedAt        time.Time `gorm:"autoCreateTime"`
+	PurgedAt          *time.Time `gorm:"null"` // File removed under the retention policy
 }
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"time"
+)
+
+// Eligibility rule types
+const (
+	RuleNationalityAllowed = "nationality_allowed" // Value: comma separated nationalities that qualify
+	RuleNationalityBlocked = "nationality_blocked" // Value: comma separated nationalities that don't qualify
+	RulePassportValidity   = "passport_validity"   // Value: months the passport must remain valid after travel
+	RuleMinFunds           = "min_funds"           // Value: minimum funds in Currency (major units)
+	RuleMinEducation       = "min_education"       // Value: secondary, diploma, bachelors, masters, doctorate
+	RuleNoPriorDenial      = "no_prior_denial"     // Value: unused
+)
+
+// EligibilityRule is one per-country requirement used to score an applicant profile.
+// Country and VisaType accept "*" to apply to every destination/visa type.
+type EligibilityRule struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	Country   string    `gorm:"column:country;type:varchar(100);not null;index"`
+	VisaType  string    `gorm:"column:visa_type;type:varchar(60);not null;default:'*'"`
+
+	RuleType  string    `gorm:"column:rule_type;type:varchar(40);not null"`
+	Value     string    `gorm:"column:value;type:varchar(255);not null;default:''"`
+	Currency  *string   `gorm:"column:currency;type:varchar(3);null"` // Only for min_funds
+
+	Weight    int       `gorm:"column:weight;not null;default:10"`       // Share of the score
+	Blocking  bool      `gorm:"column:blocking;not null;default:false"`  // Failing it makes the applicant ineligible
+	Message   string    `gorm:"column:message;type:text;not null"`       // Explanation shown when the rule fails
+	Active    bool      `gorm:"column:active;not null;default:true"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,89 @@
+package entity
+
+import "time"
+
+// Entitlement keys, what plans can grant
+const (
+	EntitlementVisaApplications  = "visa_applications" // Quota, applications a subscription covers
+	EntitlementAIAdvisor         = "ai_advisor"        // Quota or unlimited
+	EntitlementAIAssessment      = "ai_assessment"     // Quota, i.e once for free users
+	EntitlementVisaTracking      = "visa_tracking"
+	EntitlementOpportunityAlerts = "opportunity_alerts"
+	EntitlementPrioritySupport   = "priority_support"
+	EntitlementResumeReview      = "resume_review"
+	EntitlementMentorship        = "mentorship"
+	EntitlementPremiumInsights   = "premium_insights"
+	EntitlementGroupSize         = "group_size" // Limit, applicants per group
+)
+
+// Entitlement types
+const (
+	EntitlementTypeBoolean = "boolean" // On or off
+	EntitlementTypeQuota   = "quota"   // Uses per reset period
+	EntitlementTypeLimit   = "limit"   // Numeric ceiling checked against a current value
+)
+
+// Quota reset periods, calendar aligned in UTC
+const (
+	ResetPeriodDay      = "day"
+	ResetPeriodWeek     = "week" // From Monday
+	ResetPeriodMonth    = "month"
+	ResetPeriodYear     = "year"
+	ResetPeriodLifetime = "lifetime" // Never resets
+)
+
+// PlanEntitlement is one typed feature of a plan. PlanFeature stays the marketing copy.
+type PlanEntitlement struct {
+	ID uint `gorm:"primaryKey"`
+
+	PlanID uint `gorm:"column:plan_id;type:int unsigned;not null;uniqueIndex:idx_plan_entitlement"` // FK to Plan
+	Plan   Plan `gorm:"foreignKey:PlanID"`
+
+	Key         string `gorm:"column:entitlement_key;type:varchar(60);not null;uniqueIndex:idx_plan_entitlement"` // i.e "visa_applications"
+	Type        string `gorm:"column:type;type:varchar(20);not null"`                                             // "boolean", "quota", "limit"
+	Enabled     bool   `gorm:"column:enabled;not null;default:true"`                                              // False switches the feature off whatever its type
+	Limit       *int64 `gorm:"column:limit_value;null"`                                                           // Quota per period or ceiling, null is unlimited
+	ResetPeriod string `gorm:"column:reset_period;type:varchar(20);not null;default:''"`                          // Quotas only, "day", "week", "month", "year", "lifetime"
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// UsageCounter counts a user's uses of a quota in one period
+type UsageCounter struct {
+	ID uint `gorm:"primaryKey"`
+
+	UserID string `gorm:"column:user_id;type:varchar(60);not null;uniqueIndex:idx_usage_counter"` // FK to User
+	User   User   `gorm:"foreignKey:UserID"`
+
+	Key         string    `gorm:"column:entitlement_key;type:varchar(60);not null;uniqueIndex:idx_usage_counter"`
+	PeriodStart time.Time `gorm:"column:period_start;not null;uniqueIndex:idx_usage_counter"` // LifetimePeriodStart for lifetime quotas
+	Used        int64     `gorm:"column:used;not null;default:0"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// LifetimePeriodStart keys the single counter of quotas that never reset
+var LifetimePeriodStart = time.Unix(0, 0).UTC()
+
+// PeriodBounds is the reset period containing now, end is zero for lifetime quotas
+func PeriodBounds(resetPeriod string, now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch resetPeriod {
+	case ResetPeriodDay:
+		return day, day.AddDate(0, 0, 1)
+	case ResetPeriodWeek:
+		start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return start, start.AddDate(0, 0, 7)
+	case ResetPeriodMonth:
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	case ResetPeriodYear:
+		start := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, 0)
+	default: // lifetime
+		return LifetimePeriodStart, time.Time{}
+	}
+}
@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"time"
+)
+
+// Fingerprint kinds, values are stored as keyed hashes only
+const (
+	FingerprintPassport = "passport"
+	FingerprintEmail    = "email"
+	FingerprintPhone    = "phone"
+)
+
+// Fraud signal kinds
+const (
+	FraudSignalPassportReuse  = "passport_reuse"  // Passport number used by another account
+	FraudSignalContactOverlap = "contact_overlap" // Email or phone shared with another account
+	FraudSignalRapidRepeat    = "rapid_repeat"    // Many submissions from one account in a short window
+)
+
+// Fraud signal severities
+const (
+	FraudSeverityLow    = "low"
+	FraudSeverityMedium = "medium"
+	FraudSeverityHigh   = "high"
+)
+
+// Agent review outcomes
+const (
+	FraudResolutionDismissed = "dismissed" // False positive
+	FraudResolutionConfirmed = "confirmed"
+)
+
+// ApplicationFingerprint is a keyed hash of an identifying value on a submitted application.
+// Hashes can be matched across accounts without keeping the values in plain text.
+type ApplicationFingerprint struct {
+	ID                uint      `gorm:"primaryKey;autoIncrement"`
+	VisaApplicationID string    `gorm:"column:visa_application_id;type:varchar(60);not null;uniqueIndex:idx_fingerprint"`
+	UserID            string    `gorm:"column:user_id;type:varchar(60);not null;index"`
+	Kind              string    `gorm:"column:kind;type:varchar(20);not null;uniqueIndex:idx_fingerprint;index:idx_fingerprint_lookup"`
+	Hash              string    `gorm:"column:hash;type:char(64);not null;uniqueIndex:idx_fingerprint;index:idx_fingerprint_lookup"`
+	CreatedAt         time.Time
+}
+
+// FraudSignal flags an application for manual review.
+// Open signals have no resolution yet.
+type FraudSignal struct {
+	ID                   uint       `gorm:"primaryKey;autoIncrement"`
+	VisaApplicationID    string     `gorm:"column:visa_application_id;type:varchar(60);not null;uniqueIndex:idx_fraud_signal"`
+	Kind                 string     `gorm:"column:kind;type:varchar(30);not null;uniqueIndex:idx_fraud_signal"`
+	RelatedApplicationID *string    `gorm:"column:related_application_id;type:varchar(60);null;uniqueIndex:idx_fraud_signal"`
+	Severity             string     `gorm:"column:severity;type:varchar(10);not null"`
+	Detail               string     `gorm:"column:detail;type:varchar(255);not null"`
+
+	// Manual review
+	Resolution           *string    `gorm:"column:resolution;type:varchar(20);null;index"`
+	ReviewNote           *string    `gorm:"column:review_note;type:text;null"`
+	ReviewedBy           *string    `gorm:"column:reviewed_by;type:varchar(60);null"`
+	ReviewedAt           *time.Time `gorm:"column:reviewed_at;null"`
+
+	CreatedAt            time.Time
+}
@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Invoice is the numbered billing document issued for a successful payment, emailed as a PDF.
+// Amounts are in minor units of Currency and tax is included in them.
+type Invoice struct {
+	ID           string     `gorm:"type:varchar(60);primaryKey"`
+	Number       string     `gorm:"column:number;type:varchar(30);not null;uniqueIndex"` // i.e "INV-2026-000042", no gaps within a year
+
+	UserID       string     `gorm:"column:user_id;type:varchar(60);not null;index"`
+	User         User       `gorm:"foreignKey:UserID"`
+	PaymentID    string     `gorm:"column:payment_id;type:varchar(60);not null;uniqueIndex"` // One invoice per payment
+	Purpose      string     `gorm:"column:purpose;type:varchar(30);not null"`                // Payment purpose
+
+	BillingName  string     `gorm:"column:billing_name;type:varchar(100);not null"`
+	BillingEmail string     `gorm:"column:billing_email;type:varchar(120);not null"`
+
+	Lines        []byte     `gorm:"column:lines;type:json"` // []InvoiceLine, discounts and credit as negative lines
+	Currency     string     `gorm:"column:currency;type:char(3);not null"`
+	Subtotal     int64      `gorm:"column:subtotal;not null"` // Total less tax
+	TaxName      string     `gorm:"column:tax_name;type:varchar(20);not null;default:''"`
+	TaxRate      int        `gorm:"column:tax_rate;not null;default:0"` // Basis points, 750 is 7.5%
+	Tax          int64      `gorm:"column:tax;not null;default:0"`
+	Total        int64      `gorm:"column:total;not null"` // What was paid
+
+	PaidAt       time.Time  `gorm:"column:paid_at;not null"`
+	EmailedAt    *time.Time `gorm:"column:emailed_at;null"`
+
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// LineItems decodes the invoice's lines
+func (i *Invoice) LineItems() ([]InvoiceLine, error) {
+	var lines []InvoiceLine
+	if len(i.Lines) == 0 {
+		return lines, nil
+	}
+	err := json.Unmarshal(i.Lines, &lines)
+	return lines, err
+}
+
+// InvoiceLine is one item on an invoice, amounts in minor units
+type InvoiceLine struct {
+	Description string
+	Quantity    int
+	UnitAmount  int64
+	Amount      int64
+}
+
+// InvoiceCounter hands out invoice numbers, one row per series (prefix and year).
+// It is locked by the settlement transaction, so a rolled back payment gives its number back.
+type InvoiceCounter struct {
+	Series string `gorm:"column:series;type:varchar(30);primaryKey"` // i.e "INV-2026"
+	Value  int64  `gorm:"column:value;not null;default:0"`           // Last number issued
+}
@@ -0,0 +1,127 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Ledger accounts. Provider clearing accounts are per provider, i.e "provider_clearing:paystack".
+const (
+	LedgerProviderClearing  = "provider_clearing"  // Asset, collected and held by the provider until paid out to us
+	LedgerPlatformRevenue   = "platform_revenue"   // Revenue, what customers paid, tax included
+	LedgerRefunds           = "refunds"            // Contra revenue, refunds and chargebacks
+	LedgerProviderFees      = "provider_fees"      // Expense, taken by the provider from what it collected
+	LedgerCommissionExpense = "commission_expense" // Expense, partner commissions earned
+	LedgerPartnerPayable    = "partner_payable"    // Liability, commissions owed to partners
+)
+
+// Ledger transaction kinds, with the record they are posted for
+const (
+	LedgerKindPayment    = "payment"    // Succeeded payment
+	LedgerKindRefund     = "refund"     // Processed refund or chargeback
+	LedgerKindCommission = "commission" // Commission earning or reversal
+	LedgerKindPayout     = "payout"     // Partner payout the provider paid
+)
+
+// LedgerTransaction is one balanced money movement in the double-entry ledger.
+// Transactions are never edited or deleted, a mistake is corrected by posting another.
+// There is one per source record, so posting the same record twice is a no-op.
+type LedgerTransaction struct {
+	ID          string        `gorm:"type:varchar(60);primaryKey"`
+	Kind        string        `gorm:"column:kind;type:varchar(20);not null;uniqueIndex:idx_ledger_source"`
+	SourceID    string        `gorm:"column:source_id;type:varchar(60);not null;uniqueIndex:idx_ledger_source"` // Payment, refund or commission it records
+	PaymentID   *string       `gorm:"column:payment_id;type:varchar(60);null;index"`                            // Payment it goes back to
+	Payment     *Payment      `gorm:"foreignKey:PaymentID"`
+	Currency    string        `gorm:"column:currency;type:char(3);not null"`
+	Description string        `gorm:"column:description;type:varchar(255);not null"`
+	OccurredAt  time.Time     `gorm:"column:occurred_at;not null;index"` // When the money moved, not when it was posted
+	Entries     []LedgerEntry `gorm:"foreignKey:TransactionID"`
+
+	CreatedAt time.Time
+}
+
+// LedgerEntry is one side of a ledger transaction. Debits are positive and credits negative,
+// a transaction's entries add up to zero.
+type LedgerEntry struct {
+	ID            string             `gorm:"type:varchar(60);primaryKey"`
+	TransactionID string             `gorm:"column:transaction_id;type:varchar(60);not null;index"`
+	Transaction   *LedgerTransaction `gorm:"foreignKey:TransactionID"`
+	Account       string             `gorm:"column:account;type:varchar(60);not null;index:idx_ledger_account"`
+	Amount        int64              `gorm:"column:amount;not null"` // Minor units
+	Currency      string             `gorm:"column:currency;type:char(3);not null;index:idx_ledger_account"`
+	OccurredAt    time.Time          `gorm:"column:occurred_at;not null;index:idx_ledger_account"` // Copied from the transaction for balance queries
+
+	CreatedAt time.Time
+}
+
+// Reconciliation statuses
+const (
+	ReconciliationMatched    = "matched"
+	ReconciliationMismatched = "mismatched" // Mismatches lists what differs, rerun until it matches or is looked into
+	ReconciliationFailed     = "failed"     // Provider settlement data could not be fetched
+)
+
+// LedgerReconciliation is the daily report comparing what a provider says it collected with the ledger
+type LedgerReconciliation struct {
+	ID         string    `gorm:"type:varchar(60);primaryKey"`
+	Provider   string    `gorm:"column:provider;type:varchar(20);not null;uniqueIndex:idx_ledger_reconciliation"`
+	Day        string    `gorm:"column:day;type:char(10);not null;uniqueIndex:idx_ledger_reconciliation"` // UTC, i.e "2026-10-18"
+	Status     string    `gorm:"column:status;type:varchar(20);not null"`
+	Totals     []byte    `gorm:"column:totals;type:json"`     // []ReconciliationTotal, by currency
+	Mismatches []byte    `gorm:"column:mismatches;type:json"` // []ReconciliationMismatch
+	Error      *string   `gorm:"column:error;type:text;null"`
+	RunAt      time.Time `gorm:"column:run_at;not null"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ReconciliationTotal compares one currency's collections for the day, minor units
+type ReconciliationTotal struct {
+	Currency       string
+	ProviderCount  int
+	ProviderAmount int64
+	ProviderFees   int64
+	LedgerCount    int
+	LedgerAmount   int64
+	LedgerFees     int64
+}
+
+// Reconciliation mismatch issues
+const (
+	MismatchMissingInLedger   = "missing_in_ledger"   // The provider collected it, the ledger has no payment
+	MismatchMissingAtProvider = "missing_at_provider" // The ledger has a payment the provider didn't report
+	MismatchAmount            = "amount_mismatch"
+	MismatchFee               = "fee_mismatch"
+)
+
+// ReconciliationMismatch is one payment the provider and the ledger disagree on
+type ReconciliationMismatch struct {
+	Reference      string
+	Issue          string
+	Currency       string
+	ProviderAmount int64
+	LedgerAmount   int64
+	ProviderFee    int64
+	LedgerFee      int64
+}
+
+// TotalsList decodes the report's totals
+func (r *LedgerReconciliation) TotalsList() ([]ReconciliationTotal, error) {
+	var totals []ReconciliationTotal
+	if len(r.Totals) == 0 {
+		return totals, nil
+	}
+	err := json.Unmarshal(r.Totals, &totals)
+	return totals, err
+}
+
+// MismatchList decodes the report's mismatches
+func (r *LedgerReconciliation) MismatchList() ([]ReconciliationMismatch, error) {
+	var mismatches []ReconciliationMismatch
+	if len(r.Mismatches) == 0 {
+		return mismatches, nil
+	}
+	err := json.Unmarshal(r.Mismatches, &mismatches)
+	return mismatches, err
+}
@@ -0,0 +1,158 @@
+package entity
+
+import (
+	"time"
+)
+
+// Payment statuses
+const (
+	PaymentStatusPending   = "pending"   // Checkout started, waiting for the customer
+	PaymentStatusSucceeded = "succeeded"
+	PaymentStatusFailed    = "failed"
+)
+
+// Payment refund statuses, a refunded payment stays succeeded
+const (
+	PaymentRefundNone    = ""
+	PaymentRefundPartial = "partially_refunded"
+	PaymentRefundFull    = "refunded"
+)
+
+// What a payment is for
+const (
+	PaymentPurposeApplication  = "visa_application" // Pay-per-application
+	PaymentPurposeSubscription = "subscription"
+)
+
+// Payment is one provider transaction. Amounts are in minor units of Currency.
+type Payment struct {
+	ID                string     `gorm:"type:varchar(60);primaryKey"`
+	UserID            string     `gorm:"column:user_id;type:varchar(60);not null;index"`
+	User              User       `gorm:"foreignKey:UserID"`
+
+	Reference         string     `gorm:"column:reference;type:varchar(60);not null;uniqueIndex"` // Our reference, sent to the provider
+	Provider          string     `gorm:"column:provider;type:varchar(20);not null"` // Provider that took the checkout
+	ProviderReference *string    `gorm:"column:provider_reference;type:varchar(100);null"`
+	Purpose           string     `gorm:"column:purpose;type:varchar(30);not null"`
+
+	Amount            int64      `gorm:"column:amount;not null"`
+	Currency          string     `gorm:"column:currency;type:char(3);not null"`
+	Fee               int64      `gorm:"column:fee;not null;default:0"` // Provider fee, known once verified
+
+	Status            string     `gorm:"column:status;type:varchar(20);not null;default:'pending';index"`
+	AuthorizationURL  string     `gorm:"column:authorization_url;type:text"` // Hosted checkout page
+	Channel           *string    `gorm:"column:channel;type:varchar(30);null"`
+	FailureReason     *string    `gorm:"column:failure_reason;type:varchar(255);null"`
+	Metadata          []byte     `gorm:"column:metadata;type:json"`
+	PaidAt            *time.Time `gorm:"column:paid_at;null"`
+	AuthorizationID   *string    `gorm:"column:authorization_id;type:varchar(60);null"` // Card it was paid with, when reusable
+
+	RefundedAmount    int64      `gorm:"column:refunded_amount;not null;default:0"` // Processed refunds and chargebacks
+	RefundStatus      string     `gorm:"column:refund_status;type:varchar(20);not null;default:''"` // "", "partially_refunded", "refunded"
+
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// PaymentAuthorization is a reusable card from a successful payment, charged for renewals
+type PaymentAuthorization struct {
+	ID        string    `gorm:"type:varchar(60);primaryKey"`
+	UserID    string    `gorm:"column:user_id;type:varchar(60);not null;index"`
+	Provider  string    `gorm:"column:provider;type:varchar(20);not null;uniqueIndex:idx_payment_authorization"`
+	Code      string    `gorm:"column:code;type:varchar(255);not null;uniqueIndex:idx_payment_authorization"` // Provider token, useless without our secret key
+	Brand     string    `gorm:"column:brand;type:varchar(30)"`
+	Last4     string    `gorm:"column:last4;type:varchar(4)"`
+	ExpMonth  string    `gorm:"column:exp_month;type:varchar(2)"`
+	ExpYear   string    `gorm:"column:exp_year;type:varchar(4)"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Payment event statuses
+const (
+	PaymentEventReceived  = "received"  // Stored, not processed yet
+	PaymentEventProcessed = "processed"
+	PaymentEventIgnored   = "ignored"   // Not about one of our payments
+	PaymentEventFailed    = "failed"    // Processing failed, a redelivery retries it
+)
+
+// PaymentEvent is a raw provider webhook, kept for audit and deduplication
+type PaymentEvent struct {
+	ID          string     `gorm:"type:varchar(60);primaryKey"`
+	Provider    string     `gorm:"column:provider;type:varchar(20);not null;uniqueIndex:idx_payment_event"`
+	EventID     string     `gorm:"column:event_id;type:varchar(100);not null;uniqueIndex:idx_payment_event"` // Provider's event id
+	Type        string     `gorm:"column:type;type:varchar(60);not null"`
+	Reference   *string    `gorm:"column:reference;type:varchar(60);null;index"` // Payment the event is about
+
+	Payload     []byte     `gorm:"column:payload;type:json;not null"` // Raw body as received
+	Status      string     `gorm:"column:status;type:varchar(20);not null;default:'received'"`
+	Error       *string    `gorm:"column:error;type:text;null"`
+	ProcessedAt *time.Time `gorm:"column:processed_at;null"`
+
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Refund statuses
+const (
+	RefundStatusPending   = "pending"   // Reserved, the provider hasn't finished it
+	RefundStatusProcessed = "processed" // Money is back with the customer
+	RefundStatusFailed    = "failed"    // Nothing was refunded, the amount can be refunded again
+)
+
+// Refund kinds
+const (
+	RefundKindRefund     = "refund"     // Started by an admin through the provider
+	RefundKindChargeback = "chargeback" // Taken back by the customer's bank after a lost dispute
+)
+
+// Refund gives part or all of a succeeded payment back. Amounts are in minor units of Currency.
+// Pending and processed refunds count against what is left to refund.
+type Refund struct {
+	ID               string     `gorm:"type:varchar(60);primaryKey"`
+	PaymentID        string     `gorm:"column:payment_id;type:varchar(60);not null;index"`
+	Payment          Payment    `gorm:"foreignKey:PaymentID"`
+
+	Kind             string     `gorm:"column:kind;type:varchar(20);not null;default:'refund'"`
+	Provider         string     `gorm:"column:provider;type:varchar(20);not null"`
+	ProviderRefundID *string    `gorm:"column:provider_refund_id;type:varchar(100);null"` // Null until the provider accepts it, and on chargebacks
+	Amount           int64      `gorm:"column:amount;not null"`
+	Currency         string     `gorm:"column:currency;type:char(3);not null"`
+	Reason           string     `gorm:"column:reason;type:varchar(255);not null;default:''"` // Shown to the customer
+
+	Status           string     `gorm:"column:status;type:varchar(20);not null;default:'pending';index"`
+	FailureReason    *string    `gorm:"column:failure_reason;type:varchar(255);null"`
+	InitiatedBy      *string    `gorm:"column:initiated_by;type:varchar(60);null"` // Admin who started it
+	ProcessedAt      *time.Time `gorm:"column:processed_at;null"`
+
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// Dispute statuses
+const (
+	DisputeStatusOpen = "open"
+	DisputeStatusWon  = "won"
+	DisputeStatusLost = "lost" // Recorded as a chargeback refund
+)
+
+// PaymentDispute is a chargeback the customer raised with their bank, kept in step with provider webhooks
+type PaymentDispute struct {
+	ID                string     `gorm:"type:varchar(60);primaryKey"`
+	PaymentID         string     `gorm:"column:payment_id;type:varchar(60);not null;index"`
+	Payment           Payment    `gorm:"foreignKey:PaymentID"`
+
+	Provider          string     `gorm:"column:provider;type:varchar(20);not null;uniqueIndex:idx_payment_dispute"`
+	ProviderDisputeID string     `gorm:"column:provider_dispute_id;type:varchar(100);not null;uniqueIndex:idx_payment_dispute"`
+	Status            string     `gorm:"column:status;type:varchar(20);not null;default:'open'"`
+	Amount            int64      `gorm:"column:amount;not null;default:0"` // Minor units the bank claims back
+	Currency          string     `gorm:"column:currency;type:char(3);not null"`
+	Reason            string     `gorm:"column:reason;type:varchar(100);not null;default:''"`
+	DueAt             *time.Time `gorm:"column:due_at;null"`          // Evidence deadline
+	RefundID          *string    `gorm:"column:refund_id;type:varchar(60);null"` // Chargeback recorded when lost
+	ResolvedAt        *time.Time `gorm:"column:resolved_at;null"`
+
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
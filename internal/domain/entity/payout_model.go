@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"time"
+)
+
+// PayoutAccount is the bank account a partner is paid into, one per currency.
+// The account number itself stays with the provider, only its recipient code and last digits are kept.
+type PayoutAccount struct {
+	ID            string    `gorm:"type:varchar(60);primaryKey"`
+	PartnerID     string    `gorm:"column:partner_id;type:varchar(60);not null;uniqueIndex:idx_payout_account"`
+	Currency      string    `gorm:"column:currency;type:char(3);not null;uniqueIndex:idx_payout_account"`
+
+	Provider      string    `gorm:"column:provider;type:varchar(20);not null"` // Provider that resolved it and sends its transfers
+	BankCode      string    `gorm:"column:bank_code;type:varchar(20);not null"`
+	AccountLast4  string    `gorm:"column:account_last4;type:varchar(4);not null"`
+	AccountName   string    `gorm:"column:account_name;type:varchar(120);not null"` // As the bank has it
+	RecipientCode string    `gorm:"column:recipient_code;type:varchar(100);not null"`
+	VerifiedAt    time.Time `gorm:"column:verified_at;not null"` // When the bank resolved it
+
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Payout batch statuses
+const (
+	PayoutBatchProcessing = "processing" // Payouts still pending
+	PayoutBatchCompleted  = "completed"
+)
+
+// PayoutBatch is one scheduled or admin-started run paying every partner over their minimum
+type PayoutBatch struct {
+	ID          string     `gorm:"type:varchar(60);primaryKey"`
+	Status      string     `gorm:"column:status;type:varchar(20);not null;index"`
+	StartedBy   *string    `gorm:"column:started_by;type:varchar(60);null"` // Admin, null for scheduled batches
+	Count       int        `gorm:"column:count;not null;default:0"`         // Payouts in the batch
+	CompletedAt *time.Time `gorm:"column:completed_at;null"`
+
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Payout statuses
+const (
+	PayoutStatusPending    = "pending"    // Created, the transfer isn't sent yet
+	PayoutStatusProcessing = "processing" // Sent, waiting on the provider
+	PayoutStatusPaid       = "paid"
+	PayoutStatusFailed     = "failed" // The amount is payable again in the next batch
+)
+
+// Payout is one transfer of a partner's available commissions. Amounts are in minor units of Currency.
+type Payout struct {
+	ID                 string         `gorm:"type:varchar(60);primaryKey"`
+	BatchID            string         `gorm:"column:batch_id;type:varchar(60);not null;index"`
+	PartnerID          string         `gorm:"column:partner_id;type:varchar(60);not null;index"`
+	AccountID          string         `gorm:"column:account_id;type:varchar(60);not null"`
+	Account            *PayoutAccount `gorm:"foreignKey:AccountID"`
+
+	Reference          string         `gorm:"column:reference;type:varchar(60);not null;uniqueIndex"` // Sent to the provider, retries reuse it
+	Provider           string         `gorm:"column:provider;type:varchar(20);not null"`
+	ProviderTransferID *string        `gorm:"column:provider_transfer_id;type:varchar(100);null"`
+
+	Amount             int64          `gorm:"column:amount;not null"`
+	Currency           string         `gorm:"column:currency;type:char(3);not null"`
+	Fee                int64          `gorm:"column:fee;not null;default:0"` // Transfer fee, paid by us
+
+	Status             string         `gorm:"column:status;type:varchar(20);not null;index"`
+	FailureReason      *string        `gorm:"column:failure_reason;type:varchar(255);null"`
+	PaidAt             *time.Time     `gorm:"column:paid_at;null"`
+
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"time"
+)
+
+// Permissions granted on top of a user's role
+const (
+	PermissionExportSensitive = "applications.export_sensitive" // Unmasked passport/personal data in exports
+)
+
+// UserPermission is an explicit grant to a single user
+type UserPermission struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement"`
+	UserID      string    `gorm:"column:user_id;type:varchar(60);not null;uniqueIndex:idx_user_permission"`
+	Permission  string    `gorm:"column:permission;type:varchar(60);not null;uniqueIndex:idx_user_permission"`
+	GrantedByID string    `gorm:"column:granted_by_id;type:varchar(60);not null"`
+	CreatedAt   time.Time
+}
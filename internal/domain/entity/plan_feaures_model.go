@@ -2,11 +2,12 @@ package entity
 
 
 type PlanFeature struct {
-	ID           uint      `gorm:"type:tinyint;primaryKey"`
+	ID           uint      `gorm:"primaryKey"`
 
-	PlanID       uint      `gorm:"column:plan_id;type:tinyint;not null"` // FK to Plan
+	PlanID       uint      `gorm:"column:plan_id;type:int unsigned;not null"` // FK to Plan
 	Plan         Plan      `gorm:"foreignKey:PlanID"`
 	
 	FeatureLabel string    `gorm:"column:feature_label;not null"` // i.e Blog access, premium travel insights
 	FeatureValue string    `gorm:"column:feature_value;not null"` // Yes, Yes
+	SortOrder    int       `gorm:"column:sort_order;not null;default:0"` // Position in the plan's list
 }
@@ -2,18 +2,61 @@ package entity
 
 import "time"
 
+// Plan statuses
+const (
+	PlanStatusActive     = "active"     // Listed and open to new subscribers
+	PlanStatusSuperseded = "superseded" // Replaced by a newer version, existing subscribers keep it
+	PlanStatusArchived   = "archived"   // Withdrawn, existing subscribers keep it
+)
 
-// Plan defines the different subscription plans you offer
+
+// Plan defines the different subscription plans you offer.
+// Each version is its own row, so subscribers stay on the version they bought (grandfathered).
 type Plan struct {
-	ID               uint          `gorm:"type:tinyint;primaryKey"`
+	ID               uint          `gorm:"type:int unsigned;primaryKey"`
 
-	Name             string       `gorm:"column:name;type:varchar(12);unique;not null"`   // e.g., "Basic", "Pro"
+	Code             string       `gorm:"column:code;type:varchar(40);not null;uniqueIndex:idx_plan_version"` // Stable across versions, i.e "pro"
+	Version          int          `gorm:"column:version;not null;default:1;uniqueIndex:idx_plan_version"`
+	Name             string       `gorm:"column:name;type:varchar(60);not null"`   // e.g., "Basic", "Pro"
 	Description      string       `gorm:"column:description;type:text"`   // A description of what this plan includes
-	Price            float64      `gorm:"column:price;not null"`         // In your base currency (e.g., NGN)
+	Amount           int64        `gorm:"column:amount;not null;default:0"`                      // Minor units of Currency
+	Currency         string       `gorm:"column:currency;type:char(3);not null;default:'NGN'"`   // ISO 4217, the plan's own price book
 	BillingCycle     string       `gorm:"column:billing_cycle;type:varchar(12);not null"` // "monthly", "yearly", "quarterly" etc.
+	Status           string       `gorm:"column:status;type:varchar(20);not null;default:'active'"` // "active", "superseded", "archived"
+	SortOrder        int          `gorm:"column:sort_order;not null;default:0"` // Position on the pricing page
+	TrialDays        int          `gorm:"column:trial_days;not null;default:0"`  // Free days before the first charge, 0 for none
 
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
 
 	PlanFeatures     []PlanFeature `gorm:"foreignKey:PlanID;references:ID"`
+	PlanPrices       []PlanPrice   `gorm:"foreignKey:PlanID;references:ID"`
+}
+
+// PriceIn is the plan's price in currency, minor units: its own price or one from its price books.
+// Plans are only sold in currencies they have a price in.
+func (p *Plan) PriceIn(currency string) (int64, bool) {
+	if p.Currency == currency {
+		return p.Amount, true
+	}
+	for _, price := range p.PlanPrices {
+		if price.Currency == currency {
+			return price.Amount, true
+		}
+	}
+	return 0, false
+}
+
+// PlanPrice is the plan's price book entry in another currency, charged to subscribers who buy in it
+type PlanPrice struct {
+	ID        uint      `gorm:"primaryKey"`
+
+	PlanID    uint      `gorm:"column:plan_id;type:int unsigned;not null;uniqueIndex:idx_plan_price"` // FK to Plan
+	Plan      Plan      `gorm:"foreignKey:PlanID"`
+
+	Currency  string    `gorm:"column:currency;type:char(3);not null;uniqueIndex:idx_plan_price"` // ISO 4217
+	Amount    int64     `gorm:"column:amount;not null"`                                           // Minor units
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"time"
+)
+
+// Add-on codes
+const (
+	AddOnExpress   = "express"    // Express handling
+	AddOnCVReview  = "cv_review"  // CV review by an agent
+	AddOnSOPReview = "sop_review" // Statement of purpose review
+)
+
+// ApplicationPrice is the service fee for one applicant, in minor units of Currency.
+// Destination and VisaType accept "*" to price every destination/visa type.
+type ApplicationPrice struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement"`
+	Destination string    `gorm:"column:destination;type:varchar(100);not null;default:'*';uniqueIndex:idx_application_price"`
+	VisaType    string    `gorm:"column:visa_type;type:varchar(60);not null;default:'*';uniqueIndex:idx_application_price"`
+	Currency    string    `gorm:"column:currency;type:char(3);not null;uniqueIndex:idx_application_price"`
+	Amount      int64     `gorm:"column:amount;not null"`
+
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// AddOn is an optional extra bought with an application
+type AddOn struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement"`
+	Code         string    `gorm:"column:code;type:varchar(30);not null;uniqueIndex:idx_add_on"`
+	Currency     string    `gorm:"column:currency;type:char(3);not null;uniqueIndex:idx_add_on"`
+	Name         string    `gorm:"column:name;type:varchar(100);not null"`
+	Amount       int64     `gorm:"column:amount;not null"`
+	PerApplicant bool      `gorm:"column:per_applicant;not null;default:false"` // Charged for every group member, i.e reviews
+	Active       bool      `gorm:"column:active;not null;default:true"`
+
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
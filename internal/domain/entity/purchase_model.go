@@ -6,6 +6,13 @@ import (
 	//"github.com/oklog/ulid/v2"
 )
 
+// Purchase statuses
+const (
+	PurchaseStatusPending = "pending" // Waiting for payment
+	PurchaseStatusPaid    = "paid"
+	PurchaseStatusFailed  = "failed"
+	PurchaseStatusRefunded = "refunded" // Payment refunded in full, the application has to be paid for again
+)
 
 // Purchase records single payments for visa application service
 type Purchase struct {
@@ -17,6 +24,13 @@ type Purchase struct {
 	VisaApplicationID  string            `gorm:"column:visa_application_id;varchar(60);not null"`
 	VisaApplication    VisaApplication   `gorm:"foreignKey:VisaApplicationID"`
 
+	PaymentID          *string           `gorm:"column:payment_id;type:varchar(60);null;index"` // Checkout paying for it, shared by a group's purchases
+	Status             string            `gorm:"column:status;type:varchar(20);not null;default:'paid'"` // "pending", "paid", "failed", "refunded"
+
+	Amount             int64             `gorm:"column:amount;not null;default:0"` // Minor units, this application's share
+	Currency           string            `gorm:"column:currency;type:char(3);not null;default:'NGN'"`
+	AddOns             []byte            `gorm:"column:add_ons;type:json"` // Add-on codes bought with it
+
 	PurchasedAt        time.Time         `gorm:"column:purchased_at;not null"` // When the purchase happened
 
 	CreatedAt          time.Time
@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"time"
+)
+
+// Purge run triggers
+const (
+	PurgeTriggerScheduled = "scheduled"
+	PurgeTriggerManual    = "manual"
+)
+
+// Purge report actions
+const (
+	PurgeActionDocumentDeleted = "document_deleted"
+	PurgeActionDocumentFailed  = "document_failed" // Retried on the next run
+	PurgeActionFormRedacted    = "form_redacted"
+)
+
+// RetentionPolicy is how long personal data is kept once an application is in a status.
+// A null period keeps that data indefinitely.
+type RetentionPolicy struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement"`
+	Status       string    `gorm:"column:status;type:varchar(30);not null;uniqueIndex"` // i.e "approved", "rejected"
+	DocumentDays *int      `gorm:"column:document_days;null"` // Days before uploaded files are deleted
+	FormDays     *int      `gorm:"column:form_days;null"`     // Days before personal form fields are redacted
+
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// LegalHold stops an application's data from being purged until released
+type LegalHold struct {
+	ID                string     `gorm:"type:varchar(60);primaryKey"`
+	VisaApplicationID string     `gorm:"column:visa_application_id;type:varchar(60);not null;index"`
+	Reason            string     `gorm:"column:reason;type:varchar(255);not null"`
+	PlacedBy          string     `gorm:"column:placed_by;type:varchar(60);not null"`
+	ReleasedBy        *string    `gorm:"column:released_by;type:varchar(60);null"`
+	ReleasedAt        *time.Time `gorm:"column:released_at;null;index"` // Null while active
+
+	CreatedAt         time.Time
+}
+
+// PurgeRun is the report of one purge job run
+type PurgeRun struct {
+	ID               string     `gorm:"type:varchar(60);primaryKey"`
+	Trigger          string     `gorm:"column:trigger;type:varchar(20);not null"`
+	TriggeredBy      *string    `gorm:"column:triggered_by;type:varchar(60);null"` // Admin for manual runs
+	DryRun           bool       `gorm:"column:dry_run;not null;default:false"`     // Report only, nothing is deleted
+	DocumentsDeleted int        `gorm:"column:documents_deleted;not null;default:0"`
+	DocumentsFailed  int        `gorm:"column:documents_failed;not null;default:0"`
+	FormsRedacted    int        `gorm:"column:forms_redacted;not null;default:0"`
+	SkippedOnHold    int        `gorm:"column:skipped_on_hold;not null;default:0"`
+	Error            *string    `gorm:"column:error;type:text;null"`
+	StartedAt        time.Time  `gorm:"column:started_at;not null;index"`
+	FinishedAt       *time.Time `gorm:"column:finished_at;null"`
+}
+
+// PurgeItem is a single action in a purge report
+type PurgeItem struct {
+	ID                uint      `gorm:"primaryKey;autoIncrement"`
+	PurgeRunID        string    `gorm:"column:purge_run_id;type:varchar(60);not null;index"`
+	VisaApplicationID string    `gorm:"column:visa_application_id;type:varchar(60);not null"`
+	DocumentID        *string   `gorm:"column:document_id;type:varchar(60);null"` // Null for the signed form and redactions
+	Action            string    `gorm:"column:action;type:varchar(30);not null"`
+	Detail            string    `gorm:"column:detail;type:varchar(255)"`
+	CreatedAt         time.Time
+}
@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+)
+
+// SLA escalation levels
+const (
+	SLALevelWarning  = "warning"  // About to breach, the assigned agent is notified
+	SLALevelBreached = "breached" // Breached, the agent and admins are notified
+)
+
+// SLATarget is the promised turnaround for an application status.
+// A null PlanID is the default target for applicants without a plan-specific one.
+type SLATarget struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement"`
+	PlanID      *uint     `gorm:"column:plan_id;type:int unsigned;null;index"`
+	Status      string    `gorm:"column:status;type:varchar(30);not null;index"` // i.e "pending", "under_review"
+	TargetHours int       `gorm:"column:target_hours;not null"`
+
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// SLAEscalation records an escalation already sent, so every stint in a status escalates once per level
+type SLAEscalation struct {
+	ID                uint      `gorm:"primaryKey;autoIncrement"`
+	VisaApplicationID string    `gorm:"column:visa_application_id;type:varchar(60);not null;uniqueIndex:idx_sla_escalation"`
+	Status            string    `gorm:"column:status;type:varchar(30);not null;uniqueIndex:idx_sla_escalation"`
+	StatusEnteredAt   time.Time `gorm:"column:status_entered_at;not null;uniqueIndex:idx_sla_escalation"`
+	Level             string    `gorm:"column:level;type:varchar(20);not null;uniqueIndex:idx_sla_escalation"`
+	NotifiedAt        time.Time `gorm:"column:notified_at;not null"`
+}
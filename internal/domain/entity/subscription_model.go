@@ -6,6 +6,14 @@ import (
 	//"github.com/oklog/ulid/v2"
 )
 
+// Subscription statuses
+const (
+	SubscriptionStatusPending  = "pending"  // Waiting for its first payment
+	SubscriptionStatusActive   = "active"
+	SubscriptionStatusPastDue  = "past_due" // Renewal failed, usable until the grace period ends
+	SubscriptionStatusCanceled = "canceled"
+	SubscriptionStatusExpired  = "expired"  // Never renewed
+)
 
 // Subscription tracks one subscription instance
 type Subscription struct {
@@ -14,15 +22,52 @@ type Subscription struct {
 	UserID      string     `gorm:"column:user_id;type:varchar(60);not null"` // FK to User
 	User        User       `gorm:"foreignKey:UserID"`
 
-	PlanID      uint       `gorm:"column:plan_id;type:tinyint;not null"` // FK to Plan
+	PlanID      uint       `gorm:"column:plan_id;type:int unsigned;not null"` // FK to Plan
 	Plan        Plan       `gorm:"foreignKey:PlanID"`
+	Currency    string     `gorm:"column:currency;type:char(3);not null;default:'NGN'"` // Price book it was bought in, renewals charge in it too
 
-	Status      string     `gorm:"column:status;type:varchar(60);not null;default:'active'"` // "active", "canceled", "expired"
+	Status      string     `gorm:"column:status;type:varchar(60);not null;default:'active'"` // "pending", "active", "past_due", "canceled", "expired"
+	PaymentID   *string    `gorm:"column:payment_id;type:varchar(60);null;uniqueIndex"` // Checkout that started it
 
-	StartedAt   time.Time  `gorm:"column:started_at;not null"` // When subscription began
+	StartedAt   time.Time  `gorm:"column:started_at;not null"` // When subscription began, or last restarted its billing cycle. Periods count from it
 	ExpiresAt   time.Time  `gorm:"column:expires_at;not null"` // When it ends
+	PeriodStartedAt *time.Time `gorm:"column:period_started_at;null"` // Start of the current period, null on older rows
 	CanceledAt  *time.Time `gorm:"column:canceled_at"`         // Null unless user canceled early
 
+	CancelAtPeriodEnd bool       `gorm:"column:cancel_at_period_end;not null;default:false"` // Runs to ExpiresAt, then cancels instead of renewing
+	AuthorizationID   *string    `gorm:"column:authorization_id;type:varchar(60);null"`      // Card renewals are charged to
+	RenewalPaymentID  *string    `gorm:"column:renewal_payment_id;type:varchar(60);null;index"` // Renewal charge waiting to settle
+	RenewalAttempts   int        `gorm:"column:renewal_attempts;not null;default:0"`         // Failed renewals this period
+	NextRenewalAt     *time.Time `gorm:"column:next_renewal_at;null"`                        // Next retry while past due
+	GraceEndsAt       *time.Time `gorm:"column:grace_ends_at;null"`                          // Past due subscriptions expire then
+	ReminderSentAt    *time.Time `gorm:"column:reminder_sent_at;null"`                       // Upcoming renewal email, once per period
+
+	PendingPlanID     *uint      `gorm:"column:pending_plan_id;type:int unsigned;null"`            // Plan it moves to, at period end for downgrades
+	PendingPlan       *Plan      `gorm:"foreignKey:PendingPlanID"`
+	ChangePaymentID   *string    `gorm:"column:change_payment_id;type:varchar(60);null;index"` // Upgrade charge waiting to settle, PendingPlanID is the upgrade
+	CreditBalance     int64      `gorm:"column:credit_balance;not null;default:0"`            // Minor units owed to the subscriber, taken off renewals
+
+	TrialEndsAt       *time.Time `gorm:"column:trial_ends_at;null"`                           // Set on trials, the first charge is due then
+	CouponCode        *string    `gorm:"column:coupon_code;type:varchar(40);null"`            // Taken off the first charge after a trial
+
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+}
+
+// InTrial reports whether the subscription is still in its free trial period, nothing paid yet
+func (s *Subscription) InTrial() bool {
+	return s.TrialEndsAt != nil && s.ExpiresAt.Equal(*s.TrialEndsAt)
+}
+
+// InGoodStanding reports whether the subscription grants its plan at now:
+// active within its period, or past due within the grace period
+func (s *Subscription) InGoodStanding(now time.Time) bool {
+	switch s.Status {
+	case SubscriptionStatusActive:
+		return s.ExpiresAt.After(now)
+	case SubscriptionStatusPastDue:
+		return s.GraceEndsAt != nil && s.GraceEndsAt.After(now)
+	default:
+		return false
+	}
 }
\ No newline at end of file
@@ -17,6 +17,10 @@ type User struct {
 	Role              string    `gorm:"column:role;type:varchar(12);not null;default:user"`        // user, agent, admin, superadmin etc.
 	BannedUntil       *time.Time `gorm:"column:banned_until;default:null"`
 	BanReason         *string    `gorm:"column:ban_reason;type:varchar(200);default:null"`
+	Country           *string    `gorm:"column:country;type:char(2);null"`                 // ISO 3166-1 alpha-2, picks the price book
+	ReferrerID        *string    `gorm:"column:referrer_id;type:varchar(60);null;index"` // Partner who referred the user
+	ReferralCodeID    *uint      `gorm:"column:referral_code_id;null"`                   // Code they signed up with
+	ReferredAt        *time.Time `gorm:"column:referred_at;null"`
 	CreatedAt         time.Time `gorm:"column:created_at;autoCreateTime"`         // GORM auto timestamps
 	UpdatedAt         time.Time `gorm:"column:updated_at;autoUpdateTime"`         // GORM auto timestamps
 
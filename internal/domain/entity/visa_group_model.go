@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"time"
+)
+
+// Relationship of a group member to the principal applicant
+const (
+	GroupRelationPrincipal = "principal"
+	GroupRelationSpouse    = "spouse"
+	GroupRelationChild     = "child"
+	GroupRelationParent    = "parent"
+	GroupRelationSibling   = "sibling"
+	GroupRelationOther     = "other"
+)
+
+// VisaApplicationGroup links a principal applicant's application to dependents
+// (spouse, children...) travelling together. Every member keeps its own
+// VisaApplication so personal info, documents and status stay per member.
+type VisaApplicationGroup struct {
+	ID                     string            `gorm:"type:varchar(60);primaryKey"`
+	UserID                 string            `gorm:"column:user_id;type:varchar(60);not null;index"` // Principal applicant's account
+	User                   User              `gorm:"foreignKey:UserID"`
+
+	PrincipalApplicationID string            `gorm:"column:principal_application_id;type:varchar(60);uniqueIndex;not null"`
+
+	// Shared travel details, copied into each dependent's form input
+	Destination            string            `gorm:"column:destination;type:varchar(100);not null"`
+	VisaType               string            `gorm:"column:visa_type;type:varchar(60);not null"`
+	TravelDate             time.Time         `gorm:"column:travel_date;not null"`
+	DurationOfStay         string            `gorm:"column:duration_of_stay;type:varchar(60);not null"`
+	Purpose                string            `gorm:"column:purpose;type:text;not null"`
+
+	Members                []VisaApplication `gorm:"foreignKey:GroupID;references:ID"`
+
+	CreatedAt              time.Time
+	UpdatedAt              time.Time
+}
@@ -6,6 +6,17 @@ import (
 	//"github.com/oklog/ulid/v2"
 )
 
+// Visa application statuses
+const (
+	VisaStatusDraft              = "draft"               // Being filled in by the applicant, relaxed validation
+	VisaStatusPending            = "pending"             // Received, waiting for an agent
+	VisaStatusUnderReview        = "under_review"        // Agent is reviewing the application
+	VisaStatusDocumentsRequested = "documents_requested" // Applicant needs to upload more documents
+	VisaStatusSubmitted          = "submitted"           // Submitted to the embassy
+	VisaStatusApproved           = "approved"            // Visa granted
+	VisaStatusRejected           = "rejected"            // Visa denied
+)
+
 // Visa application model
 type VisaApplication struct {
 	ID              string       `gorm:"type:varchar(60);primaryKey"` // Primary Key for the visa application
@@ -13,7 +24,8 @@ type VisaApplication struct {
 	User            User         `gorm:"foreignKey:UserID"` // The user who is applying for the visa
 
 	// Optional: provide form fields instead of uploading a form
-	// JSON column for dynamic visa form input
+	// JSON column for dynamic visa form input.
+	// Drafts keep the raw (partial) request payload, submitted applications keep entity.VisaFormInput
 	VisaFormInput   []byte        `gorm:"column:visa_form_input;type:json"` // Or use gorm.io/datatypes
 	//VisaFormInput    *VisaFormInput `gorm:"foreignKey:VisaApplicationID;references:ID"` // One-to-One relationship (nullable)
 
@@ -28,9 +40,24 @@ type VisaApplication struct {
 	Agent           *User         `gorm:"foreignKey:AgentID"` // The agent assigned to the application
 
 	// Track status of the application
-	Status          *string       `gorm:"column:status;type:varchar(12);null;default:'pending'"` // The status of the application (e.g., "pending", "submitted", "under review", "approved"  etc.)
+	Status          *string       `gorm:"column:status;type:varchar(30);null;default:'pending'"` // The status of the application (e.g., "pending", "submitted", "under_review", "approved"  etc.)
 	Feedback        *string       `gorm:"column:feedback;type:text;null"` // Feedback from the embassy or agent
 
+	// Family/group applications (null for solo applications)
+	GroupID         *string       `gorm:"column:group_id;type:varchar(60);null;index"`
+	Relationship    *string       `gorm:"column:relationship;type:varchar(20);null"` // principal, spouse, child etc.
+	ApplicantName   *string       `gorm:"column:applicant_name;type:varchar(100);null"` // Dependent's name, null when the applicant is the account owner
+
+	// Partner (consultancy/affiliate) who referred the application, receives its webhooks
+	ReferrerID      *string       `gorm:"column:referrer_id;type:varchar(60);null;index"`
+
+	// Data retention, set once the policy was applied
+	DocumentsPurgedAt *time.Time  `gorm:"column:documents_purged_at;null"`
+	FormRedactedAt    *time.Time  `gorm:"column:form_redacted_at;null"`
+
+	// Optimistic concurrency for draft autosave, bumped on every draft update
+	Version         uint          `gorm:"column:version;not null;default:1"`
+
 	// Timestamps
 	CreatedAt       time.Time    // Automatically set by GORM
 	UpdatedAt       time.Time    // Automatically set by GORM
@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+)
+
+// VisaStatusHistory records every status change of a visa application.
+// It powers the applicant's timeline and time-in-status reporting.
+type VisaStatusHistory struct {
+	ID                uint            `gorm:"primaryKey;autoIncrement"`
+	VisaApplicationID string          `gorm:"column:visa_application_id;type:varchar(60);not null;index"`
+	VisaApplication   VisaApplication `gorm:"foreignKey:VisaApplicationID"`
+
+	FromStatus        *string         `gorm:"column:from_status;type:varchar(30);null"` // Null for the first entry
+	ToStatus          string          `gorm:"column:to_status;type:varchar(30);not null"`
+	Note              *string         `gorm:"column:note;type:text;null"`               // Feedback or requested documents shown to the applicant
+
+	ChangedByID       *string         `gorm:"column:changed_by_id;type:varchar(60);null"` // Agent/admin who made the change (null for the applicant)
+
+	CreatedAt         time.Time       `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"time"
+)
+
+// Webhook event types
+const (
+	WebhookEventApplicationCreated            = "visa_application.created"
+	WebhookEventApplicationStatusChanged      = "visa_application.status_changed"
+	WebhookEventApplicationDocumentsRequested = "visa_application.documents_requested"
+	WebhookEventApplicationDecision           = "visa_application.decision"
+	WebhookEventPaymentSucceeded              = "payment.succeeded"
+	WebhookEventPaymentFailed                 = "payment.failed"
+	WebhookEventPaymentRefunded               = "payment.refunded"
+)
+
+// WebhookEventTypes lists every event an endpoint can subscribe to
+var WebhookEventTypes = []string{
+	WebhookEventApplicationCreated,
+	WebhookEventApplicationStatusChanged,
+	WebhookEventApplicationDocumentsRequested,
+	WebhookEventApplicationDecision,
+	WebhookEventPaymentSucceeded,
+	WebhookEventPaymentFailed,
+	WebhookEventPaymentRefunded,
+}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"   // Waiting for its first or next attempt
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryDead      = "dead"      // Gave up after the maximum attempts
+)
+
+// WebhookEndpoint is a subscription to events.
+// Partner endpoints only receive events for what they referred, platform endpoints (no owner) receive everything.
+type WebhookEndpoint struct {
+	ID          string    `gorm:"type:varchar(60);primaryKey"`
+	OwnerID     *string   `gorm:"column:owner_id;type:varchar(60);null;index"` // Partner user, null for platform endpoints
+	URL         string    `gorm:"column:url;type:varchar(500);not null"`
+	Secret      string    `gorm:"column:secret;type:varchar(100);not null"` // HMAC-SHA256 signing secret
+	Events      string    `gorm:"column:events;type:text;not null"`         // Comma separated event types, "*" for all
+	Description *string   `gorm:"column:description;type:varchar(255);null"`
+	Active      bool      `gorm:"column:active;not null;default:true"`
+
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// WebhookDelivery is one event queued for one endpoint
+type WebhookDelivery struct {
+	ID             string     `gorm:"type:varchar(60);primaryKey"`
+	EndpointID     string     `gorm:"column:endpoint_id;type:varchar(60);not null;index"`
+	EventID        string     `gorm:"column:event_id;type:varchar(60);not null;index"`
+	EventType      string     `gorm:"column:event_type;type:varchar(60);not null"`
+	Payload        []byte     `gorm:"column:payload;type:json;not null"` // Exactly the signed body
+	ReplayOfID     *string    `gorm:"column:replay_of_id;type:varchar(60);null"`
+
+	Status         string     `gorm:"column:status;type:varchar(20);not null;default:'pending';index:idx_webhook_due"`
+	Attempts       int        `gorm:"column:attempts;not null;default:0"`
+	NextAttemptAt  time.Time  `gorm:"column:next_attempt_at;not null;index:idx_webhook_due"`
+	LastStatusCode *int       `gorm:"column:last_status_code;null"`
+	LastError      *string    `gorm:"column:last_error;type:text;null"`
+	DeliveredAt    *time.Time `gorm:"column:delivered_at;null"`
+
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// WebhookAttempt logs every delivery attempt
+type WebhookAttempt struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement"`
+	DeliveryID string    `gorm:"column:delivery_id;type:varchar(60);not null;index"`
+	StatusCode *int      `gorm:"column:status_code;null"`
+	Error      *string   `gorm:"column:error;type:text;null"`
+	DurationMS int64     `gorm:"column:duration_ms;not null"`
+	CreatedAt  time.Time
+}
@@ -0,0 +1,329 @@
+// DB interaction logic using GORM
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"japa/internal/domain/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TYPES
+
+// AffiliateRepository to interface with DB (referral codes, commission rules and the commission ledger)
+type AffiliateRepository struct {
+	DB *gorm.DB
+}
+
+// CommissionTotals sums a partner's ledger in one currency, minor units
+type CommissionTotals struct {
+	Currency  string
+	Earned    int64 // Earnings before reversals
+	Reversed  int64 // Positive
+	Pending   int64 // Net, still in the hold period
+	Available int64 // Net, past the hold period
+}
+
+// ReferredPurchases is the part of an application checkout paid for applications one partner referred
+type ReferredPurchases struct {
+	ReferrerID string
+	Amount     int64 // Minor units
+	Count      int64 // Applications
+}
+
+// METHODS
+
+// Initialize AffiliateRepository
+func NewAffiliateRepository(db *gorm.DB) *AffiliateRepository {
+	return &AffiliateRepository{DB: db}
+}
+
+// Fetch a partner's referral codes, newest first
+func (ar *AffiliateRepository) FindCodes(ctx context.Context, partnerID string) ([]entity.ReferralCode, error) {
+	var codes []entity.ReferralCode
+	err := ar.DB.
+		WithContext(ctx).
+		Where("partner_id = ?", partnerID).
+		Order("created_at desc").
+		Find(&codes).Error
+	return codes, err
+}
+
+// Find a referral code by its code
+func (ar *AffiliateRepository) FindCodeByCode(ctx context.Context, tx *gorm.DB, code string) (*entity.ReferralCode, error) {
+	var referralCode entity.ReferralCode
+	if err := tx.
+		WithContext(ctx).
+		Where("code = ?", code).
+		First(&referralCode).Error; err != nil {
+		return nil, err
+	}
+	return &referralCode, nil
+}
+
+// Find one of the partner's referral codes
+func (ar *AffiliateRepository) FindPartnerCode(ctx context.Context, partnerID string, codeID uint) (*entity.ReferralCode, error) {
+	var referralCode entity.ReferralCode
+	if err := ar.DB.
+		WithContext(ctx).
+		Where("id = ? AND partner_id = ?", codeID, partnerID).
+		First(&referralCode).Error; err != nil {
+		return nil, err
+	}
+	return &referralCode, nil
+}
+
+// Create a referral code
+func (ar *AffiliateRepository) CreateCode(ctx context.Context, referralCode *entity.ReferralCode) error {
+	return ar.DB.WithContext(ctx).Create(referralCode).Error
+}
+
+// Save a referral code's label and status
+func (ar *AffiliateRepository) UpdateCode(ctx context.Context, referralCode *entity.ReferralCode) error {
+	return ar.DB.
+		WithContext(ctx).
+		Model(referralCode).
+		Updates(map[string]any{
+			"label":  referralCode.Label,
+			"active": referralCode.Active,
+		}).Error
+}
+
+// Count a referral link visit
+func (ar *AffiliateRepository) IncrementClicks(ctx context.Context, codeID uint) error {
+	return ar.DB.
+		WithContext(ctx).
+		Model(&entity.ReferralCode{}).
+		Where("id = ?", codeID).
+		UpdateColumn("clicks", gorm.Expr("clicks + 1")).Error
+}
+
+// Fetch all commission rules
+func (ar *AffiliateRepository) FindRules(ctx context.Context) ([]entity.CommissionRule, error) {
+	var rules []entity.CommissionRule
+	err := ar.DB.
+		WithContext(ctx).
+		Order("product asc, plan_code asc").
+		Find(&rules).Error
+	return rules, err
+}
+
+// The active rule for a product, a plan's own rule before the every-plan one
+func (ar *AffiliateRepository) FindRule(ctx context.Context, tx *gorm.DB, product string, planCode string) (*entity.CommissionRule, error) {
+	var rule entity.CommissionRule
+	if err := tx.
+		WithContext(ctx).
+		Where("product = ? AND plan_code IN ? AND active = ?", product, []string{planCode, ""}, true).
+		Order("plan_code desc").
+		First(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// Create or update the rule for a product and plan code
+func (ar *AffiliateRepository) SaveRule(ctx context.Context, rule *entity.CommissionRule) error {
+	return ar.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing entity.CommissionRule
+		err := tx.
+			Where("product = ? AND plan_code = ?", rule.Product, rule.PlanCode).
+			First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			rule.ID = existing.ID
+			rule.CreatedAt = existing.CreatedAt
+		}
+
+		return tx.Save(rule).Error
+	})
+}
+
+// Delete a commission rule, the ledger keeps its entries
+func (ar *AffiliateRepository) DeleteRule(ctx context.Context, ruleID uint) error {
+	result := ar.DB.
+		WithContext(ctx).
+		Delete(&entity.CommissionRule{}, "id = ?", ruleID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Add entries to the commission ledger
+func (ar *AffiliateRepository) CreateCommissions(ctx context.Context, tx *gorm.DB, commissions []entity.Commission) error {
+	if len(commissions) == 0 {
+		return nil
+	}
+	return tx.WithContext(ctx).Create(&commissions).Error
+}
+
+// Whether the partner already earned on the referred user's product, for first payment only rules
+func (ar *AffiliateRepository) HasEarned(ctx context.Context, tx *gorm.DB, partnerID string, referredUserID string, product string) (bool, error) {
+	var count int64
+	err := tx.
+		WithContext(ctx).
+		Model(&entity.Commission{}).
+		Where("partner_id = ? AND referred_user_id = ? AND product = ? AND kind = ?",
+			partnerID, referredUserID, product, entity.CommissionKindEarning).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Find and lock a payment's earnings, with what was already reversed of each
+func (ar *AffiliateRepository) LockEarnings(ctx context.Context, tx *gorm.DB, paymentID string) ([]entity.Commission, map[string]int64, error) {
+	var earnings []entity.Commission
+	if err := tx.
+		WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("payment_id = ? AND kind = ?", paymentID, entity.CommissionKindEarning).
+		Find(&earnings).Error; err != nil {
+		return nil, nil, err
+	}
+
+	reversed := map[string]int64{}
+	if len(earnings) == 0 {
+		return earnings, reversed, nil
+	}
+	ids := make([]string, len(earnings))
+	for i, earning := range earnings {
+		ids[i] = earning.ID
+	}
+
+	var rows []struct {
+		ReversalOf string
+		Total      int64
+	}
+	if err := tx.
+		WithContext(ctx).
+		Model(&entity.Commission{}).
+		Select("reversal_of, -SUM(amount) AS total").
+		Where("reversal_of IN ?", ids).
+		Group("reversal_of").
+		Scan(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, row := range rows {
+		reversed[row.ReversalOf] = row.Total
+	}
+	return earnings, reversed, nil
+}
+
+// An application checkout's purchases grouped by their application's referrer, unreferred ones left out
+func (ar *AffiliateRepository) SumReferredPurchases(ctx context.Context, tx *gorm.DB, paymentID string) ([]ReferredPurchases, error) {
+	var rows []ReferredPurchases
+	err := tx.
+		WithContext(ctx).
+		Model(&entity.Purchase{}).
+		Select("visa_applications.referrer_id AS referrer_id, SUM(purchases.amount) AS amount, COUNT(*) AS count").
+		Joins("JOIN visa_applications ON visa_applications.id = purchases.visa_application_id").
+		Where("purchases.payment_id = ? AND visa_applications.referrer_id IS NOT NULL", paymentID).
+		Group("visa_applications.referrer_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// Fetch a partner's ledger, newest first
+func (ar *AffiliateRepository) FindCommissions(ctx context.Context, partnerID string, limit int, offset int) ([]entity.Commission, int64, error) {
+	query := ar.DB.
+		WithContext(ctx).
+		Model(&entity.Commission{}).
+		Where("partner_id = ?", partnerID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var commissions []entity.Commission
+	err := query.
+		Order("created_at desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&commissions).Error
+	return commissions, total, err
+}
+
+// Users the partner referred, by referral code. Users attributed without a code are under 0.
+func (ar *AffiliateRepository) CountSignups(ctx context.Context, partnerID string) (map[uint]int64, error) {
+	var rows []struct {
+		ReferralCodeID *uint
+		Total          int64
+	}
+	if err := ar.DB.
+		WithContext(ctx).
+		Model(&entity.User{}).
+		Select("referral_code_id, COUNT(*) AS total").
+		Where("referrer_id = ?", partnerID).
+		Group("referral_code_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	signups := map[uint]int64{}
+	for _, row := range rows {
+		var codeID uint
+		if row.ReferralCodeID != nil {
+			codeID = *row.ReferralCodeID
+		}
+		signups[codeID] += row.Total
+	}
+	return signups, nil
+}
+
+// Referred users who made a payment the partner earned on
+func (ar *AffiliateRepository) CountConversions(ctx context.Context, partnerID string) (int64, error) {
+	var count int64
+	err := ar.DB.
+		WithContext(ctx).
+		Model(&entity.Commission{}).
+		Where("partner_id = ? AND kind = ?", partnerID, entity.CommissionKindEarning).
+		Distinct("referred_user_id").
+		Count(&count).Error
+	return count, err
+}
+
+// The partner's ledger totals by currency at now
+func (ar *AffiliateRepository) SumCommissions(ctx context.Context, partnerID string, now time.Time) ([]CommissionTotals, error) {
+	var totals []CommissionTotals
+	err := ar.DB.
+		WithContext(ctx).
+		Model(&entity.Commission{}).
+		Select(`currency,
+			SUM(CASE WHEN kind = ? THEN amount ELSE 0 END) AS earned,
+			-SUM(CASE WHEN kind = ? THEN amount ELSE 0 END) AS reversed,
+			SUM(CASE WHEN available_at > ? THEN amount ELSE 0 END) AS pending,
+			SUM(CASE WHEN available_at <= ? THEN amount ELSE 0 END) AS available`,
+			entity.CommissionKindEarning, entity.CommissionKindReversal, now, now).
+		Where("partner_id = ?", partnerID).
+		Group("currency").
+		Order("currency asc").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// The partner's ledger totals by currency for entries created in [from, to), for statements.
+// Only Earned and Reversed are set.
+func (ar *AffiliateRepository) SumCommissionsBetween(ctx context.Context, partnerID string, from time.Time, to time.Time) ([]CommissionTotals, error) {
+	var totals []CommissionTotals
+	err := ar.DB.
+		WithContext(ctx).
+		Model(&entity.Commission{}).
+		Select(`currency,
+			SUM(CASE WHEN kind = ? THEN amount ELSE 0 END) AS earned,
+			-SUM(CASE WHEN kind = ? THEN amount ELSE 0 END) AS reversed`,
+			entity.CommissionKindEarning, entity.CommissionKindReversal).
+		Where("partner_id = ? AND created_at >= ? AND created_at < ?", partnerID, from, to).
+		Group("currency").
+		Order("currency asc").
+		Scan(&totals).Error
+	return totals, err
+}
@@ -0,0 +1,111 @@
+// DB interaction logic using GORM
+package repository
+
+import (
+	"context"
+	"time"
+
+	"japa/internal/domain/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TYPES
+
+// AppointmentRepository to interface with DB
+type AppointmentRepository struct {
+	DB *gorm.DB
+}
+
+// METHODS
+
+// Initialize AppointmentRepository
+func NewAppointmentRepository(db *gorm.DB) *AppointmentRepository {
+	return &AppointmentRepository{DB: db}
+}
+
+// Create appointment
+func (ar *AppointmentRepository) Create(ctx context.Context, tx *gorm.DB, appointment *entity.Appointment) error {
+	return tx.WithContext(ctx).Omit("VisaApplication").Create(appointment).Error
+}
+
+// Update appointment
+func (ar *AppointmentRepository) Update(ctx context.Context, tx *gorm.DB, appointment *entity.Appointment) error {
+	return tx.WithContext(ctx).Omit("VisaApplication").Save(appointment).Error
+}
+
+// Find appointment by id together with its application and applicant
+func (ar *AppointmentRepository) FindByID(ctx context.Context, tx *gorm.DB, appointmentID string) (*entity.Appointment, error) {
+	var appointment entity.Appointment
+	if err := tx.
+		WithContext(ctx).
+		Preload("VisaApplication.User").
+		Where("id = ?", appointmentID).
+		First(&appointment).Error; err != nil {
+		return nil, err
+	}
+
+	return &appointment, nil
+}
+
+// Appointments of an application, soonest first
+func (ar *AppointmentRepository) FindByApplication(ctx context.Context, applicationID string) ([]entity.Appointment, error) {
+	var appointments []entity.Appointment
+	err := ar.DB.
+		WithContext(ctx).
+		Where("visa_application_id = ?", applicationID).
+		Order("scheduled_at asc").
+		Find(&appointments).Error
+	return appointments, err
+}
+
+// Scheduled appointments starting within [from, to]
+func (ar *AppointmentRepository) FindUpcoming(ctx context.Context, from time.Time, to time.Time) ([]entity.Appointment, error) {
+	var appointments []entity.Appointment
+	err := ar.DB.
+		WithContext(ctx).
+		Preload("VisaApplication.User").
+		Where("status = ?", entity.AppointmentStatusScheduled).
+		Where("scheduled_at BETWEEN ? AND ?", from, to).
+		Find(&appointments).Error
+	return appointments, err
+}
+
+// Offsets (in minutes) already sent for an appointment
+func (ar *AppointmentRepository) FindSentOffsets(ctx context.Context, appointmentID string) (map[int]bool, error) {
+	var offsets []int
+	if err := ar.DB.
+		WithContext(ctx).
+		Model(&entity.AppointmentReminder{}).
+		Where("appointment_id = ?", appointmentID).
+		Pluck("offset_minutes", &offsets).Error; err != nil {
+		return nil, err
+	}
+
+	sent := make(map[int]bool, len(offsets))
+	for _, offset := range offsets {
+		sent[offset] = true
+	}
+	return sent, nil
+}
+
+// Claim a reminder before sending it.
+// Returns false if another worker already claimed it.
+func (ar *AppointmentRepository) ClaimReminder(ctx context.Context, reminder *entity.AppointmentReminder) (bool, error) {
+	result := ar.DB.
+		WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(reminder)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Release a claimed reminder so it is retried
+func (ar *AppointmentRepository) ReleaseReminder(ctx context.Context, reminder *entity.AppointmentReminder) error {
+	return ar.DB.WithContext(ctx).Delete(reminder).Error
+}
+
+// Forget sent reminders (after a reschedule they must go out again)
+func (ar *AppointmentRepository) DeleteReminders(ctx context.Context, tx *gorm.DB, appointmentID string) error {
+	return tx.WithContext(ctx).Where("appointment_id = ?", appointmentID).Delete(&entity.AppointmentReminder{}).Error
+}
@@ -0,0 +1,102 @@
+// DB interaction logic using GORM
+package repository
+
+import (
+	"context"
+
+	"japa/internal/domain/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TYPES
+
+// AuditRepository to interface with DB (audit logs and permissions)
+type AuditRepository struct {
+	DB *gorm.DB
+}
+
+// METHODS
+
+// Initialize AuditRepository
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{DB: db}
+}
+
+// Record an audit log entry
+func (ar *AuditRepository) Create(ctx context.Context, tx *gorm.DB, log *entity.AuditLog) error {
+	return tx.WithContext(ctx).Create(log).Error
+}
+
+// Update an entry's metadata, i.e row counts once an export finishes
+func (ar *AuditRepository) UpdateMetadata(ctx context.Context, logID string, metadata []byte) error {
+	return ar.DB.
+		WithContext(ctx).
+		Model(&entity.AuditLog{}).
+		Where("id = ?", logID).
+		Update("metadata", metadata).Error
+}
+
+// Fetch audit logs, newest first. Empty filters match everything.
+func (ar *AuditRepository) Find(ctx context.Context, actorID string, action string, limit, offset int) ([]entity.AuditLog, int64, error) {
+	query := ar.DB.WithContext(ctx).Model(&entity.AuditLog{})
+	if actorID != "" {
+		query = query.Where("actor_id = ?", actorID)
+	}
+	if action != "" {
+		query = query.Where("action = ?", action)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []entity.AuditLog
+	err := query.
+		Order("created_at desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&logs).Error
+	return logs, total, err
+}
+
+// Whether the user holds the permission
+func (ar *AuditRepository) HasPermission(ctx context.Context, userID string, permission string) (bool, error) {
+	var count int64
+	err := ar.DB.
+		WithContext(ctx).
+		Model(&entity.UserPermission{}).
+		Where("user_id = ? AND permission = ?", userID, permission).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Fetch a user's permissions
+func (ar *AuditRepository) FindPermissions(ctx context.Context, userID string) ([]entity.UserPermission, error) {
+	var permissions []entity.UserPermission
+	err := ar.DB.
+		WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("permission asc").
+		Find(&permissions).Error
+	return permissions, err
+}
+
+// Grant a permission, granting twice is a no-op
+func (ar *AuditRepository) GrantPermission(ctx context.Context, tx *gorm.DB, permission *entity.UserPermission) error {
+	return tx.
+		WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(permission).Error
+}
+
+// Revoke a permission
+func (ar *AuditRepository) RevokePermission(ctx context.Context, tx *gorm.DB, userID string, permission string) (int64, error) {
+	result := tx.
+		WithContext(ctx).
+		Where("user_id = ? AND permission = ?", userID, permission).
+		Delete(&entity.UserPermission{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,208 @@
+// DB interaction logic using GORM
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"japa/internal/domain/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TYPES
+
+// BillingRepository to interface with DB (prices, add-ons and purchases)
+type BillingRepository struct {
+	DB *gorm.DB
+}
+
+// METHODS
+
+// Initialize BillingRepository
+func NewBillingRepository(db *gorm.DB) *BillingRepository {
+	return &BillingRepository{DB: db}
+}
+
+// Fetch all application prices
+func (br *BillingRepository) FindPrices(ctx context.Context) ([]entity.ApplicationPrice, error) {
+	var prices []entity.ApplicationPrice
+	err := br.DB.
+		WithContext(ctx).
+		Order("destination asc, visa_type asc, currency asc").
+		Find(&prices).Error
+	return prices, err
+}
+
+// Most specific price for a destination and visa type, exact matches win over "*"
+func (br *BillingRepository) FindPrice(ctx context.Context, destination string, visaType string, currency string) (*entity.ApplicationPrice, error) {
+	var price entity.ApplicationPrice
+	if err := br.DB.
+		WithContext(ctx).
+		Where("currency = ? AND destination IN ? AND visa_type IN ?", currency, []string{destination, "*"}, []string{visaType, "*"}).
+		Order("destination = '*' asc, visa_type = '*' asc").
+		First(&price).Error; err != nil {
+		return nil, err
+	}
+	return &price, nil
+}
+
+// Create or update the price for a destination, visa type and currency
+func (br *BillingRepository) SavePrice(ctx context.Context, price *entity.ApplicationPrice) error {
+	return br.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing entity.ApplicationPrice
+		err := tx.
+			Where("destination = ? AND visa_type = ? AND currency = ?", price.Destination, price.VisaType, price.Currency).
+			First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			price.ID = existing.ID
+			price.CreatedAt = existing.CreatedAt
+		}
+
+		return tx.Save(price).Error
+	})
+}
+
+// Delete a price
+func (br *BillingRepository) DeletePrice(ctx context.Context, priceID uint) error {
+	result := br.DB.WithContext(ctx).Delete(&entity.ApplicationPrice{}, priceID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Fetch add-ons, all currencies when currency is empty
+func (br *BillingRepository) FindAddOns(ctx context.Context, currency string, activeOnly bool) ([]entity.AddOn, error) {
+	query := br.DB.WithContext(ctx)
+	if currency != "" {
+		query = query.Where("currency = ?", currency)
+	}
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+
+	var addOns []entity.AddOn
+	err := query.Order("code asc, currency asc").Find(&addOns).Error
+	return addOns, err
+}
+
+// Active add-ons by code in a currency
+func (br *BillingRepository) FindAddOnsByCode(ctx context.Context, codes []string, currency string) ([]entity.AddOn, error) {
+	var addOns []entity.AddOn
+	if len(codes) == 0 {
+		return addOns, nil
+	}
+	err := br.DB.
+		WithContext(ctx).
+		Where("code IN ? AND currency = ? AND active = ?", codes, currency, true).
+		Order("code asc").
+		Find(&addOns).Error
+	return addOns, err
+}
+
+// Create or update the add-on for a code and currency
+func (br *BillingRepository) SaveAddOn(ctx context.Context, addOn *entity.AddOn) error {
+	return br.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing entity.AddOn
+		err := tx.
+			Where("code = ? AND currency = ?", addOn.Code, addOn.Currency).
+			First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			addOn.ID = existing.ID
+			addOn.CreatedAt = existing.CreatedAt
+		}
+
+		return tx.Save(addOn).Error
+	})
+}
+
+// Create purchases
+func (br *BillingRepository) CreatePurchases(ctx context.Context, tx *gorm.DB, purchases []entity.Purchase) error {
+	return tx.WithContext(ctx).Create(&purchases).Error
+}
+
+// Move the payment's pending purchases to status, repeated calls change nothing.
+// paidAt becomes the purchase time of paid purchases.
+func (br *BillingRepository) SettlePurchases(ctx context.Context, tx *gorm.DB, paymentID string, status string, paidAt time.Time) error {
+	updates := map[string]any{"status": status}
+	if status == entity.PurchaseStatusPaid {
+		updates["purchased_at"] = paidAt
+	}
+	return tx.
+		WithContext(ctx).
+		Model(&entity.Purchase{}).
+		Where("payment_id = ? AND status = ?", paymentID, entity.PurchaseStatusPending).
+		Updates(updates).Error
+}
+
+// Mark the payment's paid purchases refunded, they no longer cover their applications
+func (br *BillingRepository) RefundPurchases(ctx context.Context, tx *gorm.DB, paymentID string) error {
+	return tx.
+		WithContext(ctx).
+		Model(&entity.Purchase{}).
+		Where("payment_id = ? AND status = ?", paymentID, entity.PurchaseStatusPaid).
+		Update("status", entity.PurchaseStatusRefunded).Error
+}
+
+// Pending purchases of the applications, oldest first
+func (br *BillingRepository) FindPendingPurchases(ctx context.Context, tx *gorm.DB, applicationIDs []string) ([]entity.Purchase, error) {
+	var purchases []entity.Purchase
+	if len(applicationIDs) == 0 {
+		return purchases, nil
+	}
+	err := tx.
+		WithContext(ctx).
+		Where("visa_application_id IN ? AND status = ?", applicationIDs, entity.PurchaseStatusPending).
+		Order("created_at asc").
+		Find(&purchases).Error
+	return purchases, err
+}
+
+// The purchases a payment pays for
+func (br *BillingRepository) FindPurchasesByPayment(ctx context.Context, tx *gorm.DB, paymentID string) ([]entity.Purchase, error) {
+	var purchases []entity.Purchase
+	err := tx.
+		WithContext(ctx).
+		Where("payment_id = ?", paymentID).
+		Find(&purchases).Error
+	return purchases, err
+}
+
+// Lock the applications for the rest of the transaction, so concurrent checkouts for them queue up
+func (br *BillingRepository) LockApplications(ctx context.Context, tx *gorm.DB, applicationIDs []string) error {
+	var ids []string
+	return tx.
+		WithContext(ctx).
+		Model(&entity.VisaApplication{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", applicationIDs).
+		Order("id asc").
+		Pluck("id", &ids).Error
+}
+
+// Which of the applications have a paid purchase
+func (br *BillingRepository) FindPaidApplicationIDs(ctx context.Context, tx *gorm.DB, applicationIDs []string) ([]string, error) {
+	var paid []string
+	if len(applicationIDs) == 0 {
+		return paid, nil
+	}
+	err := tx.
+		WithContext(ctx).
+		Model(&entity.Purchase{}).
+		Where("visa_application_id IN ? AND status = ?", applicationIDs, entity.PurchaseStatusPaid).
+		Distinct().
+		Pluck("visa_application_id", &paid).Error
+	return paid, err
+}
@@ -0,0 +1,191 @@
+// DB interaction logic using GORM
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"japa/internal/domain/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TYPES
+
+// CouponRepository to interface with DB (coupons and their redemptions)
+type CouponRepository struct {
+	DB *gorm.DB
+}
+
+// METHODS
+
+// Initialize CouponRepository
+func NewCouponRepository(db *gorm.DB) *CouponRepository {
+	return &CouponRepository{DB: db}
+}
+
+// Fetch all coupons, newest first
+func (cr *CouponRepository) FindAll(ctx context.Context) ([]entity.Coupon, error) {
+	var coupons []entity.Coupon
+	err := cr.DB.
+		WithContext(ctx).
+		Order("created_at desc").
+		Find(&coupons).Error
+	return coupons, err
+}
+
+// Find a coupon by code
+func (cr *CouponRepository) FindByCode(ctx context.Context, tx *gorm.DB, code string) (*entity.Coupon, error) {
+	var coupon entity.Coupon
+	if err := tx.
+		WithContext(ctx).
+		Where("code = ?", code).
+		First(&coupon).Error; err != nil {
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+// Find and lock a coupon by code, so its redemptions are counted one checkout at a time
+func (cr *CouponRepository) LockByCode(ctx context.Context, tx *gorm.DB, code string) (*entity.Coupon, error) {
+	var coupon entity.Coupon
+	if err := tx.
+		WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code = ?", code).
+		First(&coupon).Error; err != nil {
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+// Create or update the coupon for a code
+func (cr *CouponRepository) Save(ctx context.Context, coupon *entity.Coupon) error {
+	return cr.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing entity.Coupon
+		err := tx.
+			Where("code = ?", coupon.Code).
+			First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			coupon.ID = existing.ID
+			coupon.CreatedAt = existing.CreatedAt
+		}
+
+		return tx.Save(coupon).Error
+	})
+}
+
+// Redemptions that count against the coupon's limits: redeemed ones, and pending ones created after heldSince.
+// An empty userID counts every user's.
+func (cr *CouponRepository) CountRedemptions(ctx context.Context, tx *gorm.DB, couponID uint, userID string, heldSince time.Time) (int64, error) {
+	query := tx.
+		WithContext(ctx).
+		Model(&entity.CouponRedemption{}).
+		Where("coupon_id = ?", couponID).
+		Where("status = ? OR (status = ? AND created_at > ?)",
+			entity.CouponRedemptionRedeemed, entity.CouponRedemptionPending, heldSince)
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// Redeemed count of each coupon
+func (cr *CouponRepository) CountRedeemed(ctx context.Context, couponIDs []uint) (map[uint]int64, error) {
+	counts := map[uint]int64{}
+	if len(couponIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		CouponID uint
+		Total    int64
+	}
+	if err := cr.DB.
+		WithContext(ctx).
+		Model(&entity.CouponRedemption{}).
+		Select("coupon_id, COUNT(*) AS total").
+		Where("coupon_id IN ? AND status = ?", couponIDs, entity.CouponRedemptionRedeemed).
+		Group("coupon_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.CouponID] = row.Total
+	}
+	return counts, nil
+}
+
+// Create a redemption
+func (cr *CouponRepository) CreateRedemption(ctx context.Context, tx *gorm.DB, redemption *entity.CouponRedemption) error {
+	return tx.WithContext(ctx).Create(redemption).Error
+}
+
+// Move the payment's pending redemptions to status, returning their total discount.
+// Repeated calls change nothing.
+func (cr *CouponRepository) SettleRedemptions(ctx context.Context, tx *gorm.DB, paymentID string, status string) (int64, error) {
+	var redemptions []entity.CouponRedemption
+	if err := tx.
+		WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("payment_id = ? AND status = ?", paymentID, entity.CouponRedemptionPending).
+		Find(&redemptions).Error; err != nil {
+		return 0, err
+	}
+	if len(redemptions) == 0 {
+		return 0, nil
+	}
+
+	var discount int64
+	ids := make([]string, len(redemptions))
+	for i, redemption := range redemptions {
+		ids[i] = redemption.ID
+		discount += redemption.Discount
+	}
+	err := tx.
+		WithContext(ctx).
+		Model(&entity.CouponRedemption{}).
+		Where("id IN ?", ids).
+		Update("status", status).Error
+	return discount, err
+}
+
+// Fetch the payment's redeemed redemptions with their coupons
+func (cr *CouponRepository) FindRedeemedByPayment(ctx context.Context, tx *gorm.DB, paymentID string) ([]entity.CouponRedemption, error) {
+	var redemptions []entity.CouponRedemption
+	err := tx.
+		WithContext(ctx).
+		Preload("Coupon").
+		Where("payment_id = ? AND status = ?", paymentID, entity.CouponRedemptionRedeemed).
+		Find(&redemptions).Error
+	return redemptions, err
+}
+
+// Fetch a coupon's redemptions, newest first
+func (cr *CouponRepository) FindRedemptions(ctx context.Context, couponID uint, limit int, offset int) ([]entity.CouponRedemption, int64, error) {
+	query := cr.DB.
+		WithContext(ctx).
+		Model(&entity.CouponRedemption{}).
+		Where("coupon_id = ?", couponID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var redemptions []entity.CouponRedemption
+	err := query.
+		Order("created_at desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&redemptions).Error
+	return redemptions, total, err
+}
@@ -0,0 +1,66 @@
+// DB interaction logic using GORM
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"japa/internal/domain/entity"
+
+	"gorm.io/gorm"
+)
+
+// TYPES
+
+// EligibilityRepository to interface with DB
+type EligibilityRepository struct {
+	DB *gorm.DB
+}
+
+// METHODS
+
+// Initialize EligibilityRepository
+func NewEligibilityRepository(db *gorm.DB) *EligibilityRepository {
+	return &EligibilityRepository{DB: db}
+}
+
+// Active rules for a destination and visa type, wildcard rules included
+func (er *EligibilityRepository) FindActiveRules(ctx context.Context, country string, visaType string) ([]entity.EligibilityRule, error) {
+	var rules []entity.EligibilityRule
+	err := er.DB.
+		WithContext(ctx).
+		Where("active = ?", true).
+		Where("LOWER(country) IN ?", []string{strings.ToLower(country), "*"}).
+		Where("LOWER(visa_type) IN ?", []string{strings.ToLower(visaType), "*"}).
+		Order("id asc").
+		Find(&rules).Error
+	return rules, err
+}
+
+// All rules, optionally for one country
+func (er *EligibilityRepository) FindRules(ctx context.Context, country string) ([]entity.EligibilityRule, error) {
+	var rules []entity.EligibilityRule
+	query := er.DB.WithContext(ctx).Order("country asc, id asc")
+	if country != "" {
+		query = query.Where("LOWER(country) = ?", strings.ToLower(country))
+	}
+	err := query.Find(&rules).Error
+	return rules, err
+}
+
+// Create rule
+func (er *EligibilityRepository) Create(ctx context.Context, rule *entity.EligibilityRule) error {
+	return er.DB.WithContext(ctx).Create(rule).Error
+}
+
+// Delete rule
+func (er *EligibilityRepository) Delete(ctx context.Context, ruleID uint) error {
+	result := er.DB.WithContext(ctx).Delete(&entity.EligibilityRule{}, ruleID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
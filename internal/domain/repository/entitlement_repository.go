@@ -0,0 +1,171 @@
+// DB interaction logic using GORM
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"japa/internal/domain/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TYPES
+
+// EntitlementRepository to interface with DB (plan entitlements and usage counters)
+type EntitlementRepository struct {
+	DB *gorm.DB
+}
+
+// METHODS
+
+// Initialize EntitlementRepository
+func NewEntitlementRepository(db *gorm.DB) *EntitlementRepository {
+	return &EntitlementRepository{DB: db}
+}
+
+// Plan of the user's subscription in good standing, the latest one when several overlap
+func (er *EntitlementRepository) FindSubscribedPlanID(ctx context.Context, tx *gorm.DB, userID string, now time.Time) (uint, error) {
+	var subscription entity.Subscription
+	if err := tx.
+		WithContext(ctx).
+		Select("plan_id").
+		Where("user_id = ?", userID).
+		Where("(status = ? AND expires_at > ?) OR (status = ? AND grace_ends_at > ?)",
+			entity.SubscriptionStatusActive, now, entity.SubscriptionStatusPastDue, now).
+		Order("started_at desc").
+		First(&subscription).Error; err != nil {
+		return 0, err
+	}
+	return subscription.PlanID, nil
+}
+
+// Find the version of a plan code on sale
+func (er *EntitlementRepository) FindActivePlanByCode(ctx context.Context, tx *gorm.DB, code string) (*entity.Plan, error) {
+	var plan entity.Plan
+	if err := tx.
+		WithContext(ctx).
+		Where("code = ? AND status = ?", code, entity.PlanStatusActive).
+		Order("version desc").
+		First(&plan).Error; err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// Find a plan by ID
+func (er *EntitlementRepository) FindPlan(ctx context.Context, tx *gorm.DB, planID uint) (*entity.Plan, error) {
+	var plan entity.Plan
+	if err := tx.
+		WithContext(ctx).
+		First(&plan, "id = ?", planID).Error; err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// Fetch a plan's entitlements
+func (er *EntitlementRepository) FindByPlan(ctx context.Context, tx *gorm.DB, planID uint) ([]entity.PlanEntitlement, error) {
+	var entitlements []entity.PlanEntitlement
+	err := tx.
+		WithContext(ctx).
+		Where("plan_id = ?", planID).
+		Order("entitlement_key asc").
+		Find(&entitlements).Error
+	return entitlements, err
+}
+
+// Create or update the plan's entitlement for a key
+func (er *EntitlementRepository) Save(ctx context.Context, entitlement *entity.PlanEntitlement) error {
+	return er.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing entity.PlanEntitlement
+		err := tx.
+			Where("plan_id = ? AND entitlement_key = ?", entitlement.PlanID, entitlement.Key).
+			First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			entitlement.ID = existing.ID
+			entitlement.CreatedAt = existing.CreatedAt
+		}
+
+		return tx.Save(entitlement).Error
+	})
+}
+
+// Delete the plan's entitlement for a key
+func (er *EntitlementRepository) Delete(ctx context.Context, planID uint, key string) error {
+	result := er.DB.
+		WithContext(ctx).
+		Where("plan_id = ? AND entitlement_key = ?", planID, key).
+		Delete(&entity.PlanEntitlement{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Find and lock the user's counter for a period, creating it at zero first.
+// Concurrent consumers of the same quota wait on the lock, so limits hold.
+func (er *EntitlementRepository) LockCounter(ctx context.Context, tx *gorm.DB, userID string, key string, periodStart time.Time) (*entity.UsageCounter, error) {
+	counter := entity.UsageCounter{UserID: userID, Key: key, PeriodStart: periodStart}
+	if err := tx.
+		WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&counter).Error; err != nil {
+		return nil, err
+	}
+
+	var locked entity.UsageCounter
+	if err := tx.
+		WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND entitlement_key = ? AND period_start = ?", userID, key, periodStart).
+		First(&locked).Error; err != nil {
+		return nil, err
+	}
+	return &locked, nil
+}
+
+// Save a counter's usage
+func (er *EntitlementRepository) UpdateCounter(ctx context.Context, tx *gorm.DB, counter *entity.UsageCounter) error {
+	return tx.
+		WithContext(ctx).
+		Model(counter).
+		Update("used", counter.Used).Error
+}
+
+// The user's counters for the given periods, by key
+func (er *EntitlementRepository) FindCounters(ctx context.Context, tx *gorm.DB, userID string, periods map[string]time.Time) (map[string]int64, error) {
+	used := map[string]int64{}
+	if len(periods) == 0 {
+		return used, nil
+	}
+
+	keys := make([]string, 0, len(periods))
+	starts := make([]time.Time, 0, len(periods))
+	for key, start := range periods {
+		keys = append(keys, key)
+		starts = append(starts, start)
+	}
+	var counters []entity.UsageCounter
+	if err := tx.
+		WithContext(ctx).
+		Where("user_id = ? AND entitlement_key IN ? AND period_start IN ?", userID, keys, starts).
+		Find(&counters).Error; err != nil {
+		return nil, err
+	}
+
+	for _, counter := range counters {
+		if counter.PeriodStart.Equal(periods[counter.Key]) {
+			used[counter.Key] = counter.Used
+		}
+	}
+	return used, nil
+}
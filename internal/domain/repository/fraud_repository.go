@@ -0,0 +1,129 @@
+// DB interaction logic using GORM
+package repository
+
+import (
+	"context"
+	"time"
+
+	"japa/internal/domain/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TYPES
+
+// FraudRepository to interface with DB
+type FraudRepository struct {
+	DB *gorm.DB
+}
+
+// METHODS
+
+// Initialize FraudRepository
+func NewFraudRepository(db *gorm.DB) *FraudRepository {
+	return &FraudRepository{DB: db}
+}
+
+// Save an application's fingerprints, existing ones are kept
+func (fr *FraudRepository) SaveFingerprints(ctx context.Context, tx *gorm.DB, fingerprints []entity.ApplicationFingerprint) error {
+	if len(fingerprints) == 0 {
+		return nil
+	}
+	return tx.
+		WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&fingerprints).Error
+}
+
+// Fingerprints of other accounts' applications matching the hashes, newest first
+func (fr *FraudRepository) FindMatches(ctx context.Context, tx *gorm.DB, kind string, hashes []string, excludeUserID string, limit int) ([]entity.ApplicationFingerprint, error) {
+	var matches []entity.ApplicationFingerprint
+	if len(hashes) == 0 {
+		return matches, nil
+	}
+
+	err := tx.
+		WithContext(ctx).
+		Where("kind = ? AND hash IN ? AND user_id <> ?", kind, hashes, excludeUserID).
+		Order("created_at desc").
+		Limit(limit).
+		Find(&matches).Error
+	return matches, err
+}
+
+// Number of the user's solo and principal applications submitted since the given time.
+// Dependents are submitted together with their principal so they are left out.
+func (fr *FraudRepository) CountSubmissionsSince(ctx context.Context, tx *gorm.DB, userID string, since time.Time) (int64, error) {
+	var count int64
+	err := tx.
+		WithContext(ctx).
+		Model(&entity.VisaStatusHistory{}).
+		Joins("JOIN visa_applications ON visa_applications.id = visa_status_histories.visa_application_id").
+		Where("visa_applications.user_id = ?", userID).
+		Where("(visa_applications.relationship IS NULL OR visa_applications.relationship = ?)", entity.GroupRelationPrincipal).
+		Where("visa_status_histories.to_status = ? AND visa_status_histories.created_at >= ?", entity.VisaStatusPending, since).
+		Distinct("visa_status_histories.visa_application_id").
+		Count(&count).Error
+	return count, err
+}
+
+// Save signals, a signal already raised for the same related application is kept
+func (fr *FraudRepository) CreateSignals(ctx context.Context, tx *gorm.DB, signals []entity.FraudSignal) error {
+	if len(signals) == 0 {
+		return nil
+	}
+	return tx.
+		WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&signals).Error
+}
+
+// Signals of the applications grouped by application, open ones only unless all is set
+func (fr *FraudRepository) FindSignals(ctx context.Context, applicationIDs []string, all bool) (map[string][]entity.FraudSignal, error) {
+	signals := make(map[string][]entity.FraudSignal, len(applicationIDs))
+	if len(applicationIDs) == 0 {
+		return signals, nil
+	}
+
+	query := fr.DB.
+		WithContext(ctx).
+		Where("visa_application_id IN ?", applicationIDs)
+	if !all {
+		query = query.Where("resolution IS NULL")
+	}
+
+	var rows []entity.FraudSignal
+	if err := query.Order("created_at asc, id asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		signals[row.VisaApplicationID] = append(signals[row.VisaApplicationID], row)
+	}
+	return signals, nil
+}
+
+// Find an application's signal by id
+func (fr *FraudRepository) FindSignal(ctx context.Context, tx *gorm.DB, applicationID string, signalID uint) (*entity.FraudSignal, error) {
+	var signal entity.FraudSignal
+	if err := tx.
+		WithContext(ctx).
+		Where("id = ? AND visa_application_id = ?", signalID, applicationID).
+		First(&signal).Error; err != nil {
+		return nil, err
+	}
+	return &signal, nil
+}
+
+// Record the review outcome of a signal
+func (fr *FraudRepository) ReviewSignal(ctx context.Context, tx *gorm.DB, signal *entity.FraudSignal) error {
+	return tx.
+		WithContext(ctx).
+		Model(signal).
+		Updates(map[string]any{
+			"resolution":  signal.Resolution,
+			"review_note": signal.ReviewNote,
+			"reviewed_by": signal.ReviewedBy,
+			"reviewed_at": signal.ReviewedAt,
+		}).Error
+}
@@ -0,0 +1,104 @@
+// DB interaction logic using GORM
+package repository
+
+import (
+	"context"
+	"time"
+
+	"japa/internal/domain/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TYPES
+
+// InvoiceRepository to interface with DB (invoices and their number series)
+type InvoiceRepository struct {
+	DB *gorm.DB
+}
+
+// METHODS
+
+// Initialize InvoiceRepository
+func NewInvoiceRepository(db *gorm.DB) *InvoiceRepository {
+	return &InvoiceRepository{DB: db}
+}
+
+// Next number in the series. The counter row stays locked until tx ends,
+// so concurrent settlements queue up and a rollback leaves no gap.
+func (ir *InvoiceRepository) NextNumber(ctx context.Context, tx *gorm.DB, series string) (int64, error) {
+	err := tx.
+		WithContext(ctx).
+		Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]any{"value": gorm.Expr("value + 1")}),
+		}).
+		Create(&entity.InvoiceCounter{Series: series, Value: 1}).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var counter entity.InvoiceCounter
+	err = tx.
+		WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&counter, "series = ?", series).Error
+	return counter.Value, err
+}
+
+// Create an invoice
+func (ir *InvoiceRepository) Create(ctx context.Context, tx *gorm.DB, invoice *entity.Invoice) error {
+	return tx.WithContext(ctx).Create(invoice).Error
+}
+
+// Find the invoice issued for a payment
+func (ir *InvoiceRepository) FindByPayment(ctx context.Context, tx *gorm.DB, paymentID string) (*entity.Invoice, error) {
+	var invoice entity.Invoice
+	if err := tx.
+		WithContext(ctx).
+		First(&invoice, "payment_id = ?", paymentID).Error; err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// Find one of the user's invoices
+func (ir *InvoiceRepository) FindForUser(ctx context.Context, userID string, invoiceID string) (*entity.Invoice, error) {
+	var invoice entity.Invoice
+	if err := ir.DB.
+		WithContext(ctx).
+		First(&invoice, "id = ? AND user_id = ?", invoiceID, userID).Error; err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// Fetch the user's invoices, newest first
+func (ir *InvoiceRepository) FindByUser(ctx context.Context, userID string, limit int, offset int) ([]entity.Invoice, int64, error) {
+	var invoices []entity.Invoice
+	var total int64
+
+	query := ir.DB.
+		WithContext(ctx).
+		Model(&entity.Invoice{}).
+		Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("paid_at desc, number desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&invoices).Error
+	return invoices, total, err
+}
+
+// Record when the invoice was last emailed
+func (ir *InvoiceRepository) MarkEmailed(ctx context.Context, invoiceID string, emailedAt time.Time) error {
+	return ir.DB.
+		WithContext(ctx).
+		Model(&entity.Invoice{}).
+		Where("id = ?", invoiceID).
+		Update("emailed_at", emailedAt).Error
+}
@@ -0,0 +1,173 @@
+// DB interaction logic using GORM
+package repository
+
+import (
+	"context"
+	"time"
+
+	"japa/internal/domain/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TYPES
+
+// LedgerRepository to interface with DB (ledger transactions, entries and reconciliation reports).
+// There is no update or delete for transactions and entries, the ledger is append only.
+type LedgerRepository struct {
+	DB *gorm.DB
+}
+
+// LedgerBalance is an account's balance in one currency, minor units
+type LedgerBalance struct {
+	Account  string
+	Currency string
+	Debits   int64 // Positive
+	Credits  int64 // Positive
+	Balance  int64 // Debits less credits
+	Entries  int64
+}
+
+// METHODS
+
+// Initialize LedgerRepository
+func NewLedgerRepository(db *gorm.DB) *LedgerRepository {
+	return &LedgerRepository{DB: db}
+}
+
+// Add a transaction with its entries. Returns false, writing nothing, when its source is already posted.
+func (lr *LedgerRepository) CreateTransaction(ctx context.Context, tx *gorm.DB, transaction *entity.LedgerTransaction) (bool, error) {
+	result := tx.
+		WithContext(ctx).
+		Omit("Entries").
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(transaction)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	return true, tx.WithContext(ctx).Create(&transaction.Entries).Error
+}
+
+// Account balances by currency from entries before at, currency is optional
+func (lr *LedgerRepository) Balances(ctx context.Context, at time.Time, currency string) ([]LedgerBalance, error) {
+	query := lr.DB.
+		WithContext(ctx).
+		Model(&entity.LedgerEntry{}).
+		Select(`account, currency,
+			SUM(CASE WHEN amount > 0 THEN amount ELSE 0 END) AS debits,
+			-SUM(CASE WHEN amount < 0 THEN amount ELSE 0 END) AS credits,
+			SUM(amount) AS balance,
+			COUNT(*) AS entries`).
+		Where("occurred_at < ?", at)
+	if currency != "" {
+		query = query.Where("currency = ?", currency)
+	}
+
+	var balances []LedgerBalance
+	err := query.
+		Group("account, currency").
+		Order("account asc, currency asc").
+		Scan(&balances).Error
+	return balances, err
+}
+
+// Fetch an account's entries with their transactions, newest first. currency is optional.
+func (lr *LedgerRepository) FindEntries(ctx context.Context, account string, currency string, limit int, offset int) ([]entity.LedgerEntry, int64, error) {
+	query := lr.DB.
+		WithContext(ctx).
+		Model(&entity.LedgerEntry{}).
+		Where("account = ?", account)
+	if currency != "" {
+		query = query.Where("currency = ?", currency)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []entity.LedgerEntry
+	err := query.
+		Preload("Transaction").
+		Order("occurred_at desc, id desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&entries).Error
+	return entries, total, err
+}
+
+// Payments posted to the provider's clearing account in [from, to), with their entries and references
+func (lr *LedgerRepository) FindPostedPayments(ctx context.Context, provider string, from time.Time, to time.Time) ([]entity.LedgerTransaction, error) {
+	var transactions []entity.LedgerTransaction
+	err := lr.postedPayments(ctx, provider).
+		Where("occurred_at >= ? AND occurred_at < ?", from, to).
+		Find(&transactions).Error
+	return transactions, err
+}
+
+// Payments posted to the provider's clearing account by their references, whenever they were paid
+func (lr *LedgerRepository) FindPostedByReferences(ctx context.Context, provider string, references []string) ([]entity.LedgerTransaction, error) {
+	var transactions []entity.LedgerTransaction
+	if len(references) == 0 {
+		return transactions, nil
+	}
+	err := lr.postedPayments(ctx, provider).
+		Where("payment_id IN (?)", lr.DB.Model(&entity.Payment{}).Select("id").Where("reference IN ?", references)).
+		Find(&transactions).Error
+	return transactions, err
+}
+
+func (lr *LedgerRepository) postedPayments(ctx context.Context, provider string) *gorm.DB {
+	return lr.DB.
+		WithContext(ctx).
+		Preload("Entries").
+		Preload("Payment", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "reference")
+		}).
+		Where("kind = ?", entity.LedgerKindPayment).
+		Where("id IN (?)", lr.DB.
+			Model(&entity.LedgerEntry{}).
+			Select("transaction_id").
+			Where("account = ?", entity.LedgerProviderClearing+":"+provider))
+}
+
+// Find the provider's report for a day
+func (lr *LedgerRepository) FindReconciliation(ctx context.Context, provider string, day string) (*entity.LedgerReconciliation, error) {
+	var report entity.LedgerReconciliation
+	if err := lr.DB.
+		WithContext(ctx).
+		Where("provider = ? AND day = ?", provider, day).
+		First(&report).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Create or replace a reconciliation report, reruns overwrite the day's report
+func (lr *LedgerRepository) SaveReconciliation(ctx context.Context, report *entity.LedgerReconciliation) error {
+	return lr.DB.WithContext(ctx).Save(report).Error
+}
+
+// Fetch reconciliation reports, latest day first. provider is optional.
+func (lr *LedgerRepository) FindReconciliations(ctx context.Context, provider string, limit int, offset int) ([]entity.LedgerReconciliation, int64, error) {
+	query := lr.DB.
+		WithContext(ctx).
+		Model(&entity.LedgerReconciliation{})
+	if provider != "" {
+		query = query.Where("provider = ?", provider)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var reports []entity.LedgerReconciliation
+	err := query.
+		Order("day desc, provider asc").
+		Limit(limit).
+		Offset(offset).
+		Find(&reports).Error
+	return reports, total, err
+}
@@ -0,0 +1,322 @@
+// DB interaction logic using GORM
+package repository
+
+import (
+	"context"
+	"time"
+
+	"japa/internal/domain/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TYPES
+
+// PaymentRepository to interface with DB
+type PaymentRepository struct {
+	DB *gorm.DB
+}
+
+// METHODS
+
+// Initialize PaymentRepository
+func NewPaymentRepository(db *gorm.DB) *PaymentRepository {
+	return &PaymentRepository{DB: db}
+}
+
+// Create payment
+func (pr *PaymentRepository) Create(ctx context.Context, tx *gorm.DB, payment *entity.Payment) error {
+	return tx.WithContext(ctx).Create(payment).Error
+}
+
+// Find payment by our reference
+func (pr *PaymentRepository) FindByReference(ctx context.Context, tx *gorm.DB, reference string) (*entity.Payment, error) {
+	var payment entity.Payment
+	if err := tx.
+		WithContext(ctx).
+		Where("reference = ?", reference).
+		First(&payment).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// Find payment by id
+func (pr *PaymentRepository) FindByID(ctx context.Context, tx *gorm.DB, paymentID string) (*entity.Payment, error) {
+	var payment entity.Payment
+	if err := tx.
+		WithContext(ctx).
+		Where("id = ?", paymentID).
+		First(&payment).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// Find and lock a payment for the rest of the transaction, so concurrent settlements apply once
+func (pr *PaymentRepository) LockByReference(ctx context.Context, tx *gorm.DB, reference string) (*entity.Payment, error) {
+	var payment entity.Payment
+	if err := tx.
+		WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("reference = ?", reference).
+		First(&payment).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// Find and lock a payment by id
+func (pr *PaymentRepository) LockByID(ctx context.Context, tx *gorm.DB, paymentID string) (*entity.Payment, error) {
+	var payment entity.Payment
+	if err := tx.
+		WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", paymentID).
+		First(&payment).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// Save the provider checkout a pending payment was started with
+func (pr *PaymentRepository) UpdateCheckout(ctx context.Context, tx *gorm.DB, payment *entity.Payment) error {
+	return tx.
+		WithContext(ctx).
+		Model(payment).
+		Updates(map[string]any{
+			"provider":           payment.Provider,
+			"provider_reference": payment.ProviderReference,
+			"authorization_url":  payment.AuthorizationURL,
+		}).Error
+}
+
+// Save the payment's settlement
+func (pr *PaymentRepository) UpdateSettlement(ctx context.Context, tx *gorm.DB, payment *entity.Payment) error {
+	return tx.
+		WithContext(ctx).
+		Model(payment).
+		Updates(map[string]any{
+			"status":             payment.Status,
+			"provider_reference": payment.ProviderReference,
+			"fee":                payment.Fee,
+			"channel":            payment.Channel,
+			"failure_reason":     payment.FailureReason,
+			"paid_at":            payment.PaidAt,
+			"authorization_id":   payment.AuthorizationID,
+		}).Error
+}
+
+// Fetch the user's payments, newest first
+func (pr *PaymentRepository) FindByUser(ctx context.Context, userID string, limit int, offset int) ([]entity.Payment, int64, error) {
+	var payments []entity.Payment
+	var total int64
+
+	query := pr.DB.
+		WithContext(ctx).
+		Model(&entity.Payment{}).
+		Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("created_at desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&payments).Error
+	return payments, total, err
+}
+
+// Pending payments created in [from, to), oldest first, for reconciliation
+func (pr *PaymentRepository) FindPendingBetween(ctx context.Context, from time.Time, to time.Time, limit int) ([]entity.Payment, error) {
+	var payments []entity.Payment
+	err := pr.DB.
+		WithContext(ctx).
+		Where("status = ? AND created_at >= ? AND created_at < ?", entity.PaymentStatusPending, from, to).
+		Order("created_at asc").
+		Limit(limit).
+		Find(&payments).Error
+	return payments, err
+}
+
+// Store a webhook event. Returns false when the provider already sent it.
+func (pr *PaymentRepository) CreateEvent(ctx context.Context, tx *gorm.DB, event *entity.PaymentEvent) (bool, error) {
+	result := tx.
+		WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(event)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Find a stored webhook event by the provider's event id
+func (pr *PaymentRepository) FindEvent(ctx context.Context, tx *gorm.DB, provider string, eventID string) (*entity.PaymentEvent, error) {
+	var event entity.PaymentEvent
+	if err := tx.
+		WithContext(ctx).
+		Where("provider = ? AND event_id = ?", provider, eventID).
+		First(&event).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// Save a webhook event's processing outcome
+func (pr *PaymentRepository) UpdateEvent(ctx context.Context, tx *gorm.DB, event *entity.PaymentEvent) error {
+	return tx.
+		WithContext(ctx).
+		Model(event).
+		Updates(map[string]any{
+			"status":       event.Status,
+			"error":        event.Error,
+			"processed_at": event.ProcessedAt,
+		}).Error
+}
+
+// Save a reusable card, or find it when the provider returned it before
+func (pr *PaymentRepository) SaveAuthorization(ctx context.Context, tx *gorm.DB, authorization *entity.PaymentAuthorization) (*entity.PaymentAuthorization, error) {
+	var saved entity.PaymentAuthorization
+	err := tx.
+		WithContext(ctx).
+		Where("provider = ? AND code = ?", authorization.Provider, authorization.Code).
+		Attrs(authorization).
+		FirstOrCreate(&saved).Error
+	return &saved, err
+}
+
+// Find a stored card
+func (pr *PaymentRepository) FindAuthorization(ctx context.Context, tx *gorm.DB, authorizationID string) (*entity.PaymentAuthorization, error) {
+	var authorization entity.PaymentAuthorization
+	if err := tx.
+		WithContext(ctx).
+		Where("id = ?", authorizationID).
+		First(&authorization).Error; err != nil {
+		return nil, err
+	}
+	return &authorization, nil
+}
+
+// Find the user's most recently saved card
+func (pr *PaymentRepository) FindLatestAuthorization(ctx context.Context, tx *gorm.DB, userID string) (*entity.PaymentAuthorization, error) {
+	var authorization entity.PaymentAuthorization
+	if err := tx.
+		WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("updated_at desc").
+		First(&authorization).Error; err != nil {
+		return nil, err
+	}
+	return &authorization, nil
+}
+
+// Save the payment's refunded total
+func (pr *PaymentRepository) UpdateRefundState(ctx context.Context, tx *gorm.DB, payment *entity.Payment) error {
+	return tx.
+		WithContext(ctx).
+		Model(payment).
+		Updates(map[string]any{
+			"refunded_amount": payment.RefundedAmount,
+			"refund_status":   payment.RefundStatus,
+		}).Error
+}
+
+// Create a refund
+func (pr *PaymentRepository) CreateRefund(ctx context.Context, tx *gorm.DB, refund *entity.Refund) error {
+	return tx.WithContext(ctx).Create(refund).Error
+}
+
+// Save a refund's progress
+func (pr *PaymentRepository) UpdateRefund(ctx context.Context, tx *gorm.DB, refund *entity.Refund) error {
+	return tx.
+		WithContext(ctx).
+		Model(refund).
+		Updates(map[string]any{
+			"provider_refund_id": refund.ProviderRefundID,
+			"status":             refund.Status,
+			"failure_reason":     refund.FailureReason,
+			"processed_at":       refund.ProcessedAt,
+		}).Error
+}
+
+// Find and lock a refund for the rest of the transaction
+func (pr *PaymentRepository) LockRefund(ctx context.Context, tx *gorm.DB, refundID string) (*entity.Refund, error) {
+	var refund entity.Refund
+	if err := tx.
+		WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", refundID).
+		First(&refund).Error; err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// Total of the payment's pending and processed refunds, what can't be refunded again
+func (pr *PaymentRepository) SumRefunds(ctx context.Context, tx *gorm.DB, paymentID string) (int64, error) {
+	var total int64
+	err := tx.
+		WithContext(ctx).
+		Model(&entity.Refund{}).
+		Where("payment_id = ? AND status IN ?", paymentID, []string{entity.RefundStatusPending, entity.RefundStatusProcessed}).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// Fetch the payment's refunds, newest first
+func (pr *PaymentRepository) FindRefunds(ctx context.Context, paymentID string) ([]entity.Refund, error) {
+	var refunds []entity.Refund
+	err := pr.DB.
+		WithContext(ctx).
+		Where("payment_id = ?", paymentID).
+		Order("created_at desc").
+		Find(&refunds).Error
+	return refunds, err
+}
+
+// Pending refunds for the payment or, with an empty paymentID, any payment's that were
+// created before before, oldest first. Those without a provider id got no answer when made.
+func (pr *PaymentRepository) FindPendingRefunds(ctx context.Context, paymentID string, before time.Time, limit int) ([]entity.Refund, error) {
+	var refunds []entity.Refund
+	query := pr.DB.
+		WithContext(ctx).
+		Where("status = ? AND created_at < ?", entity.RefundStatusPending, before)
+	if paymentID != "" {
+		query = query.Where("payment_id = ?", paymentID)
+	}
+	err := query.
+		Order("created_at asc").
+		Limit(limit).
+		Find(&refunds).Error
+	return refunds, err
+}
+
+// Find and lock a dispute by the provider's id
+func (pr *PaymentRepository) LockDispute(ctx context.Context, tx *gorm.DB, provider string, providerDisputeID string) (*entity.PaymentDispute, error) {
+	var dispute entity.PaymentDispute
+	if err := tx.
+		WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("provider = ? AND provider_dispute_id = ?", provider, providerDisputeID).
+		First(&dispute).Error; err != nil {
+		return nil, err
+	}
+	return &dispute, nil
+}
+
+// Create or update a dispute
+func (pr *PaymentRepository) SaveDispute(ctx context.Context, tx *gorm.DB, dispute *entity.PaymentDispute) error {
+	return tx.WithContext(ctx).Save(dispute).Error
+}
+
+// Fetch the payment's disputes, newest first
+func (pr *PaymentRepository) FindDisputes(ctx context.Context, paymentID string) ([]entity.PaymentDispute, error) {
+	var disputes []entity.PaymentDispute
+	err := pr.DB.
+		WithContext(ctx).
+		Where("payment_id = ?", paymentID).
+		Order("created_at desc").
+		Find(&disputes).Error
+	return disputes, err
+}
@@ -0,0 +1,286 @@
+// DB interaction logic using GORM
+package repository
+
+import (
+	"context"
+	"time"
+
+	"japa/internal/domain/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TYPES
+
+// PayoutRepository to interface with DB (partner payout accounts, batches and payouts)
+type PayoutRepository struct {
+	DB *gorm.DB
+}
+
+// PayoutTotals sums a partner's payouts that didn't fail in one currency, minor units
+type PayoutTotals struct {
+	Currency string
+	Amount   int64
+	Count    int64
+}
+
+// Statuses of payouts that are still waiting on the provider
+var openPayoutStatuses = []string{entity.PayoutStatusPending, entity.PayoutStatusProcessing}
+
+// METHODS
+
+// Initialize PayoutRepository
+func NewPayoutRepository(db *gorm.DB) *PayoutRepository {
+	return &PayoutRepository{DB: db}
+}
+
+// Fetch a partner's payout accounts
+func (pr *PayoutRepository) FindAccounts(ctx context.Context, partnerID string) ([]entity.PayoutAccount, error) {
+	var accounts []entity.PayoutAccount
+	err := pr.DB.
+		WithContext(ctx).
+		Where("partner_id = ?", partnerID).
+		Order("currency asc").
+		Find(&accounts).Error
+	return accounts, err
+}
+
+// Find a partner's payout account for a currency
+func (pr *PayoutRepository) FindAccount(ctx context.Context, tx *gorm.DB, partnerID string, currency string) (*entity.PayoutAccount, error) {
+	var account entity.PayoutAccount
+	if err := tx.
+		WithContext(ctx).
+		Where("partner_id = ? AND currency = ?", partnerID, currency).
+		First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// Find and lock a payout account, payouts to it are worked out one batch at a time
+func (pr *PayoutRepository) LockAccount(ctx context.Context, tx *gorm.DB, accountID string) (*entity.PayoutAccount, error) {
+	var account entity.PayoutAccount
+	if err := tx.
+		WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", accountID).
+		First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// Every payout account's ID, for batches
+func (pr *PayoutRepository) FindAccountIDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	err := pr.DB.
+		WithContext(ctx).
+		Model(&entity.PayoutAccount{}).
+		Order("created_at asc").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Create or replace a payout account
+func (pr *PayoutRepository) SaveAccount(ctx context.Context, tx *gorm.DB, account *entity.PayoutAccount) error {
+	return tx.WithContext(ctx).Save(account).Error
+}
+
+// Remove a payout account
+func (pr *PayoutRepository) DeleteAccount(ctx context.Context, tx *gorm.DB, accountID string) error {
+	return tx.WithContext(ctx).Delete(&entity.PayoutAccount{}, "id = ?", accountID).Error
+}
+
+// The partner's payouts that didn't fail by currency, created before until. A zero until sums them all.
+func (pr *PayoutRepository) SumPayouts(ctx context.Context, tx *gorm.DB, partnerID string, until time.Time) ([]PayoutTotals, error) {
+	query := tx.
+		WithContext(ctx).
+		Model(&entity.Payout{}).
+		Select("currency, SUM(amount) AS amount, COUNT(*) AS count").
+		Where("partner_id = ? AND status <> ?", partnerID, entity.PayoutStatusFailed)
+	if !until.IsZero() {
+		query = query.Where("created_at < ?", until)
+	}
+
+	var totals []PayoutTotals
+	err := query.
+		Group("currency").
+		Order("currency asc").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// Whether the partner has payouts waiting on the provider in a currency
+func (pr *PayoutRepository) HasOpenPayouts(ctx context.Context, tx *gorm.DB, partnerID string, currency string) (bool, error) {
+	var count int64
+	err := tx.
+		WithContext(ctx).
+		Model(&entity.Payout{}).
+		Where("partner_id = ? AND currency = ? AND status IN ?", partnerID, currency, openPayoutStatuses).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Start a payout batch
+func (pr *PayoutRepository) CreateBatch(ctx context.Context, batch *entity.PayoutBatch) error {
+	return pr.DB.WithContext(ctx).Create(batch).Error
+}
+
+// Save a batch's payout count
+func (pr *PayoutRepository) UpdateBatchCount(ctx context.Context, batch *entity.PayoutBatch) error {
+	return pr.DB.
+		WithContext(ctx).
+		Model(batch).
+		Update("count", batch.Count).Error
+}
+
+// The latest batch, scheduled or not
+func (pr *PayoutRepository) FindLastBatch(ctx context.Context) (*entity.PayoutBatch, error) {
+	var batch entity.PayoutBatch
+	if err := pr.DB.
+		WithContext(ctx).
+		Order("created_at desc").
+		First(&batch).Error; err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// Find a batch with its payouts
+func (pr *PayoutRepository) FindBatch(ctx context.Context, batchID string) (*entity.PayoutBatch, []entity.Payout, error) {
+	var batch entity.PayoutBatch
+	if err := pr.DB.
+		WithContext(ctx).
+		Where("id = ?", batchID).
+		First(&batch).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var payouts []entity.Payout
+	err := pr.DB.
+		WithContext(ctx).
+		Preload("Account").
+		Where("batch_id = ?", batchID).
+		Order("created_at asc").
+		Find(&payouts).Error
+	return &batch, payouts, err
+}
+
+// Fetch batches, newest first
+func (pr *PayoutRepository) FindBatches(ctx context.Context, limit int, offset int) ([]entity.PayoutBatch, int64, error) {
+	query := pr.DB.
+		WithContext(ctx).
+		Model(&entity.PayoutBatch{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var batches []entity.PayoutBatch
+	err := query.
+		Order("created_at desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&batches).Error
+	return batches, total, err
+}
+
+// Completes processing batches that have no payouts waiting on the provider
+func (pr *PayoutRepository) CompleteBatches(ctx context.Context, now time.Time) error {
+	return pr.DB.
+		WithContext(ctx).
+		Model(&entity.PayoutBatch{}).
+		Where("status = ?", entity.PayoutBatchProcessing).
+		Where("NOT EXISTS (?)", pr.DB.
+			Model(&entity.Payout{}).
+			Select("1").
+			Where("payouts.batch_id = payout_batches.id AND payouts.status IN ?", openPayoutStatuses)).
+		Updates(map[string]any{
+			"status":       entity.PayoutBatchCompleted,
+			"completed_at": now,
+		}).Error
+}
+
+// Add a payout
+func (pr *PayoutRepository) CreatePayout(ctx context.Context, tx *gorm.DB, payout *entity.Payout) error {
+	return tx.WithContext(ctx).Create(payout).Error
+}
+
+// Find and lock a payout
+func (pr *PayoutRepository) LockPayout(ctx context.Context, tx *gorm.DB, payoutID string) (*entity.Payout, error) {
+	var payout entity.Payout
+	if err := tx.
+		WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", payoutID).
+		First(&payout).Error; err != nil {
+		return nil, err
+	}
+	return &payout, nil
+}
+
+// Save a payout's transfer outcome
+func (pr *PayoutRepository) UpdatePayout(ctx context.Context, tx *gorm.DB, payout *entity.Payout) error {
+	return tx.
+		WithContext(ctx).
+		Model(payout).
+		Updates(map[string]any{
+			"provider_transfer_id": payout.ProviderTransferID,
+			"status":               payout.Status,
+			"fee":                  payout.Fee,
+			"failure_reason":       payout.FailureReason,
+			"paid_at":              payout.PaidAt,
+		}).Error
+}
+
+// Payouts waiting on the provider, with their accounts, oldest first. An empty batchID means any batch.
+func (pr *PayoutRepository) FindOpenPayouts(ctx context.Context, batchID string, limit int) ([]entity.Payout, error) {
+	query := pr.DB.
+		WithContext(ctx).
+		Preload("Account").
+		Where("status IN ?", openPayoutStatuses)
+	if batchID != "" {
+		query = query.Where("batch_id = ?", batchID)
+	}
+
+	var payouts []entity.Payout
+	err := query.
+		Order("created_at asc").
+		Limit(limit).
+		Find(&payouts).Error
+	return payouts, err
+}
+
+// Fetch a partner's payouts, newest first
+func (pr *PayoutRepository) FindPayouts(ctx context.Context, partnerID string, limit int, offset int) ([]entity.Payout, int64, error) {
+	query := pr.DB.
+		WithContext(ctx).
+		Model(&entity.Payout{}).
+		Where("partner_id = ?", partnerID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var payouts []entity.Payout
+	err := query.
+		Order("created_at desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&payouts).Error
+	return payouts, total, err
+}
+
+// A partner's payouts created in [from, to), oldest first, for statements
+func (pr *PayoutRepository) FindPayoutsBetween(ctx context.Context, partnerID string, from time.Time, to time.Time) ([]entity.Payout, error) {
+	var payouts []entity.Payout
+	err := pr.DB.
+		WithContext(ctx).
+		Where("partner_id = ? AND created_at >= ? AND created_at < ?", partnerID, from, to).
+		Order("created_at asc").
+		Find(&payouts).Error
+	return payouts, err
+}
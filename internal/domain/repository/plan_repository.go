@@ -0,0 +1,298 @@
+// DB interaction logic using GORM
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"japa/internal/domain/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TYPES
+
+// PlanRepository to interface with DB (plans, their features and prices)
+type PlanRepository struct {
+	DB *gorm.DB
+}
+
+// METHODS
+
+// Initialize PlanRepository
+func NewPlanRepository(db *gorm.DB) *PlanRepository {
+	return &PlanRepository{DB: db}
+}
+
+// Fetch plans with their features and prices in pricing page order, every status when statuses is empty
+func (pr *PlanRepository) FindAll(ctx context.Context, statuses []string) ([]entity.Plan, error) {
+	query := pr.DB.WithContext(ctx)
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+
+	var plans []entity.Plan
+	err := query.
+		Preload("PlanFeatures", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order asc, id asc")
+		}).
+		Preload("PlanPrices").
+		Order("sort_order asc, code asc, version desc").
+		Find(&plans).Error
+	return plans, err
+}
+
+// Find a plan with its features and prices
+func (pr *PlanRepository) FindByID(ctx context.Context, tx *gorm.DB, planID uint) (*entity.Plan, error) {
+	var plan entity.Plan
+	if err := tx.
+		WithContext(ctx).
+		Preload("PlanFeatures", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order asc, id asc")
+		}).
+		Preload("PlanPrices").
+		First(&plan, "id = ?", planID).Error; err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// Find and lock a plan, so versions of the same code are created one at a time
+func (pr *PlanRepository) Lock(ctx context.Context, tx *gorm.DB, planID uint) (*entity.Plan, error) {
+	var plan entity.Plan
+	if err := tx.
+		WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&plan, "id = ?", planID).Error; err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// Highest version of a plan code, 0 when the code is new
+func (pr *PlanRepository) LatestVersion(ctx context.Context, tx *gorm.DB, code string) (int, error) {
+	var version int
+	err := tx.
+		WithContext(ctx).
+		Model(&entity.Plan{}).
+		Where("code = ?", code).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&version).Error
+	return version, err
+}
+
+// Create a plan with its features and prices
+func (pr *PlanRepository) Create(ctx context.Context, tx *gorm.DB, plan *entity.Plan) error {
+	return tx.WithContext(ctx).Create(plan).Error
+}
+
+// Save a plan's own fields, features and prices are saved separately
+func (pr *PlanRepository) Update(ctx context.Context, tx *gorm.DB, plan *entity.Plan) error {
+	return tx.
+		WithContext(ctx).
+		Model(plan).
+		Updates(map[string]any{
+			"name":        plan.Name,
+			"description": plan.Description,
+			"status":      plan.Status,
+			"sort_order":  plan.SortOrder,
+		}).Error
+}
+
+// Set the position of each plan, in the order of planIDs
+func (pr *PlanRepository) Reorder(ctx context.Context, planIDs []uint) error {
+	return pr.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.
+			Model(&entity.Plan{}).
+			Where("id IN ?", planIDs).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if int(count) != len(planIDs) {
+			return gorm.ErrRecordNotFound // rollback
+		}
+
+		for position, planID := range planIDs {
+			if err := tx.
+				Model(&entity.Plan{}).
+				Where("id = ?", planID).
+				Update("sort_order", position).Error; err != nil {
+				return err // rollback
+			}
+		}
+		return nil // commit
+	})
+}
+
+// Fetch a plan's entitlements, to carry them over to a new version
+func (pr *PlanRepository) FindEntitlements(ctx context.Context, tx *gorm.DB, planID uint) ([]entity.PlanEntitlement, error) {
+	var entitlements []entity.PlanEntitlement
+	err := tx.
+		WithContext(ctx).
+		Where("plan_id = ?", planID).
+		Find(&entitlements).Error
+	return entitlements, err
+}
+
+// Create entitlements
+func (pr *PlanRepository) CreateEntitlements(ctx context.Context, tx *gorm.DB, entitlements []entity.PlanEntitlement) error {
+	if len(entitlements) == 0 {
+		return nil
+	}
+	return tx.WithContext(ctx).Create(&entitlements).Error
+}
+
+// Create a feature
+func (pr *PlanRepository) CreateFeature(ctx context.Context, feature *entity.PlanFeature) error {
+	return pr.DB.WithContext(ctx).Create(feature).Error
+}
+
+// Find a plan's feature
+func (pr *PlanRepository) FindFeature(ctx context.Context, planID uint, featureID uint) (*entity.PlanFeature, error) {
+	var feature entity.PlanFeature
+	if err := pr.DB.
+		WithContext(ctx).
+		Where("id = ? AND plan_id = ?", featureID, planID).
+		First(&feature).Error; err != nil {
+		return nil, err
+	}
+	return &feature, nil
+}
+
+// Save a feature's label and value
+func (pr *PlanRepository) UpdateFeature(ctx context.Context, feature *entity.PlanFeature) error {
+	return pr.DB.
+		WithContext(ctx).
+		Model(feature).
+		Updates(map[string]any{
+			"feature_label": feature.FeatureLabel,
+			"feature_value": feature.FeatureValue,
+		}).Error
+}
+
+// Delete a plan's feature
+func (pr *PlanRepository) DeleteFeature(ctx context.Context, planID uint, featureID uint) error {
+	result := pr.DB.
+		WithContext(ctx).
+		Where("id = ? AND plan_id = ?", featureID, planID).
+		Delete(&entity.PlanFeature{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Next position at the end of a plan's features
+func (pr *PlanRepository) NextFeatureOrder(ctx context.Context, planID uint) (int, error) {
+	var next int
+	err := pr.DB.
+		WithContext(ctx).
+		Model(&entity.PlanFeature{}).
+		Where("plan_id = ?", planID).
+		Select("COALESCE(MAX(sort_order) + 1, 0)").
+		Scan(&next).Error
+	return next, err
+}
+
+// Set the position of each of a plan's features, in the order of featureIDs
+func (pr *PlanRepository) ReorderFeatures(ctx context.Context, planID uint, featureIDs []uint) error {
+	return pr.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.
+			Model(&entity.PlanFeature{}).
+			Where("plan_id = ? AND id IN ?", planID, featureIDs).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if int(count) != len(featureIDs) {
+			return gorm.ErrRecordNotFound // rollback, a feature isn't the plan's
+		}
+
+		for position, featureID := range featureIDs {
+			if err := tx.
+				Model(&entity.PlanFeature{}).
+				Where("id = ?", featureID).
+				Update("sort_order", position).Error; err != nil {
+				return err // rollback
+			}
+		}
+		return nil // commit
+	})
+}
+
+// Create or update the plan's price in a currency
+func (pr *PlanRepository) SavePrice(ctx context.Context, price *entity.PlanPrice) error {
+	return pr.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing entity.PlanPrice
+		err := tx.
+			Where("plan_id = ? AND currency = ?", price.PlanID, price.Currency).
+			First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			price.ID = existing.ID
+			price.CreatedAt = existing.CreatedAt
+		}
+
+		return tx.Save(price).Error
+	})
+}
+
+// Delete the plan's price in a currency
+func (pr *PlanRepository) DeletePrice(ctx context.Context, planID uint, currency string) error {
+	result := pr.DB.
+		WithContext(ctx).
+		Where("plan_id = ? AND currency = ?", planID, currency).
+		Delete(&entity.PlanPrice{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Number of subscriptions on each plan version that still hold it (active or past due)
+func (pr *PlanRepository) CountSubscribers(ctx context.Context, planIDs []uint) (map[uint]int64, error) {
+	counts := map[uint]int64{}
+	if len(planIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		PlanID uint
+		Total  int64
+	}
+	if err := pr.DB.
+		WithContext(ctx).
+		Model(&entity.Subscription{}).
+		Select("plan_id, COUNT(*) AS total").
+		Where("plan_id IN ? AND status IN ?", planIDs, []string{entity.SubscriptionStatusActive, entity.SubscriptionStatusPastDue}).
+		Group("plan_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.PlanID] = row.Total
+	}
+	return counts, nil
+}
+
+// Count the live subscriptions renewing at the plan's price in currency, scheduled downgrades to it included
+func (pr *PlanRepository) CountSubscribersIn(ctx context.Context, planID uint, currency string) (int64, error) {
+	var count int64
+	err := pr.DB.
+		WithContext(ctx).
+		Model(&entity.Subscription{}).
+		Where("(plan_id = ? OR pending_plan_id = ?) AND currency = ?", planID, planID, currency).
+		Where("status IN ?", []string{entity.SubscriptionStatusPending, entity.SubscriptionStatusActive, entity.SubscriptionStatusPastDue}).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,285 @@
+// DB interaction logic using GORM
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"japa/internal/domain/entity"
+
+	"gorm.io/gorm"
+)
+
+// Applications with an unreleased legal hold
+const activeLegalHold = "EXISTS (SELECT 1 FROM legal_holds WHERE legal_holds.visa_application_id = visa_applications.id AND legal_holds.released_at IS NULL)"
+
+// TYPES
+
+// RetentionRepository to interface with DB
+type RetentionRepository struct {
+	DB *gorm.DB
+}
+
+// METHODS
+
+// Initialize RetentionRepository
+func NewRetentionRepository(db *gorm.DB) *RetentionRepository {
+	return &RetentionRepository{DB: db}
+}
+
+// Fetch every retention policy
+func (rr *RetentionRepository) FindPolicies(ctx context.Context) ([]entity.RetentionPolicy, error) {
+	var policies []entity.RetentionPolicy
+	err := rr.DB.
+		WithContext(ctx).
+		Order("status asc").
+		Find(&policies).Error
+	return policies, err
+}
+
+// Create or update the policy for a status
+func (rr *RetentionRepository) SavePolicy(ctx context.Context, policy *entity.RetentionPolicy) error {
+	return rr.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing entity.RetentionPolicy
+		err := tx.Where("status = ?", policy.Status).First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			policy.ID = existing.ID
+			policy.CreatedAt = existing.CreatedAt
+		}
+
+		return tx.Save(policy).Error
+	})
+}
+
+// Delete policy by id
+func (rr *RetentionRepository) DeletePolicy(ctx context.Context, policyID uint) error {
+	result := rr.DB.WithContext(ctx).Delete(&entity.RetentionPolicy{}, policyID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Applications in the status since before the cutoff whose files are still stored, in id order after afterID.
+// Applications on legal hold are left out.
+func (rr *RetentionRepository) FindDueForDocumentPurge(ctx context.Context, status string, cutoff time.Time, afterID string, limit int) ([]entity.VisaApplication, error) {
+	var applications []entity.VisaApplication
+	err := rr.dueQuery(ctx, status, cutoff, "documents_purged_at").
+		Select("visa_applications.*").
+		Where("visa_applications.id > ?", afterID).
+		Where("NOT " + activeLegalHold).
+		Preload("Documents", "purged_at IS NULL").
+		Order("visa_applications.id asc").
+		Limit(limit).
+		Find(&applications).Error
+	return applications, err
+}
+
+// Applications in the status since before the cutoff whose form is not redacted yet, in id order after afterID.
+// Applications on legal hold are left out.
+func (rr *RetentionRepository) FindDueForFormRedaction(ctx context.Context, status string, cutoff time.Time, afterID string, limit int) ([]entity.VisaApplication, error) {
+	var applications []entity.VisaApplication
+	err := rr.dueQuery(ctx, status, cutoff, "form_redacted_at").
+		Select("visa_applications.*").
+		Where("visa_applications.id > ?", afterID).
+		Where("NOT " + activeLegalHold).
+		Order("visa_applications.id asc").
+		Limit(limit).
+		Find(&applications).Error
+	return applications, err
+}
+
+// Applications that would be due but are on legal hold
+func (rr *RetentionRepository) CountHeld(ctx context.Context, status string, cutoff time.Time, purgedColumn string) (int64, error) {
+	var count int64
+	err := rr.dueQuery(ctx, status, cutoff, purgedColumn).
+		Where(activeLegalHold).
+		Count(&count).Error
+	return count, err
+}
+
+// Applications in the status since before the cutoff with purgedColumn unset.
+// Applications without a timeline fall back to their last update.
+func (rr *RetentionRepository) dueQuery(ctx context.Context, status string, cutoff time.Time, purgedColumn string) *gorm.DB {
+	entered := rr.DB.
+		Model(&entity.VisaStatusHistory{}).
+		Select("visa_application_id, MAX(created_at) AS entered_at").
+		Group("visa_application_id")
+
+	return rr.DB.
+		WithContext(ctx).
+		Model(&entity.VisaApplication{}).
+		Joins("LEFT JOIN (?) AS status_entered ON status_entered.visa_application_id = visa_applications.id", entered).
+		Where("visa_applications.status = ?", status).
+		Where("COALESCE(status_entered.entered_at, visa_applications.updated_at) < ?", cutoff).
+		Where("visa_applications." + purgedColumn + " IS NULL")
+}
+
+// Mark a document's file as deleted
+func (rr *RetentionRepository) MarkDocumentPurged(ctx context.Context, tx *gorm.DB, documentID string, purgedAt time.Time) error {
+	return tx.
+		WithContext(ctx).
+		Model(&entity.Document{}).
+		Where("id = ?", documentID).
+		Updates(map[string]any{
+			"file_path": "",
+			"purged_at": purgedAt,
+		}).Error
+}
+
+// Mark an application's files as deleted, clearing the signed form
+func (rr *RetentionRepository) MarkDocumentsPurged(ctx context.Context, tx *gorm.DB, applicationID string, purgedAt time.Time) error {
+	return tx.
+		WithContext(ctx).
+		Model(&entity.VisaApplication{}).
+		Where("id = ?", applicationID).
+		UpdateColumns(map[string]any{
+			"visa_form_url":       nil,
+			"documents_purged_at": purgedAt,
+		}).Error
+}
+
+// Replace the form with its redacted version and drop the fingerprints derived from it
+func (rr *RetentionRepository) RedactForm(ctx context.Context, tx *gorm.DB, applicationID string, formInput []byte, redactedAt time.Time) error {
+	if err := tx.
+		WithContext(ctx).
+		Model(&entity.VisaApplication{}).
+		Where("id = ?", applicationID).
+		UpdateColumns(map[string]any{
+			"visa_form_input":  formInput,
+			"applicant_name":   nil,
+			"form_redacted_at": redactedAt,
+		}).Error; err != nil {
+		return err
+	}
+
+	return tx.
+		WithContext(ctx).
+		Where("visa_application_id = ?", applicationID).
+		Delete(&entity.ApplicationFingerprint{}).Error
+}
+
+// Create a legal hold
+func (rr *RetentionRepository) CreateHold(ctx context.Context, tx *gorm.DB, hold *entity.LegalHold) error {
+	return tx.WithContext(ctx).Create(hold).Error
+}
+
+// Find the application's active legal hold
+func (rr *RetentionRepository) FindActiveHold(ctx context.Context, tx *gorm.DB, applicationID string) (*entity.LegalHold, error) {
+	var hold entity.LegalHold
+	if err := tx.
+		WithContext(ctx).
+		Where("visa_application_id = ? AND released_at IS NULL", applicationID).
+		First(&hold).Error; err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// Release a legal hold
+func (rr *RetentionRepository) ReleaseHold(ctx context.Context, tx *gorm.DB, hold *entity.LegalHold) error {
+	return tx.
+		WithContext(ctx).
+		Model(hold).
+		Updates(map[string]any{
+			"released_by": hold.ReleasedBy,
+			"released_at": hold.ReleasedAt,
+		}).Error
+}
+
+// Fetch active legal holds, newest first
+func (rr *RetentionRepository) FindActiveHolds(ctx context.Context, limit int, offset int) ([]entity.LegalHold, int64, error) {
+	var holds []entity.LegalHold
+	var total int64
+
+	query := rr.DB.
+		WithContext(ctx).
+		Model(&entity.LegalHold{}).
+		Where("released_at IS NULL")
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("created_at desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&holds).Error
+	return holds, total, err
+}
+
+// Create a purge run
+func (rr *RetentionRepository) CreateRun(ctx context.Context, run *entity.PurgeRun) error {
+	return rr.DB.WithContext(ctx).Create(run).Error
+}
+
+// Save the run's totals and outcome
+func (rr *RetentionRepository) UpdateRun(ctx context.Context, run *entity.PurgeRun) error {
+	return rr.DB.WithContext(ctx).Save(run).Error
+}
+
+// Append items to a purge report
+func (rr *RetentionRepository) CreateItems(ctx context.Context, tx *gorm.DB, items []entity.PurgeItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return tx.WithContext(ctx).Create(&items).Error
+}
+
+// Fetch purge runs, newest first
+func (rr *RetentionRepository) FindRuns(ctx context.Context, limit int, offset int) ([]entity.PurgeRun, int64, error) {
+	var runs []entity.PurgeRun
+	var total int64
+
+	query := rr.DB.WithContext(ctx).Model(&entity.PurgeRun{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("started_at desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&runs).Error
+	return runs, total, err
+}
+
+// Find purge run by id
+func (rr *RetentionRepository) FindRun(ctx context.Context, runID string) (*entity.PurgeRun, error) {
+	var run entity.PurgeRun
+	if err := rr.DB.
+		WithContext(ctx).
+		Where("id = ?", runID).
+		First(&run).Error; err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// Fetch a page of a run's report items
+func (rr *RetentionRepository) FindItems(ctx context.Context, runID string, limit int, offset int) ([]entity.PurgeItem, int64, error) {
+	var items []entity.PurgeItem
+	var total int64
+
+	query := rr.DB.
+		WithContext(ctx).
+		Model(&entity.PurgeItem{}).
+		Where("purge_run_id = ?", runID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("id asc").
+		Limit(limit).
+		Offset(offset).
+		Find(&items).Error
+	return items, total, err
+}
@@ -0,0 +1,110 @@
+// DB interaction logic using GORM
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"japa/internal/domain/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TYPES
+
+// SLARepository to interface with DB
+type SLARepository struct {
+	DB *gorm.DB
+}
+
+// METHODS
+
+// Initialize SLARepository
+func NewSLARepository(db *gorm.DB) *SLARepository {
+	return &SLARepository{DB: db}
+}
+
+// Fetch every SLA target
+func (sr *SLARepository) FindTargets(ctx context.Context) ([]entity.SLATarget, error) {
+	var targets []entity.SLATarget
+	err := sr.DB.
+		WithContext(ctx).
+		Order("plan_id asc, status asc").
+		Find(&targets).Error
+	return targets, err
+}
+
+// Create or update the target for a plan (nil for the default) and status
+func (sr *SLARepository) SaveTarget(ctx context.Context, target *entity.SLATarget) error {
+	return sr.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("status = ?", target.Status)
+		if target.PlanID == nil {
+			query = query.Where("plan_id IS NULL")
+		} else {
+			query = query.Where("plan_id = ?", *target.PlanID)
+		}
+
+		var existing entity.SLATarget
+		err := query.First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			target.ID = existing.ID
+			target.CreatedAt = existing.CreatedAt
+		}
+
+		return tx.Save(target).Error
+	})
+}
+
+// Delete target by id
+func (sr *SLARepository) DeleteTarget(ctx context.Context, targetID uint) error {
+	result := sr.DB.WithContext(ctx).Delete(&entity.SLATarget{}, targetID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Plan of each user's current active subscription, users without one are left out
+func (sr *SLARepository) FindActivePlans(ctx context.Context, userIDs []string) (map[string]uint, error) {
+	plans := make(map[string]uint, len(userIDs))
+	if len(userIDs) == 0 {
+		return plans, nil
+	}
+
+	var subscriptions []entity.Subscription
+	if err := sr.DB.
+		WithContext(ctx).
+		Where("user_id IN ? AND status = ? AND expires_at > ?", userIDs, "active", time.Now()).
+		Order("expires_at asc").
+		Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+
+	// Ordered by expiry, so the longest running subscription wins
+	for _, subscription := range subscriptions {
+		plans[subscription.UserID] = subscription.PlanID
+	}
+	return plans, nil
+}
+
+// Record an escalation, returns false when it was already sent
+func (sr *SLARepository) ClaimEscalation(ctx context.Context, escalation *entity.SLAEscalation) (bool, error) {
+	result := sr.DB.
+		WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(escalation)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Release a claimed escalation so it is retried
+func (sr *SLARepository) ReleaseEscalation(ctx context.Context, escalation *entity.SLAEscalation) error {
+	return sr.DB.WithContext(ctx).Delete(escalation).Error
+}
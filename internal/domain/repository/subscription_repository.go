@@ -0,0 +1,215 @@
+// DB interaction logic using GORM
+package repository
+
+import (
+	"context"
+	"time"
+
+	"japa/internal/domain/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TYPES
+
+// SubscriptionRepository to interface with DB
+type SubscriptionRepository struct {
+	DB *gorm.DB
+}
+
+// METHODS
+
+// Initialize SubscriptionRepository
+func NewSubscriptionRepository(db *gorm.DB) *SubscriptionRepository {
+	return &SubscriptionRepository{DB: db}
+}
+
+// Create subscription
+func (sr *SubscriptionRepository) Create(ctx context.Context, tx *gorm.DB, subscription *entity.Subscription) error {
+	return tx.WithContext(ctx).Create(subscription).Error
+}
+
+// Find a plan with its price books
+func (sr *SubscriptionRepository) FindPlan(ctx context.Context, planID uint) (*entity.Plan, error) {
+	var plan entity.Plan
+	if err := sr.DB.
+		WithContext(ctx).
+		Preload("PlanPrices").
+		First(&plan, "id = ?", planID).Error; err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// The user's latest subscription that is neither pending nor over, with its plan
+func (sr *SubscriptionRepository) FindCurrent(ctx context.Context, tx *gorm.DB, userID string) (*entity.Subscription, error) {
+	var subscription entity.Subscription
+	if err := tx.
+		WithContext(ctx).
+		Preload("Plan.PlanPrices").
+		Preload("PendingPlan.PlanPrices").
+		Where("user_id = ? AND status IN ?", userID, []string{entity.SubscriptionStatusActive, entity.SubscriptionStatusPastDue}).
+		Order("started_at desc").
+		First(&subscription).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// The user's subscriptions still waiting on their first payment
+func (sr *SubscriptionRepository) FindPending(ctx context.Context, tx *gorm.DB, userID string) ([]entity.Subscription, error) {
+	var subscriptions []entity.Subscription
+	err := tx.
+		WithContext(ctx).
+		Where("user_id = ? AND status = ?", userID, entity.SubscriptionStatusPending).
+		Order("started_at desc").
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// Lock the subscriber's user row so subscriptions for one user start and activate one at a time
+func (sr *SubscriptionRepository) LockSubscriber(ctx context.Context, tx *gorm.DB, userID string) error {
+	var ids []string
+	return tx.
+		WithContext(ctx).
+		Model(&entity.User{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", userID).
+		Pluck("id", &ids).Error
+}
+
+// Whether the user ever started a trial, trials are once per user
+func (sr *SubscriptionRepository) HasTrialed(ctx context.Context, tx *gorm.DB, userID string) (bool, error) {
+	var count int64
+	err := tx.
+		WithContext(ctx).
+		Model(&entity.Subscription{}).
+		Where("user_id = ? AND trial_ends_at IS NOT NULL", userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Find a subscription with its plan and owner
+func (sr *SubscriptionRepository) FindByID(ctx context.Context, tx *gorm.DB, subscriptionID string) (*entity.Subscription, error) {
+	return sr.findWhere(ctx, tx, false, "id = ?", subscriptionID)
+}
+
+// Find and lock a subscription with its plan and owner
+func (sr *SubscriptionRepository) Lock(ctx context.Context, tx *gorm.DB, subscriptionID string) (*entity.Subscription, error) {
+	return sr.findWhere(ctx, tx, true, "id = ?", subscriptionID)
+}
+
+// Find and lock the pending subscription a checkout started
+func (sr *SubscriptionRepository) LockPendingByPayment(ctx context.Context, tx *gorm.DB, paymentID string) (*entity.Subscription, error) {
+	return sr.findWhere(ctx, tx, true, "payment_id = ? AND status = ?", paymentID, entity.SubscriptionStatusPending)
+}
+
+// Find and lock the subscription a checkout started, whatever its status
+func (sr *SubscriptionRepository) LockByPayment(ctx context.Context, tx *gorm.DB, paymentID string) (*entity.Subscription, error) {
+	return sr.findWhere(ctx, tx, true, "payment_id = ?", paymentID)
+}
+
+// Find and lock the subscription a renewal charge is for
+func (sr *SubscriptionRepository) LockByRenewalPayment(ctx context.Context, tx *gorm.DB, paymentID string) (*entity.Subscription, error) {
+	return sr.findWhere(ctx, tx, true, "renewal_payment_id = ?", paymentID)
+}
+
+// Find and lock the subscription an upgrade charge is for
+func (sr *SubscriptionRepository) LockByChangePayment(ctx context.Context, tx *gorm.DB, paymentID string) (*entity.Subscription, error) {
+	return sr.findWhere(ctx, tx, true, "change_payment_id = ?", paymentID)
+}
+
+func (sr *SubscriptionRepository) findWhere(ctx context.Context, tx *gorm.DB, lock bool, query string, args ...any) (*entity.Subscription, error) {
+	db := tx.WithContext(ctx)
+	if lock {
+		db = db.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+
+	var subscription entity.Subscription
+	if err := db.
+		Preload("Plan.PlanPrices").
+		Preload("PendingPlan.PlanPrices").
+		Preload("User", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "full_name", "email")
+		}).
+		Where(query, args...).
+		First(&subscription).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// Save the subscription's lifecycle fields
+func (sr *SubscriptionRepository) Update(ctx context.Context, tx *gorm.DB, subscription *entity.Subscription) error {
+	return tx.
+		WithContext(ctx).
+		Model(subscription).
+		Updates(map[string]any{
+			"plan_id":              subscription.PlanID,
+			"status":               subscription.Status,
+			"started_at":           subscription.StartedAt,
+			"expires_at":           subscription.ExpiresAt,
+			"period_started_at":    subscription.PeriodStartedAt,
+			"canceled_at":          subscription.CanceledAt,
+			"cancel_at_period_end": subscription.CancelAtPeriodEnd,
+			"authorization_id":     subscription.AuthorizationID,
+			"renewal_payment_id":   subscription.RenewalPaymentID,
+			"renewal_attempts":     subscription.RenewalAttempts,
+			"next_renewal_at":      subscription.NextRenewalAt,
+			"grace_ends_at":        subscription.GraceEndsAt,
+			"reminder_sent_at":     subscription.ReminderSentAt,
+			"pending_plan_id":      subscription.PendingPlanID,
+			"change_payment_id":    subscription.ChangePaymentID,
+			"credit_balance":       subscription.CreditBalance,
+			"coupon_code":          subscription.CouponCode,
+		}).Error
+}
+
+// Active subscriptions past their period that cancel instead of renewing
+func (sr *SubscriptionRepository) FindDueCancellations(ctx context.Context, now time.Time, limit int) ([]entity.Subscription, error) {
+	return sr.findIDs(ctx, limit,
+		"status = ? AND cancel_at_period_end = ? AND expires_at <= ?",
+		entity.SubscriptionStatusActive, true, now)
+}
+
+// Active subscriptions past their period with no renewal or upgrade charge in flight
+func (sr *SubscriptionRepository) FindDueRenewals(ctx context.Context, now time.Time, limit int) ([]entity.Subscription, error) {
+	return sr.findIDs(ctx, limit,
+		"status = ? AND cancel_at_period_end = ? AND expires_at <= ? AND renewal_payment_id IS NULL AND change_payment_id IS NULL",
+		entity.SubscriptionStatusActive, false, now)
+}
+
+// Past due subscriptions whose next renewal attempt is due
+func (sr *SubscriptionRepository) FindDueRetries(ctx context.Context, now time.Time, limit int) ([]entity.Subscription, error) {
+	return sr.findIDs(ctx, limit,
+		"status = ? AND next_renewal_at <= ? AND grace_ends_at > ? AND renewal_payment_id IS NULL",
+		entity.SubscriptionStatusPastDue, now, now)
+}
+
+// Past due subscriptions whose grace period is over
+func (sr *SubscriptionRepository) FindDueExpiries(ctx context.Context, now time.Time, limit int) ([]entity.Subscription, error) {
+	return sr.findIDs(ctx, limit,
+		"status = ? AND grace_ends_at <= ?",
+		entity.SubscriptionStatusPastDue, now)
+}
+
+// Renewing subscriptions whose period ends before until and that weren't reminded yet
+func (sr *SubscriptionRepository) FindDueReminders(ctx context.Context, now time.Time, until time.Time, limit int) ([]entity.Subscription, error) {
+	return sr.findIDs(ctx, limit,
+		"status = ? AND cancel_at_period_end = ? AND expires_at > ? AND expires_at <= ? AND reminder_sent_at IS NULL",
+		entity.SubscriptionStatusActive, false, now, until)
+}
+
+// Only IDs are loaded, each one is locked and re-checked before it changes
+func (sr *SubscriptionRepository) findIDs(ctx context.Context, limit int, query string, args ...any) ([]entity.Subscription, error) {
+	var subscriptions []entity.Subscription
+	err := sr.DB.
+		WithContext(ctx).
+		Select("id").
+		Where(query, args...).
+		Order("expires_at asc").
+		Limit(limit).
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
@@ -93,4 +93,14 @@ func (ur *UserRepository) SaveRefreshToken(ctx context.Context, refreshToken *en
 // Delete refresh token
 func (ur *UserRepository) DeleteRefreshToken(ctx context.Context, refreshToken string) error {
 	return ur.DB.WithContext(ctx).Where("token = ?", refreshToken).Delete(&entity.RefreshToken{}).Error
-}
\ No newline at end of file
+}
+
+// Find users with any of the roles, i.e admins for escalations
+func (ur *UserRepository) FindUsersByRoles(ctx context.Context, roles ...string) ([]entity.User, error) {
+	var users []entity.User
+	err := ur.DB.
+		WithContext(ctx).
+		Where("role IN ?", roles).
+		Find(&users).Error
+	return users, err
+}
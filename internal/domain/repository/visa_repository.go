@@ -2,6 +2,10 @@
 package repository
 
 import (
+	"context"
+	"time"
+
+	"japa/internal/app/http/dto/request"
 	"japa/internal/domain/entity"
 	
 	"gorm.io/gorm"
@@ -30,4 +34,238 @@ func NewVisaRepository(db *gorm.DB) *VisaRepository {
 // Create application
 func (vr *VisaRepository) Create(tx *gorm.DB, visa *entity.VisaApplication) error {
 	return tx.Create(visa).Error
-}
\ No newline at end of file
+}
+
+// Find application by id together with its applicant
+func (vr *VisaRepository) FindByID(ctx context.Context, tx *gorm.DB, applicationID string) (*entity.VisaApplication, error) {
+	var application entity.VisaApplication
+	if err := tx.
+		WithContext(ctx).
+		Preload("User").
+		Where("id = ?", applicationID).
+		First(&application).Error; err != nil {
+		return nil, err
+	}
+
+	return &application, nil
+}
+
+// Update application status, feedback and assigned agent
+func (vr *VisaRepository) UpdateStatus(ctx context.Context, tx *gorm.DB, application *entity.VisaApplication) error {
+	return tx.
+		WithContext(ctx).
+		Model(&entity.VisaApplication{}).
+		Where("id = ?", application.ID).
+		Updates(map[string]any{
+			"status":   application.Status,
+			"feedback": application.Feedback,
+			"agent_id": application.AgentID,
+		}).Error
+}
+
+// Record a status change on the application timeline
+func (vr *VisaRepository) CreateStatusHistory(ctx context.Context, tx *gorm.DB, history *entity.VisaStatusHistory) error {
+	return tx.WithContext(ctx).Create(history).Error
+}
+
+// Fetch the application timeline, oldest first
+func (vr *VisaRepository) FindStatusHistory(ctx context.Context, applicationID string) ([]entity.VisaStatusHistory, error) {
+	var history []entity.VisaStatusHistory
+	err := vr.DB.
+		WithContext(ctx).
+		Where("visa_application_id = ?", applicationID).
+		Order("created_at asc, id asc").
+		Find(&history).Error
+	return history, err
+}
+
+// Fetch the user's draft applications, most recently edited first
+func (vr *VisaRepository) FindDrafts(ctx context.Context, userID string) ([]entity.VisaApplication, error) {
+	var drafts []entity.VisaApplication
+	err := vr.DB.
+		WithContext(ctx).
+		Where("user_id = ? AND status = ?", userID, entity.VisaStatusDraft).
+		Order("updated_at desc").
+		Find(&drafts).Error
+	return drafts, err
+}
+
+// Save draft input only if nobody changed it since expectedVersion.
+// Returns the number of rows updated (0 means a version conflict).
+func (vr *VisaRepository) UpdateDraft(ctx context.Context, tx *gorm.DB, draft *entity.VisaApplication, expectedVersion uint) (int64, error) {
+	result := tx.
+		WithContext(ctx).
+		Model(&entity.VisaApplication{}).
+		Where("id = ? AND version = ? AND status = ?", draft.ID, expectedVersion, entity.VisaStatusDraft).
+		Updates(map[string]any{
+			"visa_form_input": draft.VisaFormInput,
+			"visa_form_url":   draft.VisaFormURL,
+			"version":         gorm.Expr("version + 1"),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// Replace the draft input with the validated form and move it out of draft
+func (vr *VisaRepository) SubmitDraft(ctx context.Context, tx *gorm.DB, draft *entity.VisaApplication, expectedVersion uint) (int64, error) {
+	result := tx.
+		WithContext(ctx).
+		Model(&entity.VisaApplication{}).
+		Where("id = ? AND version = ? AND status = ?", draft.ID, expectedVersion, entity.VisaStatusDraft).
+		Updates(map[string]any{
+			"visa_form_input": draft.VisaFormInput,
+			"status":          draft.Status,
+			"version":         gorm.Expr("version + 1"),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// Replace a submitted application's form, i.e with its group's new travel details
+func (vr *VisaRepository) UpdateFormInput(ctx context.Context, tx *gorm.DB, application *entity.VisaApplication) error {
+	return tx.
+		WithContext(ctx).
+		Model(&entity.VisaApplication{}).
+		Where("id = ?", application.ID).
+		Update("visa_form_input", application.VisaFormInput).Error
+}
+
+// Create application group
+func (vr *VisaRepository) CreateGroup(ctx context.Context, tx *gorm.DB, group *entity.VisaApplicationGroup) error {
+	return tx.WithContext(ctx).Create(group).Error
+}
+
+// Find group by id together with its members
+func (vr *VisaRepository) FindGroupByID(ctx context.Context, tx *gorm.DB, groupID string) (*entity.VisaApplicationGroup, error) {
+	var group entity.VisaApplicationGroup
+	if err := tx.
+		WithContext(ctx).
+		Preload("Members", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at asc")
+		}).
+		Where("id = ?", groupID).
+		First(&group).Error; err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// Save the group's shared travel details
+func (vr *VisaRepository) UpdateGroupTravel(ctx context.Context, tx *gorm.DB, group *entity.VisaApplicationGroup) error {
+	return tx.
+		WithContext(ctx).
+		Model(group).
+		Updates(map[string]any{
+			"destination":      group.Destination,
+			"visa_type":        group.VisaType,
+			"travel_date":      group.TravelDate,
+			"duration_of_stay": group.DurationOfStay,
+			"purpose":          group.Purpose,
+		}).Error
+}
+
+// Attach an existing application to a group
+func (vr *VisaRepository) AssignToGroup(ctx context.Context, tx *gorm.DB, applicationID string, groupID string, relationship string) error {
+	return tx.
+		WithContext(ctx).
+		Model(&entity.VisaApplication{}).
+		Where("id = ?", applicationID).
+		Updates(map[string]any{
+			"group_id":     groupID,
+			"relationship": relationship,
+		}).Error
+}
+
+// Create supporting document
+func (vr *VisaRepository) CreateDocument(ctx context.Context, tx *gorm.DB, document *entity.Document) error {
+	return tx.WithContext(ctx).Create(document).Error
+}
+
+// Fetch applications for the agent queue, oldest first.
+// Without a status filter only open (non-draft, undecided) applications are returned.
+func (vr *VisaRepository) FindQueue(ctx context.Context, filter request.VisaQueueRequest) ([]entity.VisaApplication, error) {
+	var applications []entity.VisaApplication
+	err := vr.queueQuery(ctx, filter).Find(&applications).Error
+	return applications, err
+}
+
+// Walk the agent queue in batches (exports), fn returning an error stops the walk
+func (vr *VisaRepository) FindQueueInBatches(ctx context.Context, filter request.VisaQueueRequest, batchSize int, fn func([]entity.VisaApplication) error) error {
+	var applications []entity.VisaApplication
+	return vr.queueQuery(ctx, filter).
+		FindInBatches(&applications, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(applications)
+		}).Error
+}
+
+// Queue filters shared by FindQueue and FindQueueInBatches
+func (vr *VisaRepository) queueQuery(ctx context.Context, filter request.VisaQueueRequest) *gorm.DB {
+	query := vr.DB.
+		WithContext(ctx).
+		Preload("User").
+		Preload("Agent")
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	} else {
+		query = query.Where("status NOT IN ?", []string{
+			entity.VisaStatusDraft,
+			entity.VisaStatusApproved,
+			entity.VisaStatusRejected,
+		})
+	}
+
+	switch filter.Assigned {
+	case "me":
+		query = query.Where("agent_id = ?", filter.AgentID)
+	case "unassigned":
+		query = query.Where("agent_id IS NULL")
+	}
+
+	if filter.Destination != "" {
+		query = query.Where("JSON_UNQUOTE(JSON_EXTRACT(visa_form_input, '$.Destination')) = ?", filter.Destination)
+	}
+
+	if filter.Flagged {
+		query = query.Where("EXISTS (SELECT 1 FROM fraud_signals WHERE fraud_signals.visa_application_id = visa_applications.id AND fraud_signals.resolution IS NULL)")
+	}
+
+	return query.Order("created_at asc, id asc")
+}
+
+// When each application entered its current status (its latest timeline entry)
+func (vr *VisaRepository) FindStatusEnteredAt(ctx context.Context, applicationIDs []string) (map[string]time.Time, error) {
+	enteredAt := make(map[string]time.Time, len(applicationIDs))
+	if len(applicationIDs) == 0 {
+		return enteredAt, nil
+	}
+
+	var rows []struct {
+		VisaApplicationID string
+		EnteredAt         time.Time
+	}
+	if err := vr.DB.
+		WithContext(ctx).
+		Model(&entity.VisaStatusHistory{}).
+		Select("visa_application_id, MAX(created_at) AS entered_at").
+		Where("visa_application_id IN ?", applicationIDs).
+		Group("visa_application_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		enteredAt[row.VisaApplicationID] = row.EnteredAt
+	}
+	return enteredAt, nil
+}
+
+// Set the referring partner on an application, group members follow the principal
+func (vr *VisaRepository) UpdateReferrer(ctx context.Context, tx *gorm.DB, application *entity.VisaApplication, referrerID *string) error {
+	query := tx.WithContext(ctx).Model(&entity.VisaApplication{})
+	if application.GroupID != nil && application.Relationship != nil && *application.Relationship == entity.GroupRelationPrincipal {
+		query = query.Where("id = ? OR group_id = ?", application.ID, *application.GroupID)
+	} else {
+		query = query.Where("id = ?", application.ID)
+	}
+	return query.Update("referrer_id", referrerID).Error
+}
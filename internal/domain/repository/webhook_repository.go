@@ -0,0 +1,188 @@
+// DB interaction logic using GORM
+package repository
+
+import (
+	"context"
+	"time"
+
+	"japa/internal/domain/entity"
+
+	"gorm.io/gorm"
+)
+
+// TYPES
+
+// WebhookRepository to interface with DB
+type WebhookRepository struct {
+	DB *gorm.DB
+}
+
+// METHODS
+
+// Initialize WebhookRepository
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{DB: db}
+}
+
+// Create endpoint
+func (wr *WebhookRepository) CreateEndpoint(ctx context.Context, endpoint *entity.WebhookEndpoint) error {
+	return wr.DB.WithContext(ctx).Create(endpoint).Error
+}
+
+// Update endpoint
+func (wr *WebhookRepository) UpdateEndpoint(ctx context.Context, endpoint *entity.WebhookEndpoint) error {
+	return wr.DB.WithContext(ctx).Save(endpoint).Error
+}
+
+// Find endpoint by id
+func (wr *WebhookRepository) FindEndpoint(ctx context.Context, endpointID string) (*entity.WebhookEndpoint, error) {
+	var endpoint entity.WebhookEndpoint
+	if err := wr.DB.
+		WithContext(ctx).
+		Where("id = ?", endpointID).
+		First(&endpoint).Error; err != nil {
+		return nil, err
+	}
+
+	return &endpoint, nil
+}
+
+// Fetch endpoints of an owner, an empty ownerID fetches every endpoint
+func (wr *WebhookRepository) FindEndpoints(ctx context.Context, ownerID string) ([]entity.WebhookEndpoint, error) {
+	query := wr.DB.WithContext(ctx)
+	if ownerID != "" {
+		query = query.Where("owner_id = ?", ownerID)
+	}
+
+	var endpoints []entity.WebhookEndpoint
+	err := query.Order("created_at asc").Find(&endpoints).Error
+	return endpoints, err
+}
+
+// Delete an endpoint together with its delivery log
+func (wr *WebhookRepository) DeleteEndpoint(ctx context.Context, endpointID string) error {
+	return wr.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		deliveries := tx.Model(&entity.WebhookDelivery{}).Select("id").Where("endpoint_id = ?", endpointID)
+		if err := tx.Where("delivery_id IN (?)", deliveries).Delete(&entity.WebhookAttempt{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("endpoint_id = ?", endpointID).Delete(&entity.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", endpointID).Delete(&entity.WebhookEndpoint{}).Error
+	})
+}
+
+// Active endpoints that may receive an event: platform endpoints and the given partners'.
+// Event type filtering is left to the caller.
+func (wr *WebhookRepository) FindActiveEndpoints(ctx context.Context, tx *gorm.DB, partnerIDs []string) ([]entity.WebhookEndpoint, error) {
+	query := tx.WithContext(ctx).Where("active = ?", true)
+	if len(partnerIDs) > 0 {
+		query = query.Where("owner_id IS NULL OR owner_id IN ?", partnerIDs)
+	} else {
+		query = query.Where("owner_id IS NULL")
+	}
+
+	var endpoints []entity.WebhookEndpoint
+	err := query.Find(&endpoints).Error
+	return endpoints, err
+}
+
+// Queue deliveries
+func (wr *WebhookRepository) CreateDeliveries(ctx context.Context, tx *gorm.DB, deliveries []entity.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return tx.WithContext(ctx).Create(&deliveries).Error
+}
+
+// Pending deliveries whose next attempt is due, oldest first
+func (wr *WebhookRepository) FindDueDeliveries(ctx context.Context, now time.Time, limit int) ([]entity.WebhookDelivery, error) {
+	var deliveries []entity.WebhookDelivery
+	err := wr.DB.
+		WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", entity.WebhookDeliveryPending, now).
+		Order("next_attempt_at asc").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// Lease a due delivery by pushing its next attempt forward.
+// Returns false when another worker got it first.
+func (wr *WebhookRepository) ClaimDelivery(ctx context.Context, delivery *entity.WebhookDelivery, leaseUntil time.Time) (bool, error) {
+	result := wr.DB.
+		WithContext(ctx).
+		Model(&entity.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at = ?", delivery.ID, entity.WebhookDeliveryPending, delivery.NextAttemptAt).
+		Update("next_attempt_at", leaseUntil)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Save the outcome of an attempt and log it
+func (wr *WebhookRepository) SaveAttempt(ctx context.Context, delivery *entity.WebhookDelivery, attempt *entity.WebhookAttempt) error {
+	return wr.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Model(&entity.WebhookDelivery{}).
+			Where("id = ?", delivery.ID).
+			Updates(map[string]any{
+				"status":           delivery.Status,
+				"attempts":         delivery.Attempts,
+				"next_attempt_at":  delivery.NextAttemptAt,
+				"last_status_code": delivery.LastStatusCode,
+				"last_error":       delivery.LastError,
+				"delivered_at":     delivery.DeliveredAt,
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Create(attempt).Error
+	})
+}
+
+// Find delivery by id
+func (wr *WebhookRepository) FindDelivery(ctx context.Context, deliveryID string) (*entity.WebhookDelivery, error) {
+	var delivery entity.WebhookDelivery
+	if err := wr.DB.
+		WithContext(ctx).
+		Where("id = ?", deliveryID).
+		First(&delivery).Error; err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// Fetch an endpoint's delivery log, newest first. An empty status matches every delivery.
+func (wr *WebhookRepository) FindDeliveries(ctx context.Context, endpointID string, status string, limit, offset int) ([]entity.WebhookDelivery, int64, error) {
+	query := wr.DB.
+		WithContext(ctx).
+		Model(&entity.WebhookDelivery{}).
+		Where("endpoint_id = ?", endpointID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []entity.WebhookDelivery
+	err := query.
+		Order("created_at desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+// Fetch a delivery's attempts, oldest first
+func (wr *WebhookRepository) FindAttempts(ctx context.Context, deliveryID string) ([]entity.WebhookAttempt, error) {
+	var attempts []entity.WebhookAttempt
+	err := wr.DB.
+		WithContext(ctx).
+		Where("delivery_id = ?", deliveryID).
+		Order("id asc").
+		Find(&attempts).Error
+	return attempts, err
+}
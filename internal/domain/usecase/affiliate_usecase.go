@@ -0,0 +1,430 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"japa/internal/app/http/dto/request"
+	"japa/internal/config"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/repository"
+	"japa/internal/infrastructure/payment"
+
+	"github.com/oklog/ulid/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ERRORS
+
+var (
+	ErrReferralCodeTaken = errors.New("referral code is already taken")
+)
+
+// TYPES
+
+// AffiliateUsecase attributes referred users to partners and keeps the commission ledger
+type AffiliateUsecase struct {
+	Config     config.AffiliateConfig
+	SiteConfig config.SiteConfig
+	Repo       *repository.AffiliateRepository
+	Ledger     *LedgerUsecase
+	DB         *gorm.DB
+}
+
+// PartnerStats is a partner's referral funnel and earnings
+type PartnerStats struct {
+	Clicks      int64
+	Signups     int64
+	Conversions int64 // Referred users the partner earned on
+	Codes       []entity.ReferralCode
+	CodeSignups map[uint]int64 // By referral code ID
+	Earnings    []repository.CommissionTotals
+}
+
+// METHODS
+
+// Initialize AffiliateUsecase
+func NewAffiliateUsecase(cfg config.AffiliateConfig, siteConfig config.SiteConfig, repo *repository.AffiliateRepository, ledger *LedgerUsecase, db *gorm.DB) *AffiliateUsecase {
+	return &AffiliateUsecase{Config: cfg, SiteConfig: siteConfig, Repo: repo, Ledger: ledger, DB: db}
+}
+
+// Where a referral link lands, the signup page with the code filled in
+func (usecase *AffiliateUsecase) LandingURL(code string) string {
+	return usecase.SiteConfig.SiteDomain + "/register?ref=" + url.QueryEscape(code)
+}
+
+// Fetch the partner's referral codes with the signups each brought
+func (usecase *AffiliateUsecase) FetchCodes(ctx context.Context, partnerID string) ([]entity.ReferralCode, map[uint]int64, error) {
+	codes, err := usecase.Repo.FindCodes(ctx, partnerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	signups, err := usecase.Repo.CountSignups(ctx, partnerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return codes, signups, nil
+}
+
+// Create a referral code for the partner, generated when the request has none
+func (usecase *AffiliateUsecase) CreateCode(ctx context.Context, req request.CreateReferralCodeRequest) (*entity.ReferralCode, error) {
+	code := normalizeCoupon(req.Code)
+	for attempt := 0; code == "" && attempt < 3; attempt++ {
+		generated, err := generateReferralCode()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := usecase.Repo.FindCodeByCode(ctx, usecase.DB, generated); errors.Is(err, gorm.ErrRecordNotFound) {
+			code = generated
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	if code == "" {
+		return nil, ErrReferralCodeTaken
+	}
+
+	if _, err := usecase.Repo.FindCodeByCode(ctx, usecase.DB, code); err == nil {
+		return nil, ErrReferralCodeTaken
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	referralCode := &entity.ReferralCode{
+		PartnerID: req.PartnerID,
+		Code:      code,
+		Label:     req.Label,
+		Active:    true,
+	}
+	if err := usecase.Repo.CreateCode(ctx, referralCode); err != nil {
+		return nil, err
+	}
+	return referralCode, nil
+}
+
+// Relabel, pause or resume one of the partner's codes
+func (usecase *AffiliateUsecase) UpdateCode(ctx context.Context, req request.UpdateReferralCodeRequest) (*entity.ReferralCode, error) {
+	referralCode, err := usecase.Repo.FindPartnerCode(ctx, req.PartnerID, req.CodeID)
+	if err != nil {
+		return nil, err
+	}
+	referralCode.Label = req.Label
+	referralCode.Active = *req.Active
+	if err := usecase.Repo.UpdateCode(ctx, referralCode); err != nil {
+		return nil, err
+	}
+	return referralCode, nil
+}
+
+// TrackClick counts a referral link visit, returning the active code it was for
+func (usecase *AffiliateUsecase) TrackClick(ctx context.Context, code string) (*entity.ReferralCode, error) {
+	referralCode, err := usecase.Repo.FindCodeByCode(ctx, usecase.DB, normalizeCoupon(code))
+	if err != nil {
+		return nil, err
+	}
+	if !referralCode.Active {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if err := usecase.Repo.IncrementClicks(ctx, referralCode.ID); err != nil {
+		return nil, err
+	}
+	return referralCode, nil
+}
+
+// Attribute sets the partner behind code on a user being registered.
+// An unknown or paused code never fails the registration, the user is just not attributed.
+func (usecase *AffiliateUsecase) Attribute(ctx context.Context, tx *gorm.DB, user *entity.User, code string) error {
+	if strings.TrimSpace(code) == "" {
+		return nil
+	}
+	referralCode, err := usecase.Repo.FindCodeByCode(ctx, tx, normalizeCoupon(code))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		zap.L().Debug("Unknown referral code at registration", zap.String("code", code))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !referralCode.Active || referralCode.PartnerID == user.ID {
+		return nil
+	}
+
+	now := time.Now()
+	user.ReferrerID = &referralCode.PartnerID
+	user.ReferralCodeID = &referralCode.ID
+	user.ReferredAt = &now
+	return nil
+}
+
+// Fetch all commission rules
+func (usecase *AffiliateUsecase) FetchRules(ctx context.Context) ([]entity.CommissionRule, error) {
+	return usecase.Repo.FindRules(ctx)
+}
+
+// Create or update the commission rule for a product
+func (usecase *AffiliateUsecase) SaveRule(ctx context.Context, req request.SaveCommissionRuleRequest) (*entity.CommissionRule, error) {
+	rule := &entity.CommissionRule{
+		Product:   req.Product,
+		Type:      req.Type,
+		Recurring: req.Recurring,
+		Active:    *req.Active,
+	}
+	if req.Product == entity.CommissionProductPlan {
+		rule.PlanCode = strings.ToLower(strings.TrimSpace(req.PlanCode))
+	}
+	if req.Type == entity.CouponTypePercentage {
+		rule.Percent = req.Percent
+	} else {
+		rule.Amount = req.Amount
+		rule.Currency = &req.Currency
+	}
+	if err := usecase.Repo.SaveRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// Delete a commission rule
+func (usecase *AffiliateUsecase) DeleteRule(ctx context.Context, ruleID uint) error {
+	return usecase.Repo.DeleteRule(ctx, ruleID)
+}
+
+// HandleSettlement adds the partners' commissions on a successful payment, registered for every purpose.
+// Plan payments earn for the user's referrer within the attribution window, application checkouts
+// for each application's referrer.
+func (usecase *AffiliateUsecase) HandleSettlement(ctx context.Context, tx *gorm.DB, record *entity.Payment, verification *payment.Verification) (func(), error) {
+	if record.Status != entity.PaymentStatusSucceeded {
+		return nil, nil
+	}
+
+	var commissions []entity.Commission
+	var err error
+	switch record.Purpose {
+	case entity.PaymentPurposeSubscription:
+		commissions, err = usecase.planCommissions(ctx, tx, record)
+	case entity.PaymentPurposeApplication:
+		commissions, err = usecase.applicationCommissions(ctx, tx, record)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, usecase.addCommissions(ctx, tx, commissions)
+}
+
+// Reverse takes back the payment's commissions in proportion to refunded, in the refund's transaction.
+// A full refund reverses whatever is left. Returns the total reversed, minor units.
+func (usecase *AffiliateUsecase) Reverse(ctx context.Context, tx *gorm.DB, record *entity.Payment, refunded int64) (int64, error) {
+	if refunded <= 0 || record.Amount <= 0 {
+		return 0, nil
+	}
+	earnings, reversed, err := usecase.Repo.LockEarnings(ctx, tx, record.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	var reversals []entity.Commission
+	for _, earning := range earnings {
+		amount := ReversalAmount(earning.Amount, reversed[earning.ID], refunded, record.Amount)
+		if amount <= 0 {
+			continue
+		}
+
+		reversals = append(reversals, entity.Commission{
+			ID:             ulid.Make().String(),
+			PartnerID:      earning.PartnerID,
+			ReferredUserID: earning.ReferredUserID,
+			PaymentID:      earning.PaymentID,
+			RuleID:         earning.RuleID,
+			ReversalOf:     &earning.ID,
+			Kind:           entity.CommissionKindReversal,
+			Product:        earning.Product,
+			Base:           refunded,
+			Amount:         -amount,
+			Currency:       earning.Currency,
+			AvailableAt:    earning.AvailableAt, // Comes off the same balance the earning went to
+		})
+		total += amount
+	}
+	return total, usecase.addCommissions(ctx, tx, reversals)
+}
+
+// Adds entries to the commission ledger and posts them as owed to partners, in the same transaction
+func (usecase *AffiliateUsecase) addCommissions(ctx context.Context, tx *gorm.DB, commissions []entity.Commission) error {
+	if err := usecase.Repo.CreateCommissions(ctx, tx, commissions); err != nil {
+		return err
+	}
+	return usecase.Ledger.PostCommissions(ctx, tx, commissions)
+}
+
+// HandleRefund is the refund handler that takes commissions back when any payment is refunded.
+// The refund that completes a payment reverses whatever is left, so rounding never leaves a remainder.
+func (usecase *AffiliateUsecase) HandleRefund(ctx context.Context, tx *gorm.DB, record *entity.Payment, refund *entity.Refund) (func(), error) {
+	refunded := refund.Amount
+	if record.RefundStatus == entity.PaymentRefundFull {
+		refunded = record.Amount
+	}
+	_, err := usecase.Reverse(ctx, tx, record, refunded)
+	return nil, err
+}
+
+// Fetch the partner's clicks, signups, conversions and earnings
+func (usecase *AffiliateUsecase) FetchStats(ctx context.Context, partnerID string) (*PartnerStats, error) {
+	codes, signups, err := usecase.FetchCodes(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+	conversions, err := usecase.Repo.CountConversions(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+	earnings, err := usecase.Repo.SumCommissions(ctx, partnerID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &PartnerStats{
+		Conversions: conversions,
+		Codes:       codes,
+		CodeSignups: signups,
+		Earnings:    earnings,
+	}
+	for _, code := range codes {
+		stats.Clicks += code.Clicks
+	}
+	for _, count := range signups {
+		stats.Signups += count
+	}
+	return stats, nil
+}
+
+// Fetch the partner's commission ledger
+func (usecase *AffiliateUsecase) FetchCommissions(ctx context.Context, partnerID string, page int, limit int) ([]entity.Commission, int64, error) {
+	return usecase.Repo.FindCommissions(ctx, partnerID, limit, (page-1)*limit)
+}
+
+// Commission on a subscription payment for the user's referrer
+func (usecase *AffiliateUsecase) planCommissions(ctx context.Context, tx *gorm.DB, record *entity.Payment) ([]entity.Commission, error) {
+	var user entity.User
+	if err := tx.WithContext(ctx).Select("id", "referrer_id", "referred_at").First(&user, "id = ?", record.UserID).Error; err != nil {
+		return nil, err
+	}
+	if user.ReferrerID == nil || user.ReferredAt == nil || record.PaidAt.After(user.ReferredAt.Add(usecase.Config.AttributionWindow)) {
+		return nil, nil
+	}
+
+	var metadata struct {
+		PlanID uint `json:"plan_id"`
+	}
+	if len(record.Metadata) > 0 {
+		if err := json.Unmarshal(record.Metadata, &metadata); err != nil {
+			return nil, err
+		}
+	}
+	var plan entity.Plan
+	if err := tx.WithContext(ctx).Select("id", "code").First(&plan, "id = ?", metadata.PlanID).Error; err != nil {
+		return nil, err
+	}
+
+	rule, err := usecase.Repo.FindRule(ctx, tx, entity.CommissionProductPlan, plan.Code)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !rule.Recurring {
+		earned, err := usecase.Repo.HasEarned(ctx, tx, *user.ReferrerID, user.ID, entity.CommissionProductPlan)
+		if err != nil || earned {
+			return nil, err
+		}
+	}
+
+	amount := CommissionAmount(rule, record.Amount, 1, record.Currency)
+	if amount == 0 {
+		return nil, nil
+	}
+	return []entity.Commission{usecase.earning(record, rule, *user.ReferrerID, entity.CommissionProductPlan, record.Amount, amount)}, nil
+}
+
+// Commissions on an application checkout for each referred application's partner
+func (usecase *AffiliateUsecase) applicationCommissions(ctx context.Context, tx *gorm.DB, record *entity.Payment) ([]entity.Commission, error) {
+	referred, err := usecase.Repo.SumReferredPurchases(ctx, tx, record.ID)
+	if err != nil || len(referred) == 0 {
+		return nil, err
+	}
+
+	rule, err := usecase.Repo.FindRule(ctx, tx, entity.CommissionProductApplication, "")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var commissions []entity.Commission
+	for _, purchases := range referred {
+		if purchases.ReferrerID == record.UserID {
+			continue // Partners don't earn on their own payments
+		}
+		if amount := CommissionAmount(rule, purchases.Amount, purchases.Count, record.Currency); amount > 0 {
+			commissions = append(commissions, usecase.earning(record, rule, purchases.ReferrerID, entity.CommissionProductApplication, purchases.Amount, amount))
+		}
+	}
+	return commissions, nil
+}
+
+func (usecase *AffiliateUsecase) earning(record *entity.Payment, rule *entity.CommissionRule, partnerID string, product string, base int64, amount int64) entity.Commission {
+	return entity.Commission{
+		ID:             ulid.Make().String(),
+		PartnerID:      partnerID,
+		ReferredUserID: record.UserID,
+		PaymentID:      record.ID,
+		RuleID:         &rule.ID,
+		Kind:           entity.CommissionKindEarning,
+		Product:        product,
+		Base:           base,
+		Amount:         amount,
+		Currency:       record.Currency,
+		AvailableAt:    record.PaidAt.Add(usecase.Config.HoldPeriod),
+	}
+}
+
+// CommissionAmount is what rule pays on base, minor units. Fixed rules pay per item,
+// only in their own currency, and never more than base.
+func CommissionAmount(rule *entity.CommissionRule, base int64, items int64, currency string) int64 {
+	if rule.Type == entity.CouponTypePercentage {
+		return int64(math.Round(float64(base) * float64(rule.Percent) / 100))
+	}
+	if rule.Currency == nil || !strings.EqualFold(*rule.Currency, currency) {
+		return 0
+	}
+	return min(rule.Amount*items, base)
+}
+
+// ReversalAmount is how much of an earning a refund of refunded out of paid takes back,
+// never more than what's left after the reversals so far. A full refund takes it all.
+func ReversalAmount(earned int64, reversed int64, refunded int64, paid int64) int64 {
+	remaining := earned - reversed
+	if refunded >= paid {
+		return remaining
+	}
+	return min(int64(math.Round(float64(earned)*float64(refunded)/float64(paid))), remaining)
+}
+
+// Eight upper case letters and digits
+func generateReferralCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,402 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"japa/internal/app/http/dto/request"
+	"japa/internal/config"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/repository"
+	"japa/internal/infrastructure/mail"
+	"japa/internal/pkg"
+
+	"github.com/oklog/ulid/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// TYPES
+
+// AppointmentUsecase handles appointment booking and reminders
+type AppointmentUsecase struct {
+	Config     config.AppointmentConfig
+	SiteConfig config.SiteConfig
+	Repo       *repository.AppointmentRepository
+	VisaRepo   *repository.VisaRepository
+	DB         *gorm.DB
+	Mailer     *mailer.ResponsiveMailer
+}
+
+// METHODS
+
+// Initialize AppointmentUsecase
+func NewAppointmentUsecase(
+	cfg        config.AppointmentConfig,
+	siteConfig config.SiteConfig,
+	repo       *repository.AppointmentRepository,
+	visaRepo   *repository.VisaRepository,
+	db         *gorm.DB,
+	mailer     *mailer.ResponsiveMailer,
+) *AppointmentUsecase {
+	return &AppointmentUsecase{
+		Config:     cfg,
+		SiteConfig: siteConfig,
+		Repo:       repo,
+		VisaRepo:   visaRepo,
+		DB:         db,
+		Mailer:     mailer,
+	}
+}
+
+// Books an appointment for an application and emails the invite
+func (usecase *AppointmentUsecase) CreateAppointment(ctx context.Context, req request.CreateAppointmentRequest) (*entity.Appointment, error) {
+	application, err := usecase.VisaRepo.FindByID(ctx, usecase.DB, req.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduledAt, err := parseLocalTime(req.ScheduledAt, req.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	appointment := &entity.Appointment{
+		ID:                ulid.Make().String(),
+		VisaApplicationID: application.ID,
+		Type:              req.Type,
+		Location:          req.Location,
+		Address:           req.Address,
+		ScheduledAt:       scheduledAt,
+		Timezone:          req.Timezone,
+		DurationMinutes:   req.DurationMinutes,
+		Status:            entity.AppointmentStatusScheduled,
+		Notes:             req.Notes,
+		CreatedByID:       req.AgentID,
+	}
+	if appointment.DurationMinutes == 0 {
+		appointment.DurationMinutes = 30
+	}
+
+	if err := usecase.Repo.Create(ctx, usecase.DB, appointment); err != nil {
+		return nil, err
+	}
+
+	appointment.VisaApplication = *application
+	usecase.sendAppointmentMail(appointment, "Appointment Scheduled", "An appointment has been booked for your visa application.")
+
+	return appointment, nil
+}
+
+
+// Updates/reschedules an appointment, a new time re-arms the reminders
+func (usecase *AppointmentUsecase) UpdateAppointment(ctx context.Context, req request.UpdateAppointmentRequest) (*entity.Appointment, error) {
+	var appointment *entity.Appointment
+	var rescheduled bool
+
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		appointment, err = usecase.Repo.FindByID(ctx, tx, req.AppointmentID)
+		if err != nil {
+			return err
+		}
+		if appointment.Status == entity.AppointmentStatusCancelled {
+			return ErrInvalidStatusTransition
+		}
+
+		if req.Type != nil {
+			appointment.Type = *req.Type
+		}
+		if req.Location != nil {
+			appointment.Location = *req.Location
+		}
+		if req.Address != nil {
+			appointment.Address = req.Address
+		}
+		if req.DurationMinutes != nil {
+			appointment.DurationMinutes = *req.DurationMinutes
+		}
+		if req.Notes != nil {
+			appointment.Notes = req.Notes
+		}
+		if req.Status != nil {
+			appointment.Status = *req.Status
+		}
+
+		// New time and/or timezone
+		if req.ScheduledAt != nil || req.Timezone != nil {
+			timezone := appointment.Timezone
+			if req.Timezone != nil {
+				timezone = *req.Timezone
+			}
+			localTime := appointment.LocalTime().Format("2006-01-02T15:04")
+			if req.ScheduledAt != nil {
+				localTime = *req.ScheduledAt
+			}
+
+			scheduledAt, err := parseLocalTime(localTime, timezone)
+			if err != nil {
+				return err
+			}
+
+			if !scheduledAt.Equal(appointment.ScheduledAt) {
+				rescheduled = true
+				if err := usecase.Repo.DeleteReminders(ctx, tx, appointment.ID); err != nil {
+					return err
+				}
+			}
+			appointment.ScheduledAt = scheduledAt
+			appointment.Timezone = timezone
+		}
+
+		appointment.Sequence++
+		return usecase.Repo.Update(ctx, tx, appointment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if rescheduled {
+		usecase.sendAppointmentMail(appointment, "Appointment Rescheduled", "Your appointment has been moved to a new time.")
+	}
+
+	return appointment, nil
+}
+
+
+// Cancels an appointment and sends the calendar cancellation
+func (usecase *AppointmentUsecase) CancelAppointment(ctx context.Context, appointmentID string) error {
+	appointment, err := usecase.Repo.FindByID(ctx, usecase.DB, appointmentID)
+	if err != nil {
+		return err
+	}
+	if appointment.Status == entity.AppointmentStatusCancelled {
+		return nil
+	}
+
+	appointment.Status = entity.AppointmentStatusCancelled
+	appointment.Sequence++
+	if err := usecase.Repo.Update(ctx, usecase.DB, appointment); err != nil {
+		return err
+	}
+
+	usecase.sendAppointmentMail(appointment, "Appointment Cancelled", "Your appointment has been cancelled. Your agent will contact you about next steps.")
+	return nil
+}
+
+
+// Lists an application's appointments.
+// An empty userID skips the ownership check (agents).
+func (usecase *AppointmentUsecase) FetchForApplication(ctx context.Context, userID string, applicationID string) ([]entity.Appointment, error) {
+	if userID != "" {
+		application, err := usecase.VisaRepo.FindByID(ctx, usecase.DB, applicationID)
+		if err != nil {
+			return nil, err
+		}
+		if application.UserID != userID {
+			return nil, gorm.ErrRecordNotFound
+		}
+	}
+
+	return usecase.Repo.FindByApplication(ctx, applicationID)
+}
+
+
+// Builds the .ics file for an appointment the user owns
+func (usecase *AppointmentUsecase) FetchICS(ctx context.Context, userID string, appointmentID string) ([]byte, error) {
+	appointment, err := usecase.Repo.FindByID(ctx, usecase.DB, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	if appointment.VisaApplication.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return usecase.buildICS(appointment), nil
+}
+
+
+// SendDueReminders emails reminders for appointments within the configured offsets.
+// Runs as a scheduled job; each (appointment, offset) is claimed before sending so it goes out once.
+func (usecase *AppointmentUsecase) SendDueReminders(ctx context.Context) error {
+	if len(usecase.Config.ReminderOffsets) == 0 {
+		return nil
+	}
+
+	var maxOffset time.Duration
+	for _, offset := range usecase.Config.ReminderOffsets {
+		if offset > maxOffset {
+			maxOffset = offset
+		}
+	}
+
+	now := time.Now().UTC()
+	appointments, err := usecase.Repo.FindUpcoming(ctx, now, now.Add(maxOffset))
+	if err != nil {
+		return err
+	}
+
+	for i := range appointments {
+		appointment := &appointments[i]
+
+		sent, err := usecase.Repo.FindSentOffsets(ctx, appointment.ID)
+		if err != nil {
+			return err
+		}
+
+		// Every due offset is marked, but only the closest one is emailed:
+		// an appointment booked 1 day ahead shouldn't get the 3-day reminder too
+		var due []int
+		closest := -1
+		for _, offset := range usecase.Config.ReminderOffsets {
+			minutes := int(offset.Minutes())
+			if sent[minutes] || now.Before(appointment.ScheduledAt.Add(-offset)) {
+				continue
+			}
+			due = append(due, minutes)
+			if closest == -1 || minutes < closest {
+				closest = minutes
+			}
+		}
+		if len(due) == 0 {
+			continue
+		}
+
+		var claimed []*entity.AppointmentReminder
+		for _, minutes := range due {
+			reminder := &entity.AppointmentReminder{AppointmentID: appointment.ID, OffsetMinutes: minutes, SentAt: now}
+			ok, err := usecase.Repo.ClaimReminder(ctx, reminder)
+			if err != nil {
+				return err
+			}
+			if ok {
+				claimed = append(claimed, reminder)
+			}
+		}
+		if len(claimed) == 0 {
+			continue // Another instance got there first
+		}
+
+		emailData := usecase.appointmentEmail(appointment, "Appointment Reminder", fmt.Sprintf(
+			"This is a reminder of your upcoming appointment in %s.", humanizeDuration(appointment.ScheduledAt.Sub(now)),
+		))
+		if err := usecase.Mailer.Send(appointment.VisaApplication.User.Email, emailData); err != nil {
+			zap.L().Error("Failed to send appointment reminder", zap.String("appointmentID", appointment.ID), zap.Error(err))
+
+			// Release the claims so the next run retries
+			for _, reminder := range claimed {
+				if err := usecase.Repo.ReleaseReminder(ctx, reminder); err != nil {
+					zap.L().Error("Failed to release appointment reminder", zap.String("appointmentID", appointment.ID), zap.Error(err))
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+
+// Sends an appointment email with the calendar invite in the background
+func (usecase *AppointmentUsecase) sendAppointmentMail(appointment *entity.Appointment, subject string, message string) {
+	if usecase.Mailer == nil || appointment.VisaApplication.User.Email == "" {
+		return
+	}
+
+	emailData := usecase.appointmentEmail(appointment, subject, message)
+	email := appointment.VisaApplication.User.Email
+
+	go func() {
+		defer func() {
+			if panicErr := recover(); panicErr != nil {
+				zap.L().Error("Panic recovered while sending appointment email", zap.Any("error", panicErr))
+			}
+		}()
+
+		if err := usecase.Mailer.Send(email, emailData); err != nil {
+			zap.L().Error("Failed to send appointment email", zap.String("appointmentID", appointment.ID), zap.Error(err))
+		}
+	}()
+}
+
+func (usecase *AppointmentUsecase) appointmentEmail(appointment *entity.Appointment, subject string, message string) *mailer.EmailData {
+	local := appointment.LocalTime()
+	details := []string{
+		"Type: " + readableStatus(appointment.Type),
+		"When: " + local.Format("Monday, 02 January 2006 at 15:04") + " (" + appointment.Timezone + ")",
+		"Where: " + appointment.Location,
+	}
+	if appointment.Address != nil {
+		details = append(details, "Address: "+*appointment.Address)
+	}
+	if appointment.Notes != nil {
+		details = append(details, "Notes: "+*appointment.Notes)
+	}
+
+	emailData := mailer.AppointmentMail(appointment.VisaApplication.User.FullName, appointment.VisaApplicationID, subject, message, details)
+	emailData.LinkURL = fmt.Sprintf("%s/account/visa/applications/%s", strings.TrimRight(usecase.SiteConfig.SiteDomain, "/"), appointment.VisaApplicationID)
+	emailData.LinkText = "View application"
+	emailData.Attachments = []mailer.Attachment{{
+		Filename:    "appointment.ics",
+		ContentType: "text/calendar; charset=UTF-8",
+		Data:        usecase.buildICS(appointment),
+	}}
+
+	return emailData
+}
+
+func (usecase *AppointmentUsecase) buildICS(appointment *entity.Appointment) []byte {
+	location := appointment.Location
+	if appointment.Address != nil {
+		location += ", " + *appointment.Address
+	}
+
+	description := fmt.Sprintf("Visa application %s", appointment.VisaApplicationID)
+	if appointment.Notes != nil {
+		description += "\n" + *appointment.Notes
+	}
+
+	return pkg.BuildICS(usecase.SiteConfig.SiteName, pkg.ICSEvent{
+		UID:         appointment.ID + "@" + usecase.SiteConfig.SiteDomain,
+		Sequence:    appointment.Sequence,
+		Summary:     readableStatus(appointment.Type) + " appointment",
+		Description: description,
+		Location:    location,
+		Start:       appointment.ScheduledAt,
+		End:         appointment.ScheduledAt.Add(time.Duration(appointment.DurationMinutes) * time.Minute),
+		Cancelled:   appointment.Status == entity.AppointmentStatusCancelled,
+		Organizer:   usecase.SiteConfig.SiteEmail,
+	})
+}
+
+// Parses "2006-01-02T15:04" in the given IANA timezone and returns UTC
+func parseLocalTime(value string, timezone string) (time.Time, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	localTime, err := time.ParseInLocation("2006-01-02T15:04", value, location)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return localTime.UTC(), nil
+}
+
+// i.e 26h => "1 day", 3h => "3 hours"
+func humanizeDuration(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%d days", int(d.Hours()/24))
+	case d >= 24*time.Hour:
+		return "1 day"
+	case d >= 2*time.Hour:
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	case d >= time.Hour:
+		return "1 hour"
+	default:
+		return fmt.Sprintf("%d minutes", int(d.Minutes()))
+	}
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+
+	"japa/internal/app/http/dto/request"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/repository"
+
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// TYPES
+
+// AuditUsecase records sensitive actions and manages explicit permissions
+type AuditUsecase struct {
+	Repo *repository.AuditRepository
+	DB   *gorm.DB
+}
+
+// AuditEntry describes an action to record
+type AuditEntry struct {
+	ActorID      string
+	Action       string
+	ResourceType string
+	ResourceID   *string
+	Metadata     map[string]any
+	IPAddress    string
+}
+
+// METHODS
+
+// Initialize AuditUsecase
+func NewAuditUsecase(repo *repository.AuditRepository, db *gorm.DB) *AuditUsecase {
+	return &AuditUsecase{Repo: repo, DB: db}
+}
+
+// Record writes an audit log entry, pass the transaction the action runs in (or usecase.DB)
+func (usecase *AuditUsecase) Record(ctx context.Context, tx *gorm.DB, entry AuditEntry) (*entity.AuditLog, error) {
+	metadata, err := json.Marshal(entry.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	log := &entity.AuditLog{
+		ID:           ulid.Make().String(),
+		ActorID:      entry.ActorID,
+		Action:       entry.Action,
+		ResourceType: entry.ResourceType,
+		ResourceID:   entry.ResourceID,
+		Metadata:     metadata,
+		IPAddress:    entry.IPAddress,
+	}
+	if err := usecase.Repo.Create(ctx, tx, log); err != nil {
+		return nil, err
+	}
+
+	return log, nil
+}
+
+// Replaces an entry's metadata
+func (usecase *AuditUsecase) UpdateMetadata(ctx context.Context, logID string, metadata map[string]any) error {
+	jsonMetadata, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	return usecase.Repo.UpdateMetadata(ctx, logID, jsonMetadata)
+}
+
+// Whether the user has been explicitly granted the permission
+func (usecase *AuditUsecase) HasPermission(ctx context.Context, userID string, permission string) (bool, error) {
+	return usecase.Repo.HasPermission(ctx, userID, permission)
+}
+
+// Fetch a user's permissions
+func (usecase *AuditUsecase) FetchPermissions(ctx context.Context, userID string) ([]entity.UserPermission, error) {
+	return usecase.Repo.FindPermissions(ctx, userID)
+}
+
+// Grants a permission and records who granted it
+func (usecase *AuditUsecase) GrantPermission(ctx context.Context, req request.PermissionRequest) error {
+	return usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&entity.User{}, "id = ?", req.UserID).Error; err != nil {
+			return err
+		}
+
+		if err := usecase.Repo.GrantPermission(ctx, tx, &entity.UserPermission{
+			UserID:      req.UserID,
+			Permission:  req.Permission,
+			GrantedByID: req.ActorID,
+		}); err != nil {
+			return err // rollback
+		}
+
+		_, err := usecase.Record(ctx, tx, AuditEntry{
+			ActorID:      req.ActorID,
+			Action:       entity.AuditActionPermissionGrant,
+			ResourceType: "user",
+			ResourceID:   &req.UserID,
+			Metadata:     map[string]any{"permission": req.Permission},
+			IPAddress:    req.IPAddress,
+		})
+		return err // commit on nil
+	})
+}
+
+// Revokes a permission and records who revoked it
+func (usecase *AuditUsecase) RevokePermission(ctx context.Context, req request.PermissionRequest) error {
+	return usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		revoked, err := usecase.Repo.RevokePermission(ctx, tx, req.UserID, req.Permission)
+		if err != nil {
+			return err
+		}
+		if revoked == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		_, err = usecase.Record(ctx, tx, AuditEntry{
+			ActorID:      req.ActorID,
+			Action:       entity.AuditActionPermissionRevoke,
+			ResourceType: "user",
+			ResourceID:   &req.UserID,
+			Metadata:     map[string]any{"permission": req.Permission},
+			IPAddress:    req.IPAddress,
+		})
+		return err // commit on nil
+	})
+}
+
+// Fetch audit logs, newest first
+func (usecase *AuditUsecase) FetchLogs(ctx context.Context, req request.FetchAuditLogsRequest) ([]entity.AuditLog, int64, error) {
+	return usecase.Repo.Find(ctx, req.ActorID, req.Action, req.Limit, (req.Page-1)*req.Limit)
+}
@@ -0,0 +1,540 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"japa/internal/app/http/dto/request"
+	"japa/internal/config"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/repository"
+	"japa/internal/infrastructure/payment"
+
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// ERRORS
+
+var (
+	ErrPaymentRequired = errors.New("application must be paid for or covered by your plan")
+	ErrAlreadyPaid     = errors.New("application is already paid for")
+	ErrNoPrice         = errors.New("no price is set for this destination and visa type")
+	ErrUnknownAddOn    = errors.New("unknown add-on")
+	ErrCheckoutPending = errors.New("another checkout for these applications is in progress")
+)
+
+// TYPES
+
+// BillingUsecase prices applications and takes payment for them
+type BillingUsecase struct {
+	Config       config.BillingConfig
+	Repo         *repository.BillingRepository
+	VisaRepo     *repository.VisaRepository
+	Payments     *PaymentUsecase
+	Entitlements *EntitlementUsecase
+	Coupons      *CouponUsecase
+	DB           *gorm.DB
+}
+
+// QuoteLine is one priced item, amounts in minor units
+type QuoteLine struct {
+	Code       string // "application" or an add-on code
+	Name       string
+	Quantity   int
+	UnitAmount int64
+	Amount     int64
+}
+
+// Quote prices the unpaid applications behind a checkout
+type Quote struct {
+	ApplicationIDs []string // The application first, then its group's unpaid drafts
+	Currency       string
+	Lines          []QuoteLine
+	Subtotal       int64
+	Coupon         string // Code the discount is from
+	Discount       int64
+	Total          int64
+
+	shares map[string]int64 // Each application's part of Total, recorded on its purchase
+}
+
+// METHODS
+
+// Initialize BillingUsecase
+func NewBillingUsecase(
+	cfg          config.BillingConfig,
+	repo         *repository.BillingRepository,
+	visaRepo     *repository.VisaRepository,
+	payments     *PaymentUsecase,
+	entitlements *EntitlementUsecase,
+	coupons      *CouponUsecase,
+	db           *gorm.DB,
+) *BillingUsecase {
+	return &BillingUsecase{Config: cfg, Repo: repo, VisaRepo: visaRepo, Payments: payments, Entitlements: entitlements, Coupons: coupons, DB: db}
+}
+
+// Quote prices a draft with the chosen add-ons and coupon. A group principal's quote covers every unpaid draft in the group.
+func (usecase *BillingUsecase) Quote(ctx context.Context, req request.ApplicationCheckoutRequest) (*Quote, error) {
+	application, err := usecase.VisaRepo.FindByID(ctx, usecase.DB, req.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	if application.UserID != req.UserID {
+		return nil, gorm.ErrRecordNotFound // Don't leak other users' applications
+	}
+	if application.Status == nil || *application.Status != entity.VisaStatusDraft {
+		return nil, ErrNotDraft
+	}
+
+	destination, visaType, candidates, err := usecase.checkoutScope(ctx, application)
+	if err != nil {
+		return nil, err
+	}
+
+	paid, err := usecase.Repo.FindPaidApplicationIDs(ctx, usecase.DB, candidates)
+	if err != nil {
+		return nil, err
+	}
+	var unpaid []string
+	for _, id := range candidates {
+		if !containsString(paid, id) {
+			unpaid = append(unpaid, id)
+		}
+	}
+	if len(unpaid) == 0 {
+		return nil, ErrAlreadyPaid
+	}
+
+	currency := PriceBook(usecase.Config, req.Currency, req.Country)
+	price, err := usecase.Repo.FindPrice(ctx, destination, visaType, currency)
+	if errors.Is(err, gorm.ErrRecordNotFound) && currency != usecase.Config.Currency {
+		// Not sold in this price book yet, the whole quote is in the base currency
+		currency = usecase.Config.Currency
+		price, err = usecase.Repo.FindPrice(ctx, destination, visaType, currency)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %s (%s)", ErrNoPrice, destination, visaType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	codes := uniqueStrings(req.AddOns)
+	addOns, err := usecase.Repo.FindAddOnsByCode(ctx, codes, currency)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := PriceQuote(fmt.Sprintf("Visa application: %s (%s)", destination, visaType), price, unpaid, codes, addOns)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Coupon != "" {
+		coupon, discount, err := usecase.Coupons.Price(ctx, req.Coupon, req.UserID, quote.CouponTarget())
+		if err != nil {
+			return nil, err
+		}
+		quote.ApplyDiscount(coupon.Code, discount)
+	}
+
+	return quote, nil
+}
+
+// PriceQuote prices applicationIDs at price, on a line called name, with the add-ons in codes picked from addOns.
+// Per applicant add-ons are charged for each application, others once, on the first.
+func PriceQuote(name string, price *entity.ApplicationPrice, applicationIDs []string, codes []string, addOns []entity.AddOn) (*Quote, error) {
+	quote := &Quote{ApplicationIDs: applicationIDs, Currency: price.Currency, shares: map[string]int64{}}
+	applicants := len(applicationIDs)
+
+	quote.addLine("application", name, applicants, price.Amount)
+	for _, id := range applicationIDs {
+		quote.shares[id] += price.Amount
+	}
+
+	for _, code := range codes {
+		var addOn *entity.AddOn
+		for i := range addOns {
+			if addOns[i].Code == code {
+				addOn = &addOns[i]
+			}
+		}
+		if addOn == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownAddOn, code)
+		}
+
+		if addOn.PerApplicant {
+			quote.addLine(addOn.Code, addOn.Name, applicants, addOn.Amount)
+			for _, id := range applicationIDs {
+				quote.shares[id] += addOn.Amount
+			}
+		} else {
+			quote.addLine(addOn.Code, addOn.Name, 1, addOn.Amount)
+			quote.shares[applicationIDs[0]] += addOn.Amount
+		}
+	}
+
+	quote.Subtotal = quote.Total
+	return quote, nil
+}
+
+// Checkout quotes the draft and starts a provider checkout, recording its pending purchases first.
+// The purchases become paid when the payment settles, see HandleSettlement. Checking out the same
+// cart again returns the pending checkout, a changed cart voids it and starts a new one.
+func (usecase *BillingUsecase) Checkout(ctx context.Context, req request.ApplicationCheckoutRequest) (*Quote, *entity.Payment, error) {
+	quote, err := usecase.Quote(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	codes := uniqueStrings(req.AddOns)
+	jsonAddOns, err := json.Marshal(codes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pending, err := usecase.Repo.FindPendingPurchases(ctx, usecase.DB, quote.ApplicationIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(pending) > 0 {
+		var paymentIDs []string
+		for _, purchase := range pending {
+			if purchase.PaymentID != nil && !containsString(paymentIDs, *purchase.PaymentID) {
+				paymentIDs = append(paymentIDs, *purchase.PaymentID)
+			}
+		}
+		for _, paymentID := range paymentIDs {
+			existing, err := usecase.Payments.Repo.FindByID(ctx, usecase.DB, paymentID)
+			if err != nil {
+				return nil, nil, err
+			}
+			same, err := usecase.sameCheckout(ctx, existing, quote, jsonAddOns)
+			if err != nil {
+				return nil, nil, err
+			}
+			if same {
+				return quote, existing, nil
+			}
+			if _, err := usecase.Payments.Void(ctx, existing.Reference, "replaced by a newer checkout"); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		// A voided checkout may have been paid after all
+		if quote, err = usecase.Quote(ctx, req); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var user entity.User
+	if err := usecase.DB.WithContext(ctx).Select("id", "email").First(&user, "id = ?", req.UserID).Error; err != nil {
+		return nil, nil, err
+	}
+
+	record, err := usecase.Payments.Initialize(ctx, Checkout{
+		UserID:   req.UserID,
+		Email:    user.Email,
+		Purpose:  entity.PaymentPurposeApplication,
+		Amount:   quote.Total,
+		Currency: quote.Currency,
+		Metadata: map[string]any{
+			"visa_application_ids": quote.ApplicationIDs,
+			"add_ons":              codes,
+			"lines":                quote.Lines, // Invoiced once paid
+		},
+	}, func(tx *gorm.DB, record *entity.Payment) error {
+		// Concurrent checkouts of the same applications queue here, the later one finds the other's purchases
+		if err := usecase.Repo.LockApplications(ctx, tx, quote.ApplicationIDs); err != nil {
+			return err
+		}
+		pending, err := usecase.Repo.FindPendingPurchases(ctx, tx, quote.ApplicationIDs)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return ErrCheckoutPending // rollback
+		}
+		paid, err := usecase.Repo.FindPaidApplicationIDs(ctx, tx, quote.ApplicationIDs)
+		if err != nil {
+			return err
+		}
+		if len(paid) > 0 {
+			return ErrAlreadyPaid // rollback
+		}
+
+		if quote.Coupon != "" {
+			if _, err := usecase.Coupons.Reserve(ctx, tx, quote.Coupon, req.UserID, quote.CouponTarget(), record.ID, nil); err != nil {
+				return err // rollback
+			}
+		}
+		purchases := make([]entity.Purchase, len(quote.ApplicationIDs))
+		for i, id := range quote.ApplicationIDs {
+			purchases[i] = entity.Purchase{
+				ID:                ulid.Make().String(),
+				UserID:            req.UserID,
+				VisaApplicationID: id,
+				PaymentID:         &record.ID,
+				Status:            entity.PurchaseStatusPending,
+				Amount:            quote.shares[id],
+				Currency:          quote.Currency,
+				AddOns:            jsonAddOns,
+				PurchasedAt:       record.CreatedAt,
+			}
+		}
+		return usecase.Repo.CreatePurchases(ctx, tx, purchases) // commit on nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return quote, record, nil
+}
+
+// Whether a pending checkout charges for exactly what quote prices, so it can be handed out again
+func (usecase *BillingUsecase) sameCheckout(ctx context.Context, record *entity.Payment, quote *Quote, addOns []byte) (bool, error) {
+	if record.Status != entity.PaymentStatusPending || record.AuthorizationURL == "" {
+		return false, nil
+	}
+	if record.Amount != quote.Total || record.Currency != quote.Currency {
+		return false, nil
+	}
+
+	purchases, err := usecase.Repo.FindPurchasesByPayment(ctx, usecase.DB, record.ID)
+	if err != nil {
+		return false, err
+	}
+	if len(purchases) != len(quote.ApplicationIDs) {
+		return false, nil
+	}
+	for _, purchase := range purchases {
+		share, ok := quote.shares[purchase.VisaApplicationID]
+		if !ok || purchase.Status != entity.PurchaseStatusPending || purchase.Amount != share || string(purchase.AddOns) != string(addOns) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// HandleSettlement marks the payment's purchases paid or failed, registered for application payments
+func (usecase *BillingUsecase) HandleSettlement(ctx context.Context, tx *gorm.DB, record *entity.Payment, verification *payment.Verification) (func(), error) {
+	status := entity.PurchaseStatusFailed
+	paidAt := record.CreatedAt
+	if record.Status == entity.PaymentStatusSucceeded {
+		status = entity.PurchaseStatusPaid
+		paidAt = *record.PaidAt
+	}
+	if _, err := usecase.Coupons.Settle(ctx, tx, record); err != nil {
+		return nil, err
+	}
+	return nil, usecase.Repo.SettlePurchases(ctx, tx, record.ID, status, paidAt)
+}
+
+// HandleRefund is the refund handler for application payments, a full refund takes the applications' paid purchases back
+func (usecase *BillingUsecase) HandleRefund(ctx context.Context, tx *gorm.DB, record *entity.Payment, refund *entity.Refund) (func(), error) {
+	if record.RefundStatus != entity.PaymentRefundFull {
+		return nil, nil
+	}
+	return nil, usecase.Repo.RefundPurchases(ctx, tx, record.ID)
+}
+
+// RequirePaid fails with ErrPaymentRequired unless every application has a paid purchase
+// or fits in the visa_applications quota of the user's plan. Runs inside the submission
+// transaction, so the quota is only used when the submission commits.
+func (usecase *BillingUsecase) RequirePaid(ctx context.Context, tx *gorm.DB, userID string, applicationIDs []string) error {
+	if !usecase.Config.RequirePayment {
+		return nil
+	}
+
+	paid, err := usecase.Repo.FindPaidApplicationIDs(ctx, tx, applicationIDs)
+	if err != nil {
+		return err
+	}
+	unpaid := 0
+	for _, id := range applicationIDs {
+		if !containsString(paid, id) {
+			unpaid++
+		}
+	}
+	return usecase.RequireQuota(ctx, tx, userID, unpaid)
+}
+
+// RequireQuota fails with ErrPaymentRequired unless count applications fit in the visa_applications
+// quota of the user's plan. For submissions that were never drafts, so can't have been bought.
+func (usecase *BillingUsecase) RequireQuota(ctx context.Context, tx *gorm.DB, userID string, count int) error {
+	if !usecase.Config.RequirePayment || count == 0 {
+		return nil
+	}
+
+	_, err := usecase.Entitlements.ConsumeQuota(ctx, tx, userID, entity.EntitlementVisaApplications, int64(count))
+	if errors.Is(err, ErrNotEntitled) || errors.Is(err, ErrQuotaExceeded) || errors.Is(err, ErrNotQuota) {
+		return fmt.Errorf("%w: %d application(s) unpaid (%s)", ErrPaymentRequired, count, err.Error())
+	}
+	return err
+}
+
+// RequireGroupSize fails with ErrLimitExceeded when size applicants are more than the group_size
+// limit of the user's plan allows, or ErrNotEntitled when the plan has no group applications.
+func (usecase *BillingUsecase) RequireGroupSize(ctx context.Context, tx *gorm.DB, userID string, size int) error {
+	if !usecase.Config.RequirePayment {
+		return nil
+	}
+	return usecase.Entitlements.RequireLimit(ctx, tx, userID, entity.EntitlementGroupSize, int64(size))
+}
+
+// Fetch all application prices
+func (usecase *BillingUsecase) FetchPrices(ctx context.Context) ([]entity.ApplicationPrice, error) {
+	return usecase.Repo.FindPrices(ctx)
+}
+
+// Create or update a price
+func (usecase *BillingUsecase) SavePrice(ctx context.Context, req request.SaveApplicationPriceRequest) (*entity.ApplicationPrice, error) {
+	if !supportedCurrency(usecase.Config, req.Currency) {
+		return nil, ErrUnsupportedCurrency
+	}
+	price := &entity.ApplicationPrice{
+		Destination: req.Destination,
+		VisaType:    req.VisaType,
+		Currency:    req.Currency,
+		Amount:      req.Amount,
+	}
+	if err := usecase.Repo.SavePrice(ctx, price); err != nil {
+		return nil, err
+	}
+	return price, nil
+}
+
+// Delete a price
+func (usecase *BillingUsecase) DeletePrice(ctx context.Context, priceID uint) error {
+	return usecase.Repo.DeletePrice(ctx, priceID)
+}
+
+// Fetch add-ons. Applicants only see active ones in the price book they pay from.
+func (usecase *BillingUsecase) FetchAddOns(ctx context.Context, all bool, currency string, country string) ([]entity.AddOn, error) {
+	if all {
+		return usecase.Repo.FindAddOns(ctx, "", false)
+	}
+	return usecase.Repo.FindAddOns(ctx, PriceBook(usecase.Config, currency, country), true)
+}
+
+// Create or update an add-on
+func (usecase *BillingUsecase) SaveAddOn(ctx context.Context, req request.SaveAddOnRequest) (*entity.AddOn, error) {
+	if !supportedCurrency(usecase.Config, req.Currency) {
+		return nil, ErrUnsupportedCurrency
+	}
+	addOn := &entity.AddOn{
+		Code:         req.Code,
+		Currency:     req.Currency,
+		Name:         req.Name,
+		Amount:       req.Amount,
+		PerApplicant: req.PerApplicant,
+		Active:       *req.Active,
+	}
+	if err := usecase.Repo.SaveAddOn(ctx, addOn); err != nil {
+		return nil, err
+	}
+	return addOn, nil
+}
+
+// What a checkout of the application pays for: its destination and visa type,
+// and the application plus, for a group principal, the group's other drafts
+func (usecase *BillingUsecase) checkoutScope(ctx context.Context, application *entity.VisaApplication) (string, string, []string, error) {
+	ids := []string{application.ID}
+
+	if application.GroupID != nil {
+		group, err := usecase.VisaRepo.FindGroupByID(ctx, usecase.DB, *application.GroupID)
+		if err != nil {
+			return "", "", nil, err
+		}
+		if group.PrincipalApplicationID == application.ID {
+			for _, member := range group.Members {
+				if member.ID != application.ID && member.Status != nil && *member.Status == entity.VisaStatusDraft {
+					ids = append(ids, member.ID)
+				}
+			}
+		}
+		return group.Destination, group.VisaType, ids, nil
+	}
+
+	// Drafts hold the request payload, converted applications the stored form
+	var travel struct {
+		Destination    string `json:"destination"`
+		VisaType       string `json:"visa_type"`
+		StoredVisaType string `json:"VisaType"`
+	}
+	if len(application.VisaFormInput) > 0 {
+		if err := json.Unmarshal(application.VisaFormInput, &travel); err != nil {
+			return "", "", nil, fmt.Errorf("%w: %s", ErrIncompleteApplication, err.Error())
+		}
+	}
+	if travel.VisaType == "" {
+		travel.VisaType = travel.StoredVisaType
+	}
+	if travel.Destination == "" || travel.VisaType == "" {
+		return "", "", nil, fmt.Errorf("%w: destination and visa_type are needed for a price", ErrIncompleteApplication)
+	}
+	return travel.Destination, travel.VisaType, ids, nil
+}
+
+// Share is the application's part of the total, recorded on its purchase
+func (quote *Quote) Share(applicationID string) int64 {
+	return quote.shares[applicationID]
+}
+
+func (quote *Quote) addLine(code string, name string, quantity int, unitAmount int64) {
+	amount := unitAmount * int64(quantity)
+	quote.Lines = append(quote.Lines, QuoteLine{
+		Code:       code,
+		Name:       name,
+		Quantity:   quantity,
+		UnitAmount: unitAmount,
+		Amount:     amount,
+	})
+	quote.Total += amount
+}
+
+// CouponTarget is what a coupon is checked against, the quote before any discount
+func (quote *Quote) CouponTarget() CouponTarget {
+	return CouponTarget{AppliesTo: entity.CouponAppliesToApplications, Amount: quote.Subtotal, Currency: quote.Currency}
+}
+
+// ApplyDiscount takes discount off the total and each application's share, in proportion. The last share absorbs rounding.
+func (quote *Quote) ApplyDiscount(code string, discount int64) {
+	quote.Coupon = code
+	quote.Discount = discount
+	quote.Total = quote.Subtotal - discount
+
+	left := discount
+	for i, id := range quote.ApplicationIDs {
+		share := left
+		if i < len(quote.ApplicationIDs)-1 {
+			share = discount * quote.shares[id] / quote.Subtotal
+		}
+		quote.shares[id] -= share
+		left -= share
+	}
+}
+
+// Lower-cased, trimmed, without blanks or repeats, in their original order
+func uniqueStrings(values []string) []string {
+	unique := []string{}
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value != "" && !containsString(unique, value) {
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
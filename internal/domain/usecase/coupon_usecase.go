@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"japa/internal/app/http/dto/request"
+	"japa/internal/config"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/repository"
+
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// ERRORS
+
+var (
+	ErrCouponInvalid       = errors.New("coupon code is not valid")
+	ErrCouponNotApplicable = errors.New("coupon does not apply to this checkout")
+	ErrCouponExhausted     = errors.New("coupon has been fully redeemed")
+	ErrCouponUsed          = errors.New("coupon was already used")
+)
+
+// TYPES
+
+// CouponUsecase prices coupons at checkout and keeps their redemption limits
+type CouponUsecase struct {
+	Config config.BillingConfig
+	Repo   *repository.CouponRepository
+	DB     *gorm.DB
+}
+
+// CouponTarget is the checkout a coupon is redeemed on
+type CouponTarget struct {
+	AppliesTo string // entity.CouponAppliesToApplications or entity.CouponAppliesToSubscriptions
+	PlanCode  string // Subscriptions only
+	Amount    int64  // Minor units, before the discount
+	Currency  string
+}
+
+// METHODS
+
+// Initialize CouponUsecase
+func NewCouponUsecase(cfg config.BillingConfig, repo *repository.CouponRepository, db *gorm.DB) *CouponUsecase {
+	return &CouponUsecase{Config: cfg, Repo: repo, DB: db}
+}
+
+// Price checks the user can redeem code on target and returns the discount, without holding a redemption
+func (usecase *CouponUsecase) Price(ctx context.Context, code string, userID string, target CouponTarget) (*entity.Coupon, int64, error) {
+	coupon, err := usecase.Repo.FindByCode(ctx, usecase.DB, normalizeCoupon(code))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, 0, ErrCouponInvalid
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	discount, err := usecase.check(ctx, usecase.DB, coupon, userID, target)
+	if err != nil {
+		return nil, 0, err
+	}
+	return coupon, discount, nil
+}
+
+// Reserve re-checks code under a lock and holds a pending redemption for the payment, in the checkout's transaction.
+// The redemption counts against the coupon's limits until the payment settles, or for CouponHold if it never does.
+func (usecase *CouponUsecase) Reserve(
+	ctx            context.Context,
+	tx             *gorm.DB,
+	code           string,
+	userID         string,
+	target         CouponTarget,
+	paymentID      string,
+	subscriptionID *string,
+) (*entity.CouponRedemption, error) {
+	coupon, err := usecase.Repo.LockByCode(ctx, tx, normalizeCoupon(code))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCouponInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	discount, err := usecase.check(ctx, tx, coupon, userID, target)
+	if err != nil {
+		return nil, err
+	}
+
+	redemption := &entity.CouponRedemption{
+		ID:             ulid.Make().String(),
+		CouponID:       coupon.ID,
+		UserID:         userID,
+		PaymentID:      paymentID,
+		SubscriptionID: subscriptionID,
+		Status:         entity.CouponRedemptionPending,
+		Discount:       discount,
+		Currency:       target.Currency,
+	}
+	if err := usecase.Repo.CreateRedemption(ctx, tx, redemption); err != nil {
+		return nil, err
+	}
+	return redemption, nil
+}
+
+// Settle redeems or voids the payment's pending redemptions, returning the discount they gave.
+// Settlement handlers call it in the settlement transaction.
+func (usecase *CouponUsecase) Settle(ctx context.Context, tx *gorm.DB, record *entity.Payment) (int64, error) {
+	status := entity.CouponRedemptionVoid
+	if record.Status == entity.PaymentStatusSucceeded {
+		status = entity.CouponRedemptionRedeemed
+	}
+	return usecase.Repo.SettleRedemptions(ctx, tx, record.ID, status)
+}
+
+// Fetch all coupons with how many times each was redeemed
+func (usecase *CouponUsecase) FetchCoupons(ctx context.Context) ([]entity.Coupon, map[uint]int64, error) {
+	coupons, err := usecase.Repo.FindAll(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	couponIDs := make([]uint, len(coupons))
+	for i, coupon := range coupons {
+		couponIDs[i] = coupon.ID
+	}
+	redeemed, err := usecase.Repo.CountRedeemed(ctx, couponIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return coupons, redeemed, nil
+}
+
+// Create or update a coupon
+func (usecase *CouponUsecase) SaveCoupon(ctx context.Context, req request.SaveCouponRequest) (*entity.Coupon, error) {
+	planCodes, err := json.Marshal(uniqueStrings(req.PlanCodes))
+	if err != nil {
+		return nil, err
+	}
+
+	coupon := &entity.Coupon{
+		Code:           normalizeCoupon(req.Code),
+		Description:    req.Description,
+		Type:           req.Type,
+		AppliesTo:      req.AppliesTo,
+		PlanCodes:      planCodes,
+		MaxRedemptions: req.MaxRedemptions,
+		PerUserLimit:   req.PerUserLimit,
+		StartsAt:       req.StartsAt,
+		ExpiresAt:      req.ExpiresAt,
+		Active:         *req.Active,
+	}
+	if req.Type == entity.CouponTypePercentage {
+		coupon.PercentOff = req.PercentOff
+	} else {
+		coupon.AmountOff = req.AmountOff
+		coupon.Currency = &req.Currency
+	}
+	if err := usecase.Repo.Save(ctx, coupon); err != nil {
+		return nil, err
+	}
+	return coupon, nil
+}
+
+// Fetch a coupon's redemptions
+func (usecase *CouponUsecase) FetchRedemptions(ctx context.Context, code string, page int, limit int) ([]entity.CouponRedemption, int64, error) {
+	coupon, err := usecase.Repo.FindByCode(ctx, usecase.DB, normalizeCoupon(code))
+	if err != nil {
+		return nil, 0, err
+	}
+	return usecase.Repo.FindRedemptions(ctx, coupon.ID, limit, (page-1)*limit)
+}
+
+// Validates the coupon against target and its limits, returning the discount
+func (usecase *CouponUsecase) check(ctx context.Context, tx *gorm.DB, coupon *entity.Coupon, userID string, target CouponTarget) (int64, error) {
+	now := time.Now()
+	if !coupon.Active || (coupon.StartsAt != nil && coupon.StartsAt.After(now)) || (coupon.ExpiresAt != nil && !coupon.ExpiresAt.After(now)) {
+		return 0, ErrCouponInvalid
+	}
+	if coupon.AppliesTo != entity.CouponAppliesToAll && coupon.AppliesTo != target.AppliesTo {
+		return 0, fmt.Errorf("%w: only valid on %s", ErrCouponNotApplicable, coupon.AppliesTo)
+	}
+	if target.AppliesTo == entity.CouponAppliesToSubscriptions && len(coupon.PlanCodes) > 0 {
+		var planCodes []string
+		if err := json.Unmarshal(coupon.PlanCodes, &planCodes); err != nil {
+			return 0, err
+		}
+		if len(planCodes) > 0 && !containsString(planCodes, target.PlanCode) {
+			return 0, fmt.Errorf("%w: not valid on this plan", ErrCouponNotApplicable)
+		}
+	}
+	if coupon.Type == entity.CouponTypeFixed && (coupon.Currency == nil || *coupon.Currency != target.Currency) {
+		return 0, fmt.Errorf("%w: not valid in %s", ErrCouponNotApplicable, target.Currency)
+	}
+
+	discount := coupon.Discount(target.Amount)
+	if discount >= target.Amount {
+		return 0, fmt.Errorf("%w: it would leave nothing to pay", ErrCouponNotApplicable)
+	}
+
+	heldSince := now.Add(-usecase.Config.CouponHold)
+	if coupon.MaxRedemptions != nil {
+		used, err := usecase.Repo.CountRedemptions(ctx, tx, coupon.ID, "", heldSince)
+		if err != nil {
+			return 0, err
+		}
+		if used >= int64(*coupon.MaxRedemptions) {
+			return 0, ErrCouponExhausted
+		}
+	}
+	if coupon.PerUserLimit != nil {
+		used, err := usecase.Repo.CountRedemptions(ctx, tx, coupon.ID, userID, heldSince)
+		if err != nil {
+			return 0, err
+		}
+		if used >= int64(*coupon.PerUserLimit) {
+			return 0, ErrCouponUsed
+		}
+	}
+	return discount, nil
+}
+
+// Coupon codes are matched upper case and trimmed
+func normalizeCoupon(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,276 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"japa/internal/app/http/dto/request"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+// ERRORS
+
+var ErrNotEligible = errors.New("applicant does not meet the eligibility requirements")
+
+// TYPES
+
+// EligibilityUsecase scores applicant profiles against per-country rules
+type EligibilityUsecase struct {
+	Repo *repository.EligibilityRepository
+	DB   *gorm.DB
+}
+
+// EligibilityProfile is what the engine knows about an applicant.
+// Nil/zero fields are "unknown": rules depending on them are skipped, not failed.
+type EligibilityProfile struct {
+	Destination    string
+	VisaType       string
+	Nationality    string
+	PassportExpiry time.Time
+	TravelDate     time.Time
+	Funds          *float64
+	FundsCurrency  string
+	EducationLevel string
+	HasBeenDenied  *bool
+}
+
+// EligibilityRuleResult explains a single rule outcome
+type EligibilityRuleResult struct {
+	RuleID   uint   `json:"rule_id"`
+	RuleType string `json:"rule_type"`
+	Message  string `json:"message"`
+	Blocking bool   `json:"blocking"`
+}
+
+// EligibilityResult is the outcome of an evaluation
+type EligibilityResult struct {
+	Score        int                     `json:"score"`    // 0-100, weighted share of evaluated rules that passed
+	Eligible     bool                    `json:"eligible"` // False when any blocking rule failed
+	Evaluated    int                     `json:"evaluated"`
+	Passed       int                     `json:"passed"`
+	FailedRules  []EligibilityRuleResult `json:"failed_rules"`
+	SkippedRules []EligibilityRuleResult `json:"skipped_rules"` // Not enough data in the profile
+}
+
+// EligibilityError is returned by submissions that fail a blocking rule
+type EligibilityError struct {
+	Result *EligibilityResult
+}
+
+func (e *EligibilityError) Error() string {
+	messages := make([]string, 0, len(e.Result.FailedRules))
+	for _, failed := range e.Result.FailedRules {
+		if failed.Blocking {
+			messages = append(messages, failed.Message)
+		}
+	}
+	return fmt.Sprintf("%s: %s", ErrNotEligible.Error(), strings.Join(messages, "; "))
+}
+
+func (e *EligibilityError) Is(target error) bool {
+	return target == ErrNotEligible
+}
+
+// Education levels in ascending order
+var educationRank = map[string]int{
+	"none":      0,
+	"secondary": 1,
+	"diploma":   2,
+	"bachelors": 3,
+	"masters":   4,
+	"doctorate": 5,
+}
+
+// METHODS
+
+// Initialize EligibilityUsecase
+func NewEligibilityUsecase(repo *repository.EligibilityRepository, db *gorm.DB) *EligibilityUsecase {
+	return &EligibilityUsecase{Repo: repo, DB: db}
+}
+
+// Evaluates a profile against the destination's active rules
+func (usecase *EligibilityUsecase) Evaluate(ctx context.Context, profile EligibilityProfile) (*EligibilityResult, error) {
+	rules, err := usecase.Repo.FindActiveRules(ctx, profile.Destination, profile.VisaType)
+	if err != nil {
+		return nil, err
+	}
+	return EvaluateRules(rules, profile), nil
+}
+
+// EvaluateRules scores a profile against rules. Rules the profile can't answer are skipped
+func EvaluateRules(rules []entity.EligibilityRule, profile EligibilityProfile) *EligibilityResult {
+	result := &EligibilityResult{
+		Eligible:     true,
+		FailedRules:  []EligibilityRuleResult{},
+		SkippedRules: []EligibilityRuleResult{},
+	}
+
+	var totalWeight, passedWeight int
+	for _, rule := range rules {
+		outcome := EligibilityRuleResult{
+			RuleID:   rule.ID,
+			RuleType: rule.RuleType,
+			Message:  rule.Message,
+			Blocking: rule.Blocking,
+		}
+
+		passed, evaluated := evaluateRule(rule, profile)
+		if !evaluated {
+			result.SkippedRules = append(result.SkippedRules, outcome)
+			continue
+		}
+
+		result.Evaluated++
+		totalWeight += rule.Weight
+		if passed {
+			result.Passed++
+			passedWeight += rule.Weight
+			continue
+		}
+
+		result.FailedRules = append(result.FailedRules, outcome)
+		if rule.Blocking {
+			result.Eligible = false
+		}
+	}
+
+	result.Score = 100
+	if totalWeight > 0 {
+		result.Score = passedWeight * 100 / totalWeight
+	}
+
+	return result
+}
+
+
+// Pre-submission check used by visa submissions, only blocking rules reject
+func (usecase *EligibilityUsecase) CheckSubmission(ctx context.Context, input *request.VisaFormInputRequest) error {
+	if input == nil {
+		return nil // Uploaded signed form, nothing to evaluate
+	}
+
+	profile := EligibilityProfile{
+		Destination:   input.Destination,
+		VisaType:      input.VisaType,
+		Nationality:   input.PersonalInfo.Nationality,
+		HasBeenDenied: &input.HasBeenDenied,
+	}
+	profile.TravelDate, _ = time.Parse("2006-01-02", input.TravelDate)
+	profile.PassportExpiry, _ = time.Parse("2006-01-02", input.PersonalInfo.PassportExpiry)
+
+	result, err := usecase.Evaluate(ctx, profile)
+	if err != nil {
+		return err
+	}
+	if !result.Eligible {
+		return &EligibilityError{Result: result}
+	}
+
+	return nil
+}
+
+
+// Lists rules for admins
+func (usecase *EligibilityUsecase) FetchRules(ctx context.Context, country string) ([]entity.EligibilityRule, error) {
+	return usecase.Repo.FindRules(ctx, country)
+}
+
+
+// Creates a rule
+func (usecase *EligibilityUsecase) CreateRule(ctx context.Context, req request.CreateEligibilityRuleRequest) (*entity.EligibilityRule, error) {
+	rule := &entity.EligibilityRule{
+		Country:  req.Country,
+		VisaType: req.VisaType,
+		RuleType: req.RuleType,
+		Value:    req.Value,
+		Currency: req.Currency,
+		Weight:   req.Weight,
+		Blocking: req.Blocking,
+		Message:  req.Message,
+		Active:   true,
+	}
+	if rule.VisaType == "" {
+		rule.VisaType = "*"
+	}
+	if rule.Weight == 0 {
+		rule.Weight = 10
+	}
+
+	if err := usecase.Repo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+
+// Deletes a rule
+func (usecase *EligibilityUsecase) DeleteRule(ctx context.Context, ruleID uint) error {
+	return usecase.Repo.Delete(ctx, ruleID)
+}
+
+
+// evaluateRule returns whether the rule passed and whether it could be evaluated at all
+func evaluateRule(rule entity.EligibilityRule, profile EligibilityProfile) (passed bool, evaluated bool) {
+	switch rule.RuleType {
+	case entity.RuleNationalityAllowed, entity.RuleNationalityBlocked:
+		if profile.Nationality == "" {
+			return false, false
+		}
+		listed := containsFold(strings.Split(rule.Value, ","), profile.Nationality)
+		if rule.RuleType == entity.RuleNationalityAllowed {
+			return listed, true
+		}
+		return !listed, true
+
+	case entity.RulePassportValidity:
+		months, err := strconv.Atoi(strings.TrimSpace(rule.Value))
+		if err != nil || profile.PassportExpiry.IsZero() || profile.TravelDate.IsZero() {
+			return false, false
+		}
+		return !profile.PassportExpiry.Before(profile.TravelDate.AddDate(0, months, 0)), true
+
+	case entity.RuleMinFunds:
+		minimum, err := strconv.ParseFloat(strings.TrimSpace(rule.Value), 64)
+		if err != nil || profile.Funds == nil {
+			return false, false
+		}
+		// Funds in another currency can't be compared
+		if rule.Currency != nil && !strings.EqualFold(*rule.Currency, profile.FundsCurrency) {
+			return false, false
+		}
+		return *profile.Funds >= minimum, true
+
+	case entity.RuleMinEducation:
+		required, knownRule := educationRank[strings.ToLower(strings.TrimSpace(rule.Value))]
+		actual, knownProfile := educationRank[strings.ToLower(profile.EducationLevel)]
+		if !knownRule || !knownProfile {
+			return false, false
+		}
+		return actual >= required, true
+
+	case entity.RuleNoPriorDenial:
+		if profile.HasBeenDenied == nil {
+			return false, false
+		}
+		return !*profile.HasBeenDenied, true
+	}
+
+	return false, false // Unknown rule types are ignored
+}
+
+func containsFold(values []string, target string) bool {
+	target = strings.TrimSpace(target)
+	for _, value := range values {
+		if strings.EqualFold(strings.TrimSpace(value), target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,282 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"japa/internal/app/http/dto/request"
+	"japa/internal/config"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+// ERRORS
+
+var (
+	ErrNotEntitled   = errors.New("plan does not include this feature")
+	ErrQuotaExceeded = errors.New("plan quota is used up for this period")
+	ErrLimitExceeded = errors.New("plan limit exceeded")
+	ErrNotQuota      = errors.New("entitlement is not a quota")
+)
+
+// TYPES
+
+// EntitlementUsecase answers what a user's plan allows and meters quota usage
+type EntitlementUsecase struct {
+	Config config.SubscriptionConfig
+	Repo   *repository.EntitlementRepository
+	DB     *gorm.DB
+}
+
+// Entitlements are what the user's plan grants, by key. Users without a subscription get the free plan's.
+type Entitlements struct {
+	PlanID   uint // 0 when neither a subscription nor a free plan applies
+	PlanName string
+	Items    map[string]entity.PlanEntitlement
+}
+
+// UsageSummary is one entitlement as the client shows it, i.e "2 of 3 used"
+type UsageSummary struct {
+	Key       string
+	Type      string
+	Enabled   bool
+	Limit     *int64 // Null is unlimited
+	Used      int64  // Quotas only
+	Remaining *int64 // Quotas only, null is unlimited
+	ResetsAt  *time.Time
+	Summary   string
+}
+
+// METHODS
+
+// Initialize EntitlementUsecase
+func NewEntitlementUsecase(cfg config.SubscriptionConfig, repo *repository.EntitlementRepository, db *gorm.DB) *EntitlementUsecase {
+	return &EntitlementUsecase{Config: cfg, Repo: repo, DB: db}
+}
+
+// For resolves the user's entitlements from their subscription in good standing, or the free plan
+func (usecase *EntitlementUsecase) For(ctx context.Context, tx *gorm.DB, userID string) (*Entitlements, error) {
+	entitlements := &Entitlements{Items: map[string]entity.PlanEntitlement{}}
+
+	planID, err := usecase.Repo.FindSubscribedPlanID(ctx, tx, userID, time.Now())
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var plan *entity.Plan
+	if err == nil {
+		plan, err = usecase.Repo.FindPlan(ctx, tx, planID)
+	} else if usecase.Config.FreePlan != "" {
+		plan, err = usecase.Repo.FindActivePlanByCode(ctx, tx, usecase.Config.FreePlan)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) || plan == nil {
+		return entitlements, nil // Nothing is granted
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := usecase.Repo.FindByPlan(ctx, tx, plan.ID)
+	if err != nil {
+		return nil, err
+	}
+	entitlements.PlanID = plan.ID
+	entitlements.PlanName = plan.Name
+	for _, item := range items {
+		entitlements.Items[item.Key] = item
+	}
+	return entitlements, nil
+}
+
+// RequireEntitlement fails with ErrNotEntitled unless the user's plan includes the feature.
+// Quotas and limits count as included unless capped at zero.
+func (usecase *EntitlementUsecase) RequireEntitlement(ctx context.Context, tx *gorm.DB, userID string, key string) error {
+	entitlements, err := usecase.For(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+	if _, ok := entitlements.granted(key); !ok {
+		return fmt.Errorf("%w: %s", ErrNotEntitled, key)
+	}
+	return nil
+}
+
+// RequireLimit fails with ErrLimitExceeded when value is over the plan's limit for key, i.e applicants in a group
+func (usecase *EntitlementUsecase) RequireLimit(ctx context.Context, tx *gorm.DB, userID string, key string, value int64) error {
+	entitlements, err := usecase.For(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+	return entitlements.Within(key, value)
+}
+
+// ConsumeQuota records amount uses of a quota in the current period, failing with ErrQuotaExceeded
+// when they don't fit. Run it in the transaction of what is being used, so a rollback gives the uses back.
+func (usecase *EntitlementUsecase) ConsumeQuota(ctx context.Context, tx *gorm.DB, userID string, key string, amount int64) (*UsageSummary, error) {
+	entitlements, err := usecase.For(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	item, ok := entitlements.granted(key)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotEntitled, key)
+	}
+	if item.Type != entity.EntitlementTypeQuota {
+		return nil, fmt.Errorf("%w: %s", ErrNotQuota, key)
+	}
+
+	start, _ := entity.PeriodBounds(item.ResetPeriod, time.Now())
+	counter, err := usecase.Repo.LockCounter(ctx, tx, userID, key, start)
+	if err != nil {
+		return nil, err
+	}
+	if item.Limit != nil && counter.Used+amount > *item.Limit {
+		summary := summarize(item, counter.Used, time.Now())
+		return summary, fmt.Errorf("%w: %s, %s", ErrQuotaExceeded, key, summary.Summary)
+	}
+
+	counter.Used += amount
+	if err := usecase.Repo.UpdateCounter(ctx, tx, counter); err != nil {
+		return nil, err
+	}
+	return summarize(item, counter.Used, time.Now()), nil
+}
+
+// Usage summarizes every entitlement of the user's plan with the current period's usage
+func (usecase *EntitlementUsecase) Usage(ctx context.Context, userID string) (*Entitlements, []UsageSummary, error) {
+	entitlements, err := usecase.For(ctx, usecase.DB, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	periods := map[string]time.Time{}
+	for key, item := range entitlements.Items {
+		if item.Type == entity.EntitlementTypeQuota {
+			periods[key], _ = entity.PeriodBounds(item.ResetPeriod, now)
+		}
+	}
+	used, err := usecase.Repo.FindCounters(ctx, usecase.DB, userID, periods)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	summaries := []UsageSummary{}
+	for _, key := range sortedKeys(entitlements.Items) {
+		summaries = append(summaries, *summarize(entitlements.Items[key], used[key], now))
+	}
+	return entitlements, summaries, nil
+}
+
+// Fetch a plan's entitlements
+func (usecase *EntitlementUsecase) FetchPlanEntitlements(ctx context.Context, planID uint) ([]entity.PlanEntitlement, error) {
+	if _, err := usecase.Repo.FindPlan(ctx, usecase.DB, planID); err != nil {
+		return nil, err
+	}
+	return usecase.Repo.FindByPlan(ctx, usecase.DB, planID)
+}
+
+// Create or update a plan's entitlement
+func (usecase *EntitlementUsecase) SaveEntitlement(ctx context.Context, req request.SavePlanEntitlementRequest) (*entity.PlanEntitlement, error) {
+	if _, err := usecase.Repo.FindPlan(ctx, usecase.DB, req.PlanID); err != nil {
+		return nil, err
+	}
+
+	entitlement := &entity.PlanEntitlement{
+		PlanID:  req.PlanID,
+		Key:     req.Key,
+		Type:    req.Type,
+		Enabled: *req.Enabled,
+	}
+	if req.Type != entity.EntitlementTypeBoolean {
+		entitlement.Limit = req.Limit
+	}
+	if req.Type == entity.EntitlementTypeQuota {
+		entitlement.ResetPeriod = req.ResetPeriod
+	}
+	if err := usecase.Repo.Save(ctx, entitlement); err != nil {
+		return nil, err
+	}
+	return entitlement, nil
+}
+
+// Delete a plan's entitlement
+func (usecase *EntitlementUsecase) DeleteEntitlement(ctx context.Context, planID uint, key string) error {
+	return usecase.Repo.Delete(ctx, planID, key)
+}
+
+// The enabled entitlement for key, quotas and limits capped at zero grant nothing
+// Within fails with ErrNotEntitled unless key is granted, and with ErrLimitExceeded when value is over its limit
+func (entitlements *Entitlements) Within(key string, value int64) error {
+	item, ok := entitlements.granted(key)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotEntitled, key)
+	}
+	if item.Limit != nil && value > *item.Limit {
+		return fmt.Errorf("%w: %s is limited to %d", ErrLimitExceeded, key, *item.Limit)
+	}
+	return nil
+}
+
+func (entitlements *Entitlements) granted(key string) (entity.PlanEntitlement, bool) {
+	item, ok := entitlements.Items[key]
+	if !ok || !item.Enabled {
+		return item, false
+	}
+	if item.Type != entity.EntitlementTypeBoolean && item.Limit != nil && *item.Limit <= 0 {
+		return item, false
+	}
+	return item, true
+}
+
+// Usage of one entitlement at now
+func summarize(item entity.PlanEntitlement, used int64, now time.Time) *UsageSummary {
+	summary := &UsageSummary{
+		Key:     item.Key,
+		Type:    item.Type,
+		Enabled: item.Enabled,
+		Limit:   item.Limit,
+	}
+
+	switch {
+	case !item.Enabled:
+		summary.Summary = "Not included"
+	case item.Type == entity.EntitlementTypeBoolean:
+		summary.Summary = "Included"
+	case item.Type == entity.EntitlementTypeLimit && item.Limit == nil:
+		summary.Summary = "Unlimited"
+	case item.Type == entity.EntitlementTypeLimit:
+		summary.Summary = fmt.Sprintf("Up to %d", *item.Limit)
+	default: // quota
+		summary.Used = used
+		if _, end := entity.PeriodBounds(item.ResetPeriod, now); !end.IsZero() {
+			summary.ResetsAt = &end
+		}
+		if item.Limit == nil {
+			summary.Summary = fmt.Sprintf("%d used, unlimited", used)
+		} else {
+			remaining := *item.Limit - used
+			if remaining < 0 {
+				remaining = 0
+			}
+			summary.Remaining = &remaining
+			summary.Summary = fmt.Sprintf("%d of %d used", used, *item.Limit)
+		}
+	}
+	return summary
+}
+
+// Map keys in order, so summaries are stable
+func sortedKeys(items map[string]entity.PlanEntitlement) []string {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
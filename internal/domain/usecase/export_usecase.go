@@ -0,0 +1,336 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"japa/internal/app/http/dto/request"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/repository"
+	"japa/internal/pkg"
+
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/zap"
+)
+
+// Rows fetched per query while exporting
+const exportBatchSize = 500
+
+// TYPES
+
+// ExportUsecase builds spreadsheet exports of the agent queue
+type ExportUsecase struct {
+	SLA      *SLAUsecase
+	VisaRepo *repository.VisaRepository
+	Audit    *AuditUsecase
+}
+
+// ExportJob is an audited export ready to be streamed
+type ExportJob struct {
+	AuditLogID  string
+	Filters     request.VisaQueueRequest
+	Format      string
+	Masked      bool
+	Filename    string
+	ContentType string
+	metadata    map[string]any
+}
+
+// rowWriter is implemented by the CSV and XLSX writers
+type rowWriter interface {
+	WriteRow(cells []string) error
+	Close() error
+}
+
+// Flattened VisaFormInput columns, in export order
+var exportColumns = []string{
+	"application_id", "account_name", "account_email", "applicant_name", "relationship", "group_id",
+	"status", "agent", "created_at", "sla_state", "hours_in_status",
+	"destination", "visa_type", "travel_date", "duration_of_stay", "purpose", "has_been_denied",
+	"passport_number", "passport_expiry", "nationality", "marital_status", "date_of_birth", "residential_address",
+	"emergency_name", "emergency_phone", "emergency_relationship",
+}
+
+// METHODS
+
+// Initialize ExportUsecase
+func NewExportUsecase(sla *SLAUsecase, visaRepo *repository.VisaRepository, audit *AuditUsecase) *ExportUsecase {
+	return &ExportUsecase{SLA: sla, VisaRepo: visaRepo, Audit: audit}
+}
+
+// PrepareExport decides masking and records the export in the audit log before anything is sent
+func (usecase *ExportUsecase) PrepareExport(ctx context.Context, req request.ExportApplicationsRequest) (*ExportJob, error) {
+	unmasked, err := usecase.Audit.HasPermission(ctx, req.Filters.AgentID, entity.PermissionExportSensitive)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &ExportJob{
+		Filters:  req.Filters,
+		Format:   req.Format,
+		Masked:   !unmasked,
+		Filename: fmt.Sprintf("applications-%s.%s", time.Now().UTC().Format("20060102-150405"), req.Format),
+		metadata: map[string]any{
+			"format": req.Format,
+			"masked": !unmasked,
+			"filters": map[string]any{
+				"status":      req.Filters.Status,
+				"assigned":    req.Filters.Assigned,
+				"destination": req.Filters.Destination,
+				"sla":         req.Filters.SLA,
+			},
+		},
+	}
+	if req.Format == "xlsx" {
+		job.ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	} else {
+		job.ContentType = "text/csv; charset=utf-8"
+	}
+
+	log, err := usecase.Audit.Record(ctx, usecase.VisaRepo.DB, AuditEntry{
+		ActorID:      req.Filters.AgentID,
+		Action:       entity.AuditActionApplicationsExport,
+		ResourceType: "visa_application",
+		Metadata:     job.metadata,
+		IPAddress:    req.IPAddress,
+	})
+	if err != nil {
+		return nil, err
+	}
+	job.AuditLogID = log.ID
+
+	return job, nil
+}
+
+// WriteExport streams the queue to w in batches and completes the audit entry with the row count
+func (usecase *ExportUsecase) WriteExport(ctx context.Context, job *ExportJob, w io.Writer) error {
+	var writer rowWriter
+	var err error
+	if job.Format == "xlsx" {
+		writer, err = newXLSXRowWriter(w)
+	} else {
+		writer = newCSVRowWriter(w)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := writer.WriteRow(exportColumns); err != nil {
+		return err
+	}
+
+	rows := 0
+	err = usecase.VisaRepo.FindQueueInBatches(ctx, job.Filters, exportBatchSize, func(applications []entity.VisaApplication) error {
+		items, err := usecase.SLA.Evaluate(ctx, applications)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			if job.Filters.SLA != "" && item.SLA.State != job.Filters.SLA {
+				continue
+			}
+			if err := writer.WriteRow(exportRow(item, job.Masked)); err != nil {
+				return err
+			}
+			rows++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	job.metadata["rows"] = rows
+	return usecase.Audit.UpdateMetadata(ctx, job.AuditLogID, job.metadata)
+}
+
+// StreamExport writes the export from the response body stream, which outlives the request handler.
+// Failures can't change the response status anymore, so they are logged.
+func (usecase *ExportUsecase) StreamExport(job *ExportJob, w io.Writer, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := usecase.WriteExport(ctx, job, w); err != nil {
+		zap.L().Error("Failed to write applications export", zap.String("auditLogID", job.AuditLogID), zap.Error(err))
+	}
+}
+
+// Flattens an application into the export columns
+func exportRow(item QueueItem, masked bool) []string {
+	application := item.Application
+
+	row := make([]string, 0, len(exportColumns))
+	row = append(row,
+		application.ID,
+		application.User.FullName,
+		maskIf(masked, application.User.Email, pkg.MaskEmail),
+		stringOrEmpty(application.ApplicantName),
+		stringOrEmpty(application.Relationship),
+		stringOrEmpty(application.GroupID),
+		stringOrEmpty(application.Status),
+	)
+	if application.Agent != nil {
+		row = append(row, application.Agent.FullName)
+	} else {
+		row = append(row, "")
+	}
+	row = append(row,
+		application.CreatedAt.UTC().Format(time.RFC3339),
+		item.SLA.State,
+		strconv.FormatFloat(item.SLA.TimeInStatus.Hours(), 'f', 1, 64),
+	)
+
+	form := item.Form
+	if form == nil {
+		// Uploaded form only, nothing to flatten
+		return append(row, make([]string, len(exportColumns)-len(row))...)
+	}
+
+	personal := form.PersonalInfo
+	row = append(row,
+		form.Destination,
+		form.VisaType,
+		formatDate(form.TravelDate),
+		form.DurationOfStay,
+		form.Purpose,
+		strconv.FormatBool(form.HasBeenDenied),
+		maskIf(masked, personal.PassportNumber, func(value string) string { return pkg.MaskString(value, 3) }),
+		formatDate(personal.PassportExpiry),
+		personal.Nationality,
+		personal.MaritalStatus,
+		maskIf(masked, formatDate(personal.DateOfBirth), maskDate),
+		maskIf(masked, personal.ResidentialAddr, func(value string) string { return pkg.MaskString(value, 0) }),
+	)
+
+	var emergency entity.EmergencyContact
+	if form.EmergencyContact != nil {
+		emergency = *form.EmergencyContact
+	}
+	row = append(row,
+		stringOrEmpty(emergency.EmergencyName),
+		maskIf(masked, stringOrEmpty(emergency.EmergencyPhone), func(value string) string { return pkg.MaskString(value, 4) }),
+		stringOrEmpty(emergency.EmergencyRelation),
+	)
+
+	return row
+}
+
+func maskIf(masked bool, value string, mask func(string) string) string {
+	if !masked || value == "" {
+		return value
+	}
+	return mask(value)
+}
+
+// Keeps the year only, i.e "1995-05-20" => "1995-**-**"
+func maskDate(value string) string {
+	if len(value) < 4 {
+		return pkg.MaskString(value, 0)
+	}
+	return value[:4] + "-**-**"
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(visaDateLayout)
+}
+
+func stringOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// Spreadsheet apps run cells starting with these as formulas
+func escapeFormula(value string) string {
+	if value == "" {
+		return value
+	}
+	switch value[0] {
+	case '=', '@', '\t', '\r':
+		return "'" + value
+	case '+', '-':
+		if len(value) > 1 && (value[1] < '0' || value[1] > '9') {
+			return "'" + value
+		}
+	}
+	return value
+}
+
+// CSV
+
+type csvRowWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVRowWriter(w io.Writer) *csvRowWriter {
+	return &csvRowWriter{writer: csv.NewWriter(w)}
+}
+
+func (cw *csvRowWriter) WriteRow(cells []string) error {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = escapeFormula(cell)
+	}
+	return cw.writer.Write(escaped)
+}
+
+func (cw *csvRowWriter) Close() error {
+	cw.writer.Flush()
+	return cw.writer.Error()
+}
+
+// XLSX
+// The stream writer spills rows to a temp file, the zip is written to w on Close
+
+type xlsxRowWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXRowWriter(w io.Writer) (*xlsxRowWriter, error) {
+	file := excelize.NewFile()
+	stream, err := file.NewStreamWriter("Sheet1")
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &xlsxRowWriter{out: w, file: file, stream: stream}, nil
+}
+
+func (xw *xlsxRowWriter) WriteRow(cells []string) error {
+	xw.row++
+	cell, err := excelize.CoordinatesToCellName(1, xw.row)
+	if err != nil {
+		return err
+	}
+
+	values := make([]any, len(cells))
+	for i, value := range cells {
+		values[i] = value // Written as strings, never formulas
+	}
+	return xw.stream.SetRow(cell, values)
+}
+
+func (xw *xlsxRowWriter) Close() error {
+	defer xw.file.Close()
+	if err := xw.stream.Flush(); err != nil {
+		return err
+	}
+	_, err := xw.file.WriteTo(xw.out)
+	return err
+}
@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"japa/internal/app/http/dto/request"
+	"japa/internal/config"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/repository"
+	"japa/internal/pkg"
+
+	"gorm.io/gorm"
+)
+
+// Related applications flagged per kind, enough for a reviewer to see the pattern
+const maxRelatedSignals = 10
+
+// ERRORS
+
+var ErrSignalReviewed = errors.New("fraud signal was already reviewed")
+
+// TYPES
+
+// FraudUsecase screens submitted applications for duplicates and fraud signals
+type FraudUsecase struct {
+	Config config.FraudConfig
+	Repo   *repository.FraudRepository
+	DB     *gorm.DB
+}
+
+// METHODS
+
+// Initialize FraudUsecase
+func NewFraudUsecase(cfg config.FraudConfig, repo *repository.FraudRepository, db *gorm.DB) *FraudUsecase {
+	return &FraudUsecase{Config: cfg, Repo: repo, DB: db}
+}
+
+// Screen fingerprints a submitted application and records any signals for manual review.
+// Runs in the submission's transaction, signals never block the applicant.
+func (usecase *FraudUsecase) Screen(ctx context.Context, tx *gorm.DB, application *entity.VisaApplication, applicant entity.User) error {
+	fingerprints := usecase.Fingerprints(application, applicant)
+
+	// Group hashes by kind for matching
+	hashes := make(map[string][]string)
+	for _, fingerprint := range fingerprints {
+		hashes[fingerprint.Kind] = append(hashes[fingerprint.Kind], fingerprint.Hash)
+	}
+
+	var signals []entity.FraudSignal
+
+	// 1. Passport number used by another account
+	matches, err := usecase.Repo.FindMatches(ctx, tx, entity.FingerprintPassport, hashes[entity.FingerprintPassport], application.UserID, maxRelatedSignals)
+	if err != nil {
+		return err
+	}
+	for _, match := range uniqueApplications(matches) {
+		signals = append(signals, newFraudSignal(
+			application.ID,
+			entity.FraudSignalPassportReuse,
+			entity.FraudSeverityHigh,
+			match.VisaApplicationID,
+			"passport number also used on an application from another account",
+		))
+	}
+
+	// 2. Email or phone shared with another account, one signal per related application
+	shared := make(map[string][]string)
+	var related []string
+	for _, kind := range []string{entity.FingerprintEmail, entity.FingerprintPhone} {
+		matches, err := usecase.Repo.FindMatches(ctx, tx, kind, hashes[kind], application.UserID, maxRelatedSignals)
+		if err != nil {
+			return err
+		}
+		for _, match := range uniqueApplications(matches) {
+			if _, ok := shared[match.VisaApplicationID]; !ok {
+				related = append(related, match.VisaApplicationID)
+			}
+			shared[match.VisaApplicationID] = append(shared[match.VisaApplicationID], kind)
+		}
+	}
+	for _, applicationID := range related {
+		signals = append(signals, newFraudSignal(
+			application.ID,
+			entity.FraudSignalContactOverlap,
+			entity.FraudSeverityMedium,
+			applicationID,
+			strings.Join(shared[applicationID], " and ")+" also used on an application from another account",
+		))
+	}
+
+	// 3. Rapid repeat submissions, the current one is already on the timeline
+	if usecase.Config.RapidRepeatCount > 0 {
+		count, err := usecase.Repo.CountSubmissionsSince(ctx, tx, application.UserID, time.Now().Add(-usecase.Config.RapidRepeatWindow))
+		if err != nil {
+			return err
+		}
+		if count >= int64(usecase.Config.RapidRepeatCount) {
+			signals = append(signals, newFraudSignal(
+				application.ID,
+				entity.FraudSignalRapidRepeat,
+				entity.FraudSeverityLow,
+				"",
+				fmt.Sprintf("%d submissions from this account within %s", count, usecase.Config.RapidRepeatWindow),
+			))
+		}
+	}
+
+	if err := usecase.Repo.SaveFingerprints(ctx, tx, fingerprints); err != nil {
+		return err
+	}
+	return usecase.Repo.CreateSignals(ctx, tx, signals)
+}
+
+// Fingerprints builds the keyed hashes of an application's identifying values.
+// Uploaded forms only carry the account's email and phone.
+func (usecase *FraudUsecase) Fingerprints(application *entity.VisaApplication, applicant entity.User) []entity.ApplicationFingerprint {
+	var fingerprints []entity.ApplicationFingerprint
+	seen := make(map[string]bool)
+	add := func(kind string, value string) {
+		if value == "" {
+			return
+		}
+		hash := pkg.KeyedHash(usecase.Config.HashKey, kind+":"+value)
+		if seen[hash] {
+			return
+		}
+		seen[hash] = true
+		fingerprints = append(fingerprints, entity.ApplicationFingerprint{
+			VisaApplicationID: application.ID,
+			UserID:            application.UserID,
+			Kind:              kind,
+			Hash:              hash,
+		})
+	}
+
+	add(entity.FingerprintEmail, pkg.NormalizeEmail(applicant.Email))
+	add(entity.FingerprintPhone, pkg.NormalizePhone(applicant.Phone))
+
+	var form entity.VisaFormInput
+	if len(application.VisaFormInput) > 0 && json.Unmarshal(application.VisaFormInput, &form) == nil {
+		add(entity.FingerprintPassport, pkg.NormalizePassport(form.PersonalInfo.PassportNumber))
+		if form.EmergencyContact != nil && form.EmergencyContact.EmergencyPhone != nil {
+			add(entity.FingerprintPhone, pkg.NormalizePhone(*form.EmergencyContact.EmergencyPhone))
+		}
+	}
+
+	return fingerprints
+}
+
+// Fetch every signal raised on an application, reviewed ones included
+func (usecase *FraudUsecase) FetchSignals(ctx context.Context, applicationID string) ([]entity.FraudSignal, error) {
+	signals, err := usecase.Repo.FindSignals(ctx, []string{applicationID}, true)
+	if err != nil {
+		return nil, err
+	}
+	return signals[applicationID], nil
+}
+
+// Record an agent's review of a signal
+func (usecase *FraudUsecase) ReviewSignal(ctx context.Context, req request.ReviewFraudSignalRequest) (*entity.FraudSignal, error) {
+	var signal *entity.FraudSignal
+
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		signal, err = usecase.Repo.FindSignal(ctx, tx, req.ApplicationID, req.SignalID)
+		if err != nil {
+			return err
+		}
+		if signal.Resolution != nil {
+			return ErrSignalReviewed
+		}
+
+		now := time.Now()
+		signal.Resolution = &req.Resolution
+		signal.ReviewNote = req.Note
+		signal.ReviewedBy = &req.AgentID
+		signal.ReviewedAt = &now
+
+		return usecase.Repo.ReviewSignal(ctx, tx, signal) // commit on nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return signal, nil
+}
+
+// One match per related application, keeping the newest
+func uniqueApplications(matches []entity.ApplicationFingerprint) []entity.ApplicationFingerprint {
+	seen := make(map[string]bool, len(matches))
+	unique := matches[:0]
+	for _, match := range matches {
+		if !seen[match.VisaApplicationID] {
+			seen[match.VisaApplicationID] = true
+			unique = append(unique, match)
+		}
+	}
+	return unique
+}
+
+func newFraudSignal(applicationID string, kind string, severity string, relatedApplicationID string, detail string) entity.FraudSignal {
+	signal := entity.FraudSignal{
+		VisaApplicationID: applicationID,
+		Kind:              kind,
+		Severity:          severity,
+		Detail:            detail,
+	}
+	if relatedApplicationID != "" {
+		signal.RelatedApplicationID = &relatedApplicationID
+	}
+	return signal
+}
@@ -0,0 +1,369 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"japa/internal/config"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/repository"
+	"japa/internal/infrastructure/mail"
+	"japa/internal/infrastructure/payment"
+	"japa/internal/pkg"
+
+	"github.com/oklog/ulid/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// TYPES
+
+// InvoiceUsecase issues numbered invoices for successful payments and sends them as PDFs
+type InvoiceUsecase struct {
+	Config     config.BillingConfig
+	SiteConfig config.SiteConfig
+	Repo       *repository.InvoiceRepository
+	Coupons    *repository.CouponRepository
+	DB         *gorm.DB
+	Mailer     *mailer.ResponsiveMailer
+}
+
+// What a settled payment's metadata says it paid for
+type invoiceMetadata struct {
+	PlanID             uint        `json:"plan_id"`
+	Renewal            bool        `json:"renewal"`
+	Change             bool        `json:"change"`
+	VisaApplicationIDs []string    `json:"visa_application_ids"`
+	Lines              []QuoteLine `json:"lines"` // Application checkouts
+}
+
+// METHODS
+
+// Initialize InvoiceUsecase
+func NewInvoiceUsecase(
+	cfg        config.BillingConfig,
+	siteConfig config.SiteConfig,
+	repo       *repository.InvoiceRepository,
+	coupons    *repository.CouponRepository,
+	db         *gorm.DB,
+	mailer     *mailer.ResponsiveMailer,
+) *InvoiceUsecase {
+	return &InvoiceUsecase{Config: cfg, SiteConfig: siteConfig, Repo: repo, Coupons: coupons, DB: db, Mailer: mailer}
+}
+
+// HandleSettlement issues the invoice for a successful payment, registered for every purpose.
+// The number is taken in the settlement's transaction, the PDF is emailed after commit.
+func (usecase *InvoiceUsecase) HandleSettlement(ctx context.Context, tx *gorm.DB, record *entity.Payment, verification *payment.Verification) (func(), error) {
+	if record.Status != entity.PaymentStatusSucceeded {
+		return nil, nil
+	}
+	_, err := usecase.Repo.FindByPayment(ctx, tx, record.ID)
+	if err == nil {
+		return nil, nil // Already issued
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var user entity.User
+	if err := tx.WithContext(ctx).Select("id", "full_name", "email").First(&user, "id = ?", record.UserID).Error; err != nil {
+		return nil, err
+	}
+	lines, err := usecase.lines(ctx, tx, record)
+	if err != nil {
+		return nil, err
+	}
+	jsonLines, err := json.Marshal(lines)
+	if err != nil {
+		return nil, err
+	}
+
+	paidAt := record.CreatedAt
+	if record.PaidAt != nil {
+		paidAt = *record.PaidAt
+	}
+	series := fmt.Sprintf("%s-%d", usecase.Config.InvoicePrefix, paidAt.Year())
+	sequence, err := usecase.Repo.NextNumber(ctx, tx, series)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prices include tax, it is worked back out of what was paid
+	tax := record.Amount - int64(math.Round(float64(record.Amount)*10000/float64(10000+usecase.Config.TaxRate)))
+	invoice := &entity.Invoice{
+		ID:           ulid.Make().String(),
+		Number:       fmt.Sprintf("%s-%06d", series, sequence),
+		UserID:       record.UserID,
+		PaymentID:    record.ID,
+		Purpose:      record.Purpose,
+		BillingName:  user.FullName,
+		BillingEmail: user.Email,
+		Lines:        jsonLines,
+		Currency:     record.Currency,
+		Subtotal:     record.Amount - tax,
+		TaxName:      usecase.Config.TaxName,
+		TaxRate:      usecase.Config.TaxRate,
+		Tax:          tax,
+		Total:        record.Amount,
+		PaidAt:       paidAt,
+	}
+	if err := usecase.Repo.Create(ctx, tx, invoice); err != nil {
+		return nil, err
+	}
+	return func() { usecase.send(invoice) }, nil
+}
+
+// Fetch the user's invoices, newest first
+func (usecase *InvoiceUsecase) FetchInvoices(ctx context.Context, userID string, page int, limit int) ([]entity.Invoice, int64, error) {
+	return usecase.Repo.FindByUser(ctx, userID, limit, (page-1)*limit)
+}
+
+// Render one of the user's invoices as a PDF
+func (usecase *InvoiceUsecase) FetchPDF(ctx context.Context, userID string, invoiceID string) (*entity.Invoice, []byte, error) {
+	invoice, err := usecase.Repo.FindForUser(ctx, userID, invoiceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	pdf, err := usecase.Render(invoice)
+	if err != nil {
+		return nil, nil, err
+	}
+	return invoice, pdf, nil
+}
+
+// Email one of the user's invoices again
+func (usecase *InvoiceUsecase) Resend(ctx context.Context, userID string, invoiceID string) (*entity.Invoice, error) {
+	invoice, err := usecase.Repo.FindForUser(ctx, userID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	usecase.send(invoice)
+	return invoice, nil
+}
+
+// Render lays the invoice out on an A4 page, continuing on more pages for long invoices
+func (usecase *InvoiceUsecase) Render(invoice *entity.Invoice) ([]byte, error) {
+	lines, err := invoice.LineItems()
+	if err != nil {
+		return nil, err
+	}
+	money := func(amount int64) string {
+		return pkg.FormatMoneyCode(amount, invoice.Currency)
+	}
+
+	const left, right = 50.0, 545.0
+	document := pkg.NewPDFDocument("Invoice " + invoice.Number)
+
+	// Seller and invoice details
+	document.Text(left, 70, 20, true, usecase.SiteConfig.SiteName)
+	document.TextRight(right, 70, 20, true, "INVOICE")
+	seller := []string{usecase.Config.Address, usecase.SiteConfig.SiteEmail, usecase.SiteConfig.SiteDomain}
+	if usecase.Config.TaxNumber != "" {
+		seller = append(seller, usecase.Config.TaxName+" no. "+usecase.Config.TaxNumber)
+	}
+	y := 92.0
+	for _, detail := range seller {
+		if detail != "" {
+			document.Text(left, y, 9, false, detail)
+			y += 13
+		}
+	}
+	document.TextRight(right, 92, 9, false, "Number: "+invoice.Number)
+	document.TextRight(right, 105, 9, false, "Date: "+invoice.PaidAt.Format("02 Jan 2006"))
+	document.TextRight(right, 118, 9, true, "PAID")
+
+	// Customer
+	y = max(y, 131) + 25
+	document.Text(left, y, 10, true, "Billed to")
+	document.Text(left, y+14, 10, false, invoice.BillingName)
+	document.Text(left, y+28, 10, false, invoice.BillingEmail)
+
+	// Items
+	header := func(y float64) {
+		document.Text(left, y, 9, true, "Description")
+		document.TextRight(360, y, 9, true, "Qty")
+		document.TextRight(450, y, 9, true, "Unit price")
+		document.TextRight(right, y, 9, true, "Amount")
+		document.Line(left, y+6, right, y+6, 0.5)
+	}
+	y += 65
+	header(y)
+	y += 22
+	for _, line := range lines {
+		if y > 760 {
+			document.AddPage()
+			y = 70
+			header(y)
+			y += 22
+		}
+		document.Text(left, y, 9, false, fitPDFText(line.Description, 9, 280))
+		document.TextRight(360, y, 9, false, strconv.Itoa(line.Quantity))
+		document.TextRight(450, y, 9, false, money(line.UnitAmount))
+		document.TextRight(right, y, 9, false, money(line.Amount))
+		y += 18
+	}
+	document.Line(left, y-8, right, y-8, 0.5)
+
+	// Totals
+	if y > 740 {
+		document.AddPage()
+		y = 70
+	}
+	y += 8
+	if invoice.TaxRate > 0 {
+		document.TextRight(450, y, 9, false, "Subtotal")
+		document.TextRight(right, y, 9, false, money(invoice.Subtotal))
+		y += 15
+		rate := strconv.FormatFloat(float64(invoice.TaxRate)/100, 'f', -1, 64)
+		document.TextRight(450, y, 9, false, fmt.Sprintf("%s (%s%%)", invoice.TaxName, rate))
+		document.TextRight(right, y, 9, false, money(invoice.Tax))
+		y += 15
+	}
+	document.TextRight(450, y+2, 11, true, "Total")
+	document.TextRight(right, y+2, 11, true, money(invoice.Total))
+	y += 30
+
+	document.Text(left, y, 9, false, "Paid in full on "+invoice.PaidAt.Format("02 Jan 2006 15:04 MST")+". Payment "+invoice.PaymentID+".")
+	if invoice.TaxRate > 0 {
+		document.Text(left, y+13, 9, false, "Prices include "+invoice.TaxName+".")
+	}
+
+	return document.Bytes(), nil
+}
+
+// The invoice's items. Application checkouts carry their quote, plan payments are priced from the plan.
+// Coupon discounts and account credit come off as negative lines so the lines add up to what was paid.
+func (usecase *InvoiceUsecase) lines(ctx context.Context, tx *gorm.DB, record *entity.Payment) ([]entity.InvoiceLine, error) {
+	var metadata invoiceMetadata
+	if len(record.Metadata) > 0 {
+		if err := json.Unmarshal(record.Metadata, &metadata); err != nil {
+			return nil, err
+		}
+	}
+	redemptions, err := usecase.Coupons.FindRedeemedByPayment(ctx, tx, record.ID)
+	if err != nil {
+		return nil, err
+	}
+	var discount int64
+	for _, redemption := range redemptions {
+		discount += redemption.Discount
+	}
+
+	var lines []entity.InvoiceLine
+	switch {
+	case record.Purpose == entity.PaymentPurposeApplication && len(metadata.Lines) > 0:
+		for _, line := range metadata.Lines {
+			lines = append(lines, invoiceLine(line.Name, line.Quantity, line.UnitAmount))
+		}
+	case record.Purpose == entity.PaymentPurposeApplication:
+		// Checkouts from before quotes were kept
+		applicants := max(len(metadata.VisaApplicationIDs), 1)
+		lines = append(lines, entity.InvoiceLine{
+			Description: "Visa application",
+			Quantity:    applicants,
+			UnitAmount:  (record.Amount + discount) / int64(applicants),
+			Amount:      record.Amount + discount,
+		})
+	case record.Purpose == entity.PaymentPurposeSubscription && metadata.PlanID != 0:
+		var plan entity.Plan
+		if err := tx.WithContext(ctx).Preload("PlanPrices").First(&plan, "id = ?", metadata.PlanID).Error; err != nil {
+			return nil, err
+		}
+		switch {
+		case metadata.Change:
+			lines = append(lines, invoiceLine(fmt.Sprintf("Change to the %s plan, prorated to the end of the period", plan.Name), 1, record.Amount))
+		case metadata.Renewal:
+			lines = append(lines, invoiceLine(fmt.Sprintf("%s plan renewal (%s)", plan.Name, plan.BillingCycle), 1, planAmount(&plan, record.Currency)))
+		default:
+			lines = append(lines, invoiceLine(fmt.Sprintf("%s plan (%s)", plan.Name, plan.BillingCycle), 1, planAmount(&plan, record.Currency)))
+		}
+	default:
+		lines = append(lines, invoiceLine("Payment", 1, record.Amount+discount))
+	}
+
+	for _, redemption := range redemptions {
+		lines = append(lines, invoiceLine("Discount ("+redemption.Coupon.Code+")", 1, -redemption.Discount))
+	}
+
+	// Whatever the lines don't account for was paid from account credit
+	var total int64
+	for _, line := range lines {
+		total += line.Amount
+	}
+	if credit := total - record.Amount; credit > 0 {
+		lines = append(lines, invoiceLine("Account credit", 1, -credit))
+	} else if credit < 0 {
+		zap.L().Warn("Invoice lines are short of the payment", zap.String("paymentID", record.ID), zap.Int64("difference", -credit))
+		lines = append(lines, invoiceLine("Adjustment", 1, -credit))
+	}
+	return lines, nil
+}
+
+// send emails the invoice PDF in the background and records when it went out.
+// A failed delivery is logged, never returned.
+func (usecase *InvoiceUsecase) send(invoice *entity.Invoice) {
+	if usecase.Mailer == nil || invoice.BillingEmail == "" {
+		return
+	}
+
+	go func() {
+		// Recover from any unexpected panic so the app doesn't crash
+		defer func() {
+			if panicErr := recover(); panicErr != nil {
+				zap.L().Error("Panic recovered while sending invoice email", zap.Any("error", panicErr))
+			}
+		}()
+
+		pdf, err := usecase.Render(invoice)
+		if err != nil {
+			zap.L().Error("Failed to render invoice", zap.String("invoiceID", invoice.ID), zap.Error(err))
+			return
+		}
+		emailData := mailer.InvoiceMail(invoice.BillingName, "Your receipt "+invoice.Number,
+			"Thank you for your payment. Here is your invoice.",
+			[]string{
+				"Invoice: " + invoice.Number,
+				"Amount paid: " + pkg.FormatMoney(invoice.Total, invoice.Currency),
+				"Date: " + invoice.PaidAt.Format("02 Jan 2006"),
+			},
+			mailer.Attachment{
+				Filename:    invoice.Number + ".pdf",
+				ContentType: "application/pdf",
+				Data:        pdf,
+			})
+
+		if err := usecase.Mailer.Send(invoice.BillingEmail, emailData); err != nil {
+			zap.L().Error("Failed to send invoice email", zap.String("invoiceID", invoice.ID), zap.Error(err))
+			return
+		}
+		if err := usecase.Repo.MarkEmailed(context.Background(), invoice.ID, time.Now()); err != nil {
+			zap.L().Error("Failed to mark invoice emailed", zap.String("invoiceID", invoice.ID), zap.Error(err))
+		}
+	}()
+}
+
+func invoiceLine(description string, quantity int, unitAmount int64) entity.InvoiceLine {
+	return entity.InvoiceLine{
+		Description: description,
+		Quantity:    quantity,
+		UnitAmount:  unitAmount,
+		Amount:      unitAmount * int64(quantity),
+	}
+}
+
+// Cuts text down to width points, ending it with "..."
+func fitPDFText(text string, size float64, width float64) string {
+	if pkg.PDFTextWidth(text, size) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pkg.PDFTextWidth(string(runes)+"...", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
@@ -0,0 +1,392 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"japa/internal/config"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/repository"
+	"japa/internal/infrastructure/payment"
+
+	"github.com/oklog/ulid/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ERRORS
+
+var (
+	ErrLedgerUnbalanced = errors.New("ledger transaction does not balance")
+	ErrReconcileTooSoon = errors.New("day has not settled yet")
+)
+
+// TYPES
+
+// LedgerUsecase posts money movements to the double-entry ledger and reconciles it with the providers.
+// Postings run inside the transaction that moves the money, so the ledger never disagrees with payments.
+type LedgerUsecase struct {
+	Config   config.LedgerConfig
+	Repo     *repository.LedgerRepository
+	Provider *payment.ResponsivePaymentProvider
+	DB       *gorm.DB
+}
+
+// METHODS
+
+// Initialize LedgerUsecase
+func NewLedgerUsecase(cfg config.LedgerConfig, repo *repository.LedgerRepository, provider *payment.ResponsivePaymentProvider, db *gorm.DB) *LedgerUsecase {
+	return &LedgerUsecase{Config: cfg, Repo: repo, Provider: provider, DB: db}
+}
+
+// Post writes a balanced transaction in tx. Zero entries are dropped,
+// and a source that is already posted is left as it is.
+func (usecase *LedgerUsecase) Post(ctx context.Context, tx *gorm.DB, transaction *entity.LedgerTransaction) error {
+	var sum int64
+	entries := make([]entity.LedgerEntry, 0, len(transaction.Entries))
+	for _, entry := range transaction.Entries {
+		if entry.Currency != transaction.Currency {
+			return fmt.Errorf("%w: %s entry in a %s transaction", ErrLedgerUnbalanced, entry.Currency, transaction.Currency)
+		}
+		if entry.Amount == 0 {
+			continue
+		}
+		sum += entry.Amount
+		entry.ID = ulid.Make().String()
+		entry.TransactionID = transaction.ID
+		entry.OccurredAt = transaction.OccurredAt
+		entries = append(entries, entry)
+	}
+	if sum != 0 || len(entries) == 0 {
+		return fmt.Errorf("%w: %s %s is off by %d", ErrLedgerUnbalanced, transaction.Kind, transaction.SourceID, sum)
+	}
+	transaction.Entries = entries
+
+	_, err := usecase.Repo.CreateTransaction(ctx, tx, transaction)
+	return err
+}
+
+// HandleSettlement is the settlement handler that posts succeeded payments: the provider
+// collected the amount as revenue and kept its fee from it
+func (usecase *LedgerUsecase) HandleSettlement(ctx context.Context, tx *gorm.DB, record *entity.Payment, verification *payment.Verification) (func(), error) {
+	if record.Status != entity.PaymentStatusSucceeded {
+		return nil, nil
+	}
+
+	clearing := entity.LedgerProviderClearing + ":" + record.Provider
+	return nil, usecase.Post(ctx, tx, &entity.LedgerTransaction{
+		ID:          ulid.Make().String(),
+		Kind:        entity.LedgerKindPayment,
+		SourceID:    record.ID,
+		PaymentID:   &record.ID,
+		Currency:    record.Currency,
+		Description: "Payment " + record.Reference + " (" + record.Purpose + ")",
+		OccurredAt:  paidAt(record),
+		Entries: []entity.LedgerEntry{
+			{Account: clearing, Amount: record.Amount, Currency: record.Currency},
+			{Account: entity.LedgerPlatformRevenue, Amount: -record.Amount, Currency: record.Currency},
+			{Account: entity.LedgerProviderFees, Amount: record.Fee, Currency: record.Currency},
+			{Account: clearing, Amount: -record.Fee, Currency: record.Currency},
+		},
+	})
+}
+
+// HandleRefund is the refund handler that posts processed refunds and chargebacks,
+// the money goes back out of what the provider holds for us
+func (usecase *LedgerUsecase) HandleRefund(ctx context.Context, tx *gorm.DB, record *entity.Payment, refund *entity.Refund) (func(), error) {
+	occurredAt := time.Now()
+	if refund.ProcessedAt != nil {
+		occurredAt = *refund.ProcessedAt
+	}
+	description := "Refund of payment " + record.Reference
+	if refund.Kind == entity.RefundKindChargeback {
+		description = "Chargeback of payment " + record.Reference
+	}
+
+	return nil, usecase.Post(ctx, tx, &entity.LedgerTransaction{
+		ID:          ulid.Make().String(),
+		Kind:        entity.LedgerKindRefund,
+		SourceID:    refund.ID,
+		PaymentID:   &record.ID,
+		Currency:    refund.Currency,
+		Description: description,
+		OccurredAt:  occurredAt,
+		Entries: []entity.LedgerEntry{
+			{Account: entity.LedgerRefunds, Amount: refund.Amount, Currency: refund.Currency},
+			{Account: entity.LedgerProviderClearing + ":" + record.Provider, Amount: -refund.Amount, Currency: refund.Currency},
+		},
+	})
+}
+
+// PostCommissions posts commission earnings as owed to partners, reversals are negative and take them back
+func (usecase *LedgerUsecase) PostCommissions(ctx context.Context, tx *gorm.DB, commissions []entity.Commission) error {
+	for i := range commissions {
+		commission := &commissions[i]
+		occurredAt := commission.CreatedAt
+		if occurredAt.IsZero() {
+			occurredAt = time.Now()
+		}
+
+		err := usecase.Post(ctx, tx, &entity.LedgerTransaction{
+			ID:          ulid.Make().String(),
+			Kind:        entity.LedgerKindCommission,
+			SourceID:    commission.ID,
+			PaymentID:   &commission.PaymentID,
+			Currency:    commission.Currency,
+			Description: "Partner commission " + commission.Kind + " (" + commission.Product + ")",
+			OccurredAt:  occurredAt,
+			Entries: []entity.LedgerEntry{
+				{Account: entity.LedgerCommissionExpense, Amount: commission.Amount, Currency: commission.Currency},
+				{Account: entity.LedgerPartnerPayable, Amount: -commission.Amount, Currency: commission.Currency},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PostPayout posts a paid partner payout, it settles what was owed out of what the provider holds.
+// The transfer fee is on top of the amount.
+func (usecase *LedgerUsecase) PostPayout(ctx context.Context, tx *gorm.DB, payout *entity.Payout) error {
+	occurredAt := time.Now()
+	if payout.PaidAt != nil {
+		occurredAt = *payout.PaidAt
+	}
+
+	clearing := entity.LedgerProviderClearing + ":" + payout.Provider
+	return usecase.Post(ctx, tx, &entity.LedgerTransaction{
+		ID:          ulid.Make().String(),
+		Kind:        entity.LedgerKindPayout,
+		SourceID:    payout.ID,
+		Currency:    payout.Currency,
+		Description: "Partner payout " + payout.Reference,
+		OccurredAt:  occurredAt,
+		Entries: []entity.LedgerEntry{
+			{Account: entity.LedgerPartnerPayable, Amount: payout.Amount, Currency: payout.Currency},
+			{Account: clearing, Amount: -payout.Amount, Currency: payout.Currency},
+			{Account: entity.LedgerProviderFees, Amount: payout.Fee, Currency: payout.Currency},
+			{Account: clearing, Amount: -payout.Fee, Currency: payout.Currency},
+		},
+	})
+}
+
+// Fetch account balances by currency as they were at at, currency is optional
+func (usecase *LedgerUsecase) FetchBalances(ctx context.Context, at time.Time, currency string) ([]repository.LedgerBalance, error) {
+	return usecase.Repo.Balances(ctx, at, currency)
+}
+
+// Fetch an account's entries, newest first
+func (usecase *LedgerUsecase) FetchEntries(ctx context.Context, account string, currency string, page int, limit int) ([]entity.LedgerEntry, int64, error) {
+	return usecase.Repo.FindEntries(ctx, account, currency, limit, (page-1)*limit)
+}
+
+// Fetch reconciliation reports, latest day first
+func (usecase *LedgerUsecase) FetchReconciliations(ctx context.Context, provider string, page int, limit int) ([]entity.LedgerReconciliation, int64, error) {
+	return usecase.Repo.FindReconciliations(ctx, provider, limit, (page-1)*limit)
+}
+
+// Reconcile reports on every settled day in the last ReconcileDays for each provider that
+// can list its transactions. Days that matched are final, the others are run again.
+func (usecase *LedgerUsecase) Reconcile(ctx context.Context) error {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	for _, provider := range usecase.Provider.Reporters() {
+		for back := usecase.Config.ReconcileDays; back >= 1; back-- {
+			day := today.AddDate(0, 0, -back)
+			if day.AddDate(0, 0, 1).Add(usecase.Config.SettleAfter).After(now) {
+				continue
+			}
+
+			report, err := usecase.Repo.FindReconciliation(ctx, provider, day.Format(time.DateOnly))
+			if err == nil && report.Status == entity.ReconciliationMatched {
+				continue
+			}
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			if _, err := usecase.ReconcileDay(ctx, provider, day); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ReconcileDay compares what the provider collected on a UTC day with the payments the ledger has
+// on its clearing account, and saves the day's report. A provider outage is a failed report, not an error.
+func (usecase *LedgerUsecase) ReconcileDay(ctx context.Context, provider string, day time.Time) (*entity.LedgerReconciliation, error) {
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+	if to.Add(usecase.Config.SettleAfter).After(time.Now()) {
+		return nil, ErrReconcileTooSoon
+	}
+	if !containsString(usecase.Provider.Reporters(), provider) {
+		return nil, fmt.Errorf("%w: %s", payment.ErrSettlementsNotSupported, provider)
+	}
+
+	report, err := usecase.Repo.FindReconciliation(ctx, provider, from.Format(time.DateOnly))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		report = &entity.LedgerReconciliation{ID: ulid.Make().String(), Provider: provider, Day: from.Format(time.DateOnly)}
+	} else if err != nil {
+		return nil, err
+	}
+	report.RunAt = time.Now()
+	report.Error = nil
+
+	settled, err := usecase.Provider.SettledTransactions(ctx, provider, from, to)
+	if err != nil {
+		message := err.Error()
+		report.Status = entity.ReconciliationFailed
+		report.Error = &message
+		zap.L().Warn("Ledger reconciliation failed", zap.String("provider", provider), zap.String("day", report.Day), zap.Error(err))
+		return report, usecase.Repo.SaveReconciliation(ctx, report)
+	}
+
+	posted, err := usecase.Repo.FindPostedPayments(ctx, provider, from, to)
+	if err != nil {
+		return nil, err
+	}
+	ledger := postedByReference(posted)
+
+	// Paid just across midnight on one side, or posted late
+	var unmatched []string
+	for _, transaction := range settled {
+		if _, ok := ledger[transaction.Reference]; !ok {
+			unmatched = append(unmatched, transaction.Reference)
+		}
+	}
+	elsewhere, err := usecase.Repo.FindPostedByReferences(ctx, provider, unmatched)
+	if err != nil {
+		return nil, err
+	}
+	others := postedByReference(elsewhere)
+
+	totals, mismatches := reconcile(settled, ledger, others)
+	report.Status = entity.ReconciliationMatched
+	if len(mismatches) > 0 {
+		report.Status = entity.ReconciliationMismatched
+		zap.L().Warn("Ledger does not match provider settlements",
+			zap.String("provider", provider), zap.String("day", report.Day), zap.Int("mismatches", len(mismatches)))
+	}
+	if report.Totals, err = json.Marshal(totals); err != nil {
+		return nil, err
+	}
+	if report.Mismatches, err = json.Marshal(mismatches); err != nil {
+		return nil, err
+	}
+	return report, usecase.Repo.SaveReconciliation(ctx, report)
+}
+
+// What the ledger says one payment collected, minor units
+type postedPayment struct {
+	Currency string
+	Amount   int64
+	Fee      int64
+}
+
+// Payment postings by payment reference
+func postedByReference(transactions []entity.LedgerTransaction) map[string]postedPayment {
+	posted := make(map[string]postedPayment, len(transactions))
+	for _, transaction := range transactions {
+		if transaction.Payment == nil {
+			continue
+		}
+		payment := postedPayment{Currency: transaction.Currency}
+		for _, entry := range transaction.Entries {
+			switch entry.Account {
+			case entity.LedgerPlatformRevenue:
+				payment.Amount -= entry.Amount
+			case entity.LedgerProviderFees:
+				payment.Fee += entry.Amount
+			}
+		}
+		posted[transaction.Payment.Reference] = payment
+	}
+	return posted
+}
+
+// Compares the provider's transactions for a day with the ledger's payments for the same day.
+// others are payments the ledger has on other days, matched by reference without counting in the day's totals.
+func reconcile(settled []payment.Verification, ledger map[string]postedPayment, others map[string]postedPayment) ([]entity.ReconciliationTotal, []entity.ReconciliationMismatch) {
+	totals := map[string]*entity.ReconciliationTotal{}
+	total := func(currency string) *entity.ReconciliationTotal {
+		if totals[currency] == nil {
+			totals[currency] = &entity.ReconciliationTotal{Currency: currency}
+		}
+		return totals[currency]
+	}
+
+	mismatches := []entity.ReconciliationMismatch{}
+	seen := make(map[string]bool, len(settled))
+	for _, transaction := range settled {
+		seen[transaction.Reference] = true
+		providerTotal := total(transaction.Currency)
+		providerTotal.ProviderCount++
+		providerTotal.ProviderAmount += transaction.Amount
+		providerTotal.ProviderFees += transaction.Fee
+
+		posted, ok := ledger[transaction.Reference]
+		if !ok {
+			posted, ok = others[transaction.Reference]
+		}
+		mismatch := entity.ReconciliationMismatch{
+			Reference:      transaction.Reference,
+			Currency:       transaction.Currency,
+			ProviderAmount: transaction.Amount,
+			ProviderFee:    transaction.Fee,
+			LedgerAmount:   posted.Amount,
+			LedgerFee:      posted.Fee,
+		}
+		switch {
+		case !ok:
+			mismatch.Issue = entity.MismatchMissingInLedger
+		case posted.Currency != transaction.Currency || posted.Amount != transaction.Amount:
+			mismatch.Issue = entity.MismatchAmount
+		case posted.Fee != transaction.Fee:
+			mismatch.Issue = entity.MismatchFee
+		default:
+			continue
+		}
+		mismatches = append(mismatches, mismatch)
+	}
+
+	for reference, posted := range ledger {
+		ledgerTotal := total(posted.Currency)
+		ledgerTotal.LedgerCount++
+		ledgerTotal.LedgerAmount += posted.Amount
+		ledgerTotal.LedgerFees += posted.Fee
+		if !seen[reference] {
+			mismatches = append(mismatches, entity.ReconciliationMismatch{
+				Reference:    reference,
+				Issue:        entity.MismatchMissingAtProvider,
+				Currency:     posted.Currency,
+				LedgerAmount: posted.Amount,
+				LedgerFee:    posted.Fee,
+			})
+		}
+	}
+
+	list := make([]entity.ReconciliationTotal, 0, len(totals))
+	for _, currencyTotal := range totals {
+		list = append(list, *currencyTotal)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Currency < list[j].Currency })
+	sort.Slice(mismatches, func(i, j int) bool { return mismatches[i].Reference < mismatches[j].Reference })
+	return list, mismatches
+}
+
+// When a payment's money moved, its creation for older rows without a paid time
+func paidAt(record *entity.Payment) time.Time {
+	if record.PaidAt != nil {
+		return *record.PaidAt
+	}
+	return record.CreatedAt
+}
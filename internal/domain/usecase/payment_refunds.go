@@ -0,0 +1,483 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"japa/internal/app/http/dto/request"
+	"japa/internal/domain/entity"
+	"japa/internal/infrastructure/mail"
+	"japa/internal/infrastructure/payment"
+	"japa/internal/pkg"
+
+	"github.com/oklog/ulid/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ERRORS
+
+var (
+	ErrNotRefundable = errors.New("only succeeded payments can be refunded")
+	ErrRefundExceeds = errors.New("refund is more than what is left of the payment")
+)
+
+// TYPES
+
+// RefundHandler takes back what a refund gives money back for, inside the transaction that completes it.
+// It runs once per processed refund or chargeback, record already carries the new refunded total.
+// The returned func, when not nil, runs after commit.
+type RefundHandler func(ctx context.Context, tx *gorm.DB, record *entity.Payment, refund *entity.Refund) (func(), error)
+
+// METHODS
+
+// OnRefunded registers what is reversed when a payment for purpose is refunded
+func (usecase *PaymentUsecase) OnRefunded(purpose string, handler RefundHandler) {
+	usecase.RefundHandlers[purpose] = handler
+}
+
+// OnAnyRefunded registers what else is reversed when any payment is refunded, after the purpose's own handler
+func (usecase *PaymentUsecase) OnAnyRefunded(handler RefundHandler) {
+	usecase.RefundObservers = append(usecase.RefundObservers, handler)
+}
+
+// Refund gives part or all of a succeeded payment back through its provider, a zero amount refunds what is left.
+// The amount is reserved before the provider is asked, so concurrent refunds can't add up to more than was paid.
+// What the payment paid for is reversed once the provider processes the refund, now or later by webhook.
+func (usecase *PaymentUsecase) Refund(ctx context.Context, req request.RefundPaymentRequest) (*entity.Refund, error) {
+	var record *entity.Payment
+	var refund *entity.Refund
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		record, err = usecase.Repo.LockByReference(ctx, tx, req.Reference)
+		if err != nil {
+			return err // rollback
+		}
+		if record.Status != entity.PaymentStatusSucceeded {
+			return ErrNotRefundable // rollback
+		}
+
+		left, err := usecase.refundable(ctx, tx, record)
+		if err != nil {
+			return err // rollback
+		}
+		amount := req.Amount
+		if amount == 0 {
+			amount = left
+		}
+		if amount <= 0 || amount > left {
+			return fmt.Errorf("%w: %s left", ErrRefundExceeds, pkg.FormatMoney(left, record.Currency)) // rollback
+		}
+
+		var initiatedBy *string // Nil for refunds the system makes
+		if req.AdminID != "" {
+			initiatedBy = &req.AdminID
+		}
+		refund = &entity.Refund{
+			ID:          ulid.Make().String(),
+			PaymentID:   record.ID,
+			Kind:        entity.RefundKindRefund,
+			Provider:    record.Provider,
+			Amount:      amount,
+			Currency:    record.Currency,
+			Reason:      req.Reason,
+			Status:      entity.RefundStatusPending,
+			InitiatedBy: initiatedBy,
+		}
+		return usecase.Repo.CreateRefund(ctx, tx, refund) // commit on nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var providerReference string
+	if record.ProviderReference != nil {
+		providerReference = *record.ProviderReference
+	}
+	result, err := usecase.Provider.Refund(ctx, record.Provider, payment.RefundRequest{
+		Reference:         record.Reference,
+		ProviderReference: providerReference,
+		Amount:            refund.Amount,
+		Currency:          refund.Currency,
+		Reason:            refund.Reason,
+	})
+	var rejection *payment.ProviderError
+	if errors.As(err, &rejection) && rejection.Rejected() {
+		// Refused, released for another try
+		providerErr := fmt.Errorf("%w: %s", ErrPaymentProvider, err.Error())
+		refund, applyErr := usecase.applyRefund(ctx, refund, &payment.Refund{Status: payment.RefundFailed}, providerErr.Error())
+		if applyErr != nil {
+			return nil, applyErr
+		}
+		return refund, providerErr
+	}
+	if err != nil {
+		// The refund may have gone through. It stays pending, holding the amount, until reconciliation finds it by the payment's reference.
+		zap.L().Warn("Refund outcome unknown", zap.String("refundID", refund.ID), zap.Error(err))
+		return refund, nil
+	}
+
+	return usecase.applyRefund(ctx, refund, result, "")
+}
+
+// Fetch a payment with its refunds and disputes, for admins
+func (usecase *PaymentUsecase) FetchRefunds(ctx context.Context, reference string) (*entity.Payment, []entity.Refund, []entity.PaymentDispute, error) {
+	record, err := usecase.Repo.FindByReference(ctx, usecase.DB, reference)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	refunds, err := usecase.Repo.FindRefunds(ctx, record.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	disputes, err := usecase.Repo.FindDisputes(ctx, record.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return record, refunds, disputes, nil
+}
+
+// What is left to refund: the amount paid less pending and processed refunds
+func (usecase *PaymentUsecase) refundable(ctx context.Context, tx *gorm.DB, record *entity.Payment) (int64, error) {
+	committed, err := usecase.Repo.SumRefunds(ctx, tx, record.ID)
+	if err != nil {
+		return 0, err
+	}
+	return max(record.Amount-committed, 0), nil
+}
+
+// Applies the provider's answer to a pending refund. Only pending refunds change,
+// so applying the same outcome twice is a no-op. failure explains a failed refund.
+func (usecase *PaymentUsecase) applyRefund(ctx context.Context, refund *entity.Refund, result *payment.Refund, failure string) (*entity.Refund, error) {
+	var followUp func()
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Payment first, like Refund, so the two never wait on each other
+		record, err := usecase.Repo.LockByID(ctx, tx, refund.PaymentID)
+		if err != nil {
+			return err // rollback
+		}
+		refund, err = usecase.Repo.LockRefund(ctx, tx, refund.ID)
+		if err != nil {
+			return err // rollback
+		}
+		if refund.Status != entity.RefundStatusPending {
+			return nil
+		}
+
+		if result.ProviderRefundID != "" {
+			refund.ProviderRefundID = &result.ProviderRefundID
+		}
+		switch result.Status {
+		case payment.RefundProcessed:
+			followUp, err = usecase.completeRefund(ctx, tx, record, refund)
+			return err // commit on nil
+		case payment.RefundFailed:
+			if failure == "" {
+				failure = "the provider could not refund the payment"
+			}
+			refund.Status = entity.RefundStatusFailed
+			refund.FailureReason = &failure
+		}
+		return usecase.Repo.UpdateRefund(ctx, tx, refund) // commit on nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if followUp != nil {
+		followUp()
+	}
+	return refund, nil
+}
+
+// Marks a refund processed on its locked payment and reverses what it paid for.
+// Returns the handlers' follow-ups and the customer's email, to run after commit.
+func (usecase *PaymentUsecase) completeRefund(ctx context.Context, tx *gorm.DB, record *entity.Payment, refund *entity.Refund) (func(), error) {
+	now := time.Now()
+	refund.Status = entity.RefundStatusProcessed
+	refund.ProcessedAt = &now
+	refund.FailureReason = nil
+	if err := usecase.Repo.UpdateRefund(ctx, tx, refund); err != nil {
+		return nil, err
+	}
+
+	record.RefundedAmount += refund.Amount
+	record.RefundStatus = entity.PaymentRefundPartial
+	if record.RefundedAmount >= record.Amount {
+		record.RefundStatus = entity.PaymentRefundFull
+	}
+	if err := usecase.Repo.UpdateRefundState(ctx, tx, record); err != nil {
+		return nil, err
+	}
+
+	followUps := []func(){}
+	handlers := usecase.RefundObservers
+	if handler, ok := usecase.RefundHandlers[record.Purpose]; ok {
+		handlers = append([]RefundHandler{handler}, handlers...)
+	}
+	for _, handler := range handlers {
+		followUp, err := handler(ctx, tx, record, refund)
+		if err != nil {
+			return nil, err
+		}
+		if followUp != nil {
+			followUps = append(followUps, followUp)
+		}
+	}
+
+	details := []string{
+		"Payment: " + record.Reference,
+		"Amount paid: " + pkg.FormatMoney(record.Amount, record.Currency),
+		"Amount refunded: " + pkg.FormatMoney(refund.Amount, refund.Currency),
+	}
+	if refund.Reason != "" {
+		details = append(details, "Reason: "+refund.Reason)
+	}
+	subject := "Your payment was refunded"
+	message := fmt.Sprintf("We've refunded %s of your payment. Refunds can take up to 10 working days to reach your account, depending on your bank.",
+		pkg.FormatMoney(refund.Amount, refund.Currency))
+	switch {
+	case refund.Kind == entity.RefundKindChargeback:
+		subject = "Your bank reversed a payment"
+		message = fmt.Sprintf("Your bank took back %s of your payment after a dispute, so what it paid for has been reversed.",
+			pkg.FormatMoney(refund.Amount, refund.Currency))
+	case record.RefundStatus == entity.PaymentRefundFull:
+		message += " The payment is refunded in full, so what it paid for has been reversed."
+	}
+	followUps = append(followUps, func() { usecase.notify(record.UserID, subject, message, details) })
+
+	return func() {
+		for _, followUp := range followUps {
+			followUp()
+		}
+	}, nil
+}
+
+// Asks the provider about the payment's pending refunds and applies their outcome
+func (usecase *PaymentUsecase) syncRefunds(ctx context.Context, refunds []entity.Refund) error {
+	var lastErr error
+	for i := range refunds {
+		refund := &refunds[i]
+		var result *payment.Refund
+		var err error
+		failure := "the provider could not refund the payment"
+		if refund.ProviderRefundID != nil {
+			result, err = usecase.Provider.VerifyRefund(ctx, refund.Provider, *refund.ProviderRefundID)
+		} else {
+			result, err = usecase.findRefund(ctx, refund)
+			failure = "the provider has no record of the refund"
+		}
+		if err == nil && result != nil {
+			_, err = usecase.applyRefund(ctx, refund, result, failure)
+		}
+		if errors.Is(err, payment.ErrRefundListNotSupported) {
+			zap.L().Info("Unconfirmed refund left for an admin to check with the provider", zap.String("refundID", refund.ID))
+			continue
+		}
+		if err != nil {
+			zap.L().Warn("Refund sync failed", zap.String("refundID", refund.ID), zap.Error(err))
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// Finds a refund whose request got no answer among the refunds the provider made on its payment,
+// by amount, skipping refunds already matched to another. A refund the provider still has no record
+// of past the reconcile delay never reached it and fails. Nil when it's too early to tell.
+func (usecase *PaymentUsecase) findRefund(ctx context.Context, refund *entity.Refund) (*payment.Refund, error) {
+	record, err := usecase.Repo.FindByID(ctx, usecase.DB, refund.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+	var providerReference string
+	if record.ProviderReference != nil {
+		providerReference = *record.ProviderReference
+	}
+	listed, err := usecase.Provider.TransactionRefunds(ctx, refund.Provider, record.Reference, providerReference)
+	if err != nil {
+		return nil, err
+	}
+	known, err := usecase.Repo.FindRefunds(ctx, record.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := map[string]bool{}
+	for _, other := range known {
+		if other.ProviderRefundID != nil {
+			claimed[*other.ProviderRefundID] = true
+		}
+	}
+	for i := range listed {
+		if listed[i].Amount == refund.Amount && !claimed[listed[i].ProviderRefundID] {
+			return &listed[i], nil
+		}
+	}
+
+	if time.Since(refund.CreatedAt) < usecase.Config.ReconcileAfter {
+		return nil, nil
+	}
+	return &payment.Refund{Status: payment.RefundFailed}, nil
+}
+
+// Applies a refund webhook: the event only says which payment to look at,
+// the payment's pending refunds are looked up with the provider
+func (usecase *PaymentUsecase) processRefundEvent(ctx context.Context, event *entity.PaymentEvent, record *entity.Payment) error {
+	refunds, err := usecase.Repo.FindPendingRefunds(ctx, record.ID, time.Now(), 20)
+	if err == nil {
+		err = usecase.syncRefunds(ctx, refunds)
+	}
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrPaymentProvider, err.Error())
+		if markErr := usecase.finishEvent(ctx, usecase.DB, event, entity.PaymentEventFailed, err); markErr != nil {
+			return markErr
+		}
+		return err
+	}
+	return usecase.finishEvent(ctx, usecase.DB, event, entity.PaymentEventProcessed, nil)
+}
+
+// Records a chargeback dispute from a signed webhook. A lost dispute becomes a processed
+// chargeback refund, reversing what the payment paid for like any other refund.
+func (usecase *PaymentUsecase) processDispute(ctx context.Context, event *entity.PaymentEvent, record *entity.Payment, update *payment.Dispute) error {
+	var followUps []func()
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		locked, err := usecase.Repo.LockByID(ctx, tx, record.ID)
+		if err != nil {
+			return err // rollback
+		}
+
+		dispute, err := usecase.Repo.LockDispute(ctx, tx, locked.Provider, update.ProviderDisputeID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dispute = &entity.PaymentDispute{
+				ID:                ulid.Make().String(),
+				PaymentID:         locked.ID,
+				Provider:          locked.Provider,
+				ProviderDisputeID: update.ProviderDisputeID,
+				Status:            entity.DisputeStatusOpen,
+				Currency:          locked.Currency,
+			}
+			followUps = append(followUps, func() {
+				usecase.notify(locked.UserID, "A dispute was opened on your payment",
+					"Your bank told us you disputed a payment. We'll work with them to resolve it, reply to this email if you have any questions.",
+					[]string{"Payment: " + locked.Reference, "Amount paid: " + pkg.FormatMoney(locked.Amount, locked.Currency)})
+			})
+		} else if err != nil {
+			return err // rollback
+		}
+		if dispute.Status != entity.DisputeStatusOpen {
+			return usecase.finishEvent(ctx, tx, event, entity.PaymentEventProcessed, nil) // Resolved already, commit on nil
+		}
+
+		if update.Amount > 0 {
+			dispute.Amount = update.Amount
+		}
+		if update.Currency != "" {
+			dispute.Currency = update.Currency
+		}
+		if update.Reason != "" {
+			dispute.Reason = update.Reason
+		}
+		if update.DueAt != nil {
+			dispute.DueAt = update.DueAt
+		}
+
+		switch update.Status {
+		case payment.DisputeWon:
+			now := time.Now()
+			dispute.Status = entity.DisputeStatusWon
+			dispute.ResolvedAt = &now
+		case payment.DisputeLost:
+			now := time.Now()
+			dispute.Status = entity.DisputeStatusLost
+			dispute.ResolvedAt = &now
+
+			// Whatever the bank took, no more than is left to refund
+			left, err := usecase.refundable(ctx, tx, locked)
+			if err != nil {
+				return err // rollback
+			}
+			amount := left
+			if dispute.Amount > 0 && strings.EqualFold(dispute.Currency, locked.Currency) {
+				amount = min(dispute.Amount, left)
+			}
+			if amount > 0 {
+				refund := &entity.Refund{
+					ID:        ulid.Make().String(),
+					PaymentID: locked.ID,
+					Kind:      entity.RefundKindChargeback,
+					Provider:  locked.Provider,
+					Amount:    amount,
+					Currency:  locked.Currency,
+					Reason:    strings.TrimSpace("Chargeback " + dispute.Reason),
+					Status:    entity.RefundStatusPending,
+				}
+				if err := usecase.Repo.CreateRefund(ctx, tx, refund); err != nil {
+					return err // rollback
+				}
+				followUp, err := usecase.completeRefund(ctx, tx, locked, refund)
+				if err != nil {
+					return err // rollback
+				}
+				followUps = append(followUps, followUp)
+				dispute.RefundID = &refund.ID
+			}
+		}
+
+		if err := usecase.Repo.SaveDispute(ctx, tx, dispute); err != nil {
+			return err // rollback
+		}
+		return usecase.finishEvent(ctx, tx, event, entity.PaymentEventProcessed, nil) // commit on nil
+	})
+	if err != nil {
+		if markErr := usecase.finishEvent(ctx, usecase.DB, event, entity.PaymentEventFailed, err); markErr != nil {
+			return markErr
+		}
+		return err
+	}
+
+	for _, followUp := range followUps {
+		followUp()
+	}
+	return nil
+}
+
+// Re-checks refunds left pending past the reconcile delay, in case their webhook was lost
+func (usecase *PaymentUsecase) reconcileRefunds(ctx context.Context) {
+	refunds, err := usecase.Repo.FindPendingRefunds(ctx, "", time.Now().Add(-usecase.Config.ReconcileAfter), 100)
+	if err != nil {
+		zap.L().Warn("Refund reconciliation failed", zap.Error(err))
+		return
+	}
+	usecase.syncRefunds(ctx, refunds) // Failures are logged
+}
+
+// notify emails the payment's owner in the background.
+// It must be called after commit: a failed delivery is logged, never returned.
+func (usecase *PaymentUsecase) notify(userID string, subject string, message string, details []string) {
+	if usecase.Mailer == nil {
+		return
+	}
+
+	go func() {
+		// Recover from any unexpected panic so the app doesn't crash
+		defer func() {
+			if panicErr := recover(); panicErr != nil {
+				zap.L().Error("Panic recovered while sending payment email", zap.Any("error", panicErr))
+			}
+		}()
+
+		var user entity.User
+		if err := usecase.DB.Select("id", "full_name", "email").First(&user, "id = ?", userID).Error; err != nil {
+			zap.L().Error("Failed to find the payment's owner", zap.String("userID", userID), zap.Error(err))
+			return
+		}
+		if err := usecase.Mailer.Send(user.Email, mailer.PaymentMail(user.FullName, subject, message, details)); err != nil {
+			zap.L().Error("Failed to send payment email", zap.String("subject", subject), zap.Error(err))
+		}
+	}()
+}
@@ -0,0 +1,580 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"japa/internal/config"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/repository"
+	"japa/internal/infrastructure/mail"
+	"japa/internal/infrastructure/payment"
+
+	"github.com/oklog/ulid/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ERRORS
+
+var (
+	ErrPaymentProvider = errors.New("payment provider error")
+	ErrPaymentMismatch = errors.New("paid amount or currency does not match the payment")
+	ErrNoFakeProvider  = errors.New("fake payment provider is not configured")
+)
+
+// TYPES
+
+// PaymentUsecase takes money through the configured providers
+type PaymentUsecase struct {
+	Config     config.PaymentConfig
+	SiteConfig config.SiteConfig
+	Repo       *repository.PaymentRepository
+	Provider   *payment.ResponsivePaymentProvider
+	DB         *gorm.DB
+	Mailer     *mailer.ResponsiveMailer
+	Handlers   map[string]SettlementHandler // By payment purpose
+	Observers  []SettlementHandler          // Every purpose, after its handler
+
+	RefundHandlers  map[string]RefundHandler // By payment purpose
+	RefundObservers []RefundHandler          // Every purpose, after its handler
+}
+
+// SettlementHandler applies a settled payment to what it paid for, inside the settlement transaction.
+// It runs once per payment. The returned func, when not nil, runs after commit (i.e emails).
+type SettlementHandler func(ctx context.Context, tx *gorm.DB, record *entity.Payment, verification *payment.Verification) (func(), error)
+
+// Checkout is what a payment is started for, Amount is in minor units
+type Checkout struct {
+	UserID   string
+	Email    string
+	Purpose  string
+	Amount   int64
+	Currency string
+	Metadata map[string]any
+}
+
+// METHODS
+
+// Initialize PaymentUsecase
+func NewPaymentUsecase(
+	cfg        config.PaymentConfig,
+	siteConfig config.SiteConfig,
+	repo       *repository.PaymentRepository,
+	provider   *payment.ResponsivePaymentProvider,
+	db         *gorm.DB,
+	mailer     *mailer.ResponsiveMailer,
+) *PaymentUsecase {
+	return &PaymentUsecase{
+		Config: cfg, SiteConfig: siteConfig, Repo: repo, Provider: provider, DB: db, Mailer: mailer,
+		Handlers: map[string]SettlementHandler{}, RefundHandlers: map[string]RefundHandler{},
+	}
+}
+
+// OnSettled registers what happens when a payment for purpose succeeds or fails
+func (usecase *PaymentUsecase) OnSettled(purpose string, handler SettlementHandler) {
+	usecase.Handlers[purpose] = handler
+}
+
+// OnAnySettled registers what else happens when any payment settles, after the purpose's own handler
+func (usecase *PaymentUsecase) OnAnySettled(handler SettlementHandler) {
+	usecase.Observers = append(usecase.Observers, handler)
+}
+
+// Initialize records the pending payment, then starts a hosted checkout with the first available provider.
+// prepare runs in the transaction recording the payment, to link it to what it pays for before anyone can pay it.
+// A checkout no provider would start fails the payment, so the settlement handlers release what prepare reserved.
+func (usecase *PaymentUsecase) Initialize(ctx context.Context, checkout Checkout, prepare func(tx *gorm.DB, record *entity.Payment) error) (*entity.Payment, error) {
+	metadata, jsonMetadata, err := checkoutMetadata(checkout)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &entity.Payment{
+		ID:        ulid.Make().String(),
+		UserID:    checkout.UserID,
+		Reference: "JP-" + ulid.Make().String(),
+		Purpose:   checkout.Purpose,
+		Amount:    checkout.Amount,
+		Currency:  strings.ToUpper(checkout.Currency),
+		Status:    entity.PaymentStatusPending,
+		Metadata:  jsonMetadata,
+	}
+	err = usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := usecase.Repo.Create(ctx, tx, record); err != nil {
+			return err // rollback
+		}
+		if prepare == nil {
+			return nil // commit
+		}
+		return prepare(tx, record) // commit on nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := usecase.Provider.Initialize(ctx, payment.InitializeRequest{
+		Reference:   record.Reference,
+		Email:       checkout.Email,
+		Amount:      record.Amount,
+		Currency:    record.Currency,
+		CallbackURL: usecase.SiteConfig.SiteDomain + "/account/payments/" + record.Reference,
+		Metadata:    metadata,
+	})
+	if err != nil {
+		// The link never reached the customer, so nothing can pay it
+		if _, failErr := usecase.settleAndNotify(context.WithoutCancel(ctx), record.Reference, &payment.Verification{
+			Reference: record.Reference,
+			Status:    payment.StatusFailed,
+			Message:   "checkout could not be started",
+		}); failErr != nil {
+			zap.L().Error("Failed to fail unstarted checkout", zap.String("reference", record.Reference), zap.Error(failErr))
+		}
+		return nil, fmt.Errorf("%w: %s", ErrPaymentProvider, err.Error())
+	}
+
+	record.Provider = transaction.Provider
+	record.AuthorizationURL = transaction.AuthorizationURL
+	if transaction.ProviderReference != "" {
+		record.ProviderReference = &transaction.ProviderReference
+	}
+	if err := usecase.Repo.UpdateCheckout(ctx, usecase.DB, record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Void fails a pending checkout that a newer one replaces, unless its provider says it was paid,
+// then it settles as paid instead. Settles like Verify, so the handlers release what it held.
+func (usecase *PaymentUsecase) Void(ctx context.Context, reference string, reason string) (*entity.Payment, error) {
+	record, err := usecase.Repo.FindByReference(ctx, usecase.DB, reference)
+	if err != nil {
+		return nil, err
+	}
+	if record.Status != entity.PaymentStatusPending {
+		return record, nil
+	}
+
+	verification := &payment.Verification{Reference: reference, Status: payment.StatusFailed, Message: reason}
+	if record.Provider != "" {
+		checked, err := usecase.Provider.Verify(ctx, record.Provider, reference)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrPaymentProvider, err.Error())
+		}
+		if checked.Status != payment.StatusPending {
+			verification = checked
+		}
+	}
+
+	return usecase.settleAndNotify(ctx, reference, verification)
+}
+
+// Verify asks the provider for a pending payment's outcome and settles it.
+// An empty userID skips the ownership check (admins).
+func (usecase *PaymentUsecase) Verify(ctx context.Context, userID string, reference string) (*entity.Payment, error) {
+	record, err := usecase.Repo.FindByReference(ctx, usecase.DB, reference)
+	if err != nil {
+		return nil, err
+	}
+	if userID != "" && record.UserID != userID {
+		return nil, gorm.ErrRecordNotFound // Don't leak other users' payments
+	}
+	if record.Status != entity.PaymentStatusPending {
+		return record, nil
+	}
+	if record.Provider == "" {
+		// Initialize stopped before any provider took the checkout
+		return usecase.settleAndNotify(ctx, reference, &payment.Verification{
+			Reference: reference,
+			Status:    payment.StatusFailed,
+			Message:   "checkout could not be started",
+		})
+	}
+
+	verification, err := usecase.Provider.Verify(ctx, record.Provider, reference)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrPaymentProvider, err.Error())
+	}
+
+	return usecase.settleAndNotify(ctx, reference, verification)
+}
+
+// ChargeAuthorization starts a payment against a stored card and settles it like Verify.
+// prepare runs in the transaction recording the payment, to link it to what it pays for before it can settle.
+// When the provider's answer is unknown the payment stays pending for reconciliation.
+func (usecase *PaymentUsecase) ChargeAuthorization(
+	ctx           context.Context,
+	checkout      Checkout,
+	authorization *entity.PaymentAuthorization,
+	prepare       func(tx *gorm.DB, record *entity.Payment) error,
+) (*entity.Payment, error) {
+	metadata, jsonMetadata, err := checkoutMetadata(checkout)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &entity.Payment{
+		ID:              ulid.Make().String(),
+		UserID:          checkout.UserID,
+		Reference:       "JP-" + ulid.Make().String(),
+		Provider:        authorization.Provider,
+		Purpose:         checkout.Purpose,
+		Amount:          checkout.Amount,
+		Currency:        strings.ToUpper(checkout.Currency),
+		Status:          entity.PaymentStatusPending,
+		Metadata:        jsonMetadata,
+		AuthorizationID: &authorization.ID,
+	}
+	err = usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := usecase.Repo.Create(ctx, tx, record); err != nil {
+			return err // rollback
+		}
+		return prepare(tx, record) // commit on nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	verification, err := usecase.Provider.Charge(ctx, authorization.Provider, payment.ChargeRequest{
+		Reference:         record.Reference,
+		Email:             checkout.Email,
+		AuthorizationCode: authorization.Code,
+		Amount:            record.Amount,
+		Currency:          record.Currency,
+		Metadata:          metadata,
+	})
+	if err != nil {
+		var providerErr *payment.ProviderError
+		if !errors.As(err, &providerErr) || !providerErr.Rejected() {
+			// May have been charged, Reconcile verifies it by reference
+			return record, fmt.Errorf("%w: %s", ErrPaymentProvider, err.Error())
+		}
+		// Rejected outright, i.e a revoked authorization
+		verification = &payment.Verification{
+			Provider:  authorization.Provider,
+			Reference: record.Reference,
+			Status:    payment.StatusFailed,
+			Message:   providerErr.Message,
+		}
+	}
+
+	return usecase.settleAndNotify(ctx, record.Reference, verification)
+}
+
+// Settles in its own transaction, then runs the handlers' follow-ups
+func (usecase *PaymentUsecase) settleAndNotify(ctx context.Context, reference string, verification *payment.Verification) (*entity.Payment, error) {
+	var record *entity.Payment
+	var followUp func()
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		record, followUp, err = usecase.settle(ctx, tx, reference, verification)
+		return err // commit on nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if followUp != nil {
+		followUp()
+	}
+	return record, nil
+}
+
+// Applies a verification to a locked payment. Only pending payments change,
+// so settling the same outcome twice is a no-op. Returns the handler's follow-up, to run after commit.
+func (usecase *PaymentUsecase) settle(ctx context.Context, tx *gorm.DB, reference string, verification *payment.Verification) (*entity.Payment, func(), error) {
+	record, err := usecase.Repo.LockByReference(ctx, tx, reference)
+	if err != nil {
+		return nil, nil, err
+	}
+	if record.Status != entity.PaymentStatusPending || verification.Status == payment.StatusPending {
+		return record, nil, nil
+	}
+
+	if verification.ProviderReference != "" {
+		record.ProviderReference = &verification.ProviderReference
+	}
+	if verification.Channel != "" {
+		record.Channel = &verification.Channel
+	}
+	record.Fee = verification.Fee
+
+	switch {
+	case verification.Status == payment.StatusSucceeded && (verification.Amount < record.Amount || !strings.EqualFold(verification.Currency, record.Currency)):
+		// Never accept a tampered checkout as paid
+		reason := fmt.Sprintf("%s: paid %d %s, expected %d %s", ErrPaymentMismatch.Error(), verification.Amount, verification.Currency, record.Amount, record.Currency)
+		record.Status = entity.PaymentStatusFailed
+		record.FailureReason = &reason
+	case verification.Status == payment.StatusSucceeded:
+		record.Status = entity.PaymentStatusSucceeded
+		record.PaidAt = verification.PaidAt
+		if record.PaidAt == nil {
+			now := time.Now()
+			record.PaidAt = &now
+		}
+		if authorization := verification.Authorization; authorization != nil && authorization.Reusable {
+			saved, err := usecase.Repo.SaveAuthorization(ctx, tx, &entity.PaymentAuthorization{
+				ID:       ulid.Make().String(),
+				UserID:   record.UserID,
+				Provider: record.Provider,
+				Code:     authorization.Code,
+				Brand:    authorization.Brand,
+				Last4:    authorization.Last4,
+				ExpMonth: authorization.ExpMonth,
+				ExpYear:  authorization.ExpYear,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			record.AuthorizationID = &saved.ID
+		}
+	default:
+		reason := verification.Message
+		if reason == "" {
+			reason = "payment was not completed"
+		}
+		record.Status = entity.PaymentStatusFailed
+		record.FailureReason = &reason
+	}
+
+	if err := usecase.Repo.UpdateSettlement(ctx, tx, record); err != nil {
+		return nil, nil, err
+	}
+
+	var followUps []func()
+	handlers := usecase.Observers
+	if handler, ok := usecase.Handlers[record.Purpose]; ok {
+		handlers = append([]SettlementHandler{handler}, handlers...)
+	}
+	for _, handler := range handlers {
+		followUp, err := handler(ctx, tx, record, verification)
+		if err != nil {
+			return nil, nil, err
+		}
+		if followUp != nil {
+			followUps = append(followUps, followUp)
+		}
+	}
+	if len(followUps) == 0 {
+		return record, nil, nil
+	}
+	return record, func() {
+		for _, followUp := range followUps {
+			followUp()
+		}
+	}, nil
+}
+
+// HandleWebhook stores a provider callback and settles, refunds or disputes the payment it is about.
+// The event only says which payment to look at, the outcome always comes from the provider's verify API.
+// Disputes are the exception, they are taken from the signed event as providers have no lookup for them.
+// Redeliveries of a handled event are no-ops, failed ones are retried.
+func (usecase *PaymentUsecase) HandleWebhook(ctx context.Context, provider string, header func(string) string, body []byte) (*entity.PaymentEvent, error) {
+	event, err := usecase.Provider.VerifyWebhook(provider, header, body)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &entity.PaymentEvent{
+		ID:       ulid.Make().String(),
+		Provider: event.Provider,
+		EventID:  event.EventID,
+		Type:     event.Type,
+		Payload:  event.Payload,
+		Status:   entity.PaymentEventReceived,
+	}
+	if event.Reference != "" {
+		record.Reference = &event.Reference
+	}
+
+	created, err := usecase.Repo.CreateEvent(ctx, usecase.DB, record)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		record, err = usecase.Repo.FindEvent(ctx, usecase.DB, event.Provider, event.EventID)
+		if err != nil {
+			return nil, err
+		}
+		if record.Status == entity.PaymentEventProcessed || record.Status == entity.PaymentEventIgnored {
+			return record, nil // Duplicate
+		}
+	}
+
+	return record, usecase.processEvent(ctx, record, event)
+}
+
+// Settles the payment behind a stored event and records the outcome on the event
+func (usecase *PaymentUsecase) processEvent(ctx context.Context, event *entity.PaymentEvent, webhook *payment.WebhookEvent) error {
+	var record *entity.Payment
+	var err error
+	if event.Reference != nil {
+		record, err = usecase.Repo.FindByReference(ctx, usecase.DB, *event.Reference)
+	}
+	if event.Reference == nil || errors.Is(err, gorm.ErrRecordNotFound) || (record != nil && record.Provider != event.Provider) {
+		// Not one of our checkouts, i.e a payment made from the provider's dashboard
+		return usecase.finishEvent(ctx, usecase.DB, event, entity.PaymentEventIgnored, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case webhook.Dispute != nil:
+		return usecase.processDispute(ctx, event, record, webhook.Dispute)
+	case strings.HasPrefix(event.Type, "refund."):
+		return usecase.processRefundEvent(ctx, event, record)
+	}
+
+	verification, err := usecase.Provider.Verify(ctx, record.Provider, record.Reference)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", ErrPaymentProvider, err.Error())
+		if markErr := usecase.finishEvent(ctx, usecase.DB, event, entity.PaymentEventFailed, err); markErr != nil {
+			return markErr
+		}
+		return err
+	}
+
+	var followUp func()
+	err = usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, followUp, err = usecase.settle(ctx, tx, record.Reference, verification); err != nil {
+			return err // rollback
+		}
+		return usecase.finishEvent(ctx, tx, event, entity.PaymentEventProcessed, nil) // commit on nil
+	})
+	if err != nil {
+		if markErr := usecase.finishEvent(ctx, usecase.DB, event, entity.PaymentEventFailed, err); markErr != nil {
+			return markErr
+		}
+		return err
+	}
+
+	if followUp != nil {
+		followUp()
+	}
+	return nil
+}
+
+// Records an event's processing outcome
+func (usecase *PaymentUsecase) finishEvent(ctx context.Context, tx *gorm.DB, event *entity.PaymentEvent, status string, cause error) error {
+	now := time.Now()
+	event.Status = status
+	event.ProcessedAt = &now
+	event.Error = nil
+	if cause != nil {
+		message := cause.Error()
+		event.Error = &message
+	}
+	return usecase.Repo.UpdateEvent(ctx, tx, event)
+}
+
+// Reconcile re-verifies pending payments and refunds that no webhook settled, in case a callback was lost.
+// Payments past the reconcile window are left alone as abandoned checkouts.
+func (usecase *PaymentUsecase) Reconcile(ctx context.Context) error {
+	now := time.Now()
+	payments, err := usecase.Repo.FindPendingBetween(ctx, now.Add(-usecase.Config.ReconcileWindow), now.Add(-usecase.Config.ReconcileAfter), 100)
+	if err != nil {
+		return err
+	}
+
+	settled := 0
+	for _, pending := range payments {
+		record, err := usecase.Verify(ctx, "", pending.Reference)
+		if err != nil {
+			zap.L().Warn("Payment reconciliation failed", zap.String("reference", pending.Reference), zap.Error(err))
+			continue
+		}
+		if record.Status != entity.PaymentStatusPending {
+			settled++
+		}
+	}
+
+	if settled > 0 {
+		zap.L().Info("Reconciled pending payments", zap.Int("checked", len(payments)), zap.Int("settled", settled))
+	}
+
+	usecase.reconcileRefunds(ctx)
+	return nil
+}
+
+// Metadata sent to the provider and stored on the payment
+func checkoutMetadata(checkout Checkout) (map[string]any, []byte, error) {
+	metadata := map[string]any{"user_id": checkout.UserID, "purpose": checkout.Purpose}
+	for key, value := range checkout.Metadata {
+		metadata[key] = value
+	}
+	jsonMetadata, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, nil, err
+	}
+	return metadata, jsonMetadata, nil
+}
+
+// PeriodEnd is when a billing period started at start ends.
+// Months are clamped, a monthly period from Jan 31st ends on the last day of February.
+func PeriodEnd(start time.Time, billingCycle string) time.Time {
+	return periodEnd(start, billingCycle, 1)
+}
+
+// PeriodEndAfter is when the billing period running at after ends, for periods counted from anchor.
+// Each end is anchor plus whole cycles so clamping doesn't carry over, from Jan 31st a monthly period
+// ends on the last day of February and the next on March 31st.
+func PeriodEndAfter(anchor time.Time, billingCycle string, after time.Time) time.Time {
+	end := periodEnd(anchor, billingCycle, 1)
+	for n := 2; !end.After(after); n++ {
+		end = periodEnd(anchor, billingCycle, n)
+	}
+	return end
+}
+
+// End of the nth billing period from start
+func periodEnd(start time.Time, billingCycle string, n int) time.Time {
+	switch strings.ToLower(billingCycle) {
+	case "weekly":
+		return start.AddDate(0, 0, 7*n)
+	case "quarterly":
+		return addMonths(start, 3*n)
+	case "biannually", "semiannually":
+		return addMonths(start, 6*n)
+	case "yearly", "annually", "annual":
+		return addMonths(start, 12*n)
+	default: // monthly
+		return addMonths(start, n)
+	}
+}
+
+// Adds months without spilling into the following month
+func addMonths(start time.Time, months int) time.Time {
+	end := start.AddDate(0, months, 0)
+	if end.Day() != start.Day() {
+		end = end.AddDate(0, 0, -end.Day()) // Last day of the intended month
+	}
+	return end
+}
+
+// Fetch the user's payments
+func (usecase *PaymentUsecase) FetchPayments(ctx context.Context, userID string, page int, limit int) ([]entity.Payment, int64, error) {
+	return usecase.Repo.FindByUser(ctx, userID, limit, (page-1)*limit)
+}
+
+// CompleteFake settles a fake checkout, for local development only
+func (usecase *PaymentUsecase) CompleteFake(ctx context.Context, reference string, succeeded bool) (*entity.Payment, error) {
+	provider, err := usecase.Provider.Provider("fake")
+	if err != nil {
+		return nil, ErrNoFakeProvider
+	}
+	fake, ok := provider.(*payment.FakeProvider)
+	if !ok {
+		return nil, ErrNoFakeProvider
+	}
+
+	if err := fake.Complete(reference, succeeded); err != nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return usecase.Verify(ctx, "", reference)
+}
@@ -0,0 +1,488 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"japa/internal/app/http/dto/request"
+	"japa/internal/config"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/repository"
+	"japa/internal/infrastructure/payment"
+
+	"github.com/oklog/ulid/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ERRORS
+
+var (
+	ErrPayoutAccountInvalid = errors.New("bank account could not be resolved")
+	ErrPayoutInProgress     = errors.New("payouts to this account are still in progress")
+)
+
+// TYPES
+
+// PayoutUsecase pays partners their available commissions in batches, through providers that can send transfers
+type PayoutUsecase struct {
+	Config     config.AffiliateConfig
+	SiteConfig config.SiteConfig
+	Repo       *repository.PayoutRepository
+	Affiliates *repository.AffiliateRepository
+	Provider   *payment.ResponsivePaymentProvider
+	Ledger     *LedgerUsecase
+	DB         *gorm.DB
+}
+
+// PayoutBalance is what a partner can be paid in one currency, minor units
+type PayoutBalance struct {
+	Currency  string
+	Available int64 // Net commissions past the hold period
+	PaidOut   int64 // Payouts that didn't fail, paid or on their way
+	Payable   int64 // Available less PaidOut
+	Minimum   int64 // Smallest payout in the currency
+}
+
+// PayoutStatementLine is a partner's commissions and payouts in one currency over a statement period.
+// Opening and Closing are what was owed, held or available, at the start and end.
+type PayoutStatementLine struct {
+	Currency string
+	Opening  int64
+	Earned   int64
+	Reversed int64
+	PaidOut  int64 // Payouts created in the period that didn't fail
+	Closing  int64
+}
+
+// PayoutStatement is a partner's statement for [From, To)
+type PayoutStatement struct {
+	From     time.Time
+	To       time.Time
+	Lines    []PayoutStatementLine
+	Payouts  []entity.Payout // Created in the period, failed ones included
+	Balances []PayoutBalance // As of now
+}
+
+// How many payouts the job checks on per run
+const payoutSyncLimit = 100
+
+// METHODS
+
+// Initialize PayoutUsecase
+func NewPayoutUsecase(cfg config.AffiliateConfig, siteConfig config.SiteConfig, repo *repository.PayoutRepository, affiliates *repository.AffiliateRepository, provider *payment.ResponsivePaymentProvider, ledger *LedgerUsecase, db *gorm.DB) *PayoutUsecase {
+	return &PayoutUsecase{
+		Config:     cfg,
+		SiteConfig: siteConfig,
+		Repo:       repo,
+		Affiliates: affiliates,
+		Provider:   provider,
+		Ledger:     ledger,
+		DB:         db,
+	}
+}
+
+// Fetch the partner's payout accounts
+func (usecase *PayoutUsecase) FetchAccounts(ctx context.Context, partnerID string) ([]entity.PayoutAccount, error) {
+	return usecase.Repo.FindAccounts(ctx, partnerID)
+}
+
+// SaveAccount resolves the bank account with a provider that can pay out in the currency, registers it as
+// a transfer recipient and makes it the partner's payout account for the currency
+func (usecase *PayoutUsecase) SaveAccount(ctx context.Context, req request.SavePayoutAccountRequest) (*entity.PayoutAccount, error) {
+	if open, err := usecase.Repo.HasOpenPayouts(ctx, usecase.DB, req.PartnerID, req.Currency); err != nil {
+		return nil, err
+	} else if open {
+		return nil, ErrPayoutInProgress
+	}
+
+	transfers, err := usecase.Provider.TransfersFor(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := transfers.ResolveAccount(ctx, req.BankCode, req.AccountNumber)
+	if err != nil {
+		var providerErr *payment.ProviderError
+		if errors.As(err, &providerErr) && providerErr.Rejected() {
+			return nil, fmt.Errorf("%w: %s", ErrPayoutAccountInvalid, providerErr.Message)
+		}
+		return nil, err
+	}
+	resolved.Currency = req.Currency
+	recipient, err := transfers.CreateRecipient(ctx, *resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &entity.PayoutAccount{
+		ID:            ulid.Make().String(),
+		PartnerID:     req.PartnerID,
+		Currency:      req.Currency,
+		Provider:      transfers.Name(),
+		BankCode:      req.BankCode,
+		AccountLast4:  req.AccountNumber[len(req.AccountNumber)-4:],
+		AccountName:   resolved.AccountName,
+		RecipientCode: recipient,
+		VerifiedAt:    time.Now(),
+	}
+	// commit on nil
+	err = usecase.DB.Transaction(func(tx *gorm.DB) error {
+		existing, err := usecase.Repo.FindAccount(ctx, tx, req.PartnerID, req.Currency)
+		if err == nil {
+			// Keep the ID, payouts point at it
+			if _, err := usecase.Repo.LockAccount(ctx, tx, existing.ID); err != nil {
+				return err
+			}
+			account.ID = existing.ID
+			account.CreatedAt = existing.CreatedAt
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		return usecase.Repo.SaveAccount(ctx, tx, account)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// Remove the partner's payout account for a currency, its balance waits until a new one is added
+func (usecase *PayoutUsecase) DeleteAccount(ctx context.Context, partnerID string, currency string) error {
+	// commit on nil
+	return usecase.DB.Transaction(func(tx *gorm.DB) error {
+		account, err := usecase.Repo.FindAccount(ctx, tx, partnerID, currency)
+		if err != nil {
+			return err
+		}
+		if _, err := usecase.Repo.LockAccount(ctx, tx, account.ID); err != nil {
+			return err
+		}
+		open, err := usecase.Repo.HasOpenPayouts(ctx, tx, partnerID, currency)
+		if err != nil {
+			return err
+		}
+		if open {
+			return ErrPayoutInProgress
+		}
+		return usecase.Repo.DeleteAccount(ctx, tx, account.ID)
+	})
+}
+
+// Fetch what the partner can be paid by currency
+func (usecase *PayoutUsecase) FetchBalances(ctx context.Context, partnerID string) ([]PayoutBalance, error) {
+	return usecase.balances(ctx, usecase.DB, partnerID)
+}
+
+// Fetch the partner's payouts, newest first
+func (usecase *PayoutUsecase) FetchPayouts(ctx context.Context, partnerID string, page int, limit int) ([]entity.Payout, int64, error) {
+	return usecase.Repo.FindPayouts(ctx, partnerID, limit, (page-1)*limit)
+}
+
+// Fetch payout batches, newest first
+func (usecase *PayoutUsecase) FetchBatches(ctx context.Context, page int, limit int) ([]entity.PayoutBatch, int64, error) {
+	return usecase.Repo.FindBatches(ctx, limit, (page-1)*limit)
+}
+
+// Fetch a batch with its payouts
+func (usecase *PayoutUsecase) FetchBatch(ctx context.Context, batchID string) (*entity.PayoutBatch, []entity.Payout, error) {
+	return usecase.Repo.FindBatch(ctx, batchID)
+}
+
+// Statement of the partner's commissions and payouts in [from, to)
+func (usecase *PayoutUsecase) Statement(ctx context.Context, partnerID string, from time.Time, to time.Time) (*PayoutStatement, error) {
+	opening, err := usecase.Affiliates.SumCommissionsBetween(ctx, partnerID, time.Time{}, from)
+	if err != nil {
+		return nil, err
+	}
+	paidBefore, err := usecase.Repo.SumPayouts(ctx, usecase.DB, partnerID, from)
+	if err != nil {
+		return nil, err
+	}
+	period, err := usecase.Affiliates.SumCommissionsBetween(ctx, partnerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	payouts, err := usecase.Repo.FindPayoutsBetween(ctx, partnerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := usecase.FetchBalances(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := map[string]*PayoutStatementLine{}
+	var currencies []string
+	line := func(currency string) *PayoutStatementLine {
+		if lines[currency] == nil {
+			lines[currency] = &PayoutStatementLine{Currency: currency}
+			currencies = append(currencies, currency)
+		}
+		return lines[currency]
+	}
+	for _, totals := range opening {
+		line(totals.Currency).Opening += totals.Earned - totals.Reversed
+	}
+	for _, totals := range paidBefore {
+		line(totals.Currency).Opening -= totals.Amount
+	}
+	for _, totals := range period {
+		line(totals.Currency).Earned += totals.Earned
+		line(totals.Currency).Reversed += totals.Reversed
+	}
+	for _, payout := range payouts {
+		if payout.Status != entity.PayoutStatusFailed {
+			line(payout.Currency).PaidOut += payout.Amount
+		}
+	}
+
+	statement := &PayoutStatement{From: from, To: to, Payouts: payouts, Balances: balances}
+	for _, currency := range currencies {
+		l := lines[currency]
+		l.Closing = l.Opening + l.Earned - l.Reversed - l.PaidOut
+		statement.Lines = append(statement.Lines, *l)
+	}
+	return statement, nil
+}
+
+// RunBatch pays every partner whose payable balance is over the currency's minimum, startedBy
+// is the admin or nil for scheduled batches. Transfers that stay pending are followed up by Process.
+func (usecase *PayoutUsecase) RunBatch(ctx context.Context, startedBy *string) (*entity.PayoutBatch, error) {
+	accountIDs, err := usecase.Repo.FindAccountIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &entity.PayoutBatch{
+		ID:        ulid.Make().String(),
+		Status:    entity.PayoutBatchProcessing,
+		StartedBy: startedBy,
+	}
+	if err := usecase.Repo.CreateBatch(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	for _, accountID := range accountIDs {
+		created, err := usecase.addPayout(ctx, batch.ID, accountID)
+		if err != nil {
+			// One partner shouldn't hold up the others
+			zap.L().Error("Failed to add payout to batch", zap.String("batch_id", batch.ID), zap.String("account_id", accountID), zap.Error(err))
+			continue
+		}
+		if created {
+			batch.Count++
+		}
+	}
+	if err := usecase.Repo.UpdateBatchCount(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	if batch.Count > 0 {
+		payouts, err := usecase.Repo.FindOpenPayouts(ctx, batch.ID, batch.Count)
+		if err != nil {
+			return nil, err
+		}
+		for i := range payouts {
+			if err := usecase.transfer(ctx, &payouts[i]); err != nil {
+				zap.L().Error("Failed to send payout", zap.String("reference", payouts[i].Reference), zap.Error(err))
+			}
+		}
+	}
+
+	if err := usecase.Repo.CompleteBatches(ctx, time.Now()); err != nil {
+		return nil, err
+	}
+	batch, _, err = usecase.Repo.FindBatch(ctx, batch.ID)
+	return batch, err
+}
+
+// Process is the payout job: it checks on payouts still waiting on the provider, completes
+// their batches and starts the next batch once PayoutEvery has passed since the last one
+func (usecase *PayoutUsecase) Process(ctx context.Context) error {
+	payouts, err := usecase.Repo.FindOpenPayouts(ctx, "", payoutSyncLimit)
+	if err != nil {
+		return err
+	}
+	for i := range payouts {
+		if err := usecase.sync(ctx, &payouts[i]); err != nil {
+			zap.L().Warn("Failed to check on payout", zap.String("reference", payouts[i].Reference), zap.Error(err))
+		}
+	}
+	if err := usecase.Repo.CompleteBatches(ctx, time.Now()); err != nil {
+		return err
+	}
+
+	last, err := usecase.Repo.FindLastBatch(ctx)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if last != nil && time.Since(last.CreatedAt) < usecase.Config.PayoutEvery {
+		return nil
+	}
+	_, err = usecase.RunBatch(ctx, nil)
+	return err
+}
+
+// Adds a payout of the account's payable balance to the batch when it is over the minimum.
+// The account is locked so concurrent batches can't pay the same balance twice.
+func (usecase *PayoutUsecase) addPayout(ctx context.Context, batchID string, accountID string) (bool, error) {
+	created := false
+	// commit on nil
+	err := usecase.DB.Transaction(func(tx *gorm.DB) error {
+		account, err := usecase.Repo.LockAccount(ctx, tx, accountID)
+		if err != nil {
+			return err
+		}
+		balances, err := usecase.balances(ctx, tx, account.PartnerID)
+		if err != nil {
+			return err
+		}
+
+		for _, balance := range balances {
+			if balance.Currency != account.Currency || balance.Payable < balance.Minimum {
+				continue
+			}
+			created = true
+			return usecase.Repo.CreatePayout(ctx, tx, &entity.Payout{
+				ID:        ulid.Make().String(),
+				BatchID:   batchID,
+				PartnerID: account.PartnerID,
+				AccountID: account.ID,
+				Reference: "po_" + strings.ToLower(ulid.Make().String()),
+				Provider:  account.Provider,
+				Amount:    balance.Payable,
+				Currency:  account.Currency,
+				Status:    entity.PayoutStatusPending,
+			})
+		}
+		return nil
+	})
+	return created && err == nil, err
+}
+
+// Sends a pending payout's transfer. A rejected transfer fails the payout, one that never got an answer stays
+// pending and sync looks it up by reference before sending it again.
+func (usecase *PayoutUsecase) transfer(ctx context.Context, payout *entity.Payout) error {
+	transfers, err := usecase.Provider.Transfers(payout.Provider)
+	if err != nil {
+		return usecase.apply(ctx, payout.ID, &payment.TransferResult{Status: payment.TransferFailed, Message: err.Error()})
+	}
+	if payout.Account == nil {
+		return usecase.apply(ctx, payout.ID, &payment.TransferResult{Status: payment.TransferFailed, Message: "payout account was removed"})
+	}
+
+	result, err := transfers.Transfer(ctx, payment.TransferRequest{
+		Reference:     payout.Reference,
+		RecipientCode: payout.Account.RecipientCode,
+		Amount:        payout.Amount,
+		Currency:      payout.Currency,
+		Reason:        usecase.SiteConfig.SiteName + " partner payout",
+	})
+	if err != nil {
+		var providerErr *payment.ProviderError
+		if errors.As(err, &providerErr) && providerErr.Rejected() {
+			return usecase.apply(ctx, payout.ID, &payment.TransferResult{Status: payment.TransferFailed, Message: providerErr.Message})
+		}
+		return err // Outcome unknown, the payout stays pending until sync finds it by reference
+	}
+	return usecase.apply(ctx, payout.ID, result)
+}
+
+// Looks an open payout up with its provider, pending payouts the provider never got are sent again
+func (usecase *PayoutUsecase) sync(ctx context.Context, payout *entity.Payout) error {
+	transfers, err := usecase.Provider.Transfers(payout.Provider)
+	if err != nil {
+		return usecase.apply(ctx, payout.ID, &payment.TransferResult{Status: payment.TransferFailed, Message: err.Error()})
+	}
+
+	result, err := transfers.VerifyTransfer(ctx, payout.Reference)
+	if err != nil {
+		var providerErr *payment.ProviderError
+		if payout.Status == entity.PayoutStatusPending && errors.As(err, &providerErr) && providerErr.StatusCode == 404 {
+			return usecase.transfer(ctx, payout)
+		}
+		return err
+	}
+	return usecase.apply(ctx, payout.ID, result)
+}
+
+// Saves a transfer's outcome on its payout, paid payouts are posted to the ledger with it
+func (usecase *PayoutUsecase) apply(ctx context.Context, payoutID string, result *payment.TransferResult) error {
+	// commit on nil
+	return usecase.DB.Transaction(func(tx *gorm.DB) error {
+		payout, err := usecase.Repo.LockPayout(ctx, tx, payoutID)
+		if err != nil {
+			return err
+		}
+		if payout.Status == entity.PayoutStatusPaid || payout.Status == entity.PayoutStatusFailed {
+			return nil
+		}
+		if result.ProviderTransferID != "" {
+			payout.ProviderTransferID = &result.ProviderTransferID
+		}
+
+		switch result.Status {
+		case payment.TransferSucceeded:
+			now := time.Now()
+			payout.Status = entity.PayoutStatusPaid
+			payout.Fee = result.Fee
+			payout.PaidAt = &now
+			if err := usecase.Ledger.PostPayout(ctx, tx, payout); err != nil {
+				return err
+			}
+		case payment.TransferFailed:
+			reason := result.Message
+			if reason == "" {
+				reason = "transfer failed"
+			}
+			if len(reason) > 255 {
+				reason = reason[:255]
+			}
+			payout.Status = entity.PayoutStatusFailed
+			payout.FailureReason = &reason
+			zap.L().Warn("Partner payout failed", zap.String("reference", payout.Reference), zap.String("reason", reason))
+		default:
+			payout.Status = entity.PayoutStatusProcessing
+		}
+		return usecase.Repo.UpdatePayout(ctx, tx, payout)
+	})
+}
+
+// The partner's payable balance by currency, payouts are read in tx
+func (usecase *PayoutUsecase) balances(ctx context.Context, tx *gorm.DB, partnerID string) ([]PayoutBalance, error) {
+	earnings, err := usecase.Affiliates.SumCommissions(ctx, partnerID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	payouts, err := usecase.Repo.SumPayouts(ctx, tx, partnerID, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	paidOut := map[string]int64{}
+	for _, totals := range payouts {
+		paidOut[totals.Currency] = totals.Amount
+	}
+	balances := make([]PayoutBalance, 0, len(earnings))
+	for _, totals := range earnings {
+		balances = append(balances, PayoutBalance{
+			Currency:  totals.Currency,
+			Available: totals.Available,
+			PaidOut:   paidOut[totals.Currency],
+			Payable:   max(totals.Available-paidOut[totals.Currency], 0),
+			Minimum:   usecase.minimum(totals.Currency),
+		})
+	}
+	return balances, nil
+}
+
+// Smallest payout in a currency, any positive balance when it isn't configured
+func (usecase *PayoutUsecase) minimum(currency string) int64 {
+	if minimum, ok := usecase.Config.PayoutMinimums[strings.ToLower(currency)]; ok && minimum > 0 {
+		return int64(minimum)
+	}
+	return 1
+}
@@ -0,0 +1,436 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"japa/internal/app/http/dto/request"
+	"japa/internal/config"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/repository"
+	"japa/internal/pkg"
+
+	"gorm.io/gorm"
+)
+
+// ERRORS
+
+var (
+	ErrPlanCodeTaken       = errors.New("a plan with this code already exists")
+	ErrNotLatestVersion    = errors.New("only the latest version of a plan can be changed this way")
+	ErrPlanNotActive       = errors.New("plan is not active")
+	ErrPlanNotArchived     = errors.New("plan is not archived")
+	ErrPlanUnavailable     = errors.New("plan is no longer offered")
+	ErrUnsupportedCurrency = errors.New("currency has no price book")
+	ErrPriceInUse          = errors.New("subscribers pay this price, create a new version to change it")
+)
+
+// TYPES
+
+// PlanUsecase manages the plan catalogue: versions, features, prices and their order
+type PlanUsecase struct {
+	Config config.BillingConfig // Price books plans can be sold in
+	Repo   *repository.PlanRepository
+	DB     *gorm.DB
+}
+
+// PricedPlan is a plan with its price in the currency the pricing page asked for
+type PricedPlan struct {
+	Plan     entity.Plan
+	Currency string
+	Amount   int64  // Minor units
+	Display  string // i.e "₦12,500.00"
+}
+
+// METHODS
+
+// Initialize PlanUsecase
+func NewPlanUsecase(cfg config.BillingConfig, repo *repository.PlanRepository, db *gorm.DB) *PlanUsecase {
+	return &PlanUsecase{Config: cfg, Repo: repo, DB: db}
+}
+
+// Catalogue lists the active plans in the price book the visitor gets, see PriceBook. Plans without
+// a price in that currency are shown in their own rather than left out, it is what subscribing charges.
+func (usecase *PlanUsecase) Catalogue(ctx context.Context, requested string, country string) ([]PricedPlan, error) {
+	plans, err := usecase.Repo.FindAll(ctx, []string{entity.PlanStatusActive})
+	if err != nil {
+		return nil, err
+	}
+
+	currency := PriceBook(usecase.Config, requested, country)
+	priced := make([]PricedPlan, len(plans))
+	for i, plan := range plans {
+		priced[i] = PricedPlan{Plan: plan, Currency: plan.Currency, Amount: plan.Amount}
+		if amount, ok := plan.PriceIn(currency); ok {
+			priced[i].Currency = currency
+			priced[i].Amount = amount
+		}
+		priced[i].Display = pkg.FormatMoney(priced[i].Amount, priced[i].Currency)
+	}
+	return priced, nil
+}
+
+// FetchPlans lists every plan version for admins, filtered by status when given, with how many subscribers hold each
+func (usecase *PlanUsecase) FetchPlans(ctx context.Context, status string) ([]entity.Plan, map[uint]int64, error) {
+	var statuses []string
+	if status != "" {
+		statuses = []string{status}
+	}
+	plans, err := usecase.Repo.FindAll(ctx, statuses)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	planIDs := make([]uint, len(plans))
+	for i, plan := range plans {
+		planIDs[i] = plan.ID
+	}
+	subscribers, err := usecase.Repo.CountSubscribers(ctx, planIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plans, subscribers, nil
+}
+
+// Fetch a plan version
+func (usecase *PlanUsecase) FetchPlan(ctx context.Context, planID uint) (*entity.Plan, error) {
+	return usecase.Repo.FindByID(ctx, usecase.DB, planID)
+}
+
+// CreatePlan adds the first version of a new plan code
+func (usecase *PlanUsecase) CreatePlan(ctx context.Context, req request.CreatePlanRequest) (*entity.Plan, error) {
+	plan := &entity.Plan{
+		Code:         req.Code,
+		Version:      1,
+		Name:         req.Name,
+		Description:  req.Description,
+		Amount:       req.Amount,
+		Currency:     usecase.Config.Currency,
+		BillingCycle: req.BillingCycle,
+		Status:       entity.PlanStatusActive,
+		SortOrder:    req.SortOrder,
+		TrialDays:    req.TrialDays,
+		PlanFeatures: planFeatures(req.Features),
+		PlanPrices:   planPrices(req.Prices),
+	}
+	if req.Currency != "" {
+		plan.Currency = req.Currency
+	}
+	if err := usecase.checkCurrencies(plan); err != nil {
+		return nil, err
+	}
+
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		latest, err := usecase.Repo.LatestVersion(ctx, tx, req.Code)
+		if err != nil {
+			return err
+		}
+		if latest > 0 {
+			return ErrPlanCodeTaken // rollback
+		}
+		return usecase.Repo.Create(ctx, tx, plan) // commit on nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// UpdatePlan renames or re-describes a version in place, subscribers see the change too
+func (usecase *PlanUsecase) UpdatePlan(ctx context.Context, req request.UpdatePlanRequest) (*entity.Plan, error) {
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		plan, err := usecase.Repo.Lock(ctx, tx, req.PlanID)
+		if err != nil {
+			return err
+		}
+		plan.Name = req.Name
+		plan.Description = req.Description
+		return usecase.Repo.Update(ctx, tx, plan) // commit on nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return usecase.Repo.FindByID(ctx, usecase.DB, req.PlanID)
+}
+
+// CreateVersion replaces the latest version of a plan for new subscribers.
+// Existing subscribers stay on, and keep renewing at, the version they bought.
+func (usecase *PlanUsecase) CreateVersion(ctx context.Context, req request.CreatePlanVersionRequest) (*entity.Plan, error) {
+	var next *entity.Plan
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := usecase.Repo.Lock(ctx, tx, req.PlanID); err != nil {
+			return err
+		}
+		current, err := usecase.Repo.FindByID(ctx, tx, req.PlanID)
+		if err != nil {
+			return err
+		}
+		latest, err := usecase.Repo.LatestVersion(ctx, tx, current.Code)
+		if err != nil {
+			return err
+		}
+		if current.Version != latest {
+			return ErrNotLatestVersion // rollback
+		}
+
+		next = &entity.Plan{
+			Code:         current.Code,
+			Version:      current.Version + 1,
+			Name:         current.Name,
+			Description:  current.Description,
+			Amount:       req.Amount,
+			Currency:     current.Currency,
+			BillingCycle: req.BillingCycle,
+			Status:       entity.PlanStatusActive,
+			SortOrder:    current.SortOrder,
+			TrialDays:    current.TrialDays,
+		}
+		if req.TrialDays != nil {
+			next.TrialDays = *req.TrialDays
+		}
+		if req.Currency != "" {
+			next.Currency = req.Currency
+		}
+		if req.Name != "" {
+			next.Name = req.Name
+		}
+		if req.Description != nil {
+			next.Description = *req.Description
+		}
+		for _, feature := range current.PlanFeatures {
+			next.PlanFeatures = append(next.PlanFeatures, entity.PlanFeature{
+				FeatureLabel: feature.FeatureLabel,
+				FeatureValue: feature.FeatureValue,
+				SortOrder:    feature.SortOrder,
+			})
+		}
+		if req.Prices != nil {
+			next.PlanPrices = planPrices(req.Prices)
+		} else {
+			for _, price := range current.PlanPrices {
+				next.PlanPrices = append(next.PlanPrices, entity.PlanPrice{Currency: price.Currency, Amount: price.Amount})
+			}
+		}
+		if err := usecase.checkCurrencies(next); err != nil {
+			return err // rollback
+		}
+		if err := usecase.Repo.Create(ctx, tx, next); err != nil {
+			return err // rollback
+		}
+
+		entitlements, err := usecase.Repo.FindEntitlements(ctx, tx, current.ID)
+		if err != nil {
+			return err
+		}
+		for i := range entitlements {
+			entitlements[i].ID = 0
+			entitlements[i].PlanID = next.ID
+		}
+		if err := usecase.Repo.CreateEntitlements(ctx, tx, entitlements); err != nil {
+			return err // rollback
+		}
+
+		current.Status = entity.PlanStatusSuperseded
+		return usecase.Repo.Update(ctx, tx, current) // commit on nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// Archive withdraws an active plan from sale, its subscribers keep it
+func (usecase *PlanUsecase) Archive(ctx context.Context, planID uint) (*entity.Plan, error) {
+	return usecase.setStatus(ctx, planID, entity.PlanStatusActive, ErrPlanNotActive, entity.PlanStatusArchived)
+}
+
+// Restore puts an archived plan back on sale, unless a newer version replaced it
+func (usecase *PlanUsecase) Restore(ctx context.Context, planID uint) (*entity.Plan, error) {
+	return usecase.setStatus(ctx, planID, entity.PlanStatusArchived, ErrPlanNotArchived, entity.PlanStatusActive)
+}
+
+// Reorder sets the pricing page order of plans
+func (usecase *PlanUsecase) Reorder(ctx context.Context, req request.ReorderRequest) error {
+	return usecase.Repo.Reorder(ctx, req.IDs)
+}
+
+// SaveFeature adds a feature at the end of the plan's list, or edits one
+func (usecase *PlanUsecase) SaveFeature(ctx context.Context, req request.SavePlanFeatureRequest) (*entity.PlanFeature, error) {
+	if req.FeatureID != 0 {
+		feature, err := usecase.Repo.FindFeature(ctx, req.PlanID, req.FeatureID)
+		if err != nil {
+			return nil, err
+		}
+		feature.FeatureLabel = req.Label
+		feature.FeatureValue = req.Value
+		if err := usecase.Repo.UpdateFeature(ctx, feature); err != nil {
+			return nil, err
+		}
+		return feature, nil
+	}
+
+	if _, err := usecase.Repo.FindByID(ctx, usecase.DB, req.PlanID); err != nil {
+		return nil, err
+	}
+	position, err := usecase.Repo.NextFeatureOrder(ctx, req.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	feature := &entity.PlanFeature{
+		PlanID:       req.PlanID,
+		FeatureLabel: req.Label,
+		FeatureValue: req.Value,
+		SortOrder:    position,
+	}
+	if err := usecase.Repo.CreateFeature(ctx, feature); err != nil {
+		return nil, err
+	}
+	return feature, nil
+}
+
+// Delete a plan's feature
+func (usecase *PlanUsecase) DeleteFeature(ctx context.Context, planID uint, featureID uint) error {
+	return usecase.Repo.DeleteFeature(ctx, planID, featureID)
+}
+
+// ReorderFeatures sets the order of a plan's features
+func (usecase *PlanUsecase) ReorderFeatures(ctx context.Context, req request.ReorderRequest) error {
+	return usecase.Repo.ReorderFeatures(ctx, req.PlanID, req.IDs)
+}
+
+// SavePrice adds the plan to a price book, or changes its price there while nobody subscribes in it.
+// Subscribers keep what they pay, a new version changes the price for new ones.
+func (usecase *PlanUsecase) SavePrice(ctx context.Context, req request.SavePlanPriceRequest) (*entity.PlanPrice, error) {
+	plan, err := usecase.Repo.FindByID(ctx, usecase.DB, req.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	if !supportedCurrency(usecase.Config, req.Currency) {
+		return nil, ErrUnsupportedCurrency
+	}
+	if req.Currency == plan.Currency {
+		return nil, ErrPriceInUse // The plan's own price only changes with a new version
+	}
+	if amount, ok := plan.PriceIn(req.Currency); ok && amount != req.Amount {
+		if err := usecase.unusedPrice(ctx, plan.ID, req.Currency); err != nil {
+			return nil, err
+		}
+	}
+
+	price := &entity.PlanPrice{PlanID: req.PlanID, Currency: req.Currency, Amount: req.Amount}
+	if err := usecase.Repo.SavePrice(ctx, price); err != nil {
+		return nil, err
+	}
+	return price, nil
+}
+
+// Take the plan out of a price book nobody subscribes in
+func (usecase *PlanUsecase) DeletePrice(ctx context.Context, planID uint, currency string) error {
+	currency = strings.ToUpper(currency)
+	if err := usecase.unusedPrice(ctx, planID, currency); err != nil {
+		return err
+	}
+	return usecase.Repo.DeletePrice(ctx, planID, currency)
+}
+
+// Moves the latest version of a plan from one status to another
+func (usecase *PlanUsecase) setStatus(ctx context.Context, planID uint, from string, fromErr error, to string) (*entity.Plan, error) {
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		plan, err := usecase.Repo.Lock(ctx, tx, planID)
+		if err != nil {
+			return err
+		}
+		if plan.Status != from {
+			return fromErr // rollback
+		}
+		latest, err := usecase.Repo.LatestVersion(ctx, tx, plan.Code)
+		if err != nil {
+			return err
+		}
+		if plan.Version != latest {
+			return ErrNotLatestVersion // rollback
+		}
+
+		plan.Status = to
+		return usecase.Repo.Update(ctx, tx, plan) // commit on nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return usecase.Repo.FindByID(ctx, usecase.DB, planID)
+}
+
+// Every currency the plan is sold in must have a price book
+func (usecase *PlanUsecase) checkCurrencies(plan *entity.Plan) error {
+	if !supportedCurrency(usecase.Config, plan.Currency) {
+		return fmt.Errorf("%w: %s", ErrUnsupportedCurrency, plan.Currency)
+	}
+	for _, price := range plan.PlanPrices {
+		if !supportedCurrency(usecase.Config, price.Currency) {
+			return fmt.Errorf("%w: %s", ErrUnsupportedCurrency, price.Currency)
+		}
+	}
+	return nil
+}
+
+// Fails with ErrPriceInUse while subscriptions renew at the plan's price in currency
+func (usecase *PlanUsecase) unusedPrice(ctx context.Context, planID uint, currency string) error {
+	subscribers, err := usecase.Repo.CountSubscribersIn(ctx, planID, currency)
+	if err != nil {
+		return err
+	}
+	if subscribers > 0 {
+		return ErrPriceInUse
+	}
+	return nil
+}
+
+// PriceBook picks the currency a customer is priced in: the one they asked for when we have it,
+// else their country's (the user's, or the visitor's by IP). Countries without a price book of their
+// own get the fallback currency, customers from nowhere we know get the base currency.
+func PriceBook(cfg config.BillingConfig, requested string, country string) string {
+	if requested = strings.ToUpper(strings.TrimSpace(requested)); requested != "" && supportedCurrency(cfg, requested) {
+		return requested
+	}
+	if country == "" {
+		return cfg.Currency
+	}
+	if currency := pkg.CountryCurrency(country); currency != "" && supportedCurrency(cfg, currency) {
+		return currency
+	}
+	if supportedCurrency(cfg, cfg.FallbackCurrency) {
+		return strings.ToUpper(cfg.FallbackCurrency)
+	}
+	return cfg.Currency
+}
+
+// Whether currency is the base currency or one of the price books
+func supportedCurrency(cfg config.BillingConfig, currency string) bool {
+	if strings.EqualFold(currency, cfg.Currency) {
+		return true
+	}
+	for _, supported := range cfg.Currencies {
+		if strings.EqualFold(currency, supported) {
+			return true
+		}
+	}
+	return false
+}
+
+func planFeatures(inputs []request.PlanFeatureInput) []entity.PlanFeature {
+	features := make([]entity.PlanFeature, len(inputs))
+	for i, input := range inputs {
+		features[i] = entity.PlanFeature{FeatureLabel: input.Label, FeatureValue: input.Value, SortOrder: i}
+	}
+	return features
+}
+
+func planPrices(inputs []request.PlanPriceInput) []entity.PlanPrice {
+	prices := make([]entity.PlanPrice, len(inputs))
+	for i, input := range inputs {
+		prices[i] = entity.PlanPrice{Currency: input.Currency, Amount: input.Amount}
+	}
+	return prices
+}
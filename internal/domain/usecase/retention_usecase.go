@@ -0,0 +1,464 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"japa/internal/app/http/dto/request"
+	"japa/internal/config"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/repository"
+	"japa/internal/infrastructure/storage"
+
+	"github.com/oklog/ulid/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Form fields kept after redaction, matched ignoring case and underscores
+// so both submitted forms ("VisaType") and raw drafts ("visa_type") are covered
+var retainedFormFields = map[string]bool{
+	"destination":    true,
+	"visatype":       true,
+	"traveldate":     true,
+	"durationofstay": true,
+	"purpose":        true,
+	"hasbeendenied":  true,
+}
+
+// ERRORS
+
+var (
+	ErrPurgeRunning       = errors.New("a purge is already running")
+	ErrOnLegalHold        = errors.New("application is already on legal hold")
+	ErrHoldReasonRequired = errors.New("a reason is required to place a legal hold")
+)
+
+// TYPES
+
+// RetentionUsecase applies retention policies and legal holds
+type RetentionUsecase struct {
+	Config   config.RetentionConfig
+	Repo     *repository.RetentionRepository
+	VisaRepo *repository.VisaRepository
+	Audit    *AuditUsecase
+	Store    storage.Store
+	DB       *gorm.DB
+
+	running  sync.Mutex // One purge at a time, scheduled or manual
+}
+
+// METHODS
+
+// Initialize RetentionUsecase
+func NewRetentionUsecase(
+	cfg      config.RetentionConfig,
+	repo     *repository.RetentionRepository,
+	visaRepo *repository.VisaRepository,
+	audit    *AuditUsecase,
+	store    storage.Store,
+	db       *gorm.DB,
+) *RetentionUsecase {
+	return &RetentionUsecase{
+		Config:   cfg,
+		Repo:     repo,
+		VisaRepo: visaRepo,
+		Audit:    audit,
+		Store:    store,
+		DB:       db,
+	}
+}
+
+// Fetch every retention policy
+func (usecase *RetentionUsecase) FetchPolicies(ctx context.Context) ([]entity.RetentionPolicy, error) {
+	return usecase.Repo.FindPolicies(ctx)
+}
+
+// Create or replace the policy for a status
+func (usecase *RetentionUsecase) SavePolicy(ctx context.Context, req request.SaveRetentionPolicyRequest) (*entity.RetentionPolicy, error) {
+	policy := &entity.RetentionPolicy{
+		Status:       req.Status,
+		DocumentDays: req.DocumentDays,
+		FormDays:     req.FormDays,
+	}
+	if err := usecase.Repo.SavePolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Delete a policy, data in that status is then kept indefinitely
+func (usecase *RetentionUsecase) DeletePolicy(ctx context.Context, policyID uint) error {
+	return usecase.Repo.DeletePolicy(ctx, policyID)
+}
+
+// Scheduled purge job
+func (usecase *RetentionUsecase) Purge(ctx context.Context) error {
+	run, err := usecase.start(ctx, entity.PurgeTriggerScheduled, nil, false)
+	if errors.Is(err, ErrPurgeRunning) {
+		zap.L().Info("Skipping scheduled purge, a manual purge is running")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return usecase.execute(ctx, run)
+}
+
+// Starts a manual purge in the background, the returned run is the report to poll
+func (usecase *RetentionUsecase) StartPurge(ctx context.Context, req request.StartPurgeRequest) (*entity.PurgeRun, error) {
+	run, err := usecase.start(ctx, entity.PurgeTriggerManual, &req.ActorID, req.DryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	// Outlives the request
+	go func() {
+		if err := usecase.execute(context.Background(), run); err != nil {
+			zap.L().Error("Manual purge failed", zap.String("run_id", run.ID), zap.Error(err))
+		}
+	}()
+
+	return run, nil
+}
+
+// Takes the purge lock and records the run
+func (usecase *RetentionUsecase) start(ctx context.Context, trigger string, actorID *string, dryRun bool) (*entity.PurgeRun, error) {
+	if !usecase.running.TryLock() {
+		return nil, ErrPurgeRunning
+	}
+
+	run := &entity.PurgeRun{
+		ID:          ulid.Make().String(),
+		Trigger:     trigger,
+		TriggeredBy: actorID,
+		DryRun:      dryRun,
+		StartedAt:   time.Now(),
+	}
+	if err := usecase.Repo.CreateRun(ctx, run); err != nil {
+		usecase.running.Unlock()
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// Runs the purge and saves the outcome on the run, releasing the lock taken by start
+func (usecase *RetentionUsecase) execute(ctx context.Context, run *entity.PurgeRun) error {
+	defer usecase.running.Unlock()
+
+	err := usecase.purge(ctx, run)
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	if err != nil {
+		message := err.Error()
+		run.Error = &message
+	}
+	if saveErr := usecase.Repo.UpdateRun(context.WithoutCancel(ctx), run); saveErr != nil {
+		return errors.Join(err, saveErr)
+	}
+
+	zap.L().Info("Purge finished",
+		zap.String("run_id", run.ID),
+		zap.Bool("dry_run", run.DryRun),
+		zap.Int("documents_deleted", run.DocumentsDeleted),
+		zap.Int("documents_failed", run.DocumentsFailed),
+		zap.Int("forms_redacted", run.FormsRedacted),
+		zap.Int("skipped_on_hold", run.SkippedOnHold),
+	)
+	return err
+}
+
+// Applies every policy in batches
+func (usecase *RetentionUsecase) purge(ctx context.Context, run *entity.PurgeRun) error {
+	policies, err := usecase.Repo.FindPolicies(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, policy := range policies {
+		if policy.DocumentDays != nil {
+			cutoff := now.AddDate(0, 0, -*policy.DocumentDays)
+
+			held, err := usecase.Repo.CountHeld(ctx, policy.Status, cutoff, "documents_purged_at")
+			if err != nil {
+				return err
+			}
+			run.SkippedOnHold += int(held)
+
+			// Keyset pagination, dry runs and failed files don't change what the query matches
+			afterID := ""
+			for {
+				applications, err := usecase.Repo.FindDueForDocumentPurge(ctx, policy.Status, cutoff, afterID, usecase.Config.BatchSize)
+				if err != nil {
+					return err
+				}
+				for i := range applications {
+					if err := usecase.purgeDocuments(ctx, run, &applications[i]); err != nil {
+						return err
+					}
+				}
+				if len(applications) < usecase.Config.BatchSize {
+					break
+				}
+				afterID = applications[len(applications)-1].ID
+			}
+		}
+
+		if policy.FormDays != nil {
+			cutoff := now.AddDate(0, 0, -*policy.FormDays)
+
+			held, err := usecase.Repo.CountHeld(ctx, policy.Status, cutoff, "form_redacted_at")
+			if err != nil {
+				return err
+			}
+			run.SkippedOnHold += int(held)
+
+			afterID := ""
+			for {
+				applications, err := usecase.Repo.FindDueForFormRedaction(ctx, policy.Status, cutoff, afterID, usecase.Config.BatchSize)
+				if err != nil {
+					return err
+				}
+				for i := range applications {
+					if err := usecase.redactForm(ctx, run, &applications[i]); err != nil {
+						return err
+					}
+				}
+				if len(applications) < usecase.Config.BatchSize {
+					break
+				}
+				afterID = applications[len(applications)-1].ID
+			}
+		}
+
+		// Progress is visible on the report while the run goes on
+		if err := usecase.Repo.UpdateRun(ctx, run); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Deletes the application's files. Files that fail are reported and retried on the next run,
+// the application is only marked purged once every file is gone.
+func (usecase *RetentionUsecase) purgeDocuments(ctx context.Context, run *entity.PurgeRun, application *entity.VisaApplication) error {
+	var items []entity.PurgeItem
+	failed := false
+
+	deleteFile := func(documentID *string, path string, label string) error {
+		item := entity.PurgeItem{
+			PurgeRunID:        run.ID,
+			VisaApplicationID: application.ID,
+			DocumentID:        documentID,
+			Action:            entity.PurgeActionDocumentDeleted,
+			Detail:            label,
+		}
+
+		if !run.DryRun {
+			if err := usecase.Store.Delete(ctx, path); err != nil {
+				failed = true
+				run.DocumentsFailed++
+				item.Action = entity.PurgeActionDocumentFailed
+				item.Detail = truncate(label+": "+err.Error(), 255)
+				items = append(items, item)
+				return nil
+			}
+			if documentID != nil {
+				if err := usecase.Repo.MarkDocumentPurged(ctx, usecase.DB, *documentID, time.Now()); err != nil {
+					return err
+				}
+			}
+		}
+
+		run.DocumentsDeleted++
+		items = append(items, item)
+		return nil
+	}
+
+	for _, document := range application.Documents {
+		if err := deleteFile(&document.ID, document.FilePath, document.FileType); err != nil {
+			return err
+		}
+	}
+	if application.VisaFormURL != nil && *application.VisaFormURL != "" {
+		if err := deleteFile(nil, *application.VisaFormURL, "signed_form"); err != nil {
+			return err
+		}
+	}
+
+	if !run.DryRun && !failed {
+		if err := usecase.Repo.MarkDocumentsPurged(ctx, usecase.DB, application.ID, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	return usecase.Repo.CreateItems(ctx, usecase.DB, items)
+}
+
+// Redacts the personal fields of the application's form
+func (usecase *RetentionUsecase) redactForm(ctx context.Context, run *entity.PurgeRun, application *entity.VisaApplication) error {
+	return usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if !run.DryRun {
+			if err := usecase.Repo.RedactForm(ctx, tx, application.ID, RedactFormInput(application.VisaFormInput), time.Now()); err != nil {
+				return err // rollback
+			}
+		}
+
+		run.FormsRedacted++
+		return usecase.Repo.CreateItems(ctx, tx, []entity.PurgeItem{{
+			PurgeRunID:        run.ID,
+			VisaApplicationID: application.ID,
+			Action:            entity.PurgeActionFormRedacted,
+		}}) // commit on nil
+	})
+}
+
+// Places a legal hold on an application
+func (usecase *RetentionUsecase) PlaceHold(ctx context.Context, req request.LegalHoldRequest) (*entity.LegalHold, error) {
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, ErrHoldReasonRequired
+	}
+
+	var hold *entity.LegalHold
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := usecase.VisaRepo.FindByID(ctx, tx, req.ApplicationID); err != nil {
+			return err
+		}
+
+		_, err := usecase.Repo.FindActiveHold(ctx, tx, req.ApplicationID)
+		if err == nil {
+			return ErrOnLegalHold
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		hold = &entity.LegalHold{
+			ID:                ulid.Make().String(),
+			VisaApplicationID: req.ApplicationID,
+			Reason:            strings.TrimSpace(req.Reason),
+			PlacedBy:          req.ActorID,
+		}
+		if err := usecase.Repo.CreateHold(ctx, tx, hold); err != nil {
+			return err // rollback
+		}
+
+		_, err = usecase.Audit.Record(ctx, tx, AuditEntry{
+			ActorID:      req.ActorID,
+			Action:       entity.AuditActionLegalHoldPlace,
+			ResourceType: "visa_application",
+			ResourceID:   &req.ApplicationID,
+			Metadata:     map[string]any{"hold_id": hold.ID, "reason": hold.Reason},
+			IPAddress:    req.IPAddress,
+		})
+		return err // commit on nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// Releases the application's active legal hold
+func (usecase *RetentionUsecase) ReleaseHold(ctx context.Context, req request.LegalHoldRequest) (*entity.LegalHold, error) {
+	var hold *entity.LegalHold
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		hold, err = usecase.Repo.FindActiveHold(ctx, tx, req.ApplicationID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		hold.ReleasedBy = &req.ActorID
+		hold.ReleasedAt = &now
+		if err := usecase.Repo.ReleaseHold(ctx, tx, hold); err != nil {
+			return err // rollback
+		}
+
+		_, err = usecase.Audit.Record(ctx, tx, AuditEntry{
+			ActorID:      req.ActorID,
+			Action:       entity.AuditActionLegalHoldRelease,
+			ResourceType: "visa_application",
+			ResourceID:   &req.ApplicationID,
+			Metadata:     map[string]any{"hold_id": hold.ID},
+			IPAddress:    req.IPAddress,
+		})
+		return err // commit on nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// Fetch active legal holds
+func (usecase *RetentionUsecase) FetchHolds(ctx context.Context, req request.RetentionPageRequest) ([]entity.LegalHold, int64, error) {
+	return usecase.Repo.FindActiveHolds(ctx, req.Limit, (req.Page-1)*req.Limit)
+}
+
+// Fetch purge runs
+func (usecase *RetentionUsecase) FetchRuns(ctx context.Context, req request.RetentionPageRequest) ([]entity.PurgeRun, int64, error) {
+	return usecase.Repo.FindRuns(ctx, req.Limit, (req.Page-1)*req.Limit)
+}
+
+// Fetch a purge run with a page of its report items
+func (usecase *RetentionUsecase) FetchRun(ctx context.Context, req request.RetentionPageRequest) (*entity.PurgeRun, []entity.PurgeItem, int64, error) {
+	run, err := usecase.Repo.FindRun(ctx, req.RunID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	items, total, err := usecase.Repo.FindItems(ctx, req.RunID, req.Limit, (req.Page-1)*req.Limit)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	return run, items, total, nil
+}
+
+// RedactFormInput keeps only the non-personal travel fields of a stored form.
+// Empty and null forms (uploaded forms) are returned unchanged.
+func RedactFormInput(formInput []byte) []byte {
+	if len(formInput) == 0 {
+		return formInput
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(formInput, &fields); err != nil || fields == nil {
+		if err != nil {
+			return []byte("{}") // Unreadable, keep nothing
+		}
+		return formInput
+	}
+
+	for key := range fields {
+		if !retainedFormFields[strings.ToLower(strings.ReplaceAll(key, "_", ""))] {
+			delete(fields, key)
+		}
+	}
+
+	redacted, err := json.Marshal(fields)
+	if err != nil {
+		return []byte("{}")
+	}
+	return redacted
+}
+
+func truncate(value string, max int) string {
+	if len(value) <= max {
+		return value
+	}
+	return value[:max]
+}
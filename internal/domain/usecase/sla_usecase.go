@@ -0,0 +1,387 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"japa/internal/app/http/dto/request"
+	"japa/internal/config"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/repository"
+	"japa/internal/infrastructure/mail"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SLA states of an application in its current status
+const (
+	SLAStateNone     = "none"     // No target for this status
+	SLAStateOK       = "ok"
+	SLAStateAtRisk   = "at_risk"  // Past the warning share of the target
+	SLAStateBreached = "breached"
+)
+
+// TYPES
+
+// SLAUsecase measures turnaround against SLA targets and escalates breaches
+type SLAUsecase struct {
+	Config     config.SLAConfig
+	SiteConfig config.SiteConfig
+	Repo       *repository.SLARepository
+	VisaRepo   *repository.VisaRepository
+	FraudRepo  *repository.FraudRepository
+	UserRepo   *repository.UserRepository
+	DB         *gorm.DB
+	Mailer     *mailer.ResponsiveMailer
+}
+
+// SLAStatus is an application's standing against its current status target
+type SLAStatus struct {
+	State        string
+	EnteredAt    time.Time
+	TimeInStatus time.Duration
+	Target       time.Duration
+	DueAt        *time.Time // Nil without a target
+}
+
+// QueueItem is an application in the agent queue with its SLA standing
+type QueueItem struct {
+	Application entity.VisaApplication
+	Destination string
+	VisaType    string
+	Form        *entity.VisaFormInput // Nil for uploaded forms
+	SLA         SLAStatus
+	Signals     []entity.FraudSignal  // Open fraud signals awaiting review
+}
+
+// METHODS
+
+// Initialize SLAUsecase
+func NewSLAUsecase(
+	cfg        config.SLAConfig,
+	siteConfig config.SiteConfig,
+	repo       *repository.SLARepository,
+	visaRepo   *repository.VisaRepository,
+	fraudRepo  *repository.FraudRepository,
+	userRepo   *repository.UserRepository,
+	db         *gorm.DB,
+	mailer     *mailer.ResponsiveMailer,
+) *SLAUsecase {
+	return &SLAUsecase{
+		Config:     cfg,
+		SiteConfig: siteConfig,
+		Repo:       repo,
+		VisaRepo:   visaRepo,
+		FraudRepo:  fraudRepo,
+		UserRepo:   userRepo,
+		DB:         db,
+		Mailer:     mailer,
+	}
+}
+
+// Fetch the agent queue with SLA flags, most urgent first
+func (usecase *SLAUsecase) FetchQueue(ctx context.Context, req request.VisaQueueRequest) ([]QueueItem, int64, error) {
+	items, err := usecase.queue(ctx, req)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Filter by SLA state
+	if req.SLA != "" {
+		filtered := items[:0]
+		for _, item := range items {
+			if item.SLA.State == req.SLA {
+				filtered = append(filtered, item)
+			}
+		}
+		items = filtered
+	}
+
+	// Paginate
+	total := int64(len(items))
+	start := (req.Page - 1) * req.Limit
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + req.Limit
+	if end > len(items) {
+		end = len(items)
+	}
+
+	return items[start:end], total, nil
+}
+
+// Loads the queue and computes every application's SLA standing
+func (usecase *SLAUsecase) queue(ctx context.Context, req request.VisaQueueRequest) ([]QueueItem, error) {
+	applications, err := usecase.VisaRepo.FindQueue(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := usecase.Evaluate(ctx, applications)
+	if err != nil {
+		return nil, err
+	}
+
+	// Breached first, then the closest to breaching, then applications without a target
+	sort.SliceStable(items, func(a, b int) bool {
+		dueA, dueB := items[a].SLA.DueAt, items[b].SLA.DueAt
+		switch {
+		case dueA == nil:
+			return false
+		case dueB == nil:
+			return true
+		default:
+			return dueA.Before(*dueB)
+		}
+	})
+
+	return items, nil
+}
+
+// Evaluate computes the SLA standing of each application, keeping their order
+func (usecase *SLAUsecase) Evaluate(ctx context.Context, applications []entity.VisaApplication) ([]QueueItem, error) {
+	applicationIDs := make([]string, len(applications))
+	userIDs := make([]string, len(applications))
+	for i, application := range applications {
+		applicationIDs[i] = application.ID
+		userIDs[i] = application.UserID
+	}
+
+	enteredAt, err := usecase.VisaRepo.FindStatusEnteredAt(ctx, applicationIDs)
+	if err != nil {
+		return nil, err
+	}
+	plans, err := usecase.Repo.FindActivePlans(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := usecase.Repo.FindTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	signals, err := usecase.FraudRepo.FindSignals(ctx, applicationIDs, false)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	items := make([]QueueItem, len(applications))
+	for i, application := range applications {
+		var status string
+		if application.Status != nil {
+			status = *application.Status
+		}
+
+		entered, ok := enteredAt[application.ID]
+		if !ok {
+			entered = application.CreatedAt // Applications from before the timeline existed
+		}
+
+		var planID *uint
+		if id, ok := plans[application.UserID]; ok {
+			planID = &id
+		}
+
+		items[i] = QueueItem{
+			Application: application,
+			SLA:         EvaluateSLA(entered, FindSLATarget(targets, planID, status), usecase.Config.WarningPercent, now),
+			Signals:     signals[application.ID],
+		}
+
+		var form entity.VisaFormInput
+		if err := json.Unmarshal(application.VisaFormInput, &form); err == nil {
+			items[i].Destination = form.Destination
+			items[i].VisaType = form.VisaType
+			items[i].Form = &form
+		}
+	}
+
+	return items, nil
+}
+
+// EvaluateSLA computes the standing of an application that entered a status at enteredAt.
+// A zero target means the status has no SLA.
+func EvaluateSLA(enteredAt time.Time, target time.Duration, warningPercent int, now time.Time) SLAStatus {
+	status := SLAStatus{
+		State:        SLAStateNone,
+		EnteredAt:    enteredAt,
+		TimeInStatus: now.Sub(enteredAt),
+		Target:       target,
+	}
+	if target <= 0 {
+		return status
+	}
+
+	dueAt := enteredAt.Add(target)
+	status.DueAt = &dueAt
+
+	warnAfter := target * time.Duration(warningPercent) / 100
+	switch {
+	case status.TimeInStatus >= target:
+		status.State = SLAStateBreached
+	case status.TimeInStatus >= warnAfter:
+		status.State = SLAStateAtRisk
+	default:
+		status.State = SLAStateOK
+	}
+	return status
+}
+
+// FindSLATarget is the plan-specific target for the status, falling back to the default (no plan) one
+func FindSLATarget(targets []entity.SLATarget, planID *uint, status string) time.Duration {
+	var fallback time.Duration
+	for _, target := range targets {
+		if target.Status != status {
+			continue
+		}
+		if target.PlanID == nil {
+			fallback = time.Duration(target.TargetHours) * time.Hour
+		} else if planID != nil && *target.PlanID == *planID {
+			return time.Duration(target.TargetHours) * time.Hour
+		}
+	}
+	return fallback
+}
+
+// Fetch every SLA target
+func (usecase *SLAUsecase) FetchTargets(ctx context.Context) ([]entity.SLATarget, error) {
+	return usecase.Repo.FindTargets(ctx)
+}
+
+// Create or update a target
+func (usecase *SLAUsecase) SaveTarget(ctx context.Context, req request.SaveSLATargetRequest) (*entity.SLATarget, error) {
+	target := &entity.SLATarget{
+		PlanID:      req.PlanID,
+		Status:      req.Status,
+		TargetHours: req.TargetHours,
+	}
+	if err := usecase.Repo.SaveTarget(ctx, target); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// Delete a target
+func (usecase *SLAUsecase) DeleteTarget(ctx context.Context, targetID uint) error {
+	return usecase.Repo.DeleteTarget(ctx, targetID)
+}
+
+// Escalate notifies about applications at risk or in breach.
+// Runs as a scheduled job: at risk goes to the assigned agent (admins when unassigned),
+// a breach goes to the agent and every admin. Each level is sent once per stint in a status.
+func (usecase *SLAUsecase) Escalate(ctx context.Context) error {
+	items, err := usecase.queue(ctx, request.VisaQueueRequest{Assigned: "any"})
+	if err != nil {
+		return err
+	}
+
+	var admins []entity.User
+	adminsLoaded := false
+
+	for _, item := range items {
+		var level string
+		switch item.SLA.State {
+		case SLAStateAtRisk:
+			level = entity.SLALevelWarning
+		case SLAStateBreached:
+			level = entity.SLALevelBreached
+		default:
+			continue
+		}
+
+		escalation := &entity.SLAEscalation{
+			VisaApplicationID: item.Application.ID,
+			Status:            *item.Application.Status,
+			StatusEnteredAt:   item.SLA.EnteredAt,
+			Level:             level,
+			NotifiedAt:        time.Now(),
+		}
+		claimed, err := usecase.Repo.ClaimEscalation(ctx, escalation)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		// Recipients
+		var recipients []entity.User
+		if item.Application.Agent != nil {
+			recipients = append(recipients, *item.Application.Agent)
+		}
+		if level == entity.SLALevelBreached || item.Application.Agent == nil {
+			if !adminsLoaded {
+				if admins, err = usecase.UserRepo.FindUsersByRoles(ctx, "admin", "superadmin"); err != nil {
+					return err
+				}
+				adminsLoaded = true
+			}
+			recipients = append(recipients, admins...)
+		}
+
+		if err := usecase.sendEscalation(item, level, recipients); err != nil {
+			zap.L().Error("Failed to send SLA escalation", zap.String("applicationID", item.Application.ID), zap.Error(err))
+
+			// Release the claim so the next run retries
+			if err := usecase.Repo.ReleaseEscalation(ctx, escalation); err != nil {
+				zap.L().Error("Failed to release SLA escalation", zap.String("applicationID", item.Application.ID), zap.Error(err))
+			}
+		}
+	}
+
+	return nil
+}
+
+// Sends the escalation to every recipient, failing if none received it
+func (usecase *SLAUsecase) sendEscalation(item QueueItem, level string, recipients []entity.User) error {
+	if usecase.Mailer == nil || len(recipients) == 0 {
+		return nil
+	}
+
+	status := readableStatus(*item.Application.Status)
+	subject := "SLA Warning: application due soon"
+	message := fmt.Sprintf("An application has been %s for %s and is close to its turnaround target.", strings.ToLower(status), humanizeDuration(item.SLA.TimeInStatus))
+	if level == entity.SLALevelBreached {
+		subject = "SLA Breached: application overdue"
+		message = fmt.Sprintf("An application has been %s for %s, past its turnaround target.", strings.ToLower(status), humanizeDuration(item.SLA.TimeInStatus))
+	}
+
+	details := []string{
+		"Status: " + status,
+		"Target: " + humanizeDuration(item.SLA.Target),
+		"Due: " + item.SLA.DueAt.UTC().Format("02 Jan 2006 15:04 MST"),
+	}
+	if item.Destination != "" {
+		details = append(details, "Destination: "+item.Destination+" ("+item.VisaType+")")
+	}
+	if item.Application.Agent != nil {
+		details = append(details, "Assigned agent: "+item.Application.Agent.FullName)
+	} else {
+		details = append(details, "Assigned agent: none")
+	}
+
+	var lastErr error
+	sent := 0
+	for _, recipient := range recipients {
+		emailData := mailer.SLAEscalationMail(recipient.FullName, item.Application.ID, subject, message, details)
+		emailData.LinkURL = fmt.Sprintf("%s/agent/visa/applications/%s", strings.TrimRight(usecase.SiteConfig.SiteDomain, "/"), item.Application.ID)
+		emailData.LinkText = "Open application"
+
+		if err := usecase.Mailer.Send(recipient.Email, emailData); err != nil {
+			lastErr = err
+			continue
+		}
+		sent++
+	}
+
+	if sent == 0 {
+		return lastErr
+	}
+	return nil
+}
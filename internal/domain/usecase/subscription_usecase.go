@@ -0,0 +1,1180 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"japa/internal/app/http/dto/request"
+	"japa/internal/config"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/repository"
+	"japa/internal/infrastructure/mail"
+	"japa/internal/infrastructure/payment"
+	"japa/internal/pkg"
+
+	"github.com/oklog/ulid/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ERRORS
+
+var (
+	ErrAlreadySubscribed = errors.New("user already has a subscription")
+	ErrNotCanceling      = errors.New("subscription is not set to cancel")
+	ErrRenewalInProgress = errors.New("a renewal payment is being processed")
+
+	ErrSubscriptionPending = errors.New("another subscription checkout is in progress")
+
+	ErrSamePlan             = errors.New("subscription is already on this plan")
+	ErrNotActive            = errors.New("only an active subscription can change plan")
+	ErrPlanChangeInProgress = errors.New("a plan change payment is being processed")
+	ErrNoPendingChange      = errors.New("no plan change is scheduled")
+	ErrSubscriptionChanged  = errors.New("subscription changed while the plan change was priced, try again")
+	ErrInTrial              = errors.New("plan cannot change during a free trial")
+	ErrPlanNotPriced        = errors.New("plan has no price in your subscription's currency")
+)
+
+// Returned from a prepare callback when the subscription changed since it was picked, nothing is charged
+var errSubscriptionNotDue = errors.New("subscription is no longer due")
+
+// TYPES
+
+// SubscriptionUsecase sells plans and runs their lifecycle: renewals, grace periods and cancellations
+type SubscriptionUsecase struct {
+	Config     config.SubscriptionConfig
+	Billing    config.BillingConfig // Price books subscribers are priced in
+	Repo       *repository.SubscriptionRepository
+	Payments   *PaymentUsecase
+	Coupons    *CouponUsecase
+	DB         *gorm.DB
+	Mailer     *mailer.ResponsiveMailer
+}
+
+// Proration prices a plan change mid period, amounts are minor units
+type Proration struct {
+	Downgrade bool      // Waits for period end, nothing is charged
+	Credit    int64     // Unused part of the current period
+	Charge    int64     // New plan until ExpiresAt
+	Due       int64     // Charged now, after credits
+	Remainder int64     // Credit left over, taken off renewals
+	ExpiresAt time.Time // End of the period on the new plan
+}
+
+// subscriptionEvent carries everything needed to email the subscriber after commit
+type subscriptionEvent struct {
+	Name    string
+	Email   string
+	Subject string
+	Message string
+	Details []string
+}
+
+// METHODS
+
+// Initialize SubscriptionUsecase
+func NewSubscriptionUsecase(
+	cfg      config.SubscriptionConfig,
+	billing  config.BillingConfig,
+	repo     *repository.SubscriptionRepository,
+	payments *PaymentUsecase,
+	coupons  *CouponUsecase,
+	db       *gorm.DB,
+	mailer   *mailer.ResponsiveMailer,
+) *SubscriptionUsecase {
+	return &SubscriptionUsecase{Config: cfg, Billing: billing, Repo: repo, Payments: payments, Coupons: coupons, DB: db, Mailer: mailer}
+}
+
+// Subscribe starts a checkout for the plan. The subscription stays pending until the payment settles, see HandleSettlement.
+// A plan with a trial starts free for a first time subscriber instead, with no payment, and the coupon waits for the first charge.
+func (usecase *SubscriptionUsecase) Subscribe(ctx context.Context, req request.SubscribeRequest) (*entity.Subscription, *entity.Payment, error) {
+	if _, err := usecase.Repo.FindCurrent(ctx, usecase.DB, req.UserID); err == nil {
+		return nil, nil, ErrAlreadySubscribed
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+
+	plan, err := usecase.Repo.FindPlan(ctx, req.PlanID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if plan.Status != entity.PlanStatusActive {
+		return nil, nil, ErrPlanUnavailable // Superseded or archived, only existing subscribers keep it
+	}
+
+	var user entity.User
+	if err := usecase.DB.WithContext(ctx).Select("id", "full_name", "email").First(&user, "id = ?", req.UserID).Error; err != nil {
+		return nil, nil, err
+	}
+
+	// The price book the user gets, or the plan's own when it isn't sold in it
+	currency := PriceBook(usecase.Billing, req.Currency, req.Country)
+	amount, ok := plan.PriceIn(currency)
+	if !ok {
+		currency, amount = plan.Currency, plan.Amount
+	}
+
+	target := CouponTarget{
+		AppliesTo: entity.CouponAppliesToSubscriptions,
+		PlanCode:  plan.Code,
+		Amount:    amount,
+		Currency:  currency,
+	}
+	var coupon *entity.Coupon
+	var discount int64
+	if req.Coupon != "" {
+		if coupon, discount, err = usecase.Coupons.Price(ctx, req.Coupon, req.UserID, target); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if plan.TrialDays > 0 && !req.SkipTrial {
+		trialed, err := usecase.Repo.HasTrialed(ctx, usecase.DB, req.UserID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !trialed {
+			subscription, err := usecase.startTrial(ctx, &user, plan, currency, coupon)
+			return subscription, nil, err
+		}
+	}
+
+	// An unpaid checkout for the same price is handed back, any other is voided before a new one starts
+	pending, err := usecase.Repo.FindPending(ctx, usecase.DB, req.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range pending {
+		if pending[i].PaymentID == nil {
+			continue
+		}
+		existing, err := usecase.Payments.Repo.FindByID(ctx, usecase.DB, *pending[i].PaymentID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if existing.Status == entity.PaymentStatusPending && existing.AuthorizationURL != "" &&
+			pending[i].PlanID == plan.ID && existing.Currency == currency && existing.Amount == target.Amount-discount {
+			pending[i].Plan = *plan
+			return &pending[i], existing, nil
+		}
+		if _, err := usecase.Payments.Void(ctx, existing.Reference, "replaced by a newer checkout"); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	now := time.Now()
+	subscription := &entity.Subscription{
+		ID:        ulid.Make().String(),
+		UserID:    req.UserID,
+		PlanID:    plan.ID,
+		Plan:      *plan,
+		Currency:  currency,
+		Status:    entity.SubscriptionStatusPending,
+		StartedAt: now,
+		ExpiresAt: now,
+	}
+	record, err := usecase.Payments.Initialize(ctx, Checkout{
+		UserID:   req.UserID,
+		Email:    user.Email,
+		Purpose:  entity.PaymentPurposeSubscription,
+		Amount:   target.Amount - discount,
+		Currency: currency,
+		Metadata: map[string]any{"plan_id": plan.ID},
+	}, func(tx *gorm.DB, record *entity.Payment) error {
+		// Concurrent subscribes for one user queue here, the later one finds the other's subscription
+		if err := usecase.Repo.LockSubscriber(ctx, tx, req.UserID); err != nil {
+			return err
+		}
+		if _, err := usecase.Repo.FindCurrent(ctx, tx, req.UserID); err == nil {
+			return ErrAlreadySubscribed // rollback
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		pending, err := usecase.Repo.FindPending(ctx, tx, req.UserID)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return ErrSubscriptionPending // rollback
+		}
+
+		subscription.PaymentID = &record.ID
+		if coupon != nil {
+			// Re-checked under lock, held until the checkout settles
+			if _, err := usecase.Coupons.Reserve(ctx, tx, coupon.Code, req.UserID, target, record.ID, &subscription.ID); err != nil {
+				return err // rollback
+			}
+		}
+		return usecase.Repo.Create(ctx, tx, subscription) // commit on nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return subscription, record, nil
+}
+
+// Starts a free trial on plan. The first charge is taken from the user's latest saved card when the trial ends, see charge.
+func (usecase *SubscriptionUsecase) startTrial(ctx context.Context, user *entity.User, plan *entity.Plan, currency string, coupon *entity.Coupon) (*entity.Subscription, error) {
+	now := time.Now()
+	trialEndsAt := now.AddDate(0, 0, plan.TrialDays)
+	subscription := &entity.Subscription{
+		ID:              ulid.Make().String(),
+		UserID:          user.ID,
+		User:            *user,
+		PlanID:          plan.ID,
+		Plan:            *plan,
+		Currency:        currency,
+		Status:          entity.SubscriptionStatusActive,
+		StartedAt:       now,
+		ExpiresAt:       trialEndsAt,
+		PeriodStartedAt: &now,
+		TrialEndsAt:     &trialEndsAt,
+	}
+	if coupon != nil {
+		subscription.CouponCode = &coupon.Code
+	}
+
+	authorization, err := usecase.Payments.Repo.FindLatestAuthorization(ctx, usecase.DB, user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if authorization != nil {
+		subscription.AuthorizationID = &authorization.ID
+	}
+
+	if err := usecase.Repo.Create(ctx, usecase.DB, subscription); err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("Your %d day free trial of the %s plan has started.", plan.TrialDays, plan.Name)
+	if authorization == nil {
+		message += " Add a card before it ends to keep your plan."
+	}
+	usecase.notify(usecase.event(subscription, "Your trial has started", message,
+		"Trial ends on: "+formatDate(trialEndsAt),
+		"Then: "+pkg.FormatMoney(planAmount(plan, currency), currency)+" "+plan.BillingCycle))
+	return subscription, nil
+}
+
+// FetchCurrent returns the user's active or past due subscription
+func (usecase *SubscriptionUsecase) FetchCurrent(ctx context.Context, userID string) (*entity.Subscription, error) {
+	return usecase.Repo.FindCurrent(ctx, usecase.DB, userID)
+}
+
+// Cancel stops renewals. At period end the plan stays until it expires, otherwise it ends now.
+func (usecase *SubscriptionUsecase) Cancel(ctx context.Context, req request.CancelSubscriptionRequest) (*entity.Subscription, error) {
+	var subscription *entity.Subscription
+	var event *subscriptionEvent
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current, err := usecase.Repo.FindCurrent(ctx, tx, req.UserID)
+		if err != nil {
+			return err
+		}
+		subscription, err = usecase.Repo.Lock(ctx, tx, current.ID)
+		if err != nil {
+			return err
+		}
+		if subscription.RenewalPaymentID != nil {
+			return ErrRenewalInProgress // rollback
+		}
+		if subscription.ChangePaymentID != nil {
+			return ErrPlanChangeInProgress // rollback
+		}
+
+		now := time.Now()
+		if *req.AtPeriodEnd && subscription.Status == entity.SubscriptionStatusActive {
+			subscription.CancelAtPeriodEnd = true
+			event = usecase.event(subscription, "Your subscription will not renew",
+				fmt.Sprintf("Your %s plan stays active until %s and will not renew.", subscription.Plan.Name, formatDate(subscription.ExpiresAt)))
+		} else {
+			subscription.Status = entity.SubscriptionStatusCanceled
+			subscription.CanceledAt = &now
+			subscription.NextRenewalAt = nil
+			subscription.PendingPlanID = nil
+			event = usecase.event(subscription, "Your subscription was canceled",
+				fmt.Sprintf("Your %s plan was canceled and has ended.", subscription.Plan.Name))
+		}
+		return usecase.Repo.Update(ctx, tx, subscription) // commit on nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	usecase.notify(event)
+	return subscription, nil
+}
+
+// Resume undoes a cancellation at period end before the period is over
+func (usecase *SubscriptionUsecase) Resume(ctx context.Context, userID string) (*entity.Subscription, error) {
+	var subscription *entity.Subscription
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current, err := usecase.Repo.FindCurrent(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		subscription, err = usecase.Repo.Lock(ctx, tx, current.ID)
+		if err != nil {
+			return err
+		}
+		if !subscription.CancelAtPeriodEnd || subscription.Status != entity.SubscriptionStatusActive {
+			return ErrNotCanceling // rollback
+		}
+
+		subscription.CancelAtPeriodEnd = false
+		return usecase.Repo.Update(ctx, tx, subscription) // commit on nil
+	})
+	return subscription, err
+}
+
+// PreviewChange prices moving the user's subscription to another plan without changing anything
+func (usecase *SubscriptionUsecase) PreviewChange(ctx context.Context, req request.ChangePlanRequest) (*entity.Subscription, *entity.Plan, *Proration, error) {
+	subscription, plan, err := usecase.changeTarget(ctx, req)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	proration := Prorate(&subscription.Plan, plan, subscription.Currency, periodStart(subscription), subscription.ExpiresAt, time.Now(), subscription.CreditBalance)
+	return subscription, plan, &proration, nil
+}
+
+// ChangePlan moves the user's subscription to another plan. Upgrades apply now for the prorated
+// difference, charged to the saved card or through a checkout, downgrades wait for period end so
+// the paid period is used in full. Entitlements follow PlanID, which only moves once paid for.
+func (usecase *SubscriptionUsecase) ChangePlan(ctx context.Context, req request.ChangePlanRequest) (*entity.Subscription, *Proration, *entity.Payment, error) {
+	subscription, plan, err := usecase.changeTarget(ctx, req)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	now := time.Now()
+	proration := Prorate(&subscription.Plan, plan, subscription.Currency, periodStart(subscription), subscription.ExpiresAt, now, subscription.CreditBalance)
+
+	// The locked subscription is still the one priced
+	unchanged := func(locked *entity.Subscription) error {
+		if err := changeable(locked, time.Now()); err != nil {
+			return err
+		}
+		if locked.PlanID != subscription.PlanID || !locked.ExpiresAt.Equal(subscription.ExpiresAt) || locked.CreditBalance != subscription.CreditBalance {
+			return ErrSubscriptionChanged
+		}
+		return nil
+	}
+
+	// Downgrades and upgrades the credit pays for change without a payment
+	if proration.Downgrade || proration.Due == 0 {
+		var event *subscriptionEvent
+		err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			locked, err := usecase.Repo.Lock(ctx, tx, subscription.ID)
+			if err != nil {
+				return err
+			}
+			if err := unchanged(locked); err != nil {
+				return err // rollback
+			}
+
+			if proration.Downgrade {
+				locked.PendingPlanID = &plan.ID
+				locked.PendingPlan = plan
+				_, amount := renewalTerms(locked)
+				event = usecase.event(locked, "Your plan change is scheduled",
+					fmt.Sprintf("You keep your %s plan until %s, then move to %s.", locked.Plan.Name, formatDate(locked.ExpiresAt), plan.Name),
+					"Next renewal: "+pkg.FormatMoney(amount, locked.Currency))
+			} else {
+				upgrade(locked, plan, now, proration.ExpiresAt, proration.Remainder)
+				details := []string{"Renews on: " + formatDate(locked.ExpiresAt)}
+				if locked.CreditBalance > 0 {
+					details = append(details, "Credit for renewals: "+pkg.FormatMoney(locked.CreditBalance, locked.Currency))
+				}
+				event = usecase.event(locked, "Your plan was changed",
+					fmt.Sprintf("Your subscription is now on the %s plan, paid for by the unused part of your previous plan.", plan.Name),
+					details...)
+			}
+			subscription = locked
+			return usecase.Repo.Update(ctx, tx, locked) // commit on nil
+		})
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		usecase.notify(event)
+		return subscription, &proration, nil, nil
+	}
+
+	checkout := Checkout{
+		UserID:   subscription.UserID,
+		Email:    subscription.User.Email,
+		Purpose:  entity.PaymentPurposeSubscription,
+		Amount:   proration.Due,
+		Currency: subscription.Currency,
+		Metadata: map[string]any{"plan_id": plan.ID, "subscription_id": subscription.ID, "from_plan_id": subscription.PlanID, "change": true},
+	}
+	pend := func(tx *gorm.DB, record *entity.Payment) error {
+		locked, err := usecase.Repo.Lock(ctx, tx, subscription.ID)
+		if err != nil {
+			return err
+		}
+		if err := unchanged(locked); err != nil {
+			return err // rollback, nothing is charged
+		}
+		locked.PendingPlanID = &plan.ID
+		locked.ChangePaymentID = &record.ID
+		return usecase.Repo.Update(ctx, tx, locked) // commit on nil
+	}
+
+	var authorization *entity.PaymentAuthorization
+	if subscription.AuthorizationID != nil {
+		authorization, err = usecase.Payments.Repo.FindAuthorization(ctx, usecase.DB, *subscription.AuthorizationID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, nil, err
+		}
+	}
+
+	var record *entity.Payment
+	if authorization != nil {
+		// Settles right away unless the provider's answer is unknown, see changed
+		record, err = usecase.Payments.ChargeAuthorization(ctx, checkout, authorization, pend)
+		if err != nil && record == nil {
+			return nil, nil, nil, err
+		}
+	} else {
+		// Settles when the user pays the checkout
+		record, err = usecase.Payments.Initialize(ctx, checkout, pend)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	subscription, err = usecase.Repo.FindByID(ctx, usecase.DB, subscription.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return subscription, &proration, record, nil
+}
+
+// CancelChange drops a downgrade scheduled for period end
+func (usecase *SubscriptionUsecase) CancelChange(ctx context.Context, userID string) (*entity.Subscription, error) {
+	var subscription *entity.Subscription
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current, err := usecase.Repo.FindCurrent(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		subscription, err = usecase.Repo.Lock(ctx, tx, current.ID)
+		if err != nil {
+			return err
+		}
+		if subscription.ChangePaymentID != nil {
+			return ErrPlanChangeInProgress // rollback
+		}
+		if subscription.PendingPlanID == nil {
+			return ErrNoPendingChange // rollback
+		}
+
+		subscription.PendingPlanID = nil
+		subscription.PendingPlan = nil
+		return usecase.Repo.Update(ctx, tx, subscription) // commit on nil
+	})
+	return subscription, err
+}
+
+// HandleSettlement applies a subscription payment, registered for subscription payments.
+// A first payment activates the pending subscription, a renewal extends it or leaves it past due.
+func (usecase *SubscriptionUsecase) HandleSettlement(ctx context.Context, tx *gorm.DB, record *entity.Payment, verification *payment.Verification) (func(), error) {
+	discount, err := usecase.Coupons.Settle(ctx, tx, record)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := usecase.Repo.LockPendingByPayment(ctx, tx, record.ID)
+	if err == nil {
+		return usecase.activate(ctx, tx, subscription, record)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	subscription, err = usecase.Repo.LockByRenewalPayment(ctx, tx, record.ID)
+	if err == nil {
+		return usecase.renewed(ctx, tx, subscription, record, discount)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	subscription, err = usecase.Repo.LockByChangePayment(ctx, tx, record.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if record.Status == entity.PaymentStatusSucceeded && strings.Contains(string(record.Metadata), `"change":true`) {
+			zap.L().Warn("Plan change payment settled after the change was dropped", zap.String("paymentID", record.ID))
+		}
+		return nil, nil // Not ours
+	}
+	if err != nil {
+		return nil, err
+	}
+	return usecase.changed(ctx, tx, subscription, record)
+}
+
+// HandleRefund is the refund handler for subscription payments. A full refund of a payment
+// for the current period ends the subscription now, the refund email tells the subscriber.
+// Partial refunds are goodwill and leave the subscription as it is.
+func (usecase *SubscriptionUsecase) HandleRefund(ctx context.Context, tx *gorm.DB, record *entity.Payment, refund *entity.Refund) (func(), error) {
+	if record.RefundStatus != entity.PaymentRefundFull {
+		return nil, nil
+	}
+
+	var metadata struct {
+		SubscriptionID string `json:"subscription_id"` // Renewals and plan changes
+	}
+	if len(record.Metadata) > 0 {
+		if err := json.Unmarshal(record.Metadata, &metadata); err != nil {
+			return nil, err
+		}
+	}
+	var subscription *entity.Subscription
+	var err error
+	if metadata.SubscriptionID != "" {
+		subscription, err = usecase.Repo.Lock(ctx, tx, metadata.SubscriptionID)
+	} else {
+		subscription, err = usecase.Repo.LockByPayment(ctx, tx, record.ID)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil // Not ours
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if subscription.Status != entity.SubscriptionStatusActive && subscription.Status != entity.SubscriptionStatusPastDue {
+		return nil, nil
+	}
+	if record.PaidAt == nil || (subscription.PeriodStartedAt != nil && record.PaidAt.Before(*subscription.PeriodStartedAt)) {
+		return nil, nil // Paid for a period that is over
+	}
+
+	now := time.Now()
+	subscription.Status = entity.SubscriptionStatusCanceled
+	subscription.CanceledAt = &now
+	subscription.ExpiresAt = now
+	subscription.CancelAtPeriodEnd = false
+	subscription.NextRenewalAt = nil
+	subscription.GraceEndsAt = nil
+	subscription.PendingPlanID = nil
+	return nil, usecase.Repo.Update(ctx, tx, subscription)
+}
+
+// First payment settled. A user who paid for another subscription first gets this payment refunded instead.
+func (usecase *SubscriptionUsecase) activate(ctx context.Context, tx *gorm.DB, subscription *entity.Subscription, record *entity.Payment) (func(), error) {
+	now := time.Now()
+	if record.Status == entity.PaymentStatusSucceeded {
+		if err := usecase.Repo.LockSubscriber(ctx, tx, subscription.UserID); err != nil {
+			return nil, err
+		}
+		current, err := usecase.Repo.FindCurrent(ctx, tx, subscription.UserID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if current != nil && current.ID != subscription.ID {
+			subscription.Status = entity.SubscriptionStatusCanceled
+			subscription.CanceledAt = &now
+			if err := usecase.Repo.Update(ctx, tx, subscription); err != nil {
+				return nil, err
+			}
+			reference := record.Reference
+			return func() {
+				// Refunded once the settlement commits, the refund handler leaves the canceled subscription alone
+				_, err := usecase.Payments.Refund(context.Background(), request.RefundPaymentRequest{
+					Reference: reference,
+					Reason:    "You already have an active subscription",
+				})
+				if err != nil {
+					zap.L().Warn("Duplicate subscription payment not refunded", zap.String("reference", reference), zap.Error(err))
+				}
+			}, nil
+		}
+	}
+
+	var event *subscriptionEvent
+	if record.Status == entity.PaymentStatusSucceeded {
+		subscription.Status = entity.SubscriptionStatusActive
+		subscription.StartedAt = *record.PaidAt
+		subscription.PeriodStartedAt = record.PaidAt
+		subscription.ExpiresAt = PeriodEnd(*record.PaidAt, subscription.Plan.BillingCycle)
+		subscription.AuthorizationID = record.AuthorizationID
+		event = usecase.event(subscription, "Your subscription is active",
+			fmt.Sprintf("Thank you, your %s plan is now active.", subscription.Plan.Name),
+			"Amount paid: "+pkg.FormatMoney(record.Amount, record.Currency),
+			"Renews on: "+formatDate(subscription.ExpiresAt))
+	} else {
+		subscription.Status = entity.SubscriptionStatusCanceled
+		subscription.CanceledAt = &now
+	}
+
+	if err := usecase.Repo.Update(ctx, tx, subscription); err != nil {
+		return nil, err
+	}
+	return func() { usecase.notify(event) }, nil
+}
+
+// Renewal charge settled, discount is what a coupon took off it
+func (usecase *SubscriptionUsecase) renewed(ctx context.Context, tx *gorm.DB, subscription *entity.Subscription, record *entity.Payment, discount int64) (func(), error) {
+	now := time.Now()
+	subscription.RenewalPaymentID = nil
+
+	var event *subscriptionEvent
+	if record.Status == entity.PaymentStatusSucceeded {
+		// Credit made up the rest of the plan's price
+		if credit := planAmount(&subscription.Plan, subscription.Currency) - discount - record.Amount; credit > 0 {
+			subscription.CreditBalance = max(subscription.CreditBalance-credit, 0)
+		}
+		if record.AuthorizationID != nil {
+			subscription.AuthorizationID = record.AuthorizationID
+		}
+		subscription.CouponCode = nil
+		details := []string{"Amount paid: " + pkg.FormatMoney(record.Amount, record.Currency)}
+		if discount > 0 {
+			details = append(details, "Discount: "+pkg.FormatMoney(discount, record.Currency))
+		}
+		converted := subscription.InTrial()
+		event = usecase.extend(subscription, *record.PaidAt, details...)
+		if converted {
+			event.Subject = "Your trial has ended"
+			event.Message = fmt.Sprintf("Your %s trial has ended and your paid plan has started.", subscription.Plan.Name)
+		}
+	} else if subscription.InTrial() {
+		// A trial that doesn't convert has no grace period
+		subscription.Status = entity.SubscriptionStatusExpired
+		subscription.NextRenewalAt = nil
+		event = usecase.event(subscription, "Your trial has ended",
+			fmt.Sprintf("We couldn't take payment when your %s trial ended, so the plan has expired. Subscribe again to restore it.", subscription.Plan.Name))
+	} else {
+		reason := "the payment was declined"
+		if record.FailureReason != nil {
+			reason = *record.FailureReason
+		}
+		event = usecase.pastDue(subscription, now, reason)
+	}
+
+	if err := usecase.Repo.Update(ctx, tx, subscription); err != nil {
+		return nil, err
+	}
+	return func() { usecase.notify(event) }, nil
+}
+
+// Upgrade charge settled
+func (usecase *SubscriptionUsecase) changed(ctx context.Context, tx *gorm.DB, subscription *entity.Subscription, record *entity.Payment) (func(), error) {
+	plan := subscription.PendingPlan
+	current := subscription.Plan
+	subscription.ChangePaymentID = nil
+	subscription.PendingPlanID = nil
+	subscription.PendingPlan = nil
+
+	var event *subscriptionEvent
+	if record.Status == entity.PaymentStatusSucceeded && plan != nil {
+		// Another billing cycle starts its period when paid, see Prorate
+		expiresAt := subscription.ExpiresAt
+		if !sameCycle(&current, plan) {
+			expiresAt = PeriodEnd(*record.PaidAt, plan.BillingCycle)
+		}
+		upgrade(subscription, plan, *record.PaidAt, expiresAt, 0) // The charge used up any credit
+		event = usecase.event(subscription, "Your plan was changed",
+			fmt.Sprintf("Your subscription is now on the %s plan.", plan.Name),
+			"Amount paid: "+pkg.FormatMoney(record.Amount, record.Currency),
+			"Renews on: "+formatDate(subscription.ExpiresAt))
+	} else if plan != nil {
+		event = usecase.event(subscription, "Your plan change didn't go through",
+			fmt.Sprintf("We couldn't take payment for the move to %s, your %s plan is unchanged.", plan.Name, current.Name))
+	}
+
+	if err := usecase.Repo.Update(ctx, tx, subscription); err != nil {
+		return nil, err
+	}
+	return func() { usecase.notify(event) }, nil
+}
+
+// Starts the next paid period. A period the subscriber kept through grace is still paid for, a lapsed one restarts at paidAt.
+// Periods are counted from StartedAt so month ends don't drift, paid periods after a trial count from its end.
+func (usecase *SubscriptionUsecase) extend(subscription *entity.Subscription, paidAt time.Time, details ...string) *subscriptionEvent {
+	periodStart := subscription.ExpiresAt
+	if subscription.Status != entity.SubscriptionStatusActive && subscription.Status != entity.SubscriptionStatusPastDue {
+		periodStart = paidAt
+		subscription.StartedAt = periodStart
+		subscription.CanceledAt = nil
+	} else if subscription.InTrial() {
+		subscription.StartedAt = periodStart
+	}
+	subscription.Status = entity.SubscriptionStatusActive
+	subscription.PeriodStartedAt = &periodStart
+	subscription.ExpiresAt = PeriodEndAfter(subscription.StartedAt, subscription.Plan.BillingCycle, periodStart)
+	subscription.RenewalAttempts = 0
+	subscription.NextRenewalAt = nil
+	subscription.GraceEndsAt = nil
+	subscription.ReminderSentAt = nil
+
+	return usecase.event(subscription, "Your subscription was renewed",
+		fmt.Sprintf("Your %s plan was renewed.", subscription.Plan.Name),
+		append(details, "Next renewal: "+formatDate(subscription.ExpiresAt))...)
+}
+
+// Records a failed renewal: the grace period starts on the first failure, retries follow until they run out
+func (usecase *SubscriptionUsecase) pastDue(subscription *entity.Subscription, now time.Time, reason string) *subscriptionEvent {
+	subscription.Status = entity.SubscriptionStatusPastDue
+	subscription.RenewalAttempts++
+	if subscription.GraceEndsAt == nil {
+		graceEndsAt := subscription.ExpiresAt.Add(usecase.Config.GracePeriod)
+		if graceEndsAt.Before(now) {
+			graceEndsAt = now.Add(usecase.Config.GracePeriod)
+		}
+		subscription.GraceEndsAt = &graceEndsAt
+	}
+
+	subscription.NextRenewalAt = nil
+	nextRenewalAt := now.Add(usecase.Config.RetryInterval)
+	if subscription.AuthorizationID != nil &&
+		subscription.RenewalAttempts < usecase.Config.MaxRenewalAttempts &&
+		nextRenewalAt.Before(*subscription.GraceEndsAt) {
+		subscription.NextRenewalAt = &nextRenewalAt
+	}
+
+	details := []string{
+		"Reason: " + reason,
+		"Plan active until: " + formatDate(*subscription.GraceEndsAt),
+	}
+	if subscription.NextRenewalAt != nil {
+		details = append(details, "Next attempt: "+formatDate(*subscription.NextRenewalAt))
+	}
+	return usecase.event(subscription, "We couldn't renew your subscription",
+		fmt.Sprintf("We couldn't renew your %s plan. Please update your payment method to keep it.", subscription.Plan.Name),
+		details...)
+}
+
+// Renew is the scheduled lifecycle job: period end cancellations, renewals, retries,
+// expiries after the grace period and upcoming renewal reminders.
+// Each subscription is handled on its own, one failure doesn't stop the rest.
+func (usecase *SubscriptionUsecase) Renew(ctx context.Context) error {
+	const batch = 100
+	now := time.Now()
+
+	cancellations, err := usecase.Repo.FindDueCancellations(ctx, now, batch)
+	if err != nil {
+		return err
+	}
+	for _, due := range cancellations {
+		usecase.transition(ctx, due.ID, "cancel", func(subscription *entity.Subscription) (*subscriptionEvent, error) {
+			if subscription.Status != entity.SubscriptionStatusActive || !subscription.CancelAtPeriodEnd || subscription.ExpiresAt.After(now) {
+				return nil, errSubscriptionNotDue
+			}
+			subscription.Status = entity.SubscriptionStatusCanceled
+			subscription.CanceledAt = &now
+			return usecase.event(subscription, "Your subscription has ended",
+				fmt.Sprintf("Your %s plan has ended as requested. You can subscribe again at any time.", subscription.Plan.Name)), nil
+		})
+	}
+
+	renewals, err := usecase.Repo.FindDueRenewals(ctx, now, batch)
+	if err != nil {
+		return err
+	}
+	retries, err := usecase.Repo.FindDueRetries(ctx, now, batch)
+	if err != nil {
+		return err
+	}
+	for _, due := range append(renewals, retries...) {
+		if err := usecase.charge(ctx, due.ID, now); err != nil && !errors.Is(err, errSubscriptionNotDue) {
+			zap.L().Warn("Subscription renewal failed", zap.String("subscriptionID", due.ID), zap.Error(err))
+		}
+	}
+
+	expiries, err := usecase.Repo.FindDueExpiries(ctx, now, batch)
+	if err != nil {
+		return err
+	}
+	for _, due := range expiries {
+		usecase.transition(ctx, due.ID, "expire", func(subscription *entity.Subscription) (*subscriptionEvent, error) {
+			if subscription.Status != entity.SubscriptionStatusPastDue || subscription.GraceEndsAt == nil || subscription.GraceEndsAt.After(now) {
+				return nil, errSubscriptionNotDue
+			}
+			subscription.Status = entity.SubscriptionStatusExpired
+			subscription.NextRenewalAt = nil
+			return usecase.event(subscription, "Your subscription has expired",
+				fmt.Sprintf("Your %s plan expired because we couldn't take payment. Subscribe again to restore it.", subscription.Plan.Name)), nil
+		})
+	}
+
+	reminders, err := usecase.Repo.FindDueReminders(ctx, now, now.Add(usecase.Config.ReminderBefore), batch)
+	if err != nil {
+		return err
+	}
+	for _, due := range reminders {
+		usecase.transition(ctx, due.ID, "remind", func(subscription *entity.Subscription) (*subscriptionEvent, error) {
+			if subscription.Status != entity.SubscriptionStatusActive || subscription.CancelAtPeriodEnd || subscription.ReminderSentAt != nil {
+				return nil, errSubscriptionNotDue
+			}
+			subscription.ReminderSentAt = &now
+			plan, amount := renewalTerms(subscription)
+			if subscription.InTrial() {
+				message := fmt.Sprintf("Your %s trial ends on %s and we'll charge your saved card to continue.", plan.Name, formatDate(subscription.ExpiresAt))
+				if subscription.AuthorizationID == nil {
+					message = fmt.Sprintf("Your %s trial ends on %s. Add a card before then to keep your plan.", plan.Name, formatDate(subscription.ExpiresAt))
+				}
+				details := []string{"Amount: " + pkg.FormatMoney(amount, subscription.Currency)}
+				if subscription.CouponCode != nil {
+					details = append(details, "Coupon: "+*subscription.CouponCode)
+				}
+				return usecase.event(subscription, "Your trial ends soon", message, details...), nil
+			}
+			return usecase.event(subscription, "Your subscription renews soon",
+				fmt.Sprintf("Your %s plan renews automatically on %s.", plan.Name, formatDate(subscription.ExpiresAt)),
+				"Amount: "+pkg.FormatMoney(amount, subscription.Currency)), nil
+		})
+	}
+
+	if total := len(cancellations) + len(renewals) + len(retries) + len(expiries) + len(reminders); total > 0 {
+		zap.L().Info(
+			"Processed subscription lifecycle",
+			zap.Int("cancellations", len(cancellations)),
+			zap.Int("renewals", len(renewals)),
+			zap.Int("retries", len(retries)),
+			zap.Int("expiries", len(expiries)),
+			zap.Int("reminders", len(reminders)),
+		)
+	}
+	return nil
+}
+
+// Charges the stored card for the next period. A subscription without one goes past due right away.
+func (usecase *SubscriptionUsecase) charge(ctx context.Context, subscriptionID string, now time.Time) error {
+	due := func(subscription *entity.Subscription) bool {
+		if subscription.RenewalPaymentID != nil || subscription.ChangePaymentID != nil {
+			return false
+		}
+		switch subscription.Status {
+		case entity.SubscriptionStatusActive:
+			return !subscription.CancelAtPeriodEnd && !subscription.ExpiresAt.After(now)
+		case entity.SubscriptionStatusPastDue:
+			return subscription.NextRenewalAt != nil && !subscription.NextRenewalAt.After(now)
+		default:
+			return false
+		}
+	}
+
+	subscription, err := usecase.Repo.FindByID(ctx, usecase.DB, subscriptionID)
+	if err != nil {
+		return err
+	}
+	if !due(subscription) {
+		return errSubscriptionNotDue
+	}
+	plan, amount := renewalTerms(subscription)
+	if _, ok := plan.PriceIn(subscription.Currency); !ok {
+		return fmt.Errorf("%w: %s %s", ErrPlanNotPriced, plan.Code, subscription.Currency) // Never charged for free, retried next run
+	}
+
+	// The locked subscription still renews on the terms priced here
+	priced := func(locked *entity.Subscription) bool {
+		lockedPlan, lockedAmount := renewalTerms(locked)
+		return due(locked) && lockedPlan.ID == plan.ID && lockedAmount == amount
+	}
+
+	// Credit covers the period, nothing to charge
+	if amount == 0 {
+		usecase.transition(ctx, subscriptionID, "renew", func(locked *entity.Subscription) (*subscriptionEvent, error) {
+			if !priced(locked) {
+				return nil, errSubscriptionNotDue
+			}
+			switchPending(locked)
+			price := planAmount(&locked.Plan, locked.Currency)
+			locked.CreditBalance -= price
+			return usecase.extend(locked, now, "Paid from credit: "+pkg.FormatMoney(price, locked.Currency)), nil
+		})
+		return nil
+	}
+
+	var authorization *entity.PaymentAuthorization
+	if subscription.AuthorizationID != nil {
+		authorization, err = usecase.Payments.Repo.FindAuthorization(ctx, usecase.DB, *subscription.AuthorizationID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+	if authorization == nil && subscription.InTrial() {
+		usecase.transition(ctx, subscriptionID, "expire", func(locked *entity.Subscription) (*subscriptionEvent, error) {
+			if !priced(locked) || !locked.InTrial() {
+				return nil, errSubscriptionNotDue
+			}
+			locked.Status = entity.SubscriptionStatusExpired
+			return usecase.event(locked, "Your trial has ended",
+				fmt.Sprintf("Your %s trial has ended. Subscribe to keep using the plan.", locked.Plan.Name)), nil
+		})
+		return nil
+	}
+	if authorization == nil {
+		usecase.transition(ctx, subscriptionID, "renew", func(locked *entity.Subscription) (*subscriptionEvent, error) {
+			if !priced(locked) {
+				return nil, errSubscriptionNotDue
+			}
+			switchPending(locked)
+			locked.AuthorizationID = nil
+			return usecase.pastDue(locked, now, "no saved card to charge"), nil
+		})
+		return nil
+	}
+
+	// A coupon given with a trial comes off the first charge, unless it's no longer valid
+	target := CouponTarget{
+		AppliesTo: entity.CouponAppliesToSubscriptions,
+		PlanCode:  plan.Code,
+		Amount:    amount,
+		Currency:  subscription.Currency,
+	}
+	var coupon *entity.Coupon
+	var discount int64
+	if subscription.CouponCode != nil && subscription.InTrial() {
+		coupon, discount, err = usecase.Coupons.Price(ctx, *subscription.CouponCode, subscription.UserID, target)
+		if err != nil {
+			zap.L().Info("Trial coupon not applied", zap.String("subscriptionID", subscription.ID), zap.Error(err))
+			coupon, discount = nil, 0
+		}
+	}
+
+	// The settlement handler moves the subscription on, see renewed
+	_, err = usecase.Payments.ChargeAuthorization(ctx, Checkout{
+		UserID:   subscription.UserID,
+		Email:    subscription.User.Email,
+		Purpose:  entity.PaymentPurposeSubscription,
+		Amount:   amount - discount,
+		Currency: subscription.Currency,
+		Metadata: map[string]any{"plan_id": plan.ID, "subscription_id": subscription.ID, "renewal": true},
+	}, authorization, func(tx *gorm.DB, record *entity.Payment) error {
+		locked, err := usecase.Repo.Lock(ctx, tx, subscriptionID)
+		if err != nil {
+			return err
+		}
+		if !priced(locked) {
+			return errSubscriptionNotDue // rollback, nothing is charged
+		}
+		if coupon != nil {
+			if _, err := usecase.Coupons.Reserve(ctx, tx, coupon.Code, locked.UserID, target, record.ID, &locked.ID); err != nil {
+				return err // rollback, priced again on the next run
+			}
+		}
+		switchPending(locked) // The new period is on the downgraded plan, paid or not
+		locked.RenewalPaymentID = &record.ID
+		return usecase.Repo.Update(ctx, tx, locked) // commit on nil
+	})
+	return err
+}
+
+// Applies change to the locked subscription and emails the subscriber after commit.
+// Failures are logged, errSubscriptionNotDue means another run got there first.
+func (usecase *SubscriptionUsecase) transition(
+	ctx      context.Context,
+	id       string,
+	action   string,
+	change   func(subscription *entity.Subscription) (*subscriptionEvent, error),
+) {
+	var event *subscriptionEvent
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		subscription, err := usecase.Repo.Lock(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if event, err = change(subscription); err != nil {
+			return err // rollback
+		}
+		return usecase.Repo.Update(ctx, tx, subscription) // commit on nil
+	})
+	if errors.Is(err, errSubscriptionNotDue) {
+		return
+	}
+	if err != nil {
+		zap.L().Warn("Subscription transition failed", zap.String("action", action), zap.String("subscriptionID", id), zap.Error(err))
+		return
+	}
+
+	usecase.notify(event)
+}
+
+// Email for the subscription's owner, details are rendered as a list after the plan
+func (usecase *SubscriptionUsecase) event(subscription *entity.Subscription, subject string, message string, details ...string) *subscriptionEvent {
+	return &subscriptionEvent{
+		Name:    subscription.User.FullName,
+		Email:   subscription.User.Email,
+		Subject: subject,
+		Message: message,
+		Details: append([]string{"Plan: " + subscription.Plan.Name}, details...),
+	}
+}
+
+// notify sends the subscriber email in the background.
+// It must be called after commit: a failed delivery is logged, never returned.
+func (usecase *SubscriptionUsecase) notify(event *subscriptionEvent) {
+	if usecase.Mailer == nil || event == nil || event.Email == "" {
+		return
+	}
+
+	emailData := mailer.SubscriptionMail(event.Name, event.Subject, event.Message, event.Details)
+	go func() {
+		// Recover from any unexpected panic so the app doesn't crash
+		defer func() {
+			if panicErr := recover(); panicErr != nil {
+				zap.L().Error("Panic recovered while sending subscription email", zap.Any("error", panicErr))
+			}
+		}()
+
+		if err := usecase.Mailer.Send(event.Email, emailData); err != nil {
+			zap.L().Error("Failed to send subscription email", zap.String("subject", event.Subject), zap.Error(err))
+		}
+	}()
+}
+
+// The user's subscription and the plan it would move to, checked it can
+func (usecase *SubscriptionUsecase) changeTarget(ctx context.Context, req request.ChangePlanRequest) (*entity.Subscription, *entity.Plan, error) {
+	current, err := usecase.Repo.FindCurrent(ctx, usecase.DB, req.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	subscription, err := usecase.Repo.FindByID(ctx, usecase.DB, current.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := changeable(subscription, time.Now()); err != nil {
+		return nil, nil, err
+	}
+
+	plan, err := usecase.Repo.FindPlan(ctx, req.PlanID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if plan.ID == subscription.PlanID {
+		return nil, nil, ErrSamePlan
+	}
+	if plan.Status != entity.PlanStatusActive {
+		return nil, nil, ErrPlanUnavailable
+	}
+	if _, ok := plan.PriceIn(subscription.Currency); !ok {
+		return nil, nil, ErrPlanNotPriced // Subscribers stay in the price book they bought in
+	}
+	return subscription, plan, nil
+}
+
+// Only an active subscription within its period and with no payment in flight changes plan
+func changeable(subscription *entity.Subscription, now time.Time) error {
+	switch {
+	case subscription.Status != entity.SubscriptionStatusActive || !subscription.InGoodStanding(now):
+		return ErrNotActive
+	case subscription.InTrial():
+		return ErrInTrial
+	case subscription.RenewalPaymentID != nil:
+		return ErrRenewalInProgress
+	case subscription.ChangePaymentID != nil:
+		return ErrPlanChangeInProgress
+	}
+	return nil
+}
+
+// Moves the subscription onto plan for a period ending at expiresAt, with credit owed for renewals
+func upgrade(subscription *entity.Subscription, plan *entity.Plan, start time.Time, expiresAt time.Time, credit int64) {
+	if !expiresAt.Equal(subscription.ExpiresAt) {
+		subscription.StartedAt = start // Periods on the new cycle count from here
+		subscription.PeriodStartedAt = &start
+		subscription.ReminderSentAt = nil
+	}
+	subscription.PlanID = plan.ID
+	subscription.Plan = *plan
+	subscription.ExpiresAt = expiresAt
+	subscription.CreditBalance = credit
+	subscription.PendingPlanID = nil
+	subscription.PendingPlan = nil
+}
+
+// Applies a downgrade scheduled for period end
+func switchPending(subscription *entity.Subscription) {
+	if subscription.PendingPlan == nil || subscription.ChangePaymentID != nil {
+		return
+	}
+	if !sameCycle(&subscription.Plan, subscription.PendingPlan) {
+		subscription.StartedAt = subscription.ExpiresAt // Periods on the new cycle count from the one starting now
+	}
+	subscription.PlanID = subscription.PendingPlan.ID
+	subscription.Plan = *subscription.PendingPlan
+	subscription.PendingPlanID = nil
+	subscription.PendingPlan = nil
+}
+
+// Plan and amount the next renewal charges: a scheduled downgrade's plan, less credit
+func renewalTerms(subscription *entity.Subscription) (*entity.Plan, int64) {
+	plan := &subscription.Plan
+	if subscription.PendingPlan != nil && subscription.ChangePaymentID == nil {
+		plan = subscription.PendingPlan
+	}
+	return plan, max(planAmount(plan, subscription.Currency)-subscription.CreditBalance, 0)
+}
+
+// Prorate prices moving from current to next at now, within the period from start to end.
+// A plan costing less per day is a downgrade, left for period end. An upgrade on the same
+// billing cycle keeps the period and costs the difference for the time left, on another
+// cycle a new period starts now, less the unused part of this one. Both are priced in currency,
+// the subscription's, and balance is credit already owed.
+func Prorate(current *entity.Plan, next *entity.Plan, currency string, start time.Time, end time.Time, now time.Time, balance int64) Proration {
+	proration := Proration{ExpiresAt: end}
+	if dailyRate(next, currency, now) < dailyRate(current, currency, now) {
+		proration.Downgrade = true
+		return proration
+	}
+
+	total := end.Sub(start)
+	remaining := min(max(end.Sub(now), 0), total)
+	proration.Credit = share(planAmount(current, currency), remaining, total)
+	if sameCycle(current, next) {
+		proration.Charge = share(planAmount(next, currency), remaining, total)
+	} else {
+		proration.Charge = planAmount(next, currency)
+		proration.ExpiresAt = PeriodEnd(now, next.BillingCycle)
+	}
+
+	proration.Due = proration.Charge - proration.Credit - balance
+	if proration.Due < 0 {
+		proration.Remainder = -proration.Due
+		proration.Due = 0
+	}
+	return proration
+}
+
+// Start of the subscription's current period. Older rows don't record it, it's walked forward from StartedAt.
+func periodStart(subscription *entity.Subscription) time.Time {
+	if subscription.PeriodStartedAt != nil {
+		return *subscription.PeriodStartedAt
+	}
+	start := subscription.StartedAt
+	for end := PeriodEndAfter(subscription.StartedAt, subscription.Plan.BillingCycle, start); end.Before(subscription.ExpiresAt); end = PeriodEndAfter(subscription.StartedAt, subscription.Plan.BillingCycle, start) {
+		start = end
+	}
+	return start
+}
+
+// Price of a day on the plan, for billing periods starting at now
+func dailyRate(plan *entity.Plan, currency string, now time.Time) float64 {
+	return float64(planAmount(plan, currency)) / PeriodEnd(now, plan.BillingCycle).Sub(now).Hours() * 24
+}
+
+func sameCycle(a *entity.Plan, b *entity.Plan) bool {
+	return strings.EqualFold(a.BillingCycle, b.BillingCycle)
+}
+
+// amount for part of whole, rounded to the minor unit
+func share(amount int64, part time.Duration, whole time.Duration) int64 {
+	if whole <= 0 {
+		return 0
+	}
+	return int64(math.Round(float64(amount) * float64(part) / float64(whole)))
+}
+
+// The plan's price in currency, 0 when it has none. Plans are checked with PriceIn before they are sold in a currency.
+func planAmount(plan *entity.Plan, currency string) int64 {
+	amount, _ := plan.PriceIn(currency)
+	return amount
+}
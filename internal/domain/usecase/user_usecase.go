@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	//"fmt"
+	"strings"
 	"time"
 
 	"japa/internal/app/http/dto/request"
@@ -28,13 +29,14 @@ import (
 type UserUsecase struct {
 	JWTConfig  config.JWTConfig 
 	Repo      *repository.UserRepository
+	Affiliates *AffiliateUsecase
 	DB        *gorm.DB
 	Mailer    *mailer.ResponsiveMailer
 }
 
 // Initialize UserUsecase
-func NewUserUsecase(jwtConfig config.JWTConfig, repo *repository.UserRepository, db *gorm.DB, mailer *mailer.ResponsiveMailer) *UserUsecase {
-	return &UserUsecase{JWTConfig: jwtConfig, Repo: repo, DB: db, Mailer: mailer}
+func NewUserUsecase(jwtConfig config.JWTConfig, repo *repository.UserRepository, affiliates *AffiliateUsecase, db *gorm.DB, mailer *mailer.ResponsiveMailer) *UserUsecase {
+	return &UserUsecase{JWTConfig: jwtConfig, Repo: repo, Affiliates: affiliates, DB: db, Mailer: mailer}
 }
 
 // Registers a new user and sends a welcome email
@@ -60,7 +62,15 @@ func (usecase *UserUsecase) RegisterUser(ctx context.Context, req request.Create
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		}
+		if req.Country != "" {
+			country := strings.ToUpper(req.Country)
+			user.Country = &country
+		}
 
+		// Partner who referred the user, by code or referral link
+		if err := usecase.Affiliates.Attribute(ctx, tx, user, req.ReferralCode); err != nil {
+			return err // rollback
+		}
 
 		if err := usecase.Repo.CreateUser(ctx, tx, user); err != nil {
 			return err // rollback
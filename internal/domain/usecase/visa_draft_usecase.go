@@ -0,0 +1,335 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"encoding/json"
+	"time"
+
+	"japa/internal/app/http/dto/request"
+	"japa/internal/domain/entity"
+	"japa/internal/pkg"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// ERRORS
+
+var (
+	ErrNotDraft              = errors.New("application is no longer a draft")
+	ErrVersionConflict       = errors.New("draft was modified elsewhere, reload and try again")
+	ErrIncompleteApplication = errors.New("application is incomplete")
+)
+
+// METHODS
+
+// Creates a draft application from partially filled input
+func (usecase *VisaUsecase) CreateDraft(ctx context.Context, req request.CreateVisaDraftRequest) (*entity.VisaApplication, error) {
+	formInput := []byte(req.VisaFormInput)
+	if len(formInput) == 0 {
+		formInput = []byte("{}")
+	}
+
+	// Partner the applicant signed up through
+	var applicant entity.User
+	if err := usecase.DB.WithContext(ctx).Select("id", "referrer_id").First(&applicant, "id = ?", req.UserID).Error; err != nil {
+		return nil, err
+	}
+
+	status := entity.VisaStatusDraft
+	draft := &entity.VisaApplication{
+		ID:            ulid.Make().String(),
+		UserID:        req.UserID,
+		VisaFormInput: formInput,
+		VisaFormURL:   req.VisaFormURL,
+		Status:        &status,
+		ReferrerID:    applicant.ReferrerID,
+		Version:       1,
+	}
+
+	if err := usecase.Repo.Create(usecase.DB.WithContext(ctx), draft); err != nil {
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+
+// Fetches the user's drafts
+func (usecase *VisaUsecase) FetchDrafts(ctx context.Context, userID string) ([]entity.VisaApplication, error) {
+	return usecase.Repo.FindDrafts(ctx, userID)
+}
+
+
+// Fetches a single draft to resume editing
+func (usecase *VisaUsecase) FetchDraft(ctx context.Context, userID string, applicationID string) (*entity.VisaApplication, error) {
+	return usecase.findOwnDraft(ctx, usecase.DB, userID, applicationID)
+}
+
+
+// Merges the autosave patch into the draft.
+// Fails with ErrVersionConflict if the draft changed since req.Version.
+func (usecase *VisaUsecase) AutosaveDraft(ctx context.Context, req request.UpdateVisaDraftRequest) (*entity.VisaApplication, error) {
+	var draft *entity.VisaApplication
+
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		draft, err = usecase.findOwnDraft(ctx, tx, req.UserID, req.ApplicationID)
+		if err != nil {
+			return err
+		}
+		if draft.Version != req.Version {
+			return ErrVersionConflict
+		}
+
+		// JSON merge semantics: only the sent keys change, null removes a key
+		if len(req.VisaFormInput) > 0 {
+			merged, err := pkg.MergePatch(draft.VisaFormInput, req.VisaFormInput)
+			if err != nil {
+				return err
+			}
+			draft.VisaFormInput = merged
+		}
+		if req.VisaFormURL != nil {
+			draft.VisaFormURL = req.VisaFormURL
+		}
+
+		updated, err := usecase.Repo.UpdateDraft(ctx, tx, draft, req.Version)
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return ErrVersionConflict // Lost the race to a concurrent autosave
+		}
+		draft.Version = req.Version + 1
+
+		// Dependents travel with the principal
+		if draft.GroupID != nil && draft.Relationship != nil && *draft.Relationship == entity.GroupRelationPrincipal && len(req.VisaFormInput) > 0 {
+			return usecase.syncGroupTravel(ctx, tx, draft) // commit on nil
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+
+// Applies full validation to the draft and starts the application lifecycle
+func (usecase *VisaUsecase) SubmitDraft(ctx context.Context, req request.SubmitVisaDraftRequest, v *validator.Validate) error {
+	var event visaApplicationEvent
+
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		draft, err := usecase.findOwnDraft(ctx, tx, req.UserID, req.ApplicationID)
+		if err != nil {
+			return err
+		}
+		if draft.Version != req.Version {
+			return ErrVersionConflict
+		}
+
+		// Full validation, same rules as a direct submission
+		submission, err := DraftToSubmission(draft)
+		if err != nil {
+			return err
+		}
+		if err := submission.Validate(v); err != nil {
+			return fmt.Errorf("%w: %s", ErrIncompleteApplication, err.Error())
+		}
+		if err := ValidateVisaDates(usecase.Validation, submission.VisaFormInput, "visa_form_input", time.Now()); err != nil {
+			return err
+		}
+		if err := usecase.Eligibility.CheckSubmission(ctx, submission.VisaFormInput); err != nil {
+			return err
+		}
+
+		jsonVisaFormInput, err := marshalVisaFormInput(submission.VisaFormInput)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrIncompleteApplication, err.Error())
+		}
+
+		// Leaving draft needs payment, a principal also pays for the dependents submitted with it
+		paying, err := usecase.submissionIDs(ctx, tx, draft)
+		if err != nil {
+			return err
+		}
+		if err := usecase.Billing.RequirePaid(ctx, tx, draft.UserID, paying); err != nil {
+			return err
+		}
+
+		status := entity.VisaStatusPending
+		draft.VisaFormInput = jsonVisaFormInput
+		draft.Status = &status
+
+		updated, err := usecase.Repo.SubmitDraft(ctx, tx, draft, req.Version)
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return ErrVersionConflict
+		}
+
+		draftStatus := entity.VisaStatusDraft
+		if err := usecase.Repo.CreateStatusHistory(ctx, tx, &entity.VisaStatusHistory{
+			VisaApplicationID: draft.ID,
+			FromStatus:        &draftStatus,
+			ToStatus:          status,
+		}); err != nil {
+			return err // rollback
+		}
+
+		// Dependents were fully validated when added, they start with the principal
+		if draft.GroupID != nil && draft.Relationship != nil && *draft.Relationship == entity.GroupRelationPrincipal {
+			if err := usecase.submitGroupDependents(ctx, tx, *draft.GroupID, draft.User, len(paying)-1); err != nil {
+				return err // rollback
+			}
+		}
+
+		event = visaApplicationEvent{
+			Type:          VisaEventCreated,
+			ApplicationID: draft.ID,
+			Name:          draft.User.FullName,
+			Email:         draft.User.Email,
+			Status:        status,
+		}
+
+		if err := usecase.publish(ctx, tx, VisaEventCreated, draft, &draftStatus); err != nil {
+			return err // rollback
+		}
+
+		return usecase.Fraud.Screen(ctx, tx, draft, draft.User) // commit on nil
+	})
+	if err != nil {
+		return err
+	}
+
+	usecase.notify(event)
+	return nil
+}
+
+
+// Moves the group's draft dependents to pending. Fails with ErrVersionConflict unless exactly
+// expected dependents move, the ones paid for, so a dependent changed meanwhile rolls it all back.
+func (usecase *VisaUsecase) submitGroupDependents(ctx context.Context, tx *gorm.DB, groupID string, applicant entity.User, expected int) error {
+	group, err := usecase.Repo.FindGroupByID(ctx, tx, groupID)
+	if err != nil {
+		return err
+	}
+
+	draftStatus := entity.VisaStatusDraft
+	pendingStatus := entity.VisaStatusPending
+	submitted := 0
+	for i := range group.Members {
+		member := &group.Members[i]
+		if member.Status == nil || *member.Status != entity.VisaStatusDraft {
+			continue
+		}
+
+		// Stored as submitted applications are, like the principal
+		submission, err := DraftToSubmission(member)
+		if err != nil {
+			return err
+		}
+		if submission.VisaFormInput != nil {
+			member.VisaFormInput, err = marshalVisaFormInput(submission.VisaFormInput)
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrIncompleteApplication, err.Error())
+			}
+		}
+
+		member.Status = &pendingStatus
+		updated, err := usecase.Repo.SubmitDraft(ctx, tx, member, member.Version)
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return ErrVersionConflict // Changed since it was read
+		}
+		submitted++
+		if err := usecase.Repo.CreateStatusHistory(ctx, tx, &entity.VisaStatusHistory{
+			VisaApplicationID: member.ID,
+			FromStatus:        &draftStatus,
+			ToStatus:          pendingStatus,
+		}); err != nil {
+			return err
+		}
+		if err := usecase.publish(ctx, tx, VisaEventCreated, member, &draftStatus); err != nil {
+			return err
+		}
+		if err := usecase.Fraud.Screen(ctx, tx, member, applicant); err != nil {
+			return err
+		}
+	}
+
+	if submitted != expected {
+		return ErrVersionConflict // Dependents added or removed since they were paid for
+	}
+	return nil
+}
+
+// Applications that leave draft when the draft is submitted
+func (usecase *VisaUsecase) submissionIDs(ctx context.Context, tx *gorm.DB, draft *entity.VisaApplication) ([]string, error) {
+	ids := []string{draft.ID}
+	if draft.GroupID == nil || draft.Relationship == nil || *draft.Relationship != entity.GroupRelationPrincipal {
+		return ids, nil
+	}
+
+	group, err := usecase.Repo.FindGroupByID(ctx, tx, *draft.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range group.Members {
+		if member.ID != draft.ID && member.Status != nil && *member.Status == entity.VisaStatusDraft {
+			ids = append(ids, member.ID)
+		}
+	}
+	return ids, nil
+}
+
+// Loads a draft owned by the user
+func (usecase *VisaUsecase) findOwnDraft(ctx context.Context, tx *gorm.DB, userID string, applicationID string) (*entity.VisaApplication, error) {
+	draft, err := usecase.Repo.FindByID(ctx, tx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	if draft.UserID != userID {
+		return nil, gorm.ErrRecordNotFound // Don't leak other users' applications
+	}
+	if draft.Status == nil || *draft.Status != entity.VisaStatusDraft {
+		return nil, ErrNotDraft
+	}
+
+	return draft, nil
+}
+
+// DraftToSubmission rebuilds a submission request from the stored draft payload
+func DraftToSubmission(draft *entity.VisaApplication) (*request.CreateVisaApplicationRequest, error) {
+	submission := &request.CreateVisaApplicationRequest{
+		UserID:      draft.UserID,
+		VisaFormURL: draft.VisaFormURL,
+	}
+
+	var formInput map[string]any
+	if len(draft.VisaFormInput) > 0 {
+		if err := json.Unmarshal(draft.VisaFormInput, &formInput); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrIncompleteApplication, err.Error())
+		}
+	}
+
+	// An untouched form is only fine when a signed form was uploaded
+	if len(formInput) == 0 {
+		return submission, nil
+	}
+
+	submission.VisaFormInput = &request.VisaFormInputRequest{}
+	if err := json.Unmarshal(draft.VisaFormInput, submission.VisaFormInput); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrIncompleteApplication, err.Error())
+	}
+
+	return submission, nil
+}
@@ -0,0 +1,467 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"japa/internal/app/http/dto/request"
+	"japa/internal/domain/entity"
+	"japa/internal/pkg"
+
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// ERRORS
+
+var (
+	ErrAlreadyInGroup = errors.New("application already belongs to a group")
+	ErrGroupDecided   = errors.New("group already has a final decision")
+)
+
+// TYPES
+
+// VisaGroupSummary is a group with its roll-up status for agents and applicants
+type VisaGroupSummary struct {
+	Group        *entity.VisaApplicationGroup
+	Status       string         // Rolled-up group status
+	StatusCounts map[string]int // Members per status
+}
+
+// Group-only statuses produced by the roll-up
+const (
+	VisaGroupStatusMixed             = "mixed"              // Members are at different stages
+	VisaGroupStatusPartiallyApproved = "partially_approved" // All decided, some approved and some rejected
+)
+
+// METHODS
+
+// Creates a group around the principal's application and adds the dependents
+func (usecase *VisaUsecase) CreateGroup(ctx context.Context, req request.CreateVisaGroupRequest) (*VisaGroupSummary, error) {
+	var groupID string
+
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		principal, err := usecase.Repo.FindByID(ctx, tx, req.PrincipalApplicationID)
+		if err != nil {
+			return err
+		}
+		if principal.UserID != req.UserID {
+			return gorm.ErrRecordNotFound // Don't leak other users' applications
+		}
+		if principal.GroupID != nil {
+			return ErrAlreadyInGroup
+		}
+		if groupDecided(&entity.VisaApplicationGroup{Members: []entity.VisaApplication{*principal}}, principal) {
+			return ErrGroupDecided // Nobody can join a trip that is decided
+		}
+		if err := usecase.Billing.RequireGroupSize(ctx, tx, req.UserID, 1+len(req.Dependents)); err != nil {
+			return err
+		}
+
+		travelDate, err := ValidateTravelDate(usecase.Validation, "travel.travel_date", req.Travel.TravelDate, time.Now())
+		if err != nil {
+			return err
+		}
+
+		group := &entity.VisaApplicationGroup{
+			ID:                     ulid.Make().String(),
+			UserID:                 req.UserID,
+			PrincipalApplicationID: principal.ID,
+			Destination:            req.Travel.Destination,
+			VisaType:               req.Travel.VisaType,
+			TravelDate:             travelDate,
+			DurationOfStay:         req.Travel.DurationOfStay,
+			Purpose:                req.Travel.Purpose,
+		}
+		if err := usecase.Repo.CreateGroup(ctx, tx, group); err != nil {
+			return err // rollback
+		}
+
+		if err := usecase.Repo.AssignToGroup(ctx, tx, principal.ID, group.ID, entity.GroupRelationPrincipal); err != nil {
+			return err // rollback
+		}
+
+		// The principal travels on the group's details from the start
+		if err := usecase.shareGroupTravel(ctx, tx, group, principal); err != nil {
+			return err // rollback
+		}
+
+		for i, dependent := range req.Dependents {
+			if _, err := usecase.createDependent(ctx, tx, group, principal, dependent, fmt.Sprintf("dependents[%d]", i)); err != nil {
+				return err // rollback
+			}
+		}
+
+		groupID = group.ID
+		return nil // commit
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return usecase.FetchGroup(ctx, req.UserID, groupID)
+}
+
+
+// Adds one dependent to an existing group
+func (usecase *VisaUsecase) AddDependent(ctx context.Context, req request.AddDependentRequest) (*entity.VisaApplication, error) {
+	var application *entity.VisaApplication
+
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		group, err := usecase.Repo.FindGroupByID(ctx, tx, req.GroupID)
+		if err != nil {
+			return err
+		}
+		if group.UserID != req.UserID {
+			return gorm.ErrRecordNotFound
+		}
+
+		principal, err := usecase.Repo.FindByID(ctx, tx, group.PrincipalApplicationID)
+		if err != nil {
+			return err
+		}
+		if groupDecided(group, principal) {
+			return ErrGroupDecided // A new dependent can't join a trip that is decided
+		}
+		if err := usecase.Billing.RequireGroupSize(ctx, tx, req.UserID, len(group.Members)+1); err != nil {
+			return err
+		}
+
+		application, err = usecase.createDependent(ctx, tx, group, principal, req.Dependent, "dependent")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return application, nil
+}
+
+
+// Fetches a group with its roll-up status.
+// An empty userID skips the ownership check (agents).
+func (usecase *VisaUsecase) FetchGroup(ctx context.Context, userID string, groupID string) (*VisaGroupSummary, error) {
+	group, err := usecase.Repo.FindGroupByID(ctx, usecase.DB, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != "" && group.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	status, counts := RollUpGroupStatus(group.Members)
+	return &VisaGroupSummary{
+		Group:        group,
+		Status:       status,
+		StatusCounts: counts,
+	}, nil
+}
+
+
+// Attaches a supporting document to one of the user's applications (any group member)
+func (usecase *VisaUsecase) AddDocument(ctx context.Context, req request.CreateDocumentRequest) (*entity.Document, error) {
+	application, err := usecase.Repo.FindByID(ctx, usecase.DB, req.VisaApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	if application.UserID != req.UserID {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	document := &entity.Document{
+		ID:                ulid.Make().String(),
+		VisaApplicationID: application.ID,
+		FileType:          req.FileType,
+		FilePath:          req.FilePath,
+	}
+	if err := usecase.Repo.CreateDocument(ctx, usecase.DB, document); err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+
+// Creates a dependent's application from the shared travel details.
+// Dependents follow the principal: drafts stay drafts, anything else starts pending
+// when covered by the plan's application quota and stays a draft until paid otherwise.
+// field is the dependent's JSON path in the request, used for validation errors.
+func (usecase *VisaUsecase) createDependent(
+	ctx context.Context,
+	tx *gorm.DB,
+	group *entity.VisaApplicationGroup,
+	principal *entity.VisaApplication,
+	dependent request.DependentRequest,
+	field string,
+) (*entity.VisaApplication, error) {
+	input := DependentFormInput(group, dependent)
+
+	// The shared travel date is checked on the group, so only passport and age can fail here
+	if err := ValidateVisaDates(usecase.Validation, input, field, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := usecase.Eligibility.CheckSubmission(ctx, input); err != nil {
+		return nil, err
+	}
+
+	applicationID := ulid.Make().String()
+	status := entity.VisaStatusPending
+	if principal.Status != nil && *principal.Status == entity.VisaStatusDraft {
+		status = entity.VisaStatusDraft
+	} else if err := usecase.Billing.RequireQuota(ctx, tx, group.UserID, 1); errors.Is(err, ErrPaymentRequired) {
+		status = entity.VisaStatusDraft // Dependents joining a submitted group are checked out on their own
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Drafts hold the request payload like any other draft, it's converted when they are submitted
+	var jsonVisaFormInput []byte
+	var err error
+	if status == entity.VisaStatusDraft {
+		jsonVisaFormInput, err = json.Marshal(input)
+	} else {
+		jsonVisaFormInput, err = marshalVisaFormInput(input)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrIncompleteApplication, err.Error())
+	}
+
+	relationship := dependent.Relationship
+	applicantName := dependent.FullName
+	application := &entity.VisaApplication{
+		ID:            applicationID,
+		UserID:        group.UserID,
+		VisaFormInput: jsonVisaFormInput,
+		Status:        &status,
+		GroupID:       &group.ID,
+		Relationship:  &relationship,
+		ApplicantName: &applicantName,
+		ReferrerID:    principal.ReferrerID, // Dependents belong to the principal's partner
+		Version:       1,
+	}
+	if err := usecase.Repo.Create(tx.WithContext(ctx), application); err != nil {
+		return nil, err
+	}
+
+	if status != entity.VisaStatusDraft {
+		if err := usecase.Repo.CreateStatusHistory(ctx, tx, &entity.VisaStatusHistory{
+			VisaApplicationID: application.ID,
+			ToStatus:          status,
+		}); err != nil {
+			return nil, err
+		}
+		if err := usecase.publish(ctx, tx, VisaEventCreated, application, nil); err != nil {
+			return nil, err
+		}
+		if err := usecase.Fraud.Screen(ctx, tx, application, principal.User); err != nil {
+			return nil, err
+		}
+	}
+
+	return application, nil
+}
+
+
+// Copies the principal draft's travel details to its group and the draft dependents, they travel together.
+// Drafts are partial, so only the details the principal has filled in are shared.
+func (usecase *VisaUsecase) syncGroupTravel(ctx context.Context, tx *gorm.DB, principal *entity.VisaApplication) error {
+	var travel struct {
+		Destination    string `json:"destination"`
+		VisaType       string `json:"visa_type"`
+		TravelDate     string `json:"travel_date"`
+		DurationOfStay string `json:"duration_of_stay"`
+		Purpose        string `json:"purpose"`
+	}
+	if err := json.Unmarshal(principal.VisaFormInput, &travel); err != nil {
+		return nil // Not parseable yet, checked on submission
+	}
+
+	group, err := usecase.Repo.FindGroupByID(ctx, tx, *principal.GroupID)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	if travel.Destination != "" && travel.Destination != group.Destination {
+		group.Destination = travel.Destination
+		changed = true
+	}
+	if travel.VisaType != "" && travel.VisaType != group.VisaType {
+		group.VisaType = travel.VisaType
+		changed = true
+	}
+	if travelDate, err := time.Parse("2006-01-02", travel.TravelDate); err == nil && !travelDate.Equal(group.TravelDate) {
+		group.TravelDate = travelDate
+		changed = true
+	}
+	if travel.DurationOfStay != "" && travel.DurationOfStay != group.DurationOfStay {
+		group.DurationOfStay = travel.DurationOfStay
+		changed = true
+	}
+	if travel.Purpose != "" && travel.Purpose != group.Purpose {
+		group.Purpose = travel.Purpose
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	if err := usecase.Repo.UpdateGroupTravel(ctx, tx, group); err != nil {
+		return err
+	}
+	for i := range group.Members {
+		member := &group.Members[i]
+		if member.ID == principal.ID || member.Status == nil || *member.Status != entity.VisaStatusDraft {
+			continue
+		}
+		if err := usecase.shareGroupTravel(ctx, tx, group, member); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Writes the group's travel details onto a member's stored form
+func (usecase *VisaUsecase) shareGroupTravel(ctx context.Context, tx *gorm.DB, group *entity.VisaApplicationGroup, member *entity.VisaApplication) error {
+	draft := member.Status != nil && *member.Status == entity.VisaStatusDraft
+	form, err := GroupTravelForm(member.VisaFormInput, group, draft)
+	if err != nil {
+		return err
+	}
+	member.VisaFormInput = form
+
+	if !draft {
+		return usecase.Repo.UpdateFormInput(ctx, tx, member)
+	}
+	updated, err := usecase.Repo.UpdateDraft(ctx, tx, member, member.Version)
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return ErrVersionConflict // Changed meanwhile
+	}
+	member.Version++
+	return nil
+}
+
+// DependentFormInput is a dependent's form: the group's travel details with the dependent's own
+func DependentFormInput(group *entity.VisaApplicationGroup, dependent request.DependentRequest) *request.VisaFormInputRequest {
+	return &request.VisaFormInputRequest{
+		Destination:      group.Destination,
+		VisaType:         group.VisaType,
+		TravelDate:       group.TravelDate.Format("2006-01-02"),
+		DurationOfStay:   group.DurationOfStay,
+		Purpose:          group.Purpose,
+		HasBeenDenied:    dependent.HasBeenDenied,
+		PersonalInfo:     dependent.PersonalInfo,
+		EmergencyContact: dependent.EmergencyContact,
+	}
+}
+
+// GroupTravelForm writes the group's travel details onto a stored form. Drafts hold the request
+// payload and are merge-patched, submitted applications hold the entity form. A submitted
+// application without a form (uploaded only) is left as it is.
+func GroupTravelForm(form []byte, group *entity.VisaApplicationGroup, draft bool) ([]byte, error) {
+	if draft {
+		patch, err := json.Marshal(map[string]any{
+			"destination":      group.Destination,
+			"visa_type":        group.VisaType,
+			"travel_date":      group.TravelDate.Format("2006-01-02"),
+			"duration_of_stay": group.DurationOfStay,
+			"purpose":          group.Purpose,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(form) == 0 {
+			form = []byte("{}")
+		}
+		return pkg.MergePatch(form, patch)
+	}
+
+	var visaFormInput *entity.VisaFormInput
+	if len(form) > 0 {
+		if err := json.Unmarshal(form, &visaFormInput); err != nil {
+			return nil, err
+		}
+	}
+	if visaFormInput == nil {
+		return form, nil
+	}
+	visaFormInput.Destination = group.Destination
+	visaFormInput.VisaType = group.VisaType
+	visaFormInput.TravelDate = group.TravelDate
+	visaFormInput.DurationOfStay = group.DurationOfStay
+	visaFormInput.Purpose = group.Purpose
+	return json.Marshal(visaFormInput)
+}
+
+// A group is decided once its principal or all of its members have a final decision
+func groupDecided(group *entity.VisaApplicationGroup, principal *entity.VisaApplication) bool {
+	if principal.Status != nil && (*principal.Status == entity.VisaStatusApproved || *principal.Status == entity.VisaStatusRejected) {
+		return true
+	}
+	status, _ := RollUpGroupStatus(group.Members)
+	return status == entity.VisaStatusApproved || status == entity.VisaStatusRejected || status == VisaGroupStatusPartiallyApproved
+}
+
+// Progress order of non-terminal statuses, the group moves as fast as its slowest member
+var visaStatusProgress = map[string]int{
+	entity.VisaStatusDraft:              0,
+	entity.VisaStatusPending:            1,
+	entity.VisaStatusUnderReview:        2,
+	entity.VisaStatusDocumentsRequested: 2,
+	entity.VisaStatusSubmitted:          3,
+}
+
+// RollUpGroupStatus derives a single group status from its members:
+//   - every member in the same status => that status
+//   - any member waiting on documents => documents_requested (the group needs action)
+//   - every member decided => approved, rejected or partially_approved
+//   - otherwise => the least advanced open status, or mixed if members are decided and open
+func RollUpGroupStatus(members []entity.VisaApplication) (string, map[string]int) {
+	counts := map[string]int{}
+	for _, member := range members {
+		status := entity.VisaStatusPending
+		if member.Status != nil {
+			status = *member.Status
+		}
+		counts[status]++
+	}
+
+	if len(counts) == 0 {
+		return entity.VisaStatusPending, counts
+	}
+	if len(counts) == 1 {
+		for status := range counts {
+			return status, counts
+		}
+	}
+
+	if counts[entity.VisaStatusDocumentsRequested] > 0 {
+		return entity.VisaStatusDocumentsRequested, counts
+	}
+
+	decided := counts[entity.VisaStatusApproved] + counts[entity.VisaStatusRejected]
+	if decided == len(members) {
+		return VisaGroupStatusPartiallyApproved, counts
+	}
+	if decided > 0 {
+		return VisaGroupStatusMixed, counts
+	}
+
+	slowest, slowestProgress := "", len(visaStatusProgress)
+	for status := range counts {
+		if progress, ok := visaStatusProgress[status]; ok && progress < slowestProgress {
+			slowest, slowestProgress = status, progress
+		}
+	}
+	if slowest == "" {
+		return VisaGroupStatusMixed, counts
+	}
+
+	return slowest, counts
+}
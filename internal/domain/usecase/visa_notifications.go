@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"japa/internal/domain/entity"
+	"japa/internal/infrastructure/mail"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Visa application events that notify the applicant
+const (
+	VisaEventCreated            = "created"
+	VisaEventStatusChanged      = "status_changed"
+	VisaEventDocumentsRequested = "documents_requested"
+	VisaEventDecision           = "decision" // approved or rejected
+)
+
+// visaApplicationEvent carries everything needed to notify
+// the applicant once the DB transaction has committed
+type visaApplicationEvent struct {
+	Type               string
+	ApplicationID      string
+	Name               string
+	Email              string
+	Status             string
+	Message            string
+	RequestedDocuments []string
+}
+
+// Link to the application page on the client
+func (usecase *VisaUsecase) applicationURL(applicationID string) string {
+	return fmt.Sprintf("%s/account/visa/applications/%s", strings.TrimRight(usecase.SiteConfig.SiteDomain, "/"), applicationID)
+}
+
+// notify sends the applicant email in the background.
+// It must be called after commit: a failed delivery is logged, never returned.
+func (usecase *VisaUsecase) notify(event visaApplicationEvent) {
+	if usecase.Mailer == nil || event.Email == "" {
+		return
+	}
+
+	var emailData *mailer.EmailData
+	switch event.Type {
+	case VisaEventCreated:
+		emailData = mailer.VisaApplicationSuccessMail(event.Name, event.ApplicationID)
+	case VisaEventStatusChanged:
+		emailData = mailer.VisaApplicationStatusMail(event.Name, event.ApplicationID, readableStatus(event.Status), event.Message)
+	case VisaEventDocumentsRequested:
+		emailData = mailer.VisaDocumentsRequestedMail(event.Name, event.ApplicationID, readableList(event.RequestedDocuments), event.Message)
+	case VisaEventDecision:
+		emailData = mailer.VisaApplicationDecisionMail(event.Name, event.ApplicationID, event.Status, event.Message)
+	default:
+		return
+	}
+
+	emailData.LinkURL = usecase.applicationURL(event.ApplicationID)
+	emailData.LinkText = "View application"
+
+	go func() {
+		// Recover from any unexpected panic so the app doesn't crash
+		defer func() {
+			if panicErr := recover(); panicErr != nil {
+				zap.L().Error("Panic recovered while sending visa notification", zap.Any("error", panicErr))
+			}
+		}()
+
+		if err := usecase.Mailer.Send(event.Email, emailData); err != nil {
+			zap.L().Error(
+				"Failed to send visa application notification",
+				zap.String("event", event.Type),
+				zap.String("applicationID", event.ApplicationID),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// Webhook event type of each applicant notification
+var visaWebhookEvents = map[string]string{
+	VisaEventCreated:            entity.WebhookEventApplicationCreated,
+	VisaEventStatusChanged:      entity.WebhookEventApplicationStatusChanged,
+	VisaEventDocumentsRequested: entity.WebhookEventApplicationDocumentsRequested,
+	VisaEventDecision:           entity.WebhookEventApplicationDecision,
+}
+
+// publish queues the partner webhook for an application change.
+// Unlike notify it must be called inside the transaction, so the event commits or rolls back with the change.
+// Payloads carry ids and statuses only, never the applicant's personal data.
+func (usecase *VisaUsecase) publish(ctx context.Context, tx *gorm.DB, eventType string, application *entity.VisaApplication, fromStatus *string) error {
+	if usecase.Webhooks == nil {
+		return nil
+	}
+
+	var partnerIDs []string
+	if application.ReferrerID != nil {
+		partnerIDs = append(partnerIDs, *application.ReferrerID)
+	}
+
+	return usecase.Webhooks.Publish(ctx, tx, WebhookEvent{
+		Type:       visaWebhookEvents[eventType],
+		PartnerIDs: partnerIDs,
+		Data: map[string]any{
+			"application_id":  application.ID,
+			"status":          application.Status,
+			"previous_status": fromStatus,
+			"group_id":        application.GroupID,
+			"relationship":    application.Relationship,
+			"referrer_id":     application.ReferrerID,
+		},
+	})
+}
+
+// i.e "documents_requested" => "Documents requested"
+func readableStatus(status string) string {
+	readable := strings.ReplaceAll(status, "_", " ")
+	if readable == "" {
+		return readable
+	}
+	return strings.ToUpper(readable[:1]) + readable[1:]
+}
+
+func readableList(items []string) []string {
+	readable := make([]string, len(items))
+	for i, item := range items {
+		readable[i] = readableStatus(item)
+	}
+	return readable
+}
@@ -3,12 +3,14 @@ package usecase
 import (
 	"time"
 	"context"
-	//"errors"
+	"errors"
 	"encoding/json"
 
 	"japa/internal/app/http/dto/request"
+	"japa/internal/config"
 	"japa/internal/domain/entity"
 	"japa/internal/domain/repository"
+	"japa/internal/infrastructure/mail"
 	//"japa/internal/util"
 
 	"go.uber.org/zap"
@@ -16,27 +18,59 @@ import (
 	"github.com/oklog/ulid/v2"
 )
 
+// ERRORS
+
+var ErrInvalidStatusTransition = errors.New("application cannot move to the requested status")
+var ErrNotPartner = errors.New("referrer must be a partner account")
+
 
 // UserUsecase handles user-related business logic
 type VisaUsecase struct {
-	Repo *repository.VisaRepository
-	DB   *gorm.DB
-	//Mailer Mailer
+	SiteConfig  config.SiteConfig
+	Validation  config.VisaValidationConfig
+	Repo        *repository.VisaRepository
+	DB          *gorm.DB
+	Mailer      *mailer.ResponsiveMailer
+	Eligibility *EligibilityUsecase
+	Webhooks    *WebhookUsecase
+	Fraud       *FraudUsecase
+	Billing     *BillingUsecase
 }
 
 // METHODS
 
 // Initialize UserUsecase
-func NewVisaUsecase(repo *repository.VisaRepository, db *gorm.DB) *VisaUsecase {
-	return &VisaUsecase{Repo: repo, DB: db}
+func NewVisaUsecase(
+	siteConfig  config.SiteConfig,
+	validation  config.VisaValidationConfig,
+	repo        *repository.VisaRepository,
+	db          *gorm.DB,
+	mailer      *mailer.ResponsiveMailer,
+	eligibility *EligibilityUsecase,
+	webhooks    *WebhookUsecase,
+	fraud       *FraudUsecase,
+	billing     *BillingUsecase,
+) *VisaUsecase {
+	return &VisaUsecase{SiteConfig: siteConfig, Validation: validation, Repo: repo, DB: db, Mailer: mailer, Eligibility: eligibility, Webhooks: webhooks, Fraud: fraud, Billing: billing}
 }
 
 // Creates a new visa application and sends a confirmation email
 func (usecase *VisaUsecase) CreateVisaApplication(ctx context.Context, req request.CreateVisaApplicationRequest) error {
-	return usecase.DB.Transaction(func(tx *gorm.DB) error {
-		// Application placeholder
-		var application = &entity.VisaApplication{}
+	// Application placeholder
+	var application = &entity.VisaApplication{}
+	var applicant entity.User
+
+	// Dates must make sense on their own and against each other
+	if err := ValidateVisaDates(usecase.Validation, req.VisaFormInput, "visa_form_input", time.Now()); err != nil {
+		return err
+	}
+
+	// Pre-submission eligibility check, blocking rules reject the application
+	if err := usecase.Eligibility.CheckSubmission(ctx, req.VisaFormInput); err != nil {
+		return err
+	}
 
+	err := usecase.DB.Transaction(func(tx *gorm.DB) error {
 		// 1. Type conversions
 		var userID string 
 		parsedUserID, err := ulid.Parse(req.UserID)
@@ -45,84 +79,316 @@ func (usecase *VisaUsecase) CreateVisaApplication(ctx context.Context, req reque
 		}
 		userID = parsedUserID.String()
 
-		// 2. Submitting by given input fields
-		if req.VisaFormInput != nil {
-			var travelDate time.Time
-			if req.VisaFormInput.TravelDate != "" {
-				travelDate, err = time.Parse("2006-01-02", req.VisaFormInput.TravelDate)
-				if err != nil {
-					return err
-				}
-			}
+		// Applicant details for the confirmation email and fraud screening
+		if err := tx.Select("id", "full_name", "email", "phone", "referrer_id").First(&applicant, "id = ?", userID).Error; err != nil {
+			return err
+		}
 
-			var passportExpiry time.Time
-			if req.VisaFormInput.PersonalInfo.PassportExpiry != "" {
-				passportExpiry, err = time.Parse("2006-01-02", req.VisaFormInput.PersonalInfo.PassportExpiry)
-				if err != nil {
-					return err
-				}
-			}
+		// 2. Submitting by given input fields
+		jsonVisaFormInput, err := marshalVisaFormInput(req.VisaFormInput)
+		if err != nil {
+			return err
+		}
 
-			var dob time.Time
-			if req.VisaFormInput.PersonalInfo.DateOfBirth != "" {
-				dob, err = time.Parse("2006-01-02", req.VisaFormInput.PersonalInfo.DateOfBirth)
-				if err != nil {
-					return err
-				}
-			}
+		application = &entity.VisaApplication{
+			ID:                ulid.Make().String(),
+			UserID:            userID,
+			VisaFormInput:     jsonVisaFormInput,
+			VisaFormURL:       req.VisaFormURL,
+			ReferrerID:        applicant.ReferrerID, // Partner the applicant signed up through
+		}
 
-			visaFormInput := &entity.VisaFormInput{
-				Destination:     req.VisaFormInput.Destination,
-				VisaType:        req.VisaFormInput.VisaType,
-				TravelDate:      travelDate,
-				DurationOfStay:  req.VisaFormInput.DurationOfStay,
-				Purpose:         req.VisaFormInput.Purpose,
-				HasBeenDenied:   req.VisaFormInput.HasBeenDenied,
-				PersonalInfo:    entity.PersonalInfo{
-					PassportNumber:   req.VisaFormInput.PersonalInfo.PassportNumber,
-					PassportExpiry:   passportExpiry,
-					ResidentialAddr:  req.VisaFormInput.PersonalInfo.ResidentialAddr,
-					Nationality:      req.VisaFormInput.PersonalInfo.Nationality,
-					MaritalStatus:    req.VisaFormInput.PersonalInfo.MaritalStatus,
-					DateOfBirth:      dob,
-				},
-				EmergencyContact: (*entity.EmergencyContact)(req.VisaFormInput.EmergencyContact),
-			}
+		// Direct submissions have no purchase to pay against, only the plan's application quota covers them
+		if err := usecase.Billing.RequireQuota(ctx, tx, userID, 1); err != nil {
+			return err
+		}
 
-			jsonVisaFormInput, err := json.Marshal(visaFormInput)
-			if err != nil {
-				return err
-			}
+		// 3. Save 
+		zap.L().Info("Saving application to DB..")
 
-			application = &entity.VisaApplication{
-				ID:                ulid.Make().String(),
-				UserID:            userID,
-				VisaFormInput:     jsonVisaFormInput,
-				VisaFormURL:       req.VisaFormURL,
-			}
-		} else {
-			jsonVisaFormInput, err := json.Marshal(req.VisaFormInput)
-			if err != nil {
-				return err
-			}
+		status := entity.VisaStatusPending
+		application.Status = &status
 
-			application = &entity.VisaApplication{
-				ID:                ulid.Make().String(),
-				UserID:            userID,
-				VisaFormInput:     jsonVisaFormInput,
-				VisaFormURL:       req.VisaFormURL,
-			}
+		if err := usecase.Repo.Create(tx, application); err != nil {
+			return err // rollback
 		}
 
+		// 4. First entry on the application timeline
+		if err := usecase.Repo.CreateStatusHistory(ctx, tx, &entity.VisaStatusHistory{
+			VisaApplicationID: application.ID,
+			ToStatus:          status,
+		}); err != nil {
+			return err // rollback
+		}
 
-		// 2. Save 
-		zap.L().Info("Saving application to DB..")
+		// 5. Partner webhooks
+		if err := usecase.publish(ctx, tx, VisaEventCreated, application, nil); err != nil {
+			return err // rollback
+		}
 
-		if err := usecase.Repo.Create(tx, application); err != nil {
+		// 6. Duplicate and fraud signals for agent review
+		if err := usecase.Fraud.Screen(ctx, tx, application, applicant); err != nil {
 			return err // rollback
 		}
 
 		// Everything succeeded
 		return nil // commit
 	})
-}
\ No newline at end of file
+	if err != nil {
+		return err
+	}
+
+	// 7. Notify applicant only after commit,
+	// so a mail outage never rolls back a submission
+	usecase.notify(visaApplicationEvent{
+		Type:          VisaEventCreated,
+		ApplicationID: application.ID,
+		Name:          applicant.FullName,
+		Email:         applicant.Email,
+		Status:        *application.Status,
+	})
+
+	return nil
+}
+
+
+// Moves an application to a new status and notifies the applicant
+func (usecase *VisaUsecase) UpdateApplicationStatus(ctx context.Context, req request.UpdateVisaStatusRequest) error {
+	var event visaApplicationEvent
+
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		application, err := usecase.Repo.FindByID(ctx, tx, req.ApplicationID)
+		if err != nil {
+			return err
+		}
+
+		fromStatus := application.Status
+		if !canTransition(fromStatus, req.Status) {
+			return ErrInvalidStatusTransition
+		}
+
+		application.Status = &req.Status
+		if req.Feedback != nil {
+			application.Feedback = req.Feedback
+		}
+
+		// Whoever moves the application first becomes the assigned agent
+		if application.AgentID == nil {
+			application.AgentID = &req.AgentID
+		}
+
+		if err := usecase.Repo.UpdateStatus(ctx, tx, application); err != nil {
+			return err // rollback
+		}
+
+		if err := usecase.Repo.CreateStatusHistory(ctx, tx, &entity.VisaStatusHistory{
+			VisaApplicationID: application.ID,
+			FromStatus:        fromStatus,
+			ToStatus:          req.Status,
+			Note:              req.Feedback,
+			ChangedByID:       &req.AgentID,
+		}); err != nil {
+			return err // rollback
+		}
+
+		event = visaApplicationEvent{
+			Type:          VisaEventStatusChanged,
+			ApplicationID: application.ID,
+			Name:          application.User.FullName,
+			Email:         application.User.Email,
+			Status:        req.Status,
+		}
+		if req.Feedback != nil {
+			event.Message = *req.Feedback
+		}
+		if req.Status == entity.VisaStatusApproved || req.Status == entity.VisaStatusRejected {
+			event.Type = VisaEventDecision
+		}
+
+		return usecase.publish(ctx, tx, event.Type, application, fromStatus) // commit on nil
+	})
+	if err != nil {
+		return err
+	}
+
+	usecase.notify(event)
+	return nil
+}
+
+
+// Asks the applicant for additional documents
+func (usecase *VisaUsecase) RequestDocuments(ctx context.Context, req request.RequestDocumentsRequest) error {
+	var event visaApplicationEvent
+
+	err := usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		application, err := usecase.Repo.FindByID(ctx, tx, req.ApplicationID)
+		if err != nil {
+			return err
+		}
+
+		fromStatus := application.Status
+		if !canTransition(fromStatus, entity.VisaStatusDocumentsRequested) {
+			return ErrInvalidStatusTransition
+		}
+
+		status := entity.VisaStatusDocumentsRequested
+		application.Status = &status
+		if application.AgentID == nil {
+			application.AgentID = &req.AgentID
+		}
+
+		if err := usecase.Repo.UpdateStatus(ctx, tx, application); err != nil {
+			return err // rollback
+		}
+
+		// Requested documents are kept on the timeline
+		documentsJSON, err := json.Marshal(req.Documents)
+		if err != nil {
+			return err
+		}
+		note := string(documentsJSON)
+
+		if err := usecase.Repo.CreateStatusHistory(ctx, tx, &entity.VisaStatusHistory{
+			VisaApplicationID: application.ID,
+			FromStatus:        fromStatus,
+			ToStatus:          status,
+			Note:              &note,
+			ChangedByID:       &req.AgentID,
+		}); err != nil {
+			return err // rollback
+		}
+
+		event = visaApplicationEvent{
+			Type:               VisaEventDocumentsRequested,
+			ApplicationID:      application.ID,
+			Name:               application.User.FullName,
+			Email:              application.User.Email,
+			Status:             status,
+			RequestedDocuments: req.Documents,
+		}
+		if req.Message != nil {
+			event.Message = *req.Message
+		}
+
+		return usecase.publish(ctx, tx, event.Type, application, fromStatus) // commit on nil
+	})
+	if err != nil {
+		return err
+	}
+
+	usecase.notify(event)
+	return nil
+}
+
+
+// Sets (or clears) the partner who referred an application and its group members
+func (usecase *VisaUsecase) SetReferrer(ctx context.Context, req request.SetReferrerRequest) error {
+	return usecase.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		application, err := usecase.Repo.FindByID(ctx, tx, req.ApplicationID)
+		if err != nil {
+			return err
+		}
+
+		if req.ReferrerID != nil {
+			var partner entity.User
+			if err := tx.Select("id", "role").First(&partner, "id = ?", *req.ReferrerID).Error; err != nil {
+				return err
+			}
+			if partner.Role != "partner" {
+				return ErrNotPartner
+			}
+		}
+
+		return usecase.Repo.UpdateReferrer(ctx, tx, application, req.ReferrerID)
+	})
+}
+
+
+// Fetches the status timeline of an application owned by the user
+func (usecase *VisaUsecase) FetchTimeline(ctx context.Context, userID string, applicationID string) ([]entity.VisaStatusHistory, error) {
+	application, err := usecase.Repo.FindByID(ctx, usecase.DB, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	if application.UserID != userID {
+		return nil, gorm.ErrRecordNotFound // Don't leak other users' applications
+	}
+
+	return usecase.Repo.FindStatusHistory(ctx, applicationID)
+}
+
+
+// Converts the submitted form into its stored JSON form
+func marshalVisaFormInput(input *request.VisaFormInputRequest) ([]byte, error) {
+	if input == nil {
+		return json.Marshal(input)
+	}
+
+	var err error
+	var travelDate time.Time
+	if input.TravelDate != "" {
+		travelDate, err = time.Parse("2006-01-02", input.TravelDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var passportExpiry time.Time
+	if input.PersonalInfo.PassportExpiry != "" {
+		passportExpiry, err = time.Parse("2006-01-02", input.PersonalInfo.PassportExpiry)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var dob time.Time
+	if input.PersonalInfo.DateOfBirth != "" {
+		dob, err = time.Parse("2006-01-02", input.PersonalInfo.DateOfBirth)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	visaFormInput := &entity.VisaFormInput{
+		Destination:     input.Destination,
+		VisaType:        input.VisaType,
+		TravelDate:      travelDate,
+		DurationOfStay:  input.DurationOfStay,
+		Purpose:         input.Purpose,
+		HasBeenDenied:   input.HasBeenDenied,
+		PersonalInfo:    entity.PersonalInfo{
+			PassportNumber:   input.PersonalInfo.PassportNumber,
+			PassportExpiry:   passportExpiry,
+			ResidentialAddr:  input.PersonalInfo.ResidentialAddr,
+			Nationality:      input.PersonalInfo.Nationality,
+			MaritalStatus:    input.PersonalInfo.MaritalStatus,
+			DateOfBirth:      dob,
+		},
+		EmergencyContact: (*entity.EmergencyContact)(input.EmergencyContact),
+	}
+
+	return json.Marshal(visaFormInput)
+}
+
+
+// Allowed status transitions
+var visaStatusTransitions = map[string][]string{
+	entity.VisaStatusPending:            {entity.VisaStatusUnderReview, entity.VisaStatusDocumentsRequested, entity.VisaStatusRejected},
+	entity.VisaStatusUnderReview:        {entity.VisaStatusDocumentsRequested, entity.VisaStatusSubmitted, entity.VisaStatusRejected},
+	entity.VisaStatusDocumentsRequested: {entity.VisaStatusUnderReview, entity.VisaStatusDocumentsRequested, entity.VisaStatusRejected},
+	entity.VisaStatusSubmitted:          {entity.VisaStatusApproved, entity.VisaStatusRejected, entity.VisaStatusDocumentsRequested},
+}
+
+func canTransition(from *string, to string) bool {
+	current := entity.VisaStatusPending
+	if from != nil {
+		current = *from
+	}
+
+	for _, allowed := range visaStatusTransitions[current] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"japa/internal/app/http/dto/apperror"
+	"japa/internal/app/http/dto/request"
+	"japa/internal/config"
+)
+
+// Dates on the visa form are plain calendar dates
+const visaDateLayout = "2006-01-02"
+
+// ValidateVisaDates checks the travel date, passport expiry and date of birth,
+// on their own and against each other. prefix is the JSON path of the form in
+// the request (i.e "visa_form_input"), failures come back as apperror.ValidationErrors.
+func ValidateVisaDates(cfg config.VisaValidationConfig, input *request.VisaFormInputRequest, prefix string, now time.Time) error {
+	if input == nil {
+		return nil
+	}
+
+	var errs apperror.ValidationErrors
+	field := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+		return prefix + "." + name
+	}
+	today := startOfDay(now)
+
+	// Travel date
+	travelDate, hasTravelDate := validateTravelDate(&errs, cfg, field("travel_date"), input.TravelDate, today)
+
+	// Passport must not be expired, and must outlive the trip by the destination's margin
+	expiryField := field("personal_info.passport_expiry")
+	if expiry, ok := parseVisaDate(&errs, expiryField, input.PersonalInfo.PassportExpiry); ok {
+		months := passportValidityMonths(cfg, input.Destination)
+		switch {
+		case !expiry.After(today):
+			errs.Add(expiryField, apperror.ErrCodeOutOfRange, "passport has expired")
+		case hasTravelDate && expiry.Before(travelDate.AddDate(0, months, 0)):
+			if months == 0 {
+				errs.Add(expiryField, apperror.ErrCodeOutOfRange, "passport must be valid on the travel date")
+			} else {
+				errs.Add(expiryField, apperror.ErrCodeOutOfRange, fmt.Sprintf(
+					"passport must be valid for at least %d months after the travel date for %s", months, input.Destination,
+				))
+			}
+		}
+	}
+
+	// Plausible age on the travel date (today when it is missing)
+	dobField := field("personal_info.date_of_birth")
+	if dob, ok := parseVisaDate(&errs, dobField, input.PersonalInfo.DateOfBirth); ok {
+		reference := today
+		if hasTravelDate {
+			reference = travelDate
+		}
+
+		if dob.After(today) {
+			errs.Add(dobField, apperror.ErrCodeOutOfRange, "date of birth cannot be in the future")
+		} else if age := ageOn(dob, reference); age < cfg.MinApplicantAge || age > cfg.MaxApplicantAge {
+			errs.Add(dobField, apperror.ErrCodeOutOfRange, fmt.Sprintf(
+				"applicant age must be between %d and %d", cfg.MinApplicantAge, cfg.MaxApplicantAge,
+			))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// ValidateTravelDate checks a standalone travel date, i.e shared group travel details
+func ValidateTravelDate(cfg config.VisaValidationConfig, field string, value string, now time.Time) (time.Time, error) {
+	var errs apperror.ValidationErrors
+	travelDate, _ := validateTravelDate(&errs, cfg, field, value, startOfDay(now))
+	if len(errs) > 0 {
+		return time.Time{}, errs
+	}
+	return travelDate, nil
+}
+
+// Travel must be after today and within the configured window
+func validateTravelDate(errs *apperror.ValidationErrors, cfg config.VisaValidationConfig, field string, value string, today time.Time) (time.Time, bool) {
+	travelDate, ok := parseVisaDate(errs, field, value)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	switch {
+	case !travelDate.After(today):
+		errs.Add(field, apperror.ErrCodeOutOfRange, "travel date must be in the future")
+		return travelDate, false
+	case cfg.MaxTravelAheadMonths > 0 && travelDate.After(today.AddDate(0, cfg.MaxTravelAheadMonths, 0)):
+		errs.Add(field, apperror.ErrCodeOutOfRange, fmt.Sprintf("travel date must be within %d months", cfg.MaxTravelAheadMonths))
+		return travelDate, false
+	}
+	return travelDate, true
+}
+
+// Parses a required YYYY-MM-DD date, recording missing/malformed values
+func parseVisaDate(errs *apperror.ValidationErrors, field string, value string) (time.Time, bool) {
+	if strings.TrimSpace(value) == "" {
+		errs.Add(field, apperror.ErrCodeMissingField, "this field is required")
+		return time.Time{}, false
+	}
+
+	date, err := time.Parse(visaDateLayout, value)
+	if err != nil {
+		errs.Add(field, apperror.ErrCodeInvalidFormat, "must be a date in the format YYYY-MM-DD")
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// Destination override, falling back to the default margin
+func passportValidityMonths(cfg config.VisaValidationConfig, destination string) int {
+	if months, ok := cfg.PassportValidityByDestination[strings.ToLower(strings.TrimSpace(destination))]; ok {
+		return months
+	}
+	return cfg.PassportValidityMonths
+}
+
+// Full years between dob and date
+func ageOn(dob time.Time, date time.Time) int {
+	age := date.Year() - dob.Year()
+	if date.Month() < dob.Month() || (date.Month() == dob.Month() && date.Day() < dob.Day()) {
+		age--
+	}
+	return age
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,410 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"japa/internal/app/http/dto/request"
+	"japa/internal/config"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/repository"
+	"japa/internal/infrastructure/webhook"
+
+	"github.com/oklog/ulid/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ERRORS
+
+var (
+	ErrInsecureWebhookURL  = errors.New("webhook url must use https")
+	ErrUnknownWebhookEvent = errors.New("unknown webhook event type")
+)
+
+// TYPES
+
+// WebhookUsecase manages endpoints and delivers events with retries
+type WebhookUsecase struct {
+	Config config.WebhookConfig
+	Repo   *repository.WebhookRepository
+	Sender *webhook.Sender
+	DB     *gorm.DB
+}
+
+// WebhookEvent is published by other usecases inside their transaction
+type WebhookEvent struct {
+	Type       string
+	PartnerIDs []string // Partners allowed to receive it, i.e the application's referrer
+	Data       map[string]any
+}
+
+// webhookPayload is the JSON body every endpoint receives
+type webhookPayload struct {
+	ID        string         `json:"id"`
+	Type      string         `json:"type"`
+	CreatedAt time.Time      `json:"created_at"`
+	Data      map[string]any `json:"data"`
+}
+
+// METHODS
+
+// Initialize WebhookUsecase
+func NewWebhookUsecase(cfg config.WebhookConfig, repo *repository.WebhookRepository, sender *webhook.Sender, db *gorm.DB) *WebhookUsecase {
+	return &WebhookUsecase{Config: cfg, Repo: repo, Sender: sender, DB: db}
+}
+
+// Publish queues the event for every subscribed endpoint.
+// Call it with the transaction that made the change, so events are only sent for committed changes.
+func (usecase *WebhookUsecase) Publish(ctx context.Context, tx *gorm.DB, event WebhookEvent) error {
+	endpoints, err := usecase.Repo.FindActiveEndpoints(ctx, tx, event.PartnerIDs)
+	if err != nil {
+		return err
+	}
+
+	eventID := ulid.Make().String()
+	payload, err := json.Marshal(webhookPayload{
+		ID:        eventID,
+		Type:      event.Type,
+		CreatedAt: time.Now().UTC(),
+		Data:      event.Data,
+	})
+	if err != nil {
+		return err
+	}
+
+	var deliveries []entity.WebhookDelivery
+	now := time.Now()
+	for _, endpoint := range endpoints {
+		if !subscribedTo(endpoint.Events, event.Type) {
+			continue
+		}
+
+		deliveries = append(deliveries, entity.WebhookDelivery{
+			ID:            ulid.Make().String(),
+			EndpointID:    endpoint.ID,
+			EventID:       eventID,
+			EventType:     event.Type,
+			Payload:       payload,
+			Status:        entity.WebhookDeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+
+	return usecase.Repo.CreateDeliveries(ctx, tx, deliveries)
+}
+
+// DeliverDue attempts every due delivery. Runs as a scheduled job.
+func (usecase *WebhookUsecase) DeliverDue(ctx context.Context) error {
+	deliveries, err := usecase.Repo.FindDueDeliveries(ctx, time.Now(), usecase.Config.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	endpoints := make(map[string]*entity.WebhookEndpoint)
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		delivery := &deliveries[i]
+
+		// Lease it past the request timeout so no other worker attempts it meanwhile
+		claimed, err := usecase.Repo.ClaimDelivery(ctx, delivery, time.Now().Add(2*usecase.Config.Timeout))
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		endpoint, ok := endpoints[delivery.EndpointID]
+		if !ok {
+			if endpoint, err = usecase.Repo.FindEndpoint(ctx, delivery.EndpointID); err != nil {
+				if !errors.Is(err, gorm.ErrRecordNotFound) {
+					return err
+				}
+				endpoint = nil
+			}
+			endpoints[delivery.EndpointID] = endpoint
+		}
+
+		if err := usecase.attempt(ctx, endpoint, delivery); err != nil {
+			zap.L().Error("Failed to record webhook attempt", zap.String("deliveryID", delivery.ID), zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// Sends one delivery and schedules the retry or dead-letters it
+func (usecase *WebhookUsecase) attempt(ctx context.Context, endpoint *entity.WebhookEndpoint, delivery *entity.WebhookDelivery) error {
+	started := time.Now()
+	var statusCode int
+	var sendErr error
+
+	switch {
+	case endpoint == nil:
+		sendErr = errors.New("endpoint was deleted")
+		delivery.Attempts = usecase.Config.MaxAttempts - 1 // Straight to dead letter
+	case !endpoint.Active:
+		sendErr = errors.New("endpoint is disabled")
+	default:
+		statusCode, sendErr = usecase.Sender.Send(ctx, webhook.Message{
+			URL:        endpoint.URL,
+			Secret:     endpoint.Secret,
+			DeliveryID: delivery.ID,
+			EventType:  delivery.EventType,
+			Payload:    delivery.Payload,
+		})
+	}
+
+	attempt := &entity.WebhookAttempt{
+		DeliveryID: delivery.ID,
+		DurationMS: time.Since(started).Milliseconds(),
+	}
+	delivery.Attempts++
+	delivery.LastStatusCode = nil
+	if statusCode != 0 {
+		delivery.LastStatusCode = &statusCode
+		attempt.StatusCode = &statusCode
+	}
+
+	if sendErr == nil {
+		now := time.Now()
+		delivery.Status = entity.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.LastError = nil
+	} else {
+		message := sendErr.Error()
+		delivery.LastError = &message
+		attempt.Error = &message
+
+		if delivery.Attempts >= usecase.Config.MaxAttempts {
+			delivery.Status = entity.WebhookDeliveryDead
+		} else {
+			delivery.NextAttemptAt = time.Now().Add(WebhookBackoff(delivery.Attempts, usecase.Config.BaseBackoff, usecase.Config.MaxBackoff))
+		}
+	}
+
+	return usecase.Repo.SaveAttempt(ctx, delivery, attempt)
+}
+
+// WebhookBackoff is the delay after the given failed attempt: base, 2*base, 4*base... capped at max
+func WebhookBackoff(attempts int, base time.Duration, max time.Duration) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
+
+// Creates an endpoint, the signing secret is only ever returned here and on rotation
+func (usecase *WebhookUsecase) CreateEndpoint(ctx context.Context, req request.CreateWebhookEndpointRequest) (*entity.WebhookEndpoint, error) {
+	events, err := usecase.checkEndpoint(req.URL, req.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &entity.WebhookEndpoint{
+		ID:          ulid.Make().String(),
+		URL:         req.URL,
+		Secret:      secret,
+		Events:      events,
+		Description: req.Description,
+		Active:      true,
+	}
+	if req.OwnerID != "" {
+		endpoint.OwnerID = &req.OwnerID
+	}
+
+	if err := usecase.Repo.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// Updates url, events, description or active flag
+func (usecase *WebhookUsecase) UpdateEndpoint(ctx context.Context, req request.UpdateWebhookEndpointRequest) (*entity.WebhookEndpoint, error) {
+	endpoint, err := usecase.FetchEndpoint(ctx, req.OwnerID, req.EndpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	endpointURL := endpoint.URL
+	if req.URL != nil {
+		endpointURL = *req.URL
+	}
+	events := strings.Split(endpoint.Events, ",")
+	if req.Events != nil {
+		events = req.Events
+	}
+
+	if endpoint.Events, err = usecase.checkEndpoint(endpointURL, events); err != nil {
+		return nil, err
+	}
+	endpoint.URL = endpointURL
+	if req.Description != nil {
+		endpoint.Description = req.Description
+	}
+	if req.Active != nil {
+		endpoint.Active = *req.Active
+	}
+
+	if err := usecase.Repo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// Replaces the signing secret
+func (usecase *WebhookUsecase) RotateSecret(ctx context.Context, ownerID string, endpointID string) (*entity.WebhookEndpoint, error) {
+	endpoint, err := usecase.FetchEndpoint(ctx, ownerID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	if endpoint.Secret, err = generateWebhookSecret(); err != nil {
+		return nil, err
+	}
+	if err := usecase.Repo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// Deletes an endpoint and its delivery log
+func (usecase *WebhookUsecase) DeleteEndpoint(ctx context.Context, ownerID string, endpointID string) error {
+	if _, err := usecase.FetchEndpoint(ctx, ownerID, endpointID); err != nil {
+		return err
+	}
+	return usecase.Repo.DeleteEndpoint(ctx, endpointID)
+}
+
+// Fetch an endpoint. An empty ownerID skips the ownership check (admins).
+func (usecase *WebhookUsecase) FetchEndpoint(ctx context.Context, ownerID string, endpointID string) (*entity.WebhookEndpoint, error) {
+	endpoint, err := usecase.Repo.FindEndpoint(ctx, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	if ownerID != "" && (endpoint.OwnerID == nil || *endpoint.OwnerID != ownerID) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return endpoint, nil
+}
+
+// Fetch endpoints. An empty ownerID fetches every endpoint (admins).
+func (usecase *WebhookUsecase) FetchEndpoints(ctx context.Context, ownerID string) ([]entity.WebhookEndpoint, error) {
+	return usecase.Repo.FindEndpoints(ctx, ownerID)
+}
+
+// Fetch an endpoint's delivery log
+func (usecase *WebhookUsecase) FetchDeliveries(ctx context.Context, req request.FetchWebhookDeliveriesRequest) ([]entity.WebhookDelivery, int64, error) {
+	if _, err := usecase.FetchEndpoint(ctx, req.OwnerID, req.EndpointID); err != nil {
+		return nil, 0, err
+	}
+	return usecase.Repo.FindDeliveries(ctx, req.EndpointID, req.Status, req.Limit, (req.Page-1)*req.Limit)
+}
+
+// Fetch a delivery with its attempts
+func (usecase *WebhookUsecase) FetchDelivery(ctx context.Context, ownerID string, deliveryID string) (*entity.WebhookDelivery, []entity.WebhookAttempt, error) {
+	delivery, err := usecase.findOwnDelivery(ctx, ownerID, deliveryID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attempts, err := usecase.Repo.FindAttempts(ctx, delivery.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return delivery, attempts, nil
+}
+
+// Replay queues the same signed payload again as a new delivery, the original log stays untouched
+func (usecase *WebhookUsecase) Replay(ctx context.Context, ownerID string, deliveryID string) (*entity.WebhookDelivery, error) {
+	original, err := usecase.findOwnDelivery(ctx, ownerID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	replay := entity.WebhookDelivery{
+		ID:            ulid.Make().String(),
+		EndpointID:    original.EndpointID,
+		EventID:       original.EventID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		ReplayOfID:    &original.ID,
+		Status:        entity.WebhookDeliveryPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := usecase.Repo.CreateDeliveries(ctx, usecase.DB, []entity.WebhookDelivery{replay}); err != nil {
+		return nil, err
+	}
+	return &replay, nil
+}
+
+func (usecase *WebhookUsecase) findOwnDelivery(ctx context.Context, ownerID string, deliveryID string) (*entity.WebhookDelivery, error) {
+	delivery, err := usecase.Repo.FindDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := usecase.FetchEndpoint(ctx, ownerID, delivery.EndpointID); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// Checks the url scheme and event types, returns the events to store
+func (usecase *WebhookUsecase) checkEndpoint(endpointURL string, events []string) (string, error) {
+	parsed, err := url.Parse(endpointURL)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Scheme != "https" && !usecase.Config.AllowInsecureURL {
+		return "", ErrInsecureWebhookURL
+	}
+
+	for _, event := range events {
+		if event != "*" && !slices.Contains(entity.WebhookEventTypes, event) {
+			return "", fmt.Errorf("%w: %s", ErrUnknownWebhookEvent, event)
+		}
+	}
+	if slices.Contains(events, "*") {
+		return "*", nil
+	}
+	return strings.Join(events, ","), nil
+}
+
+func subscribedTo(events string, eventType string) bool {
+	return events == "*" || slices.Contains(strings.Split(events, ","), eventType)
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -3,6 +3,7 @@ package db
 
 import (
 	//"time"
+	"strings"
 
 	"japa/internal/config"
 	"japa/internal/domain/entity"
@@ -42,6 +43,11 @@ func NewGormDB(cfg config.DBConfig) *gorm.DB {
 
 	zap.L().Debug("Database connection established. Starting migration...")
 
+	if err := migratePlanKeys(gormDB); err != nil {
+		zap.L().Error("Plan key migration failed", zap.Error(err))
+		panic("Plan key migration failed: " + err.Error())
+	}
+
 	// Auto-migrate all models
 	if err := gormDB.AutoMigrate(
 		&entity.User{},
@@ -50,20 +56,171 @@ func NewGormDB(cfg config.DBConfig) *gorm.DB {
 		&entity.Purchase{},
 		&entity.Plan{},
 		&entity.PlanFeature{},
+		&entity.PlanPrice{},
+		&entity.PlanEntitlement{},
+		&entity.UsageCounter{},
+		&entity.Coupon{},
+		&entity.CouponRedemption{},
+		&entity.ReferralCode{},
+		&entity.CommissionRule{},
+		&entity.Commission{},
+		&entity.Invoice{},
+		&entity.InvoiceCounter{},
 		&entity.Post{},
 		&entity.Comment{},
 		&entity.ScrapedPost{},
 		//&entity.Reply{},
 		//&entity.VisaFormInput{},
 		&entity.VisaApplication{},
+		&entity.VisaStatusHistory{},
+		&entity.VisaApplicationGroup{},
 		&entity.Document{},
+		&entity.EligibilityRule{},
+		&entity.Appointment{},
+		&entity.AppointmentReminder{},
+		&entity.SLATarget{},
+		&entity.SLAEscalation{},
+		&entity.UserPermission{},
+		&entity.AuditLog{},
+		&entity.WebhookEndpoint{},
+		&entity.WebhookDelivery{},
+		&entity.WebhookAttempt{},
+		&entity.ApplicationFingerprint{},
+		&entity.FraudSignal{},
+		&entity.RetentionPolicy{},
+		&entity.LegalHold{},
+		&entity.PurgeRun{},
+		&entity.PurgeItem{},
+		&entity.Payment{},
+		&entity.PaymentEvent{},
+		&entity.ApplicationPrice{},
+		&entity.AddOn{},
+		&entity.PaymentAuthorization{},
+		&entity.Refund{},
+		&entity.PaymentDispute{},
+		&entity.LedgerTransaction{},
+		&entity.LedgerEntry{},
+		&entity.LedgerReconciliation{},
+		&entity.PayoutAccount{},
+		&entity.PayoutBatch{},
+		&entity.Payout{},
 	); err != nil {
 		zap.L().Error("Database migration failed", zap.Error(err))
 		panic("Database migration failed: " + err.Error())
 	}
 
+	if err := migratePlanAmounts(gormDB); err != nil {
+		zap.L().Error("Plan price migration failed", zap.Error(err))
+		panic("Plan price migration failed: " + err.Error())
+	}
+
 	zap.L().Debug("Database migration completed successfully!")
 
 	// Return *gorm.DB
 	return gormDB
 }
+
+// Runs before AutoMigrate on an existing plans table: (code, version) is unique
+// once plans have codes, and plan ids need widening before AutoMigrate touches
+// the columns referencing them.
+func migratePlanKeys(gormDB *gorm.DB) error {
+	if !gormDB.Migrator().HasTable(&entity.Plan{}) {
+		return nil // Fresh database
+	}
+	if err := migratePlans(gormDB); err != nil {
+		return err
+	}
+	return migratePlanIDs(gormDB)
+}
+
+// Plans became versioned: names repeat across versions and every plan needs a code.
+// Drops the old unique name index and derives codes for plans created before.
+func migratePlans(gormDB *gorm.DB) error {
+	migrator := gormDB.Migrator()
+	if migrator.HasIndex(&entity.Plan{}, "name") {
+		if err := migrator.DropIndex(&entity.Plan{}, "name"); err != nil {
+			return err
+		}
+	}
+	if migrator.HasIndex(&entity.Plan{}, "idx_plans_code") {
+		if err := migrator.DropIndex(&entity.Plan{}, "idx_plans_code"); err != nil {
+			return err // Replaced by idx_plan_version
+		}
+	}
+	for _, field := range []string{"Code", "Version"} {
+		if !migrator.HasColumn(&entity.Plan{}, field) {
+			if err := migrator.AddColumn(&entity.Plan{}, field); err != nil {
+				return err
+			}
+		}
+	}
+	return gormDB.
+		Model(&entity.Plan{}).
+		Where("code = ?", "").
+		Update("code", gorm.Expr("LOWER(REPLACE(TRIM(name), ' ', '-'))")).Error
+}
+
+// Plan ids were a tinyint, too few once every plan version is its own row.
+// Widens the id and every column referencing it with foreign key checks off
+// on the connection, MySQL won't change one side of a foreign key alone.
+func migratePlanIDs(gormDB *gorm.DB) error {
+	columns, err := gormDB.Migrator().ColumnTypes(&entity.Plan{})
+	if err != nil {
+		return err
+	}
+	narrow := false
+	for _, column := range columns {
+		if column.Name() == "id" {
+			narrow = strings.EqualFold(column.DatabaseTypeName(), "tinyint")
+		}
+	}
+	if !narrow {
+		return nil
+	}
+
+	references := []struct {
+		model any
+		field string
+	}{
+		{&entity.Plan{}, "ID"},
+		{&entity.PlanFeature{}, "PlanID"},
+		{&entity.PlanPrice{}, "PlanID"},
+		{&entity.PlanEntitlement{}, "PlanID"},
+		{&entity.Subscription{}, "PlanID"},
+		{&entity.Subscription{}, "PendingPlanID"},
+		{&entity.SLATarget{}, "PlanID"},
+	}
+	return gormDB.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SET FOREIGN_KEY_CHECKS = 0").Error; err != nil {
+			return err
+		}
+		defer conn.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+		migrator := conn.Migrator()
+		for _, reference := range references {
+			if !migrator.HasColumn(reference.model, reference.field) {
+				continue // Added by AutoMigrate with the new type
+			}
+			if err := migrator.AlterColumn(reference.model, reference.field); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Plan prices moved from a float in major units (price) to minor units (amount) with a currency.
+// Converts the old prices once and drops the column, new plans don't set it.
+func migratePlanAmounts(gormDB *gorm.DB) error {
+	migrator := gormDB.Migrator()
+	if !migrator.HasColumn(&entity.Plan{}, "price") {
+		return nil
+	}
+	if err := gormDB.
+		Model(&entity.Plan{}).
+		Where("amount = ?", 0).
+		Update("amount", gorm.Expr("ROUND(price * 100)")).Error; err != nil {
+		return err
+	}
+	return migrator.DropColumn(&entity.Plan{}, "price")
+}
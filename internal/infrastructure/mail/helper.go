@@ -2,9 +2,11 @@ package mailer
 
 import (
 	"bytes"
+	"encoding/base64"
 	"fmt"
 	"html/template"
 	"path/filepath"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -35,4 +37,43 @@ func ParseEmailTemplate(templatesDir string, mailData *EmailData, logger *zap.Lo
 	}
 
 	return buf.String(), nil
+}
+
+// BuildMessage builds the raw MIME message for one recipient.
+// Without attachments it is a plain HTML email, otherwise multipart/mixed.
+func BuildMessage(from string, to string, subject string, body string, attachments []Attachment) []byte {
+	if len(attachments) == 0 {
+		return []byte(fmt.Sprintf(
+			"To: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/html; charset=\"UTF-8\"\r\n\r\n%s",
+			to, subject, body,
+		))
+	}
+
+	boundary := fmt.Sprintf("japa-%x", time.Now().UnixNano())
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\n", from, to, subject)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=\"%s\"\r\n\r\n", boundary)
+
+	// HTML body
+	fmt.Fprintf(&buf, "--%s\r\nContent-Type: text/html; charset=\"UTF-8\"\r\n\r\n%s\r\n", boundary, body)
+
+	// Attachments, base64 encoded and wrapped at 76 chars
+	for _, attachment := range attachments {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		fmt.Fprintf(&buf, "Content-Type: %s; name=\"%s\"\r\n", attachment.ContentType, attachment.Filename)
+		fmt.Fprintf(&buf, "Content-Transfer-Encoding: base64\r\n")
+		fmt.Fprintf(&buf, "Content-Disposition: attachment; filename=\"%s\"\r\n\r\n", attachment.Filename)
+
+		encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+		for len(encoded) > 76 {
+			buf.WriteString(encoded[:76] + "\r\n")
+			encoded = encoded[76:]
+		}
+		buf.WriteString(encoded + "\r\n")
+	}
+
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes()
 }
\ No newline at end of file
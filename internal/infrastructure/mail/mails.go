@@ -15,6 +15,13 @@ var (
 	Year       int    = time.Now().Year()
 )
 
+// Attachment is a file sent along with an email (i.e .ics invites, PDF receipts)
+type Attachment struct {
+	Filename    string
+	ContentType string // i.e "text/calendar; method=PUBLISH", "application/pdf"
+	Data        []byte
+}
+
 type EmailData struct {
 	Name          string
 	Subject       string
@@ -24,6 +31,9 @@ type EmailData struct {
 	LinkText      string
 	LogoURL       string // optional
 	ApplicationID string // optional
+	Status        string   // optional
+	Items         []string // optional, rendered as a list (e.g requested documents)
+	Attachments   []Attachment // optional
 	SiteName      string
 	SiteDomain    string
 	SiteEmail     string
@@ -55,3 +65,145 @@ func VisaApplicationSuccessMail(name string, applicationID string) *EmailData {
 		Year:          Year,
 	}
 }
+
+func VisaApplicationStatusMail(name string, applicationID string, status string, feedback string) *EmailData {
+	return &EmailData{
+		Name:          name,
+		Subject:       "Visa Application Status Update",
+		ApplicationID: applicationID,
+		Status:        status,
+		Message:       feedback,
+		SiteName:      SiteName,
+		SiteEmail:     SiteEmail,
+		SiteDomain:    SiteDomain,
+		EmailTemplate: "visa_application_status.html",
+		Year:          Year,
+	}
+}
+
+func VisaDocumentsRequestedMail(name string, applicationID string, documents []string, message string) *EmailData {
+	return &EmailData{
+		Name:          name,
+		Subject:       "Additional Documents Required",
+		ApplicationID: applicationID,
+		Items:         documents,
+		Message:       message,
+		SiteName:      SiteName,
+		SiteEmail:     SiteEmail,
+		SiteDomain:    SiteDomain,
+		EmailTemplate: "visa_documents_requested.html",
+		Year:          Year,
+	}
+}
+
+func VisaApplicationDecisionMail(name string, applicationID string, status string, feedback string) *EmailData {
+	subject := "Your Visa Application Was Not Approved"
+	if status == "approved" {
+		subject = "Congratulations! Your Visa Application Was Approved"
+	}
+
+	return &EmailData{
+		Name:          name,
+		Subject:       subject,
+		ApplicationID: applicationID,
+		Status:        status,
+		Message:       feedback,
+		SiteName:      SiteName,
+		SiteEmail:     SiteEmail,
+		SiteDomain:    SiteDomain,
+		EmailTemplate: "visa_application_decision.html",
+		Year:          Year,
+	}
+}
+
+// Appointment booked, updated, cancelled or coming up.
+// details are rendered as a list (type, time, location...)
+func AppointmentMail(name string, applicationID string, subject string, message string, details []string) *EmailData {
+	return &EmailData{
+		Name:          name,
+		Subject:       subject,
+		Heading:       subject,
+		ApplicationID: applicationID,
+		Message:       message,
+		Items:         details,
+		SiteName:      SiteName,
+		SiteEmail:     SiteEmail,
+		SiteDomain:    SiteDomain,
+		EmailTemplate: "appointment.html",
+		Year:          Year,
+	}
+}
+
+func SLAEscalationMail(name string, applicationID string, subject string, message string, details []string) *EmailData {
+	return &EmailData{
+		Name:          name,
+		Subject:       subject,
+		Heading:       subject,
+		ApplicationID: applicationID,
+		Message:       message,
+		Items:         details,
+		SiteName:      SiteName,
+		SiteEmail:     SiteEmail,
+		SiteDomain:    SiteDomain,
+		EmailTemplate: "sla_escalation.html",
+		Year:          Year,
+	}
+}
+
+// Subscription started, renewed, failed to renew, canceled or about to renew.
+// details are rendered as a list (plan, amount, dates...)
+func SubscriptionMail(name string, subject string, message string, details []string) *EmailData {
+	return &EmailData{
+		Name:          name,
+		Subject:       subject,
+		Heading:       subject,
+		Message:       message,
+		Items:         details,
+		LinkURL:       SiteDomain + "/account/subscription",
+		LinkText:      "Manage subscription",
+		SiteName:      SiteName,
+		SiteEmail:     SiteEmail,
+		SiteDomain:    SiteDomain,
+		EmailTemplate: "subscription.html",
+		Year:          Year,
+	}
+}
+
+// Refund, chargeback or dispute on one of the user's payments.
+// details are rendered as a list (reference, amounts, reason...)
+func PaymentMail(name string, subject string, message string, details []string) *EmailData {
+	return &EmailData{
+		Name:          name,
+		Subject:       subject,
+		Heading:       subject,
+		Message:       message,
+		Items:         details,
+		LinkURL:       SiteDomain + "/account/payments",
+		LinkText:      "View payments",
+		SiteName:      SiteName,
+		SiteEmail:     SiteEmail,
+		SiteDomain:    SiteDomain,
+		EmailTemplate: "payment.html",
+		Year:          Year,
+	}
+}
+
+// Invoice or receipt for a payment, the PDF goes as an attachment.
+// details are rendered as a list (number, amount, date...)
+func InvoiceMail(name string, subject string, message string, details []string, pdf Attachment) *EmailData {
+	return &EmailData{
+		Name:          name,
+		Subject:       subject,
+		Heading:       subject,
+		Message:       message,
+		Items:         details,
+		Attachments:   []Attachment{pdf},
+		LinkURL:       SiteDomain + "/account/billing/invoices",
+		LinkText:      "Billing history",
+		SiteName:      SiteName,
+		SiteEmail:     SiteEmail,
+		SiteDomain:    SiteDomain,
+		EmailTemplate: "invoice.html",
+		Year:          Year,
+	}
+}
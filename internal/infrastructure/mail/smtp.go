@@ -39,7 +39,7 @@ func (s *SMTPMailer) Send(to any, emailData *EmailData) error {
 		return err
 	}
 	
-	if err := s.send(to, emailData.Subject, body); err != nil {
+	if err := s.send(to, emailData.Subject, body, emailData.Attachments); err != nil {
 		s.Logger.Error("error sending mail via smtp", zap.Error(err))
 		return err
 	}
@@ -49,7 +49,7 @@ func (s *SMTPMailer) Send(to any, emailData *EmailData) error {
 
 // Handles the low-level email delivery.
 // to @param is expecting either string or []string
-func (s *SMTPMailer) send(to any, subject, body string) error {
+func (s *SMTPMailer) send(to any, subject, body string, attachments []Attachment) error {
 	addr := fmt.Sprintf("%s:%d", s.EmailConfig.EMAIL_HOST, s.EmailConfig.EMAIL_PORT)
 	auth := smtp.PlainAuth("", s.EmailConfig.EMAIL_USERNAME, s.EmailConfig.EMAIL_PASSWORD, s.EmailConfig.EMAIL_HOST)
 
@@ -68,10 +68,7 @@ func (s *SMTPMailer) send(to any, subject, body string) error {
 		// If receipients is less than 20 then loop normally
 
 		for _, recipient := range recipients {
-			msg := []byte(fmt.Sprintf(
-				"To: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/html; charset=\"UTF-8\"\r\n\r\n%s",
-				recipient, subject, body,
-			))
+			msg := BuildMessage(s.SiteConfig.SiteEmail, recipient, subject, body, attachments)
 
 			err := smtp.SendMail(addr, auth, s.SiteConfig.SiteEmail, []string{recipient}, msg)
 			if err != nil {
@@ -106,7 +103,7 @@ func (s *SMTPMailer) send(to any, subject, body string) error {
 				}()
 
 				// Attempt to send the email
-				err := s.send(email, subject, body)
+				err := s.send(email, subject, body, attachments)
 				if err != nil {
 					s.Logger.Error("Failed to send email", zap.String("to", email), zap.Error(err))
 				}
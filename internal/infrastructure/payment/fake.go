@@ -0,0 +1,338 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeProvider keeps transactions in memory, for local development and tests.
+// Nothing is charged, transactions succeed on Verify when AutoSucceed is set
+// and can otherwise be settled with Complete.
+type FakeProvider struct {
+	CheckoutURL     string   // Base of the returned authorization URLs
+	Currencies      []string // Empty supports every currency
+	AutoSucceed     bool
+	FailInitialize  bool     // Simulates an outage, to exercise provider fallback
+	DeclineCharges  bool     // Stored authorizations are declined, to exercise renewal failures
+	TimeoutCharges  bool     // Charges go through but the reply times out, to exercise reconciliation
+	PendingRefunds  bool     // Refunds stay pending until CompleteRefund, like bank refunds do
+	LoseRefunds     bool     // Refunds go through but the reply is lost, to exercise reconciliation
+	PendingTransfers bool    // Transfers stay pending until CompleteTransfer
+
+	mu              sync.Mutex
+	transactions    map[string]*Verification
+	refunds         map[string]*fakeRefund
+	refunded        map[string]int64 // By transaction reference, failed refunds excluded
+	transfers       map[string]*TransferResult // By our reference
+}
+
+// Refund and the transaction it gives money back from
+type fakeRefund struct {
+	Refund
+	reference string
+}
+
+// Initialize FakeProvider
+func NewFakeProvider(checkoutURL string, autoSucceed bool) *FakeProvider {
+	return &FakeProvider{
+		CheckoutURL:  checkoutURL,
+		AutoSucceed:  autoSucceed,
+		transactions: make(map[string]*Verification),
+		refunds:      make(map[string]*fakeRefund),
+		refunded:     make(map[string]int64),
+		transfers:    make(map[string]*TransferResult),
+	}
+}
+
+func (f *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (f *FakeProvider) Supports(currency string) bool {
+	return len(f.Currencies) == 0 || supportsCurrency(f.Currencies, currency)
+}
+
+func (f *FakeProvider) Initialize(ctx context.Context, req InitializeRequest) (*Transaction, error) {
+	if f.FailInitialize {
+		return nil, &ProviderError{Provider: f.Name(), StatusCode: 503, Message: "simulated outage"}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, exists := f.transactions[req.Reference]; exists {
+		return nil, &ProviderError{Provider: f.Name(), StatusCode: 400, Message: "duplicate transaction reference"}
+	}
+	f.transactions[req.Reference] = &Verification{
+		Provider:          f.Name(),
+		Reference:         req.Reference,
+		ProviderReference: "fake_" + req.Reference,
+		Status:            StatusPending,
+		Amount:            req.Amount,
+		Currency:          strings.ToUpper(req.Currency),
+		Channel:           "card",
+	}
+
+	return &Transaction{
+		Provider:          f.Name(),
+		Reference:         req.Reference,
+		ProviderReference: "fake_" + req.Reference,
+		AuthorizationURL:  f.CheckoutURL + "?reference=" + url.QueryEscape(req.Reference),
+	}, nil
+}
+
+func (f *FakeProvider) Verify(ctx context.Context, reference string) (*Verification, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	transaction, ok := f.transactions[reference]
+	if !ok {
+		return nil, &ProviderError{Provider: f.Name(), StatusCode: 404, Message: "transaction not found"}
+	}
+	if f.AutoSucceed && transaction.Status == StatusPending {
+		f.settle(transaction, StatusSucceeded)
+	}
+
+	verification := *transaction
+	return &verification, nil
+}
+
+func (f *FakeProvider) Refund(ctx context.Context, req RefundRequest) (*Refund, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	transaction, ok := f.transactions[req.Reference]
+	if !ok {
+		return nil, &ProviderError{Provider: f.Name(), StatusCode: 404, Message: "transaction not found"}
+	}
+	if transaction.Status != StatusSucceeded {
+		return nil, &ProviderError{Provider: f.Name(), StatusCode: 400, Message: "transaction has not succeeded"}
+	}
+
+	left := transaction.Amount - f.refunded[req.Reference]
+	amount := req.Amount
+	if amount == 0 {
+		amount = left
+	}
+	if amount <= 0 || amount > left {
+		return nil, &ProviderError{Provider: f.Name(), StatusCode: 400, Message: "refund exceeds the transaction amount"}
+	}
+
+	refund := &fakeRefund{
+		Refund: Refund{
+			Provider:         f.Name(),
+			ProviderRefundID: "fake_refund_" + strconv.Itoa(len(f.refunds)+1),
+			Status:           RefundProcessed,
+			Amount:           amount,
+		},
+		reference: req.Reference,
+	}
+	if f.PendingRefunds {
+		refund.Status = RefundPending
+	}
+	f.refunds[refund.ProviderRefundID] = refund
+	f.refunded[req.Reference] += amount
+
+	if f.LoseRefunds {
+		return nil, &ProviderError{Provider: f.Name(), Message: "simulated timeout"}
+	}
+	copied := refund.Refund
+	return &copied, nil
+}
+
+func (f *FakeProvider) VerifyRefund(ctx context.Context, providerRefundID string) (*Refund, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	refund, ok := f.refunds[providerRefundID]
+	if !ok {
+		return nil, &ProviderError{Provider: f.Name(), StatusCode: 404, Message: "refund not found"}
+	}
+	copied := refund.Refund
+	return &copied, nil
+}
+
+func (f *FakeProvider) TransactionRefunds(ctx context.Context, reference string, providerReference string) ([]Refund, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.transactions[reference]; !ok {
+		return nil, &ProviderError{Provider: f.Name(), StatusCode: 404, Message: "transaction not found"}
+	}
+	refunds := []Refund{}
+	for _, refund := range f.refunds {
+		if refund.reference == reference {
+			refunds = append(refunds, refund.Refund)
+		}
+	}
+	return refunds, nil
+}
+
+// CompleteRefund settles a pending refund as processed or failed
+func (f *FakeProvider) CompleteRefund(providerRefundID string, processed bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	refund, ok := f.refunds[providerRefundID]
+	if !ok || refund.Status != RefundPending {
+		return fmt.Errorf("fake refund %s not found or not pending", providerRefundID)
+	}
+	refund.Status = RefundProcessed
+	if !processed {
+		refund.Status = RefundFailed
+		f.refunded[refund.reference] -= refund.Amount // Can be refunded again
+	}
+	return nil
+}
+
+// Fees are always zero, nothing is charged
+func (f *FakeProvider) SettledTransactions(ctx context.Context, from time.Time, to time.Time) ([]Verification, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	transactions := make([]Verification, 0, len(f.transactions))
+	for _, transaction := range f.transactions {
+		transactions = append(transactions, *transaction)
+	}
+	return paidBetween(transactions, from, to), nil
+}
+
+// Charge a stored authorization, settled immediately
+func (f *FakeProvider) Charge(ctx context.Context, req ChargeRequest) (*Verification, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, exists := f.transactions[req.Reference]; exists {
+		return nil, &ProviderError{Provider: f.Name(), StatusCode: 400, Message: "duplicate transaction reference"}
+	}
+	transaction := &Verification{
+		Provider:          f.Name(),
+		Reference:         req.Reference,
+		ProviderReference: "fake_" + req.Reference,
+		Amount:            req.Amount,
+		Currency:          strings.ToUpper(req.Currency),
+		Channel:           "card",
+	}
+	f.transactions[req.Reference] = transaction
+
+	if f.DeclineCharges {
+		f.settle(transaction, StatusFailed)
+		transaction.Message = "Declined"
+	} else {
+		f.settle(transaction, StatusSucceeded)
+	}
+
+	if f.TimeoutCharges {
+		return nil, &ProviderError{Provider: f.Name(), StatusCode: 408, Message: "simulated timeout"}
+	}
+	verification := *transaction
+	return &verification, nil
+}
+
+// Complete settles a pending transaction as succeeded or failed
+func (f *FakeProvider) Complete(reference string, succeeded bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	transaction, ok := f.transactions[reference]
+	if !ok {
+		return fmt.Errorf("fake payment %s not found", reference)
+	}
+
+	status := StatusFailed
+	if succeeded {
+		status = StatusSucceeded
+	}
+	f.settle(transaction, status)
+	return nil
+}
+
+func (f *FakeProvider) settle(transaction *Verification, status string) {
+	transaction.Status = status
+	if status == StatusSucceeded {
+		paidAt := time.Now()
+		transaction.PaidAt = &paidAt
+		transaction.Authorization = &Authorization{
+			Code:     "fake_auth_" + transaction.Reference,
+			Brand:    "visa",
+			Last4:    "4081",
+			ExpMonth: "12",
+			ExpYear:  strconv.Itoa(paidAt.Year() + 3),
+			Reusable: true,
+		}
+	}
+}
+
+// Any 10 digit account number resolves, to a name made from it
+func (f *FakeProvider) ResolveAccount(ctx context.Context, bankCode string, accountNumber string) (*BankAccount, error) {
+	if len(accountNumber) != 10 || strings.Trim(accountNumber, "0123456789") != "" {
+		return nil, &ProviderError{Provider: f.Name(), StatusCode: 422, Message: "could not resolve account name"}
+	}
+	return &BankAccount{
+		BankCode:      bankCode,
+		AccountNumber: accountNumber,
+		AccountName:   "FAKE ACCOUNT " + accountNumber[6:],
+	}, nil
+}
+
+func (f *FakeProvider) CreateRecipient(ctx context.Context, account BankAccount) (string, error) {
+	return "fake_rcp_" + account.BankCode + "_" + account.AccountNumber, nil
+}
+
+// Transfers succeed immediately unless PendingTransfers is set, fees are always zero
+func (f *FakeProvider) Transfer(ctx context.Context, req TransferRequest) (*TransferResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, exists := f.transfers[req.Reference]; exists {
+		return nil, &ProviderError{Provider: f.Name(), StatusCode: 400, Message: "duplicate transfer reference"}
+	}
+	transfer := &TransferResult{
+		Provider:           f.Name(),
+		Reference:          req.Reference,
+		ProviderTransferID: "fake_trf_" + strconv.Itoa(len(f.transfers)+1),
+		Status:             TransferSucceeded,
+		Amount:             req.Amount,
+	}
+	if f.PendingTransfers {
+		transfer.Status = TransferPending
+	}
+	f.transfers[req.Reference] = transfer
+
+	copied := *transfer
+	return &copied, nil
+}
+
+func (f *FakeProvider) VerifyTransfer(ctx context.Context, reference string) (*TransferResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	transfer, ok := f.transfers[reference]
+	if !ok {
+		return nil, &ProviderError{Provider: f.Name(), StatusCode: 404, Message: "transfer not found"}
+	}
+	copied := *transfer
+	return &copied, nil
+}
+
+// CompleteTransfer settles a pending transfer as succeeded or failed
+func (f *FakeProvider) CompleteTransfer(reference string, succeeded bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	transfer, ok := f.transfers[reference]
+	if !ok || transfer.Status != TransferPending {
+		return fmt.Errorf("fake transfer %s not found or not pending", reference)
+	}
+	transfer.Status = TransferSucceeded
+	if !succeeded {
+		transfer.Status = TransferFailed
+		transfer.Message = "Account could not be credited"
+	}
+	return nil
+}
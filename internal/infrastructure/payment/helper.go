@@ -0,0 +1,75 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+)
+
+// Provider responses are small, anything bigger is not a valid reply
+const maxResponseBody = 1 << 20
+
+// doJSON sends a JSON request with a bearer token and decodes the JSON reply into out.
+// Non-2xx replies are returned as a *ProviderError carrying the provider's message when there is one.
+func doJSON(ctx context.Context, client *http.Client, provider string, method string, url string, secretKey string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+secretKey)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return &ProviderError{Provider: provider, Message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if err != nil {
+		return &ProviderError{Provider: provider, StatusCode: resp.StatusCode, Message: err.Error()}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var reply struct {
+			Message string `json:"message"`
+		}
+		json.Unmarshal(data, &reply)
+		if reply.Message == "" {
+			reply.Message = http.StatusText(resp.StatusCode)
+		}
+		return &ProviderError{Provider: provider, StatusCode: resp.StatusCode, Message: reply.Message}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return &ProviderError{Provider: provider, StatusCode: resp.StatusCode, Message: "invalid response: " + err.Error()}
+	}
+	return nil
+}
+
+// Every supported currency has two decimal places
+func toMajor(amount int64) float64 {
+	return float64(amount) / 100
+}
+
+func toMinor(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
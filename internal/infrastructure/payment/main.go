@@ -0,0 +1,214 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"japa/internal/config"
+)
+
+// Transaction statuses reported by Verify, normalized across providers
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Refund statuses, normalized across providers
+const (
+	RefundPending   = "pending"
+	RefundProcessed = "processed"
+	RefundFailed    = "failed"
+)
+
+var (
+	ErrProviderNotFound    = errors.New("payment provider not configured")
+	ErrUnsupportedCurrency = errors.New("currency not supported by any payment provider")
+)
+
+// ProviderError is a failed or rejected provider API call
+type ProviderError struct {
+	Provider   string
+	StatusCode int // 0 when the request never got a response
+	Message    string
+}
+
+func (e *ProviderError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("%s: %s", e.Provider, e.Message)
+	}
+	return fmt.Sprintf("%s: %s (status %d)", e.Provider, e.Message, e.StatusCode)
+}
+
+// Rejected reports whether the provider answered and refused the request, so it certainly didn't happen.
+// No answer, a server error, a timeout or rate limiting leave the outcome unknown.
+func (e *ProviderError) Rejected() bool {
+	return e.StatusCode >= 400 && e.StatusCode < 500 && e.StatusCode != http.StatusRequestTimeout && e.StatusCode != http.StatusTooManyRequests
+}
+
+// InitializeRequest starts a hosted checkout. Amounts are in minor units (kobo, pesewas, cents).
+type InitializeRequest struct {
+	Reference   string // Our unique reference, the key for Verify
+	Email       string
+	Amount      int64
+	Currency    string // ISO 4217, i.e "NGN"
+	CallbackURL string // Where the customer returns after paying
+	Metadata    map[string]any
+}
+
+// Transaction is an initialized checkout
+type Transaction struct {
+	Provider          string
+	Reference         string
+	ProviderReference string // The provider's own id, when it gives one
+	AuthorizationURL  string // Hosted checkout page
+}
+
+// Verification is the provider's view of a transaction
+type Verification struct {
+	Provider          string
+	Reference         string
+	ProviderReference string
+	Status            string
+	Amount            int64
+	Currency          string
+	Fee               int64
+	Channel           string // i.e "card", "bank_transfer"
+	PaidAt            *time.Time
+	Message           string // Gateway response, useful on failures
+	Authorization     *Authorization // Card the customer paid with, when the provider returns one
+}
+
+// RefundRequest refunds a verified transaction, a zero Amount refunds it in full
+type RefundRequest struct {
+	Reference         string
+	ProviderReference string
+	Amount            int64
+	Currency          string
+	Reason            string
+}
+
+// Refund is a refund accepted by the provider
+type Refund struct {
+	Provider         string
+	ProviderRefundID string
+	Status           string
+	Amount           int64
+}
+
+// For responsiveness, all payment providers
+// and functions must satisfy this interface
+type PaymentProvider interface {
+	Name() string
+	Supports(currency string) bool
+	Initialize(ctx context.Context, req InitializeRequest) (*Transaction, error)
+	Verify(ctx context.Context, reference string) (*Verification, error)
+	Refund(ctx context.Context, req RefundRequest) (*Refund, error)
+}
+
+// ResponsivePaymentProvider tries providers in order until one initializes the transaction.
+// Verify and Refund go to the provider that owns the transaction.
+type ResponsivePaymentProvider struct {
+	Providers []PaymentProvider // List of providers to try, in priority order
+}
+
+// Initialize starts the checkout with the first provider that supports the currency and succeeds
+func (rp *ResponsivePaymentProvider) Initialize(ctx context.Context, req InitializeRequest) (*Transaction, error) {
+	lastErr := ErrUnsupportedCurrency // Stays if no provider takes the currency
+
+	// Loop through each provider in order
+	for _, provider := range rp.Providers {
+		if !provider.Supports(req.Currency) {
+			continue
+		}
+
+		transaction, err := provider.Initialize(ctx, req)
+		if err == nil {
+			return transaction, nil
+		}
+
+		// Stop falling back once the caller gave up
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		// If initializing failed, store this error and continue to the next provider
+		lastErr = err
+	}
+
+	// If we got here,
+	// all providers failed.
+	return nil, lastErr
+}
+
+// Verify asks the owning provider for the transaction's status
+func (rp *ResponsivePaymentProvider) Verify(ctx context.Context, providerName string, reference string) (*Verification, error) {
+	provider, err := rp.Provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Verify(ctx, reference)
+}
+
+// Refund refunds through the owning provider
+func (rp *ResponsivePaymentProvider) Refund(ctx context.Context, providerName string, req RefundRequest) (*Refund, error) {
+	provider, err := rp.Provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Refund(ctx, req)
+}
+
+// Provider finds a configured provider by name
+func (rp *ResponsivePaymentProvider) Provider(name string) (PaymentProvider, error) {
+	for _, provider := range rp.Providers {
+		if provider.Name() == name {
+			return provider, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrProviderNotFound, name)
+}
+
+func supportsCurrency(currencies []string, currency string) bool {
+	for _, supported := range currencies {
+		if strings.EqualFold(supported, currency) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewResponsivePaymentProvider builds the configured providers in priority order.
+// fakeCheckoutURL is where fake payments send the customer.
+func NewResponsivePaymentProvider(cfg config.PaymentConfig, fakeCheckoutURL string) (*ResponsivePaymentProvider, error) {
+	registry := &ResponsivePaymentProvider{}
+	for _, name := range cfg.Providers {
+		switch name {
+		case "paystack":
+			if cfg.PaystackSecretKey == "" {
+				return nil, errors.New("PAYSTACK_SECRET_KEY is required for the paystack provider")
+			}
+			registry.Providers = append(registry.Providers, NewPaystackProvider(cfg.PaystackBaseURL, cfg.PaystackSecretKey, cfg.Timeout))
+		case "flutterwave":
+			if cfg.FlutterwaveSecretKey == "" {
+				return nil, errors.New("FLUTTERWAVE_SECRET_KEY is required for the flutterwave provider")
+			}
+			flutterwave := NewFlutterwaveProvider(cfg.FlutterwaveBaseURL, cfg.FlutterwaveSecretKey, cfg.Timeout)
+			flutterwave.WebhookHash = cfg.FlutterwaveWebhookHash
+			registry.Providers = append(registry.Providers, flutterwave)
+		case "fake":
+			registry.Providers = append(registry.Providers, NewFakeProvider(fakeCheckoutURL, cfg.FakeAutoSucceed))
+		default:
+			return nil, fmt.Errorf("unknown payment provider: %s", name)
+		}
+	}
+
+	if len(registry.Providers) == 0 {
+		return nil, errors.New("no payment provider configured")
+	}
+	return registry, nil
+}
@@ -0,0 +1,454 @@
+package payment
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Paystack settles in these currencies
+var paystackCurrencies = []string{"NGN", "GHS", "KES", "ZAR", "USD"}
+
+// PaystackProvider talks to the Paystack API, amounts are already in minor units there
+type PaystackProvider struct {
+	BaseURL   string // i.e "https://api.paystack.co"
+	SecretKey string
+	Client    *http.Client
+}
+
+// Initialize PaystackProvider
+func NewPaystackProvider(baseURL string, secretKey string, timeout time.Duration) *PaystackProvider {
+	return &PaystackProvider{
+		BaseURL:   strings.TrimRight(baseURL, "/"),
+		SecretKey: secretKey,
+		Client:    &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *PaystackProvider) Name() string {
+	return "paystack"
+}
+
+func (p *PaystackProvider) Supports(currency string) bool {
+	return supportsCurrency(paystackCurrencies, currency)
+}
+
+// Initialize a hosted checkout, POST /transaction/initialize
+func (p *PaystackProvider) Initialize(ctx context.Context, req InitializeRequest) (*Transaction, error) {
+	body := map[string]any{
+		"email":     req.Email,
+		"amount":    req.Amount,
+		"currency":  strings.ToUpper(req.Currency),
+		"reference": req.Reference,
+		"metadata":  req.Metadata,
+	}
+	if req.CallbackURL != "" {
+		body["callback_url"] = req.CallbackURL
+	}
+
+	var reply struct {
+		Status  bool   `json:"status"`
+		Message string `json:"message"`
+		Data    struct {
+			AuthorizationURL string `json:"authorization_url"`
+			AccessCode       string `json:"access_code"`
+			Reference        string `json:"reference"`
+		} `json:"data"`
+	}
+	if err := doJSON(ctx, p.Client, p.Name(), http.MethodPost, p.BaseURL+"/transaction/initialize", p.SecretKey, body, &reply); err != nil {
+		return nil, err
+	}
+	if !reply.Status {
+		return nil, &ProviderError{Provider: p.Name(), Message: reply.Message}
+	}
+
+	return &Transaction{
+		Provider:          p.Name(),
+		Reference:         reply.Data.Reference,
+		ProviderReference: reply.Data.AccessCode,
+		AuthorizationURL:  reply.Data.AuthorizationURL,
+	}, nil
+}
+
+// Verify a transaction, GET /transaction/verify/:reference
+func (p *PaystackProvider) Verify(ctx context.Context, reference string) (*Verification, error) {
+	var reply struct {
+		Status  bool                `json:"status"`
+		Message string              `json:"message"`
+		Data    paystackTransaction `json:"data"`
+	}
+	if err := doJSON(ctx, p.Client, p.Name(), http.MethodGet, p.BaseURL+"/transaction/verify/"+url.PathEscape(reference), p.SecretKey, nil, &reply); err != nil {
+		return nil, err
+	}
+	if !reply.Status {
+		return nil, &ProviderError{Provider: p.Name(), Message: reply.Message}
+	}
+
+	return reply.Data.verification(p.Name()), nil
+}
+
+// Charge a stored authorization, POST /transaction/charge_authorization
+func (p *PaystackProvider) Charge(ctx context.Context, req ChargeRequest) (*Verification, error) {
+	body := map[string]any{
+		"email":              req.Email,
+		"amount":             req.Amount,
+		"currency":           strings.ToUpper(req.Currency),
+		"reference":          req.Reference,
+		"authorization_code": req.AuthorizationCode,
+		"metadata":           req.Metadata,
+	}
+
+	var reply struct {
+		Status  bool                `json:"status"`
+		Message string              `json:"message"`
+		Data    paystackTransaction `json:"data"`
+	}
+	if err := doJSON(ctx, p.Client, p.Name(), http.MethodPost, p.BaseURL+"/transaction/charge_authorization", p.SecretKey, body, &reply); err != nil {
+		return nil, err
+	}
+	if !reply.Status {
+		return nil, &ProviderError{Provider: p.Name(), Message: reply.Message}
+	}
+
+	return reply.Data.verification(p.Name()), nil
+}
+
+// Transaction as returned by verify and charge_authorization
+type paystackTransaction struct {
+	ID              int64      `json:"id"`
+	Status          string     `json:"status"` // success, failed, abandoned, ongoing, pending...
+	Reference       string     `json:"reference"`
+	Amount          int64      `json:"amount"`
+	Currency        string     `json:"currency"`
+	Fees            int64      `json:"fees"`
+	Channel         string     `json:"channel"`
+	GatewayResponse string     `json:"gateway_response"`
+	PaidAt          *time.Time `json:"paid_at"`
+	Authorization   struct {
+		AuthorizationCode string `json:"authorization_code"`
+		CardType          string `json:"card_type"`
+		Last4             string `json:"last4"`
+		ExpMonth          string `json:"exp_month"`
+		ExpYear           string `json:"exp_year"`
+		Reusable          bool   `json:"reusable"`
+	} `json:"authorization"`
+}
+
+func (t paystackTransaction) verification(provider string) *Verification {
+	status := StatusPending
+	switch t.Status {
+	case "success":
+		status = StatusSucceeded
+	case "failed", "abandoned", "reversed":
+		status = StatusFailed
+	}
+
+	verification := &Verification{
+		Provider:          provider,
+		Reference:         t.Reference,
+		ProviderReference: strconv.FormatInt(t.ID, 10),
+		Status:            status,
+		Amount:            t.Amount,
+		Currency:          t.Currency,
+		Fee:               t.Fees,
+		Channel:           t.Channel,
+		PaidAt:            t.PaidAt,
+		Message:           t.GatewayResponse,
+	}
+	if t.Authorization.AuthorizationCode != "" {
+		verification.Authorization = &Authorization{
+			Code:     t.Authorization.AuthorizationCode,
+			Brand:    strings.TrimSpace(t.Authorization.CardType),
+			Last4:    t.Authorization.Last4,
+			ExpMonth: t.Authorization.ExpMonth,
+			ExpYear:  t.Authorization.ExpYear,
+			Reusable: t.Authorization.Reusable,
+		}
+	}
+	return verification
+}
+
+// Refund a transaction in full or in part, POST /refund
+func (p *PaystackProvider) Refund(ctx context.Context, req RefundRequest) (*Refund, error) {
+	body := map[string]any{
+		"transaction": req.Reference,
+	}
+	if req.Amount > 0 {
+		body["amount"] = req.Amount
+	}
+	if req.Currency != "" {
+		body["currency"] = strings.ToUpper(req.Currency)
+	}
+	if req.Reason != "" {
+		body["merchant_note"] = req.Reason
+	}
+
+	var reply struct {
+		Status  bool   `json:"status"`
+		Message string `json:"message"`
+		Data    struct {
+			ID     int64  `json:"id"`
+			Status string `json:"status"` // pending, processing, processed, failed
+			Amount int64  `json:"amount"`
+		} `json:"data"`
+	}
+	if err := doJSON(ctx, p.Client, p.Name(), http.MethodPost, p.BaseURL+"/refund", p.SecretKey, body, &reply); err != nil {
+		return nil, err
+	}
+	if !reply.Status {
+		return nil, &ProviderError{Provider: p.Name(), Message: reply.Message}
+	}
+
+	return &Refund{
+		Provider:         p.Name(),
+		ProviderRefundID: strconv.FormatInt(reply.Data.ID, 10),
+		Status:           paystackRefundStatus(reply.Data.Status),
+		Amount:           reply.Data.Amount,
+	}, nil
+}
+
+// Look up a refund, GET /refund/:id
+func (p *PaystackProvider) VerifyRefund(ctx context.Context, providerRefundID string) (*Refund, error) {
+	var reply struct {
+		Status  bool   `json:"status"`
+		Message string `json:"message"`
+		Data    struct {
+			ID     int64  `json:"id"`
+			Status string `json:"status"`
+			Amount int64  `json:"amount"`
+		} `json:"data"`
+	}
+	if err := doJSON(ctx, p.Client, p.Name(), http.MethodGet, p.BaseURL+"/refund/"+url.PathEscape(providerRefundID), p.SecretKey, nil, &reply); err != nil {
+		return nil, err
+	}
+	if !reply.Status {
+		return nil, &ProviderError{Provider: p.Name(), Message: reply.Message}
+	}
+
+	return &Refund{
+		Provider:         p.Name(),
+		ProviderRefundID: strconv.FormatInt(reply.Data.ID, 10),
+		Status:           paystackRefundStatus(reply.Data.Status),
+		Amount:           reply.Data.Amount,
+	}, nil
+}
+
+// Refunds made on a transaction, GET /refund?transaction=:id.
+// Paystack filters by its transaction id, it is looked up when not given.
+func (p *PaystackProvider) TransactionRefunds(ctx context.Context, reference string, providerReference string) ([]Refund, error) {
+	transactionID := providerReference
+	if _, err := strconv.ParseInt(transactionID, 10, 64); err != nil {
+		verification, err := p.Verify(ctx, reference)
+		if err != nil {
+			return nil, err
+		}
+		transactionID = verification.ProviderReference
+	}
+
+	var reply struct {
+		Status  bool   `json:"status"`
+		Message string `json:"message"`
+		Data    []struct {
+			ID     int64  `json:"id"`
+			Status string `json:"status"`
+			Amount int64  `json:"amount"`
+		} `json:"data"`
+	}
+	endpoint := p.BaseURL + "/refund?transaction=" + url.QueryEscape(transactionID)
+	if err := doJSON(ctx, p.Client, p.Name(), http.MethodGet, endpoint, p.SecretKey, nil, &reply); err != nil {
+		return nil, err
+	}
+	if !reply.Status {
+		return nil, &ProviderError{Provider: p.Name(), Message: reply.Message}
+	}
+
+	refunds := make([]Refund, len(reply.Data))
+	for i, data := range reply.Data {
+		refunds[i] = Refund{
+			Provider:         p.Name(),
+			ProviderRefundID: strconv.FormatInt(data.ID, 10),
+			Status:           paystackRefundStatus(data.Status),
+			Amount:           data.Amount,
+		}
+	}
+	return refunds, nil
+}
+
+// pending, processing, processed, failed, needs-attention
+func paystackRefundStatus(status string) string {
+	switch status {
+	case "processed":
+		return RefundProcessed
+	case "failed":
+		return RefundFailed
+	default:
+		return RefundPending
+	}
+}
+
+// Successful transactions paid in [from, to), GET /transaction a page at a time
+func (p *PaystackProvider) SettledTransactions(ctx context.Context, from time.Time, to time.Time) ([]Verification, error) {
+	var transactions []Verification
+	for page := 1; ; page++ {
+		var reply struct {
+			Status  bool                  `json:"status"`
+			Message string                `json:"message"`
+			Data    []paystackTransaction `json:"data"`
+			Meta    struct {
+				PageCount int `json:"pageCount"`
+			} `json:"meta"`
+		}
+		query := url.Values{}
+		query.Set("status", "success")
+		query.Set("from", from.UTC().Format(time.RFC3339))
+		query.Set("to", to.UTC().Format(time.RFC3339))
+		query.Set("perPage", "100")
+		query.Set("page", strconv.Itoa(page))
+		if err := doJSON(ctx, p.Client, p.Name(), http.MethodGet, p.BaseURL+"/transaction?"+query.Encode(), p.SecretKey, nil, &reply); err != nil {
+			return nil, err
+		}
+		if !reply.Status {
+			return nil, &ProviderError{Provider: p.Name(), Message: reply.Message}
+		}
+
+		for _, transaction := range reply.Data {
+			transactions = append(transactions, *transaction.verification(p.Name()))
+		}
+		if page >= reply.Meta.PageCount {
+			return paidBetween(transactions, from, to), nil
+		}
+	}
+}
+
+// Look up the name on a bank account, GET /bank/resolve
+func (p *PaystackProvider) ResolveAccount(ctx context.Context, bankCode string, accountNumber string) (*BankAccount, error) {
+	var reply struct {
+		Status  bool   `json:"status"`
+		Message string `json:"message"`
+		Data    struct {
+			AccountNumber string `json:"account_number"`
+			AccountName   string `json:"account_name"`
+		} `json:"data"`
+	}
+	query := url.Values{}
+	query.Set("account_number", accountNumber)
+	query.Set("bank_code", bankCode)
+	if err := doJSON(ctx, p.Client, p.Name(), http.MethodGet, p.BaseURL+"/bank/resolve?"+query.Encode(), p.SecretKey, nil, &reply); err != nil {
+		return nil, err
+	}
+	if !reply.Status {
+		return nil, &ProviderError{Provider: p.Name(), Message: reply.Message}
+	}
+
+	return &BankAccount{
+		BankCode:      bankCode,
+		AccountNumber: reply.Data.AccountNumber,
+		AccountName:   reply.Data.AccountName,
+	}, nil
+}
+
+// Register a resolved account to send transfers to, POST /transferrecipient
+func (p *PaystackProvider) CreateRecipient(ctx context.Context, account BankAccount) (string, error) {
+	recipientType := "nuban"
+	switch strings.ToUpper(account.Currency) {
+	case "GHS":
+		recipientType = "ghipss"
+	case "KES":
+		recipientType = "kepss"
+	case "ZAR":
+		recipientType = "basa"
+	}
+	body := map[string]any{
+		"type":           recipientType,
+		"name":           account.AccountName,
+		"account_number": account.AccountNumber,
+		"bank_code":      account.BankCode,
+		"currency":       strings.ToUpper(account.Currency),
+	}
+
+	var reply struct {
+		Status  bool   `json:"status"`
+		Message string `json:"message"`
+		Data    struct {
+			RecipientCode string `json:"recipient_code"`
+		} `json:"data"`
+	}
+	if err := doJSON(ctx, p.Client, p.Name(), http.MethodPost, p.BaseURL+"/transferrecipient", p.SecretKey, body, &reply); err != nil {
+		return "", err
+	}
+	if !reply.Status {
+		return "", &ProviderError{Provider: p.Name(), Message: reply.Message}
+	}
+	return reply.Data.RecipientCode, nil
+}
+
+// Pay out of our balance, POST /transfer
+func (p *PaystackProvider) Transfer(ctx context.Context, req TransferRequest) (*TransferResult, error) {
+	body := map[string]any{
+		"source":    "balance",
+		"amount":    req.Amount,
+		"currency":  strings.ToUpper(req.Currency),
+		"recipient": req.RecipientCode,
+		"reference": req.Reference,
+		"reason":    req.Reason,
+	}
+
+	var reply struct {
+		Status  bool             `json:"status"`
+		Message string           `json:"message"`
+		Data    paystackTransfer `json:"data"`
+	}
+	if err := doJSON(ctx, p.Client, p.Name(), http.MethodPost, p.BaseURL+"/transfer", p.SecretKey, body, &reply); err != nil {
+		return nil, err
+	}
+	if !reply.Status {
+		return nil, &ProviderError{Provider: p.Name(), Message: reply.Message}
+	}
+	return reply.Data.result(p.Name()), nil
+}
+
+// Look up a transfer by our reference, GET /transfer/verify/:reference
+func (p *PaystackProvider) VerifyTransfer(ctx context.Context, reference string) (*TransferResult, error) {
+	var reply struct {
+		Status  bool             `json:"status"`
+		Message string           `json:"message"`
+		Data    paystackTransfer `json:"data"`
+	}
+	if err := doJSON(ctx, p.Client, p.Name(), http.MethodGet, p.BaseURL+"/transfer/verify/"+url.PathEscape(reference), p.SecretKey, nil, &reply); err != nil {
+		return nil, err
+	}
+	if !reply.Status {
+		return nil, &ProviderError{Provider: p.Name(), Message: reply.Message}
+	}
+	return reply.Data.result(p.Name()), nil
+}
+
+// Transfer as returned by transfer and transfer/verify
+type paystackTransfer struct {
+	TransferCode string `json:"transfer_code"`
+	Reference    string `json:"reference"`
+	Status       string `json:"status"` // pending, otp, received, success, failed, reversed, abandoned, blocked, rejected
+	Amount       int64  `json:"amount"`
+	Fee          int64  `json:"fee_charged"`
+}
+
+func (t paystackTransfer) result(provider string) *TransferResult {
+	status := TransferPending
+	switch t.Status {
+	case "success":
+		status = TransferSucceeded
+	case "failed", "reversed", "abandoned", "blocked", "rejected":
+		status = TransferFailed
+	}
+	return &TransferResult{
+		Provider:           provider,
+		Reference:          t.Reference,
+		ProviderTransferID: t.TransferCode,
+		Status:             status,
+		Amount:             t.Amount,
+		Fee:                t.Fee,
+	}
+}
@@ -0,0 +1,48 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrRecurringNotSupported = errors.New("payment provider cannot charge stored authorizations")
+
+// Authorization is a card the provider lets us charge again without the customer
+type Authorization struct {
+	Code     string // Paystack authorization_code, Flutterwave card token
+	Brand    string // i.e "visa"
+	Last4    string
+	ExpMonth string
+	ExpYear  string
+	Reusable bool
+}
+
+// ChargeRequest charges a stored authorization. Amounts are in minor units.
+type ChargeRequest struct {
+	Reference         string // Our unique reference, the key for Verify
+	Email             string // Paystack charges the authorization's customer by email
+	AuthorizationCode string
+	Amount            int64
+	Currency          string
+	Metadata          map[string]any
+}
+
+// RecurringProvider is implemented by providers that can charge stored authorizations (renewals).
+// The returned verification may still be pending, the outcome then arrives by webhook.
+type RecurringProvider interface {
+	Charge(ctx context.Context, req ChargeRequest) (*Verification, error)
+}
+
+// Charge a stored authorization through the provider that issued it
+func (rp *ResponsivePaymentProvider) Charge(ctx context.Context, providerName string, req ChargeRequest) (*Verification, error) {
+	provider, err := rp.Provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	recurring, ok := provider.(RecurringProvider)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRecurringNotSupported, providerName)
+	}
+	return recurring.Charge(ctx, req)
+}
@@ -0,0 +1,69 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrRefundLookupNotSupported = errors.New("payment provider cannot look up refunds")
+	ErrRefundListNotSupported   = errors.New("payment provider cannot list a transaction's refunds")
+)
+
+// Dispute statuses, normalized across providers
+const (
+	DisputeOpen = "open" // Waiting on us or the customer's bank
+	DisputeWon  = "won"  // Resolved in our favour, nothing is taken back
+	DisputeLost = "lost" // The customer's bank took the money back (chargeback)
+)
+
+// Dispute is a chargeback raised with the customer's bank, as reported by a signed webhook.
+// Amounts are in minor units.
+type Dispute struct {
+	ProviderDisputeID string
+	Status            string
+	Amount            int64 // What the bank claims back
+	Currency          string
+	Reason            string     // Category or note given by the provider
+	DueAt             *time.Time // Evidence is due by then, when the provider says
+}
+
+// RefundVerifier is implemented by providers that can look up a refund they accepted.
+// Refunds often stay pending for days, their outcome is fetched again instead of trusting webhooks.
+type RefundVerifier interface {
+	VerifyRefund(ctx context.Context, providerRefundID string) (*Refund, error)
+}
+
+// RefundLister is implemented by providers that can list the refunds made on a transaction.
+// A refund whose request got no answer is found this way, it has no provider id to look up.
+type RefundLister interface {
+	TransactionRefunds(ctx context.Context, reference string, providerReference string) ([]Refund, error)
+}
+
+// VerifyRefund looks up a refund with the provider that accepted it
+func (rp *ResponsivePaymentProvider) VerifyRefund(ctx context.Context, providerName string, providerRefundID string) (*Refund, error) {
+	provider, err := rp.Provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	verifier, ok := provider.(RefundVerifier)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRefundLookupNotSupported, providerName)
+	}
+	return verifier.VerifyRefund(ctx, providerRefundID)
+}
+
+// TransactionRefunds lists the refunds made on a transaction with the provider that took it
+func (rp *ResponsivePaymentProvider) TransactionRefunds(ctx context.Context, providerName string, reference string, providerReference string) ([]Refund, error) {
+	provider, err := rp.Provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	lister, ok := provider.(RefundLister)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRefundListNotSupported, providerName)
+	}
+	return lister.TransactionRefunds(ctx, reference, providerReference)
+}
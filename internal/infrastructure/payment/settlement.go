@@ -0,0 +1,55 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrSettlementsNotSupported = errors.New("payment provider cannot report settled transactions")
+
+// SettlementReporter is implemented by providers that can list the transactions they collected.
+// It is what the ledger is reconciled against, fees are what the provider kept.
+type SettlementReporter interface {
+	SettledTransactions(ctx context.Context, from time.Time, to time.Time) ([]Verification, error)
+}
+
+// SettledTransactions lists the successful transactions a provider collected in [from, to)
+func (rp *ResponsivePaymentProvider) SettledTransactions(ctx context.Context, providerName string, from time.Time, to time.Time) ([]Verification, error) {
+	provider, err := rp.Provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	reporter, ok := provider.(SettlementReporter)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSettlementsNotSupported, providerName)
+	}
+	return reporter.SettledTransactions(ctx, from, to)
+}
+
+// Reporters lists the names of providers that can report settled transactions, in priority order
+func (rp *ResponsivePaymentProvider) Reporters() []string {
+	var names []string
+	for _, provider := range rp.Providers {
+		if _, ok := provider.(SettlementReporter); ok {
+			names = append(names, provider.Name())
+		}
+	}
+	return names
+}
+
+// Keeps transactions paid in [from, to), providers filter by day or by creation time
+func paidBetween(transactions []Verification, from time.Time, to time.Time) []Verification {
+	var paid []Verification
+	for _, transaction := range transactions {
+		if transaction.Status != StatusSucceeded || transaction.PaidAt == nil {
+			continue
+		}
+		if transaction.PaidAt.Before(from) || !transaction.PaidAt.Before(to) {
+			continue
+		}
+		paid = append(paid, transaction)
+	}
+	return paid
+}
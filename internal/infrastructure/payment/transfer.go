@@ -0,0 +1,79 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Transfer statuses, normalized across providers
+const (
+	TransferPending   = "pending"
+	TransferSucceeded = "succeeded"
+	TransferFailed    = "failed" // Includes transfers the provider reversed
+)
+
+var ErrTransfersNotSupported = errors.New("no payment provider can send transfers in this currency")
+
+// BankAccount is an account the provider resolved with the bank, AccountName is the bank's
+type BankAccount struct {
+	BankCode      string
+	AccountNumber string
+	AccountName   string
+	Currency      string
+}
+
+// TransferRequest pays out of our provider balance into a recipient's account. Amounts are in minor units.
+type TransferRequest struct {
+	Reference     string // Our unique reference, the provider rejects it twice
+	RecipientCode string
+	Amount        int64
+	Currency      string
+	Reason        string // Shown on the recipient's statement
+}
+
+// TransferResult is the provider's view of a transfer
+type TransferResult struct {
+	Provider           string
+	Reference          string
+	ProviderTransferID string
+	Status             string
+	Amount             int64
+	Fee                int64  // Taken from our balance on top of Amount, when the provider says
+	Message            string // Reason for failures
+}
+
+// TransferProvider is implemented by providers that can pay money out (partner payouts).
+// Transfers often stay pending, their outcome is looked up again with VerifyTransfer.
+type TransferProvider interface {
+	Name() string
+	Supports(currency string) bool
+	ResolveAccount(ctx context.Context, bankCode string, accountNumber string) (*BankAccount, error)
+	CreateRecipient(ctx context.Context, account BankAccount) (string, error)
+	Transfer(ctx context.Context, req TransferRequest) (*TransferResult, error)
+	VerifyTransfer(ctx context.Context, reference string) (*TransferResult, error)
+}
+
+// TransfersFor finds the first provider, in priority order, that can send transfers in currency
+func (rp *ResponsivePaymentProvider) TransfersFor(currency string) (TransferProvider, error) {
+	for _, provider := range rp.Providers {
+		transfers, ok := provider.(TransferProvider)
+		if ok && provider.Supports(currency) {
+			return transfers, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrTransfersNotSupported, currency)
+}
+
+// Transfers finds a configured provider that can send transfers by name
+func (rp *ResponsivePaymentProvider) Transfers(providerName string) (TransferProvider, error) {
+	provider, err := rp.Provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	transfers, ok := provider.(TransferProvider)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTransfersNotSupported, providerName)
+	}
+	return transfers, nil
+}
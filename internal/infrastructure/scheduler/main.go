@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+
+// Job is a unit of background work run periodically by the Scheduler
+type Job interface {
+	Run(ctx context.Context) error
+}
+
+// JobFunc lets plain functions satisfy Job
+type JobFunc func(ctx context.Context) error
+
+func (f JobFunc) Run(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Task is a named job and how often it runs
+type Task struct {
+	Name     string
+	Job      Job
+	Interval time.Duration
+}
+
+// Scheduler runs every task on its own interval until ctx is canceled
+type Scheduler struct {
+	Tasks  []Task
+	Logger *zap.Logger
+}
+
+// Run starts each task in its own goroutine.
+// Like MultiScraper, a task runs immediately and then after every interval.
+func (s *Scheduler) Run(ctx context.Context) {
+	for _, task := range s.Tasks {
+		go s.runTask(ctx, task)
+	}
+}
+
+func (s *Scheduler) runTask(ctx context.Context, task Task) {
+	for {
+		s.runOnce(ctx, task)
+
+		select {
+		case <-time.After(task.Interval):
+			// continue
+		case <-ctx.Done():
+			s.Logger.Info("Scheduled task stopped due to shutdown signal", zap.String("task", task.Name))
+			return
+		}
+	}
+}
+
+// runOnce runs a single cycle, a panicking job must not kill the scheduler
+func (s *Scheduler) runOnce(ctx context.Context, task Task) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			s.Logger.Error("Panic recovered in scheduled task", zap.String("task", task.Name), zap.Any("error", panicErr))
+		}
+	}()
+
+	if err := task.Job.Run(ctx); err != nil {
+		s.Logger.Error("Scheduled task failed", zap.String("task", task.Name), zap.Error(err))
+	}
+}
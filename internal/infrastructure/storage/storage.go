@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrRemoteFile  = errors.New("file is not in local storage")
+	ErrOutsideRoot = errors.New("file path is outside the uploads directory")
+)
+
+// Store removes uploaded files, a cloud store can implement it for remote URLs
+type Store interface {
+	Delete(ctx context.Context, path string) error
+}
+
+// LocalStore deletes files under Root
+type LocalStore struct {
+	Root string
+}
+
+// Initialize LocalStore
+func NewLocalStore(root string) *LocalStore {
+	return &LocalStore{Root: root}
+}
+
+// Delete removes the file, relative paths are resolved against Root.
+// A file that is already gone counts as deleted.
+func (s *LocalStore) Delete(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return ErrRemoteFile
+	}
+
+	root, err := filepath.Abs(s.Root)
+	if err != nil {
+		return err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	path = filepath.Clean(path)
+
+	// Never follow a stored path out of the uploads directory
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ErrOutsideRoot
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"japa/internal/pkg"
+)
+
+// Response bodies are not needed, only read a little so connections are reused
+const maxResponseBody = 4 << 10
+
+// Sender posts signed webhook payloads
+type Sender struct {
+	Client    *http.Client
+	UserAgent string
+}
+
+// Message is a single signed delivery
+type Message struct {
+	URL        string
+	Secret     string
+	DeliveryID string
+	EventType  string
+	Payload    []byte
+}
+
+// Initialize Sender
+func NewSender(timeout time.Duration, userAgent string) *Sender {
+	return &Sender{
+		Client: &http.Client{
+			Timeout: timeout,
+			// Never follow redirects, the endpoint URL is what was registered
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		UserAgent: userAgent,
+	}
+}
+
+// Send posts the message and returns the response status code.
+// Any non-2xx status is returned as an error together with the code.
+func (s *Sender) Send(ctx context.Context, message Message) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, message.URL, bytes.NewReader(message.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", s.UserAgent)
+	req.Header.Set("X-Japa-Event", message.EventType)
+	req.Header.Set("X-Japa-Delivery", message.DeliveryID)
+	req.Header.Set(pkg.WebhookSignatureHeader, pkg.SignWebhook(message.Secret, time.Now(), message.Payload))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook endpoint responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
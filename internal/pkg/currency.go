@@ -0,0 +1,36 @@
+package pkg
+
+import (
+	"strings"
+)
+
+// Currencies of the countries we price for, by ISO 3166-1 alpha-2 code
+var countryCurrencies = map[string]string{
+	"NG": "NGN",
+	"GH": "GHS",
+	"KE": "KES",
+	"US": "USD",
+	"GB": "GBP",
+	"ZA": "ZAR",
+	"UG": "UGX",
+	"TZ": "TZS",
+	"RW": "RWF",
+	"EG": "EGP",
+	"CA": "CAD",
+	"AU": "AUD",
+	"IE": "EUR",
+	"DE": "EUR",
+	"FR": "EUR",
+	"NL": "EUR",
+	"BE": "EUR",
+	"IT": "EUR",
+	"ES": "EUR",
+	"PT": "EUR",
+	"FI": "EUR",
+	"AT": "EUR",
+}
+
+// CountryCurrency is the currency used in country, "" when we don't know it
+func CountryCurrency(country string) string {
+	return countryCurrencies[strings.ToUpper(strings.TrimSpace(country))]
+}
@@ -0,0 +1,58 @@
+package pkg
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode"
+)
+
+// Phone numbers are compared on their trailing digits, so
+// "+234 801 234 5678" and "08012345678" fingerprint the same
+const phoneSignificantDigits = 10
+
+// KeyedHash returns the hex HMAC-SHA256 of value.
+// Without the key the hash can't be brute forced back to a passport number or phone.
+func KeyedHash(key string, value string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NormalizePassport uppercases and drops separators, i.e "a12 345-678" => "A12345678"
+func NormalizePassport(passport string) string {
+	var b strings.Builder
+	for _, r := range passport {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return b.String()
+}
+
+// NormalizeEmail lowercases and drops the "+tag" of the local part, i.e "Ada+2@Mail.com" => "ada@mail.com"
+func NormalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	local, domain, found := strings.Cut(email, "@")
+	if !found {
+		return email
+	}
+	local, _, _ = strings.Cut(local, "+")
+	return local + "@" + domain
+}
+
+// NormalizePhone keeps the significant trailing digits
+func NormalizePhone(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	digits := b.String()
+	if len(digits) > phoneSignificantDigits {
+		digits = digits[len(digits)-phoneSignificantDigits:]
+	}
+	return digits
+}
@@ -0,0 +1,89 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ICSEvent is a single calendar event (RFC 5545)
+type ICSEvent struct {
+	UID         string // Globally unique, i.e "<appointment id>@<site domain>"
+	Sequence    int    // Bump on every update so calendars replace the old event
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	Cancelled   bool
+	Organizer   string // Optional email
+}
+
+const icsTimeFormat = "20060102T150405Z"
+
+// BuildICS renders an iCalendar document with a single event.
+// Times are written in UTC so every client shows them in its own timezone.
+func BuildICS(productID string, event ICSEvent) []byte {
+	var buf bytes.Buffer
+
+	method := "PUBLISH"
+	status := "CONFIRMED"
+	if event.Cancelled {
+		method = "CANCEL"
+		status = "CANCELLED"
+	}
+
+	writeICSLine(&buf, "BEGIN:VCALENDAR")
+	writeICSLine(&buf, "VERSION:2.0")
+	writeICSLine(&buf, "PRODID:-//"+escapeICS(productID)+"//EN")
+	writeICSLine(&buf, "CALSCALE:GREGORIAN")
+	writeICSLine(&buf, "METHOD:"+method)
+	writeICSLine(&buf, "BEGIN:VEVENT")
+	writeICSLine(&buf, "UID:"+escapeICS(event.UID))
+	writeICSLine(&buf, fmt.Sprintf("SEQUENCE:%d", event.Sequence))
+	writeICSLine(&buf, "DTSTAMP:"+time.Now().UTC().Format(icsTimeFormat))
+	writeICSLine(&buf, "DTSTART:"+event.Start.UTC().Format(icsTimeFormat))
+	writeICSLine(&buf, "DTEND:"+event.End.UTC().Format(icsTimeFormat))
+	writeICSLine(&buf, "SUMMARY:"+escapeICS(event.Summary))
+	if event.Description != "" {
+		writeICSLine(&buf, "DESCRIPTION:"+escapeICS(event.Description))
+	}
+	if event.Location != "" {
+		writeICSLine(&buf, "LOCATION:"+escapeICS(event.Location))
+	}
+	if event.Organizer != "" {
+		writeICSLine(&buf, "ORGANIZER:mailto:"+event.Organizer)
+	}
+	writeICSLine(&buf, "STATUS:"+status)
+	writeICSLine(&buf, "END:VEVENT")
+	writeICSLine(&buf, "END:VCALENDAR")
+
+	return buf.Bytes()
+}
+
+// Escapes text values as required by RFC 5545
+func escapeICS(value string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return replacer.Replace(value)
+}
+
+// Writes a content line folded at 75 octets, lines end with CRLF
+func writeICSLine(buf *bytes.Buffer, line string) {
+	for len(line) > 75 {
+		cut := 75
+		// Don't split a multi-byte character
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		buf.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+	}
+	buf.WriteString(line + "\r\n")
+}
@@ -0,0 +1,55 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ErrNotJSONObject is returned when a merge target or patch is not a JSON object
+var ErrNotJSONObject = errors.New("json merge patch: document must be a JSON object")
+
+// MergePatch applies a JSON merge patch (RFC 7386) to original and returns the result.
+//   - keys in patch overwrite keys in original
+//   - null values delete the key
+//   - nested objects are merged recursively, anything else (arrays included) is replaced
+//
+// An empty original is treated as {}.
+func MergePatch(original []byte, patch []byte) ([]byte, error) {
+	target := map[string]any{}
+	if len(original) > 0 && string(original) != "null" {
+		if err := json.Unmarshal(original, &target); err != nil {
+			return nil, ErrNotJSONObject
+		}
+	}
+
+	var patchDoc map[string]any
+	if err := json.Unmarshal(patch, &patchDoc); err != nil || patchDoc == nil {
+		return nil, ErrNotJSONObject
+	}
+
+	return json.Marshal(mergeObjects(target, patchDoc))
+}
+
+func mergeObjects(target map[string]any, patch map[string]any) map[string]any {
+	if target == nil {
+		target = map[string]any{}
+	}
+
+	for key, patchValue := range patch {
+		if patchValue == nil {
+			delete(target, key)
+			continue
+		}
+
+		patchObject, isObject := patchValue.(map[string]any)
+		if !isObject {
+			target[key] = patchValue
+			continue
+		}
+
+		targetObject, _ := target[key].(map[string]any)
+		target[key] = mergeObjects(targetObject, patchObject)
+	}
+
+	return target
+}
@@ -0,0 +1,24 @@
+package pkg
+
+import (
+	"strings"
+)
+
+// MaskString hides all but the last visible characters, i.e "A12345678" => "******678"
+func MaskString(value string, visible int) string {
+	runes := []rune(value)
+	if len(runes) <= visible {
+		return strings.Repeat("*", len(runes))
+	}
+	return strings.Repeat("*", len(runes)-visible) + string(runes[len(runes)-visible:])
+}
+
+// MaskEmail keeps the first character and the domain, i.e "john@mail.com" => "j***@mail.com"
+func MaskEmail(email string) string {
+	local, domain, found := strings.Cut(email, "@")
+	if !found || local == "" {
+		return MaskString(email, 0)
+	}
+	runes := []rune(local)
+	return string(runes[:1]) + strings.Repeat("*", len(runes)-1) + "@" + domain
+}
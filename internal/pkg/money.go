@@ -0,0 +1,53 @@
+package pkg
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Symbols shown on prices, other currencies are shown by their ISO code
+var currencySymbols = map[string]string{
+	"NGN": "₦",
+	"GHS": "GH₵",
+	"KES": "KSh ",
+	"USD": "$",
+	"GBP": "£",
+	"EUR": "€",
+}
+
+// FormatMoney renders an amount in minor units for display, i.e 1250000 NGN => "₦12,500.00".
+// Whole amounts keep their decimals so prices line up on the pricing page.
+func FormatMoney(amount int64, currency string) string {
+	currency = strings.ToUpper(currency)
+	sign, value := formatMinor(amount)
+	if symbol, ok := currencySymbols[currency]; ok {
+		return sign + symbol + value
+	}
+	return sign + currency + " " + value
+}
+
+// FormatMoneyCode renders an amount with its ISO code, i.e 1250000 NGN => "NGN 12,500.00".
+// For documents whose fonts have no currency symbols, like PDF invoices.
+func FormatMoneyCode(amount int64, currency string) string {
+	sign, value := formatMinor(amount)
+	return sign + strings.ToUpper(currency) + " " + value
+}
+
+// Splits an amount in minor units into its sign and grouped major units, i.e -1250000 => "-", "12,500.00"
+func formatMinor(amount int64) (string, string) {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	major := strconv.FormatInt(amount/100, 10)
+	var grouped strings.Builder
+	for i, digit := range major {
+		if i > 0 && (len(major)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	return sign, grouped.String() + "." + strconv.FormatInt(100+amount%100, 10)[1:]
+}
@@ -0,0 +1,156 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 in points
+const (
+	PDFPageWidth  = 595.28
+	PDFPageHeight = 841.89
+)
+
+// PDFDocument lays out text and rules on A4 pages, enough for invoices and receipts (PDF 1.4).
+// It uses the standard Helvetica fonts so nothing is embedded, which limits text to WinAnsi (Latin-1):
+// other characters come out as "?".
+type PDFDocument struct {
+	Title string
+	pages []*bytes.Buffer
+}
+
+// Helvetica advance widths of the printable ASCII characters (32-126), per 1000 units of font size
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// WinAnsi codes of the characters outside Latin-1 that invoices commonly carry
+var winAnsiExtras = map[rune]byte{
+	'€': 0x80, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+}
+
+// NewPDFDocument starts a document with a first, empty page
+func NewPDFDocument(title string) *PDFDocument {
+	document := &PDFDocument{Title: title}
+	document.AddPage()
+	return document
+}
+
+// AddPage starts a new page, later drawing goes on it
+func (d *PDFDocument) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+// Text writes text with its baseline at (x, y), y measured from the top of the page
+func (d *PDFDocument) Text(x float64, y float64, size float64, bold bool, text string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.page(), "BT /%s %.2f Tf %.2f %.2f Td %s Tj ET\n", font, size, x, PDFPageHeight-y, pdfString(text))
+}
+
+// TextRight writes text ending at x, for amounts lined up in a column
+func (d *PDFDocument) TextRight(x float64, y float64, size float64, bold bool, text string) {
+	d.Text(x-PDFTextWidth(text, size), y, size, bold, text)
+}
+
+// Line draws a rule from (x1, y1) to (x2, y2), y measured from the top of the page
+func (d *PDFDocument) Line(x1 float64, y1 float64, x2 float64, y2 float64, width float64) {
+	fmt.Fprintf(d.page(), "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, PDFPageHeight-y1, x2, PDFPageHeight-y2)
+}
+
+// Bytes renders the document
+func (d *PDFDocument) Bytes() []byte {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1 catalog, 2 page tree, 3-4 fonts, 5 info, then a page and its content per page
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title %s /Producer (japa) >>", pdfString(d.Title)))
+	for i, page := range d.pages {
+		object(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			PDFPageWidth, PDFPageHeight, 7+2*i,
+		))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+// PDFTextWidth is how wide text is in Helvetica at size, in points.
+// Bold text is measured the same, a little narrow but close enough to line up columns.
+func PDFTextWidth(text string, size float64) float64 {
+	var width int
+	for _, char := range winAnsi(text) {
+		if char >= 32 && char <= 126 {
+			width += helveticaWidths[char-32]
+		} else {
+			width += 556
+		}
+	}
+	return float64(width) * size / 1000
+}
+
+func (d *PDFDocument) page() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// Encodes text as a PDF literal string, i.e "Total (NGN)" => "(Total \(NGN\))"
+func pdfString(text string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('(')
+	for _, char := range winAnsi(text) {
+		if char == '(' || char == ')' || char == '\\' {
+			buf.WriteByte('\\')
+		}
+		buf.WriteByte(char)
+	}
+	buf.WriteByte(')')
+	return buf.String()
+}
+
+// Converts text to WinAnsi bytes, control characters become spaces
+func winAnsi(text string) []byte {
+	encoded := make([]byte, 0, len(text))
+	for _, char := range text {
+		switch {
+		case char < 32:
+			encoded = append(encoded, ' ')
+		case char < 127 || (char >= 0xA0 && char <= 0xFF):
+			encoded = append(encoded, byte(char))
+		case winAnsiExtras[char] != 0:
+			encoded = append(encoded, winAnsiExtras[char])
+		default:
+			encoded = append(encoded, '?')
+		}
+	}
+	return encoded
+}
@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header carrying the webhook signature, i.e "t=1718000000,v1=5257a869e7..."
+const WebhookSignatureHeader = "X-Japa-Signature"
+
+var (
+	ErrInvalidSignatureHeader = errors.New("invalid webhook signature header")
+	ErrSignatureMismatch      = errors.New("webhook signature mismatch")
+	ErrSignatureExpired       = errors.New("webhook signature timestamp outside tolerance")
+)
+
+// SignWebhook returns the signature header for a payload.
+// The HMAC-SHA256 covers "<unix timestamp>.<payload>" so a captured body can't be replayed later.
+func SignWebhook(secret string, timestamp time.Time, payload []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + unix + ",v1=" + computeWebhookSignature(secret, unix, payload)
+}
+
+// VerifyWebhookSignature checks a signature header, receivers can use it as the reference implementation.
+// A zero tolerance skips the timestamp check.
+func VerifyWebhookSignature(secret string, header string, payload []byte, tolerance time.Duration, now time.Time) error {
+	var unix string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			return ErrInvalidSignatureHeader
+		}
+		switch key {
+		case "t":
+			unix = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if unix == "" || len(signatures) == 0 {
+		return ErrInvalidSignatureHeader
+	}
+
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil {
+		return ErrInvalidSignatureHeader
+	}
+	if tolerance > 0 {
+		if age := now.Sub(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+			return ErrSignatureExpired
+		}
+	}
+
+	expected := computeWebhookSignature(secret, unix, payload)
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrSignatureMismatch
+}
+
+func computeWebhookSignature(secret string, unix string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,57 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"japa/internal/infrastructure/mail"
+)
+
+func TestVisaApplicationTemplates(t *testing.T) {
+	tests := []struct {
+		name string
+		data *mailer.EmailData
+		want []string
+	}{
+		{
+			"status",
+			mailer.VisaApplicationStatusMail("Ada", "APP123", "Under review", "We are on it"),
+			[]string{"Ada", "APP123", "Under review", "We are on it"},
+		},
+		{
+			"documents requested",
+			mailer.VisaDocumentsRequestedMail("Ada", "APP123", []string{"Bank statement", "Passport photo"}, "Recent ones please"),
+			[]string{"APP123", "<li>Bank statement</li>", "<li>Passport photo</li>", "Recent ones please"},
+		},
+		{
+			"approved",
+			mailer.VisaApplicationDecisionMail("Ada", "APP123", "approved", "Safe travels"),
+			[]string{"APP123", "Safe travels"},
+		},
+		{
+			"rejected",
+			mailer.VisaApplicationDecisionMail("Ada", "APP123", "rejected", "Insufficient funds"),
+			[]string{"APP123", "Insufficient funds"},
+		},
+	}
+
+	for _, tt := range tests {
+		html, err := mailer.ParseEmailTemplate("../../templates", tt.data, nil)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(html, want) {
+				t.Errorf("%s: missing %q", tt.name, want)
+			}
+		}
+	}
+
+	if subject := mailer.VisaApplicationDecisionMail("Ada", "APP123", "approved", "").Subject; !strings.Contains(subject, "Approved") {
+		t.Errorf("approved subject: %q", subject)
+	}
+	if subject := mailer.VisaApplicationDecisionMail("Ada", "APP123", "rejected", "").Subject; !strings.Contains(subject, "Not Approved") {
+		t.Errorf("rejected subject: %q", subject)
+	}
+}
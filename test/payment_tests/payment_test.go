@@ -0,0 +1,644 @@
+package test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"japa/internal/config"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/usecase"
+	"japa/internal/infrastructure/payment"
+)
+
+func TestResponsiveProviderFallback(t *testing.T) {
+	outage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer outage.Close()
+
+	down := payment.NewPaystackProvider(outage.URL, "sk_test", 5*time.Second)
+	cedisOnly := payment.NewFakeProvider("https://example.com/checkout", true)
+	cedisOnly.Currencies = []string{"GHS"}
+
+	registry := &payment.ResponsivePaymentProvider{Providers: []payment.PaymentProvider{down, cedisOnly}}
+	ctx := context.Background()
+
+	transaction, err := registry.Initialize(ctx, payment.InitializeRequest{Reference: "JP-1", Amount: 5000, Currency: "GHS"})
+	if err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	if transaction.AuthorizationURL != "https://example.com/checkout?reference=JP-1" {
+		t.Errorf("AuthorizationURL = %s", transaction.AuthorizationURL)
+	}
+
+	verification, err := registry.Verify(ctx, transaction.Provider, "JP-1")
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if verification.Status != payment.StatusSucceeded || verification.Amount != 5000 {
+		t.Errorf("verification = %+v", verification)
+	}
+
+	if _, err := registry.Initialize(ctx, payment.InitializeRequest{Reference: "JP-2", Amount: 5000, Currency: "USD"}); err == nil {
+		t.Error("Initialize() with every provider down or unsupported succeeded")
+	}
+	if _, err := registry.Verify(ctx, "flutterwave", "JP-1"); !errors.Is(err, payment.ErrProviderNotFound) {
+		t.Errorf("error = %v, want %v", err, payment.ErrProviderNotFound)
+	}
+}
+
+func TestFakeProviderRefund(t *testing.T) {
+	fake := payment.NewFakeProvider("https://example.com/checkout", false)
+	ctx := context.Background()
+
+	if _, err := fake.Initialize(ctx, payment.InitializeRequest{Reference: "JP-1", Amount: 10000, Currency: "NGN"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fake.Refund(ctx, payment.RefundRequest{Reference: "JP-1"}); err == nil {
+		t.Error("refund of an unpaid transaction succeeded")
+	}
+
+	if err := fake.Complete("JP-1", true); err != nil {
+		t.Fatal(err)
+	}
+	refund, err := fake.Refund(ctx, payment.RefundRequest{Reference: "JP-1", Amount: 2500})
+	if err != nil {
+		t.Fatalf("Refund() error = %v", err)
+	}
+	if refund.Amount != 2500 || refund.Status != payment.RefundProcessed {
+		t.Errorf("refund = %+v", refund)
+	}
+
+	// Pending refunds settle later, a failed one gives its amount back
+	fake.PendingRefunds = true
+	rest, err := fake.Refund(ctx, payment.RefundRequest{Reference: "JP-1"})
+	if err != nil {
+		t.Fatalf("Refund() error = %v", err)
+	}
+	if rest.Amount != 7500 || rest.Status != payment.RefundPending {
+		t.Errorf("refund of what is left = %+v", rest)
+	}
+	if _, err := fake.Refund(ctx, payment.RefundRequest{Reference: "JP-1", Amount: 1}); err == nil {
+		t.Error("refund past the amount paid succeeded")
+	}
+	if err := fake.CompleteRefund(rest.ProviderRefundID, false); err != nil {
+		t.Fatal(err)
+	}
+	verified, err := fake.VerifyRefund(ctx, rest.ProviderRefundID)
+	if err != nil {
+		t.Fatalf("VerifyRefund() error = %v", err)
+	}
+	if verified.Status != payment.RefundFailed {
+		t.Errorf("verified refund = %+v", verified)
+	}
+	if retry, err := fake.Refund(ctx, payment.RefundRequest{Reference: "JP-1"}); err != nil || retry.Amount != 7500 {
+		t.Errorf("retry = %+v, error = %v", retry, err)
+	}
+}
+
+func TestFakeProviderLostRefund(t *testing.T) {
+	fake := payment.NewFakeProvider("https://example.com/checkout", false)
+	ctx := context.Background()
+
+	if _, err := fake.Initialize(ctx, payment.InitializeRequest{Reference: "JP-1", Amount: 10000, Currency: "NGN"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.Complete("JP-1", true); err != nil {
+		t.Fatal(err)
+	}
+
+	// No answer is not a rejection, the refund is found by the transaction's reference
+	fake.LoseRefunds = true
+	_, err := fake.Refund(ctx, payment.RefundRequest{Reference: "JP-1", Amount: 4000})
+	var providerErr *payment.ProviderError
+	if !errors.As(err, &providerErr) || providerErr.Rejected() {
+		t.Fatalf("lost refund error = %v, want an unanswered provider error", err)
+	}
+	refunds, err := fake.TransactionRefunds(ctx, "JP-1", "")
+	if err != nil {
+		t.Fatalf("TransactionRefunds() error = %v", err)
+	}
+	if len(refunds) != 1 || refunds[0].Amount != 4000 || refunds[0].Status != payment.RefundProcessed {
+		t.Errorf("refunds = %+v", refunds)
+	}
+
+	fake.LoseRefunds = false
+	if _, err := fake.Refund(ctx, payment.RefundRequest{Reference: "JP-1", Amount: 7000}); !errors.As(err, &providerErr) || !providerErr.Rejected() {
+		t.Errorf("refund past the amount paid error = %v, want a rejection", err)
+	}
+}
+
+func TestPaystackProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":false,"message":"Invalid key"}`))
+			return
+		}
+
+		switch r.URL.Path {
+		case "/transaction/initialize":
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["amount"] != float64(150000) || body["currency"] != "NGN" {
+				t.Errorf("unexpected initialize body %v", body)
+			}
+			w.Write([]byte(`{"status":true,"message":"ok","data":{"authorization_url":"https://checkout.paystack.com/abc","access_code":"abc","reference":"JP-1"}}`))
+		case "/transaction/verify/JP-1":
+			w.Write([]byte(`{"status":true,"message":"ok","data":{"id":42,"status":"success","reference":"JP-1","amount":150000,"currency":"NGN","fees":2250,"channel":"card","paid_at":"2025-01-02T10:00:00.000Z"}}`))
+		case "/transaction/verify/JP-2":
+			w.Write([]byte(`{"status":true,"message":"ok","data":{"id":43,"status":"abandoned","reference":"JP-2","amount":150000,"currency":"NGN"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"status":false,"message":"Transaction reference not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	paystack := payment.NewPaystackProvider(server.URL, "sk_test", 5*time.Second)
+	ctx := context.Background()
+
+	transaction, err := paystack.Initialize(ctx, payment.InitializeRequest{Reference: "JP-1", Email: "ada@mail.com", Amount: 150000, Currency: "ngn"})
+	if err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	if transaction.AuthorizationURL != "https://checkout.paystack.com/abc" {
+		t.Errorf("AuthorizationURL = %s", transaction.AuthorizationURL)
+	}
+
+	verification, err := paystack.Verify(ctx, "JP-1")
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if verification.Status != payment.StatusSucceeded || verification.Fee != 2250 || verification.ProviderReference != "42" || verification.PaidAt == nil {
+		t.Errorf("verification = %+v", verification)
+	}
+
+	if verification, err = paystack.Verify(ctx, "JP-2"); err != nil || verification.Status != payment.StatusFailed {
+		t.Errorf("abandoned: verification = %+v, error = %v", verification, err)
+	}
+
+	_, err = paystack.Verify(ctx, "JP-3")
+	var providerErr *payment.ProviderError
+	if !errors.As(err, &providerErr) || providerErr.StatusCode != http.StatusNotFound || providerErr.Message != "Transaction reference not found" {
+		t.Errorf("error = %v, want a 404 provider error", err)
+	}
+}
+
+func TestFlutterwaveProviderAmounts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/payments":
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["amount"] != 1500.5 || body["tx_ref"] != "JP-1" {
+				t.Errorf("unexpected payment body %v", body)
+			}
+			w.Write([]byte(`{"status":"success","message":"Hosted Link","data":{"link":"https://checkout.flutterwave.com/pay/abc"}}`))
+		case "/transactions/verify_by_reference":
+			w.Write([]byte(`{"status":"success","message":"ok","data":{"id":7,"tx_ref":"JP-1","status":"successful","amount":1500.5,"currency":"GBP","app_fee":21.01,"payment_type":"card","created_at":"2025-01-02T10:00:00.000Z"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	flutterwave := payment.NewFlutterwaveProvider(server.URL, "FLWSECK_TEST", 5*time.Second)
+	ctx := context.Background()
+
+	if _, err := flutterwave.Initialize(ctx, payment.InitializeRequest{Reference: "JP-1", Amount: 150050, Currency: "GBP"}); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	verification, err := flutterwave.Verify(ctx, "JP-1")
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if verification.Status != payment.StatusSucceeded || verification.Amount != 150050 || verification.Fee != 2101 {
+		t.Errorf("verification = %+v", verification)
+	}
+}
+
+func TestWebhookSignatures(t *testing.T) {
+	paystack := payment.NewPaystackProvider("https://api.paystack.co", "sk_test", 5*time.Second)
+	flutterwave := payment.NewFlutterwaveProvider("https://api.flutterwave.com/v3", "FLWSECK_TEST", 5*time.Second)
+	flutterwave.WebhookHash = "dashboard-hash"
+	registry := &payment.ResponsivePaymentProvider{Providers: []payment.PaymentProvider{paystack, flutterwave}}
+
+	headers := func(values map[string]string) func(string) string {
+		return func(key string) string { return values[key] }
+	}
+
+	body := []byte(`{"event":"charge.success","data":{"id":302961,"status":"success","reference":"JP-1","amount":150000}}`)
+	mac := hmac.New(sha512.New, []byte("sk_test"))
+	mac.Write(body)
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	event, err := registry.VerifyWebhook("paystack", headers(map[string]string{"x-paystack-signature": signature}), body)
+	if err != nil {
+		t.Fatalf("VerifyWebhook() error = %v", err)
+	}
+	if event.EventID != "charge.success:302961" || event.Reference != "JP-1" || event.Type != "charge.success" {
+		t.Errorf("event = %+v", event)
+	}
+
+	// Tampered body, missing and wrong signatures
+	tampered := []byte(`{"event":"charge.success","data":{"id":302961,"status":"success","reference":"JP-1","amount":1}}`)
+	if _, err := registry.VerifyWebhook("paystack", headers(map[string]string{"x-paystack-signature": signature}), tampered); !errors.Is(err, payment.ErrInvalidSignature) {
+		t.Errorf("tampered body error = %v, want %v", err, payment.ErrInvalidSignature)
+	}
+	if _, err := registry.VerifyWebhook("paystack", headers(nil), body); !errors.Is(err, payment.ErrInvalidSignature) {
+		t.Errorf("unsigned body error = %v, want %v", err, payment.ErrInvalidSignature)
+	}
+
+	body = []byte(`{"event":"charge.completed","data":{"id":285959875,"tx_ref":"JP-2","status":"successful"}}`)
+	event, err = registry.VerifyWebhook("flutterwave", headers(map[string]string{"verif-hash": "dashboard-hash"}), body)
+	if err != nil {
+		t.Fatalf("VerifyWebhook() error = %v", err)
+	}
+	if event.EventID != "charge.completed:285959875" || event.Reference != "JP-2" {
+		t.Errorf("event = %+v", event)
+	}
+	if _, err := registry.VerifyWebhook("flutterwave", headers(map[string]string{"verif-hash": "guess"}), body); !errors.Is(err, payment.ErrInvalidSignature) {
+		t.Errorf("wrong hash error = %v, want %v", err, payment.ErrInvalidSignature)
+	}
+
+	// Disputes are taken from the signed event
+	body = []byte(`{"event":"charge.dispute.resolve","data":{"id":358950,"refund_amount":150000,"currency":"NGN","status":"resolved","resolution":"merchant-accepted","category":"fraud","transaction":{"reference":"JP-1"}}}`)
+	mac = hmac.New(sha512.New, []byte("sk_test"))
+	mac.Write(body)
+	event, err = registry.VerifyWebhook("paystack", headers(map[string]string{"x-paystack-signature": hex.EncodeToString(mac.Sum(nil))}), body)
+	if err != nil {
+		t.Fatalf("VerifyWebhook() error = %v", err)
+	}
+	if event.Reference != "JP-1" || event.Dispute == nil {
+		t.Fatalf("event = %+v", event)
+	}
+	if event.Dispute.ProviderDisputeID != "358950" || event.Dispute.Status != payment.DisputeLost || event.Dispute.Amount != 150000 {
+		t.Errorf("dispute = %+v", event.Dispute)
+	}
+
+	// Providers without webhooks
+	fake := &payment.ResponsivePaymentProvider{Providers: []payment.PaymentProvider{payment.NewFakeProvider("https://example.com/checkout", true)}}
+	if _, err := fake.VerifyWebhook("fake", headers(nil), body); !errors.Is(err, payment.ErrWebhooksNotSupported) {
+		t.Errorf("error = %v, want %v", err, payment.ErrWebhooksNotSupported)
+	}
+}
+
+func TestPeriodEnd(t *testing.T) {
+	start := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"monthly":   time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC),
+		"quarterly": time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC),
+		"yearly":    time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC),
+	}
+	for cycle, want := range cases {
+		if got := usecase.PeriodEnd(start, cycle); !got.Equal(want) {
+			t.Errorf("PeriodEnd(%s) = %s, want %s", cycle, got, want)
+		}
+	}
+}
+
+func TestPeriodEndAfter(t *testing.T) {
+	anchor := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	want := []time.Time{
+		time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC),
+	}
+	start := anchor
+	for i := range want {
+		end := usecase.PeriodEndAfter(anchor, "monthly", start)
+		if !end.Equal(want[i]) {
+			t.Fatalf("period %d ends %s, want %s", i+1, end, want[i])
+		}
+		start = end
+	}
+
+	// Mid period
+	if got := usecase.PeriodEndAfter(anchor, "monthly", time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)); !got.Equal(want[1]) {
+		t.Errorf("PeriodEndAfter mid period = %s, want %s", got, want[1])
+	}
+}
+
+func TestProrate(t *testing.T) {
+	starter := &entity.Plan{ID: 1, Amount: 300000, Currency: "NGN", BillingCycle: "monthly",
+		PlanPrices: []entity.PlanPrice{{Currency: "USD", Amount: 1000}}}
+	pro := &entity.Plan{ID: 2, Amount: 600000, Currency: "NGN", BillingCycle: "monthly",
+		PlanPrices: []entity.PlanPrice{{Currency: "USD", Amount: 3000}}}
+	proYearly := &entity.Plan{ID: 3, Amount: 6000000, Currency: "NGN", BillingCycle: "yearly"}
+	start := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 4, 16, 0, 0, 0, 0, time.UTC) // Half the period left
+
+	// Same cycle: the difference for the time left, period unchanged
+	got := usecase.Prorate(starter, pro, "NGN", start, end, now, 0)
+	if got.Downgrade || got.Credit != 150000 || got.Charge != 300000 || got.Due != 150000 || !got.ExpiresAt.Equal(end) {
+		t.Errorf("upgrade = %+v", got)
+	}
+
+	// Credit owed pays first, what's left carries over
+	got = usecase.Prorate(starter, pro, "NGN", start, end, now, 200000)
+	if got.Due != 0 || got.Remainder != 50000 {
+		t.Errorf("upgrade with credit = %+v", got)
+	}
+
+	// Another cycle starts a new period now
+	got = usecase.Prorate(starter, proYearly, "NGN", start, end, now, 0)
+	if got.Due != 6000000-150000 || !got.ExpiresAt.Equal(time.Date(2026, 4, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("cycle upgrade = %+v", got)
+	}
+
+	// Cheaper plans wait for period end
+	got = usecase.Prorate(pro, starter, "NGN", start, end, now, 0)
+	if !got.Downgrade || got.Due != 0 {
+		t.Errorf("downgrade = %+v", got)
+	}
+
+	// Priced from the subscription's price book
+	got = usecase.Prorate(starter, pro, "USD", start, end, now, 0)
+	if got.Credit != 500 || got.Charge != 1500 || got.Due != 1000 {
+		t.Errorf("upgrade in USD = %+v", got)
+	}
+}
+
+func TestPriceBook(t *testing.T) {
+	cfg := config.BillingConfig{Currency: "NGN", Currencies: []string{"ngn", "ghs", "kes", "usd", "gbp"}, FallbackCurrency: "USD"}
+	cases := []struct {
+		requested, country, want string
+	}{
+		{"", "", "NGN"},   // Unknown visitor, base currency
+		{"", "GH", "GHS"}, // Country with its own book
+		{"", "gb", "GBP"},
+		{"", "FR", "USD"},    // EUR isn't sold, fallback
+		{"kes", "GH", "KES"}, // Asking beats the country
+		{"JPY", "KE", "KES"}, // Unsupported requests are ignored
+	}
+	for _, c := range cases {
+		if got := usecase.PriceBook(cfg, c.requested, c.country); got != c.want {
+			t.Errorf("PriceBook(%q, %q) = %s, want %s", c.requested, c.country, got, c.want)
+		}
+	}
+}
+
+func TestFakeProviderCharge(t *testing.T) {
+	fake := payment.NewFakeProvider("https://example.com/checkout", true)
+	registry := &payment.ResponsivePaymentProvider{Providers: []payment.PaymentProvider{fake}}
+	ctx := context.Background()
+
+	transaction, err := registry.Initialize(ctx, payment.InitializeRequest{Reference: "JP-1", Amount: 5000, Currency: "NGN"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := registry.Verify(ctx, transaction.Provider, "JP-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Authorization == nil || !first.Authorization.Reusable {
+		t.Fatalf("authorization = %+v, want a reusable card", first.Authorization)
+	}
+
+	renewal, err := registry.Charge(ctx, "fake", payment.ChargeRequest{Reference: "JP-2", AuthorizationCode: first.Authorization.Code, Amount: 5000, Currency: "NGN"})
+	if err != nil {
+		t.Fatalf("Charge() error = %v", err)
+	}
+	if renewal.Status != payment.StatusSucceeded || renewal.Amount != 5000 {
+		t.Errorf("renewal = %+v", renewal)
+	}
+
+	fake.DeclineCharges = true
+	declined, err := registry.Charge(ctx, "fake", payment.ChargeRequest{Reference: "JP-3", AuthorizationCode: first.Authorization.Code, Amount: 5000, Currency: "NGN"})
+	if err != nil {
+		t.Fatalf("Charge() error = %v", err)
+	}
+	if declined.Status != payment.StatusFailed {
+		t.Errorf("status = %s, want %s", declined.Status, payment.StatusFailed)
+	}
+}
+
+func TestFakeProviderChargeTimeout(t *testing.T) {
+	fake := payment.NewFakeProvider("https://example.com/checkout", true)
+	registry := &payment.ResponsivePaymentProvider{Providers: []payment.PaymentProvider{fake}}
+	ctx := context.Background()
+
+	// A timeout is not a decline, the charge may have gone through and is found by its reference
+	fake.TimeoutCharges = true
+	_, err := registry.Charge(ctx, "fake", payment.ChargeRequest{Reference: "JP-1", AuthorizationCode: "AUTH_1", Amount: 5000, Currency: "NGN"})
+	var providerErr *payment.ProviderError
+	if !errors.As(err, &providerErr) || providerErr.StatusCode != http.StatusRequestTimeout || providerErr.Rejected() {
+		t.Fatalf("timed out charge error = %v, want an unanswered provider error", err)
+	}
+	verification, err := registry.Verify(ctx, "fake", "JP-1")
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if verification.Status != payment.StatusSucceeded || verification.Amount != 5000 {
+		t.Errorf("verification = %+v, want the charge settled", verification)
+	}
+
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusInternalServerError, 0} {
+		if (&payment.ProviderError{StatusCode: status}).Rejected() {
+			t.Errorf("status %d counted as a rejection", status)
+		}
+	}
+
+	// Answered and refused, nothing was charged
+	fake.TimeoutCharges = false
+	if _, err := registry.Charge(ctx, "fake", payment.ChargeRequest{Reference: "JP-1", AuthorizationCode: "AUTH_1", Amount: 5000, Currency: "NGN"}); !errors.As(err, &providerErr) || !providerErr.Rejected() {
+		t.Errorf("duplicate charge error = %v, want a rejection", err)
+	}
+}
+
+func TestSubscriptionInGoodStanding(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Hour)
+	earlier := now.Add(-time.Hour)
+
+	cases := []struct {
+		name         string
+		subscription entity.Subscription
+		want         bool
+	}{
+		{"active in period", entity.Subscription{Status: entity.SubscriptionStatusActive, ExpiresAt: later}, true},
+		{"active past period", entity.Subscription{Status: entity.SubscriptionStatusActive, ExpiresAt: earlier}, false},
+		{"past due in grace", entity.Subscription{Status: entity.SubscriptionStatusPastDue, ExpiresAt: earlier, GraceEndsAt: &later}, true},
+		{"past due after grace", entity.Subscription{Status: entity.SubscriptionStatusPastDue, ExpiresAt: earlier, GraceEndsAt: &earlier}, false},
+		{"canceled", entity.Subscription{Status: entity.SubscriptionStatusCanceled, ExpiresAt: later}, false},
+	}
+	for _, c := range cases {
+		if got := c.subscription.InGoodStanding(now); got != c.want {
+			t.Errorf("%s: InGoodStanding() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestPeriodBounds(t *testing.T) {
+	now := time.Date(2025, 3, 13, 15, 4, 5, 0, time.UTC) // A Thursday
+	cases := map[string][2]time.Time{
+		entity.ResetPeriodDay:   {time.Date(2025, 3, 13, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)},
+		entity.ResetPeriodWeek:  {time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC)},
+		entity.ResetPeriodMonth: {time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)},
+		entity.ResetPeriodYear:  {time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for period, want := range cases {
+		start, end := entity.PeriodBounds(period, now)
+		if !start.Equal(want[0]) || !end.Equal(want[1]) {
+			t.Errorf("PeriodBounds(%s) = [%s, %s), want [%s, %s)", period, start, end, want[0], want[1])
+		}
+	}
+
+	start, end := entity.PeriodBounds(entity.ResetPeriodLifetime, now)
+	if !start.Equal(entity.LifetimePeriodStart) || !end.IsZero() {
+		t.Errorf("PeriodBounds(lifetime) = [%s, %s)", start, end)
+	}
+}
+
+func TestCouponDiscount(t *testing.T) {
+	cases := []struct {
+		name   string
+		coupon entity.Coupon
+		amount int64
+		want   int64
+	}{
+		{"percentage", entity.Coupon{Type: entity.CouponTypePercentage, PercentOff: 20}, 500000, 100000},
+		{"percentage rounds", entity.Coupon{Type: entity.CouponTypePercentage, PercentOff: 15}, 333, 50},
+		{"fixed", entity.Coupon{Type: entity.CouponTypeFixed, AmountOff: 150000}, 500000, 150000},
+		{"fixed capped", entity.Coupon{Type: entity.CouponTypeFixed, AmountOff: 900000}, 500000, 500000},
+	}
+	for _, c := range cases {
+		if got := c.coupon.Discount(c.amount); got != c.want {
+			t.Errorf("%s: Discount(%d) = %d, want %d", c.name, c.amount, got, c.want)
+		}
+	}
+
+	trialEndsAt := time.Now().Add(24 * time.Hour)
+	trial := entity.Subscription{ExpiresAt: trialEndsAt, TrialEndsAt: &trialEndsAt}
+	if !trial.InTrial() {
+		t.Error("InTrial() = false during the trial")
+	}
+	trial.ExpiresAt = trialEndsAt.AddDate(0, 1, 0)
+	if trial.InTrial() {
+		t.Error("InTrial() = true after conversion")
+	}
+}
+
+func TestLedgerRejectsUnbalanced(t *testing.T) {
+	ledger := &usecase.LedgerUsecase{}
+	ctx := context.Background()
+
+	unbalanced := &entity.LedgerTransaction{
+		ID: "01JBLEDGER", Kind: entity.LedgerKindPayment, SourceID: "pay_1", Currency: "NGN", OccurredAt: time.Now(),
+		Entries: []entity.LedgerEntry{
+			{Account: entity.LedgerProviderClearing + ":fake", Amount: 10000, Currency: "NGN"},
+			{Account: entity.LedgerPlatformRevenue, Amount: -9000, Currency: "NGN"},
+		},
+	}
+	if err := ledger.Post(ctx, nil, unbalanced); !errors.Is(err, usecase.ErrLedgerUnbalanced) {
+		t.Errorf("unbalanced error = %v, want %v", err, usecase.ErrLedgerUnbalanced)
+	}
+
+	mixed := &entity.LedgerTransaction{
+		ID: "01JBLEDGER", Kind: entity.LedgerKindPayment, SourceID: "pay_1", Currency: "NGN", OccurredAt: time.Now(),
+		Entries: []entity.LedgerEntry{
+			{Account: entity.LedgerProviderClearing + ":fake", Amount: 10000, Currency: "NGN"},
+			{Account: entity.LedgerPlatformRevenue, Amount: -10000, Currency: "USD"},
+		},
+	}
+	if err := ledger.Post(ctx, nil, mixed); !errors.Is(err, usecase.ErrLedgerUnbalanced) {
+		t.Errorf("mixed currency error = %v, want %v", err, usecase.ErrLedgerUnbalanced)
+	}
+
+	// Failed payments move no money
+	failed := &entity.Payment{ID: "pay_2", Status: entity.PaymentStatusFailed, Amount: 10000, Currency: "NGN"}
+	if _, err := ledger.HandleSettlement(ctx, nil, failed, nil); err != nil {
+		t.Errorf("HandleSettlement() of a failed payment error = %v", err)
+	}
+}
+
+func TestFakeProviderSettledTransactions(t *testing.T) {
+	fake := payment.NewFakeProvider("https://example.com/checkout", false)
+	registry := &payment.ResponsivePaymentProvider{Providers: []payment.PaymentProvider{fake}}
+	ctx := context.Background()
+
+	for _, reference := range []string{"JP-1", "JP-2", "JP-3"} {
+		if _, err := fake.Initialize(ctx, payment.InitializeRequest{Reference: reference, Amount: 10000, Currency: "NGN"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fake.Complete("JP-1", true)
+	fake.Complete("JP-2", false)
+
+	now := time.Now()
+	settled, err := registry.SettledTransactions(ctx, "fake", now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("SettledTransactions() error = %v", err)
+	}
+	if len(settled) != 1 || settled[0].Reference != "JP-1" {
+		t.Errorf("settled = %+v, want only JP-1", settled)
+	}
+
+	settled, _ = registry.SettledTransactions(ctx, "fake", now.Add(time.Hour), now.Add(2*time.Hour))
+	if len(settled) != 0 {
+		t.Errorf("settled outside the window = %+v", settled)
+	}
+	if names := registry.Reporters(); len(names) != 1 || names[0] != "fake" {
+		t.Errorf("Reporters() = %v", names)
+	}
+}
+
+func TestFakeProviderTransfers(t *testing.T) {
+	fake := payment.NewFakeProvider("https://example.com/checkout", false)
+	fake.Currencies = []string{"NGN"}
+	registry := &payment.ResponsivePaymentProvider{Providers: []payment.PaymentProvider{fake}}
+	ctx := context.Background()
+
+	if _, err := registry.TransfersFor("USD"); !errors.Is(err, payment.ErrTransfersNotSupported) {
+		t.Errorf("TransfersFor(USD) error = %v, want %v", err, payment.ErrTransfersNotSupported)
+	}
+	transfers, err := registry.TransfersFor("NGN")
+	if err != nil {
+		t.Fatalf("TransfersFor(NGN) error = %v", err)
+	}
+
+	var providerErr *payment.ProviderError
+	if _, err := transfers.ResolveAccount(ctx, "058", "12345"); !errors.As(err, &providerErr) || providerErr.StatusCode != 422 {
+		t.Errorf("ResolveAccount() of a short number error = %v", err)
+	}
+	account, err := transfers.ResolveAccount(ctx, "058", "0123456789")
+	if err != nil || account.AccountName != "FAKE ACCOUNT 6789" {
+		t.Fatalf("ResolveAccount() = %+v, %v", account, err)
+	}
+	recipient, err := transfers.CreateRecipient(ctx, *account)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake.PendingTransfers = true
+	result, err := transfers.Transfer(ctx, payment.TransferRequest{Reference: "po_1", RecipientCode: recipient, Amount: 500000, Currency: "NGN"})
+	if err != nil || result.Status != payment.TransferPending {
+		t.Fatalf("Transfer() = %+v, %v", result, err)
+	}
+	if _, err := transfers.Transfer(ctx, payment.TransferRequest{Reference: "po_1", RecipientCode: recipient, Amount: 500000, Currency: "NGN"}); err == nil {
+		t.Error("Transfer() accepted a duplicate reference")
+	}
+
+	if err := fake.CompleteTransfer("po_1", false); err != nil {
+		t.Fatal(err)
+	}
+	result, err = transfers.VerifyTransfer(ctx, "po_1")
+	if err != nil || result.Status != payment.TransferFailed || result.Message == "" {
+		t.Errorf("VerifyTransfer() = %+v, %v", result, err)
+	}
+	if _, err := transfers.VerifyTransfer(ctx, "po_2"); !errors.As(err, &providerErr) || providerErr.StatusCode != 404 {
+		t.Errorf("VerifyTransfer() of an unknown reference error = %v", err)
+	}
+}
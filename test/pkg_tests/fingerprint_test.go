@@ -0,0 +1,40 @@
+package test
+
+import (
+	"testing"
+
+	"japa/internal/pkg"
+)
+
+func TestFingerprintNormalization(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"passport separators", pkg.NormalizePassport(" a12 345-678 "), "A12345678"},
+		{"email case and tag", pkg.NormalizeEmail(" Ada+visa2@Mail.COM "), "ada@mail.com"},
+		{"email without at", pkg.NormalizeEmail("Not-An-Email"), "not-an-email"},
+		{"phone international", pkg.NormalizePhone("+234 801 234 5678"), "8012345678"},
+		{"phone local", pkg.NormalizePhone("0801-234-5678"), "8012345678"},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestKeyedHash(t *testing.T) {
+	hash := pkg.KeyedHash("key", "passport:A12345678")
+	if len(hash) != 64 {
+		t.Fatalf("len(hash) = %d, want 64", len(hash))
+	}
+	if hash != pkg.KeyedHash("key", "passport:A12345678") {
+		t.Error("hash is not deterministic")
+	}
+	if hash == pkg.KeyedHash("other-key", "passport:A12345678") {
+		t.Error("hash does not depend on the key")
+	}
+}
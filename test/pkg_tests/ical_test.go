@@ -0,0 +1,52 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"japa/internal/pkg"
+)
+
+func TestBuildICS(t *testing.T) {
+	start := time.Date(2026, 5, 4, 10, 30, 0, 0, time.FixedZone("WAT", 3600))
+	event := pkg.ICSEvent{
+		UID:         "01HX@japa.test",
+		Sequence:    2,
+		Summary:     "Biometrics; Lagos, VFS",
+		Description: "Bring your passport\nand photos",
+		Location:    strings.Repeat("Victoria Island ", 6),
+		Start:       start,
+		End:         start.Add(time.Hour),
+	}
+
+	ics := string(pkg.BuildICS("Japa", event))
+	for _, want := range []string{
+		"METHOD:PUBLISH\r\n",
+		"SEQUENCE:2\r\n",
+		"DTSTART:20260504T093000Z\r\n",
+		"DTEND:20260504T103000Z\r\n",
+		`SUMMARY:Biometrics\; Lagos\, VFS` + "\r\n",
+		`DESCRIPTION:Bring your passport\nand photos` + "\r\n",
+		"STATUS:CONFIRMED\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("missing %q in:\n%s", want, ics)
+		}
+	}
+	for _, line := range strings.Split(ics, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 octets: %q", line)
+		}
+	}
+	// Folded lines continue after CRLF and a space
+	if !strings.Contains(strings.ReplaceAll(ics, "\r\n ", ""), "LOCATION:"+event.Location+"\r\n") {
+		t.Errorf("long location was not folded:\n%s", ics)
+	}
+
+	event.Cancelled = true
+	ics = string(pkg.BuildICS("Japa", event))
+	if !strings.Contains(ics, "METHOD:CANCEL\r\n") || !strings.Contains(ics, "STATUS:CANCELLED\r\n") {
+		t.Errorf("cancelled event:\n%s", ics)
+	}
+}
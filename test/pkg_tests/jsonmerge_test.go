@@ -0,0 +1,50 @@
+package test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"japa/internal/pkg"
+)
+
+func assertJSONEqual(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var gotValue, wantValue any
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatalf("invalid json result: %v", err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("invalid expected json: %v", err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestMergePatch_NestedMergeAndDelete(t *testing.T) {
+	original := []byte(`{"destination":"Germany","personal_info":{"nationality":"Nigerian","marital_status":"Single"},"purpose":"Study"}`)
+	patch := []byte(`{"personal_info":{"marital_status":"Married"},"purpose":null,"visa_type":"Student"}`)
+
+	merged, err := pkg.MergePatch(original, patch)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+
+	assertJSONEqual(t, merged, `{"destination":"Germany","personal_info":{"nationality":"Nigerian","marital_status":"Married"},"visa_type":"Student"}`)
+}
+
+func TestMergePatch_EmptyOriginal(t *testing.T) {
+	merged, err := pkg.MergePatch(nil, []byte(`{"destination":"Canada"}`))
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+
+	assertJSONEqual(t, merged, `{"destination":"Canada"}`)
+}
+
+func TestMergePatch_RejectsNonObjectPatch(t *testing.T) {
+	if _, err := pkg.MergePatch([]byte(`{}`), []byte(`["destination"]`)); err == nil {
+		t.Errorf("expected error for array patch, got nil")
+	}
+}
@@ -0,0 +1,45 @@
+package test
+
+import (
+	"testing"
+
+	"japa/internal/pkg"
+)
+
+func TestMaskString(t *testing.T) {
+	cases := []struct {
+		value   string
+		visible int
+		want    string
+	}{
+		{"A12345678", 3, "******678"},
+		{"+2348012345678", 4, "**********5678"},
+		{"12 Allen Avenue", 0, "***************"},
+		{"AB", 3, "**"},
+		{"Ọ̀ṣun", 1, "****n"},
+		{"", 2, ""},
+	}
+	for _, c := range cases {
+		if got := pkg.MaskString(c.value, c.visible); got != c.want {
+			t.Errorf("MaskString(%q, %d) = %q, want %q", c.value, c.visible, got, c.want)
+		}
+	}
+}
+
+func TestMaskEmail(t *testing.T) {
+	cases := []struct {
+		email string
+		want  string
+	}{
+		{"john@mail.com", "j***@mail.com"},
+		{"a@mail.com", "a@mail.com"},
+		{"ọla@mail.com", "ọ**@mail.com"},
+		{"@mail.com", "*********"},
+		{"not-an-email", "************"},
+	}
+	for _, c := range cases {
+		if got := pkg.MaskEmail(c.email); got != c.want {
+			t.Errorf("MaskEmail(%q) = %q, want %q", c.email, got, c.want)
+		}
+	}
+}
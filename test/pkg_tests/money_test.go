@@ -0,0 +1,36 @@
+package test
+
+import (
+	"testing"
+
+	"japa/internal/pkg"
+)
+
+func TestFormatMoney(t *testing.T) {
+	cases := []struct {
+		amount   int64
+		currency string
+		want     string
+	}{
+		{1250000, "NGN", "₦12,500.00"},
+		{999, "usd", "$9.99"},
+		{100000005, "GBP", "£1,000,000.05"},
+		{5, "KES", "KSh 0.05"},
+		{-2500, "GHS", "-GH₵25.00"},
+		{12345, "ZAR", "ZAR 123.45"},
+	}
+	for _, c := range cases {
+		if got := pkg.FormatMoney(c.amount, c.currency); got != c.want {
+			t.Errorf("FormatMoney(%d, %s) = %q, want %q", c.amount, c.currency, got, c.want)
+		}
+	}
+}
+
+func TestFormatMoneyCode(t *testing.T) {
+	if got := pkg.FormatMoneyCode(1250000, "ngn"); got != "NGN 12,500.00" {
+		t.Errorf("FormatMoneyCode = %q, want %q", got, "NGN 12,500.00")
+	}
+	if got := pkg.FormatMoneyCode(-2500, "GHS"); got != "-GHS 25.00" {
+		t.Errorf("FormatMoneyCode = %q, want %q", got, "-GHS 25.00")
+	}
+}
@@ -0,0 +1,59 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"japa/internal/pkg"
+)
+
+func TestPDFDocument(t *testing.T) {
+	document := pkg.NewPDFDocument("Invoice INV-2026-000001")
+	document.Text(50, 60, 18, true, "Invoice (INV-2026-000001)")
+	document.TextRight(545, 60, 10, false, "Café – ₦")
+	document.Line(50, 70, 545, 70, 0.5)
+	document.AddPage()
+	document.Text(50, 60, 10, false, `C:\path`)
+	data := document.Bytes()
+
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatalf("not a PDF file: %q...", data[:min(len(data), 20)])
+	}
+	for _, want := range []string{
+		`(Invoice \(INV-2026-000001\)) Tj`,
+		"(Caf\xe9 \x96 ?) Tj", // WinAnsi, no naira sign in the standard fonts
+		`(C:\\path) Tj`,
+		"/Count 2",
+	} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("PDF is missing %q", want)
+		}
+	}
+
+	// The cross-reference table points at each object
+	match := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	if match == nil {
+		t.Fatal("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(match[1]))
+	if !bytes.HasPrefix(data[xref:], []byte("xref\n0 10\n")) {
+		t.Fatalf("startxref %d does not point at a 10 entry xref table", xref)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(data[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i+1, data[offset:offset+10])
+		}
+	}
+}
+
+func TestPDFTextWidth(t *testing.T) {
+	// Digits are 556 units wide, the comma and period 278
+	if got := pkg.PDFTextWidth("1,000.00", 10); got != 38.92 {
+		t.Errorf("PDFTextWidth = %v, want 38.92", got)
+	}
+}
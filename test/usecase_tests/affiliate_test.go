@@ -0,0 +1,56 @@
+package test
+
+import (
+	"testing"
+
+	"japa/internal/domain/entity"
+	"japa/internal/domain/usecase"
+)
+
+func TestCommissionAmount(t *testing.T) {
+	ngn := "NGN"
+	percentage := &entity.CommissionRule{Type: entity.CouponTypePercentage, Percent: 15}
+	fixed := &entity.CommissionRule{Type: entity.CouponTypeFixed, Amount: 200000, Currency: &ngn}
+
+	cases := []struct {
+		name     string
+		rule     *entity.CommissionRule
+		base     int64
+		items    int64
+		currency string
+		want     int64
+	}{
+		{"percentage", percentage, 1250000, 1, "NGN", 187500},
+		{"percentage rounds", percentage, 999, 1, "USD", 150},
+		{"fixed per item", fixed, 3000000, 3, "ngn", 600000},
+		{"fixed capped at base", fixed, 300000, 2, "NGN", 300000},
+		{"fixed other currency", fixed, 3000000, 1, "USD", 0},
+	}
+	for _, c := range cases {
+		if got := usecase.CommissionAmount(c.rule, c.base, c.items, c.currency); got != c.want {
+			t.Errorf("%s: got %d, want %d", c.name, got, c.want)
+		}
+	}
+}
+
+func TestReversalAmount(t *testing.T) {
+	cases := []struct {
+		name     string
+		earned   int64
+		reversed int64
+		refunded int64
+		paid     int64
+		want     int64
+	}{
+		{"half refund", 150000, 0, 500000, 1000000, 75000},
+		{"rounds", 1000, 0, 1, 3, 333},
+		{"capped at what's left", 150000, 100000, 500000, 1000000, 50000},
+		{"full refund takes the rest", 150000, 75000, 1000000, 1000000, 75000},
+		{"already reversed", 150000, 150000, 300000, 1000000, 0},
+	}
+	for _, c := range cases {
+		if got := usecase.ReversalAmount(c.earned, c.reversed, c.refunded, c.paid); got != c.want {
+			t.Errorf("%s: got %d, want %d", c.name, got, c.want)
+		}
+	}
+}
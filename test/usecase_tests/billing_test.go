@@ -0,0 +1,151 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"japa/internal/config"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/repository"
+	"japa/internal/domain/usecase"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestPriceQuote(t *testing.T) {
+	price := &entity.ApplicationPrice{Destination: "Canada", VisaType: "Student", Currency: "NGN", Amount: 10000}
+	addOns := []entity.AddOn{
+		{Code: "courier", Name: "Passport courier", Amount: 2000, PerApplicant: true},
+		{Code: "review", Name: "Document review", Amount: 5000},
+	}
+	ids := []string{"principal", "dependent"}
+
+	quote, err := usecase.PriceQuote("Visa application: Canada (Student)", price, ids, []string{"courier", "review"}, addOns)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if quote.Currency != "NGN" || quote.Subtotal != 29000 || quote.Total != 29000 {
+		t.Errorf("got %s %d/%d, want NGN 29000/29000", quote.Currency, quote.Subtotal, quote.Total)
+	}
+	if len(quote.Lines) != 3 || quote.Lines[0].Amount != 20000 || quote.Lines[1].Quantity != 2 || quote.Lines[2].Quantity != 1 {
+		t.Errorf("unexpected lines: %+v", quote.Lines)
+	}
+	if quote.Share("principal") != 17000 || quote.Share("dependent") != 12000 {
+		t.Errorf("got shares %d/%d, want 17000/12000", quote.Share("principal"), quote.Share("dependent"))
+	}
+
+	if _, err := usecase.PriceQuote("", price, ids, []string{"express"}, addOns); !errors.Is(err, usecase.ErrUnknownAddOn) {
+		t.Errorf("unknown add-on: got %v, want ErrUnknownAddOn", err)
+	}
+}
+
+func TestQuoteApplyDiscount(t *testing.T) {
+	price := &entity.ApplicationPrice{Currency: "NGN", Amount: 10000}
+	addOns := []entity.AddOn{{Code: "review", Name: "Document review", Amount: 5000}}
+	ids := []string{"a", "b", "c"}
+
+	tests := []struct {
+		name     string
+		discount int64
+		shares   []int64
+	}{
+		{"none", 0, []int64{15000, 10000, 10000}},
+		{"proportional", 3500, []int64{13500, 9000, 9000}},
+		{"last absorbs rounding", 1000, []int64{14572, 9715, 9713}},
+		{"whole total", 35000, []int64{0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		quote, err := usecase.PriceQuote("Visa application", price, ids, []string{"review"}, addOns)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if target := quote.CouponTarget(); target.Amount != 35000 || target.Currency != "NGN" {
+			t.Errorf("%s: coupon target %+v", tt.name, target)
+		}
+
+		quote.ApplyDiscount("SAVE", tt.discount)
+		if quote.Coupon != "SAVE" || quote.Discount != tt.discount || quote.Total != 35000-tt.discount {
+			t.Errorf("%s: got coupon %q discount %d total %d", tt.name, quote.Coupon, quote.Discount, quote.Total)
+		}
+		sum := int64(0)
+		for i, id := range ids {
+			sum += quote.Share(id)
+			if quote.Share(id) != tt.shares[i] {
+				t.Errorf("%s: share of %s is %d, want %d", tt.name, id, quote.Share(id), tt.shares[i])
+			}
+		}
+		if sum != quote.Total {
+			t.Errorf("%s: shares add up to %d, want %d", tt.name, sum, quote.Total)
+		}
+	}
+}
+
+func TestRequirePaid(t *testing.T) {
+	// Dry run statements aren't sent, every lookup finds nothing: no purchases and no plan
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "user:pass@tcp(127.0.0.1:3306)/japa", SkipInitializeWithVersion: true}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	entitlements := usecase.NewEntitlementUsecase(config.SubscriptionConfig{}, repository.NewEntitlementRepository(db), db)
+	billing := &usecase.BillingUsecase{Repo: repository.NewBillingRepository(db), Entitlements: entitlements, DB: db}
+	ctx := context.Background()
+
+	if err := billing.RequirePaid(ctx, db, "user", []string{"a"}); err != nil {
+		t.Errorf("payment not required: got %v, want nil", err)
+	}
+
+	billing.Config.RequirePayment = true
+	if err := billing.RequirePaid(ctx, db, "user", []string{"a", "b"}); !errors.Is(err, usecase.ErrPaymentRequired) {
+		t.Errorf("unpaid without a plan: got %v, want ErrPaymentRequired", err)
+	}
+	if err := billing.RequirePaid(ctx, db, "user", nil); err != nil {
+		t.Errorf("nothing to pay for: got %v, want nil", err)
+	}
+	if err := billing.RequireQuota(ctx, db, "user", 0); err != nil {
+		t.Errorf("no applications: got %v, want nil", err)
+	}
+}
+
+func TestRequireGroupSize(t *testing.T) {
+	// Dry run statements aren't sent, every lookup finds nothing: no plan
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "user:pass@tcp(127.0.0.1:3306)/japa", SkipInitializeWithVersion: true}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	entitlements := usecase.NewEntitlementUsecase(config.SubscriptionConfig{}, repository.NewEntitlementRepository(db), db)
+	billing := &usecase.BillingUsecase{Entitlements: entitlements, DB: db}
+	ctx := context.Background()
+
+	if err := billing.RequireGroupSize(ctx, db, "user", 5); err != nil {
+		t.Errorf("payment not required: got %v, want nil", err)
+	}
+	billing.Config.RequirePayment = true
+	if err := billing.RequireGroupSize(ctx, db, "user", 2); !errors.Is(err, usecase.ErrNotEntitled) {
+		t.Errorf("without a plan: got %v, want ErrNotEntitled", err)
+	}
+
+	four := int64(4)
+	zero := int64(0)
+	tests := []struct {
+		name  string
+		items map[string]entity.PlanEntitlement
+		size  int64
+		want  error
+	}{
+		{"within the limit", map[string]entity.PlanEntitlement{entity.EntitlementGroupSize: {Type: entity.EntitlementTypeLimit, Enabled: true, Limit: &four}}, 4, nil},
+		{"over the limit", map[string]entity.PlanEntitlement{entity.EntitlementGroupSize: {Type: entity.EntitlementTypeLimit, Enabled: true, Limit: &four}}, 5, usecase.ErrLimitExceeded},
+		{"unlimited", map[string]entity.PlanEntitlement{entity.EntitlementGroupSize: {Type: entity.EntitlementTypeLimit, Enabled: true}}, 50, nil},
+		{"capped at zero", map[string]entity.PlanEntitlement{entity.EntitlementGroupSize: {Type: entity.EntitlementTypeLimit, Enabled: true, Limit: &zero}}, 1, usecase.ErrNotEntitled},
+		{"disabled", map[string]entity.PlanEntitlement{entity.EntitlementGroupSize: {Type: entity.EntitlementTypeLimit, Limit: &four}}, 1, usecase.ErrNotEntitled},
+		{"not in the plan", map[string]entity.PlanEntitlement{}, 1, usecase.ErrNotEntitled},
+	}
+	for _, tt := range tests {
+		granted := &usecase.Entitlements{Items: tt.items}
+		if err := granted.Within(entity.EntitlementGroupSize, tt.size); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
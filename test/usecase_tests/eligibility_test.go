@@ -0,0 +1,59 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"japa/internal/domain/entity"
+	"japa/internal/domain/usecase"
+)
+
+func TestEvaluateRules(t *testing.T) {
+	usd := "USD"
+	rules := []entity.EligibilityRule{
+		{ID: 1, RuleType: entity.RuleNationalityAllowed, Value: "NG, GH", Weight: 10, Blocking: true, Message: "nationality"},
+		{ID: 2, RuleType: entity.RulePassportValidity, Value: "6", Weight: 20, Blocking: true, Message: "passport"},
+		{ID: 3, RuleType: entity.RuleMinFunds, Value: "5000", Currency: &usd, Weight: 30, Message: "funds"},
+		{ID: 4, RuleType: entity.RuleMinEducation, Value: "bachelors", Weight: 40, Message: "education"},
+		{ID: 5, RuleType: entity.RuleNoPriorDenial, Weight: 10, Message: "denial"},
+	}
+	travel := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	funds := 6000.0
+	profile := usecase.EligibilityProfile{
+		Nationality:    "ng",
+		PassportExpiry: travel.AddDate(0, 3, 0),
+		TravelDate:     travel,
+		Funds:          &funds,
+		FundsCurrency:  "usd",
+		EducationLevel: "Masters",
+	}
+
+	result := usecase.EvaluateRules(rules, profile)
+	if result.Eligible || result.Score != 80 || result.Evaluated != 4 || result.Passed != 3 {
+		t.Errorf("got eligible %t score %d evaluated %d passed %d, want false 80 4 3", result.Eligible, result.Score, result.Evaluated, result.Passed)
+	}
+	if len(result.FailedRules) != 1 || result.FailedRules[0].RuleID != 2 {
+		t.Errorf("failed rules: %+v", result.FailedRules)
+	}
+	if len(result.SkippedRules) != 1 || result.SkippedRules[0].RuleID != 5 {
+		t.Errorf("skipped rules: %+v", result.SkippedRules)
+	}
+
+	err := &usecase.EligibilityError{Result: result}
+	if !errors.Is(err, usecase.ErrNotEligible) || err.Error() != usecase.ErrNotEligible.Error()+": passport" {
+		t.Errorf("got %q", err.Error())
+	}
+
+	// Funds in another currency can't be compared, the rule is skipped
+	profile.FundsCurrency = "EUR"
+	profile.PassportExpiry = travel.AddDate(1, 0, 0)
+	result = usecase.EvaluateRules(rules, profile)
+	if !result.Eligible || result.Score != 100 || len(result.SkippedRules) != 2 {
+		t.Errorf("got eligible %t score %d skipped %+v", result.Eligible, result.Score, result.SkippedRules)
+	}
+
+	if result := usecase.EvaluateRules(nil, profile); !result.Eligible || result.Score != 100 {
+		t.Errorf("no rules: got eligible %t score %d", result.Eligible, result.Score)
+	}
+}
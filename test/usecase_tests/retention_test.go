@@ -0,0 +1,78 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"japa/internal/domain/usecase"
+	"japa/internal/infrastructure/storage"
+)
+
+func TestRedactFormInput(t *testing.T) {
+	submitted := []byte(`{"Destination":"Germany","VisaType":"Student","TravelDate":"2025-09-01T00:00:00Z","Purpose":"Study",` +
+		`"PersonalInfo":{"PassportNumber":"A12345678"},"EmergencyContact":{"EmergencyPhone":"+2348012345678"}}`)
+	draft := []byte(`{"destination":"Canada","visa_type":"Work","personal_info":{"passport_number":"B7654321"}}`)
+
+	tests := []struct {
+		name  string
+		input []byte
+		want  map[string]any
+	}{
+		{"submitted form", submitted, map[string]any{"Destination": "Germany", "VisaType": "Student", "TravelDate": "2025-09-01T00:00:00Z", "Purpose": "Study"}},
+		{"draft payload", draft, map[string]any{"destination": "Canada", "visa_type": "Work"}},
+		{"unreadable", []byte(`{"Destination":`), map[string]any{}},
+	}
+
+	for _, tt := range tests {
+		var got map[string]any
+		if err := json.Unmarshal(usecase.RedactFormInput(tt.input), &got); err != nil {
+			t.Fatalf("%s: invalid json: %v", tt.name, err)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for key, value := range tt.want {
+			if got[key] != value {
+				t.Errorf("%s: %s = %v, want %v", tt.name, key, got[key], value)
+			}
+		}
+	}
+
+	if got := string(usecase.RedactFormInput([]byte("null"))); got != "null" {
+		t.Errorf("null form: got %s, want null", got)
+	}
+}
+
+func TestLocalStoreDelete(t *testing.T) {
+	root := t.TempDir()
+	store := storage.NewLocalStore(root)
+	ctx := context.Background()
+
+	path := filepath.Join(root, "passport.jpg")
+	if err := os.WriteFile(path, []byte("scan"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Delete(ctx, "passport.jpg"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("file still exists, stat error = %v", err)
+	}
+
+	// Already gone counts as deleted
+	if err := store.Delete(ctx, "passport.jpg"); err != nil {
+		t.Errorf("Delete() of missing file error = %v", err)
+	}
+	if err := store.Delete(ctx, "../outside.jpg"); !errors.Is(err, storage.ErrOutsideRoot) {
+		t.Errorf("error = %v, want %v", err, storage.ErrOutsideRoot)
+	}
+	if err := store.Delete(ctx, "https://cdn.example.com/passport.jpg"); !errors.Is(err, storage.ErrRemoteFile) {
+		t.Errorf("error = %v, want %v", err, storage.ErrRemoteFile)
+	}
+}
@@ -0,0 +1,62 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"japa/internal/domain/entity"
+	"japa/internal/domain/usecase"
+)
+
+func TestEvaluateSLA(t *testing.T) {
+	entered := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	target := 48 * time.Hour
+
+	tests := []struct {
+		name   string
+		target time.Duration
+		now    time.Time
+		want   string
+	}{
+		{"no target", 0, entered.Add(100 * time.Hour), usecase.SLAStateNone},
+		{"within target", target, entered.Add(24 * time.Hour), usecase.SLAStateOK},
+		{"past warning", target, entered.Add(40 * time.Hour), usecase.SLAStateAtRisk},
+		{"at target", target, entered.Add(target), usecase.SLAStateBreached},
+	}
+
+	for _, tt := range tests {
+		got := usecase.EvaluateSLA(entered, tt.target, 80, tt.now)
+		if got.State != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got.State, tt.want)
+		}
+		if got.TimeInStatus != tt.now.Sub(entered) {
+			t.Errorf("%s: time in status %s", tt.name, got.TimeInStatus)
+		}
+		if (got.DueAt == nil) != (tt.target == 0) || (got.DueAt != nil && !got.DueAt.Equal(entered.Add(tt.target))) {
+			t.Errorf("%s: due at %v", tt.name, got.DueAt)
+		}
+	}
+}
+
+func TestFindSLATarget(t *testing.T) {
+	premium := uint(3)
+	other := uint(4)
+	targets := []entity.SLATarget{
+		{Status: "pending", TargetHours: 72},
+		{Status: "pending", PlanID: &premium, TargetHours: 24},
+		{Status: "under_review", TargetHours: 120},
+	}
+
+	if got := usecase.FindSLATarget(targets, &premium, "pending"); got != 24*time.Hour {
+		t.Errorf("plan target: got %s, want 24h", got)
+	}
+	if got := usecase.FindSLATarget(targets, &other, "pending"); got != 72*time.Hour {
+		t.Errorf("other plan: got %s, want 72h", got)
+	}
+	if got := usecase.FindSLATarget(targets, nil, "under_review"); got != 120*time.Hour {
+		t.Errorf("no plan: got %s, want 120h", got)
+	}
+	if got := usecase.FindSLATarget(targets, nil, "approved"); got != 0 {
+		t.Errorf("no target: got %s, want 0", got)
+	}
+}
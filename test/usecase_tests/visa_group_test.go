@@ -0,0 +1,150 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"japa/internal/app/http/dto/request"
+	"japa/internal/domain/entity"
+	"japa/internal/domain/usecase"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func members(statuses ...string) []entity.VisaApplication {
+	applications := make([]entity.VisaApplication, len(statuses))
+	for i := range statuses {
+		if statuses[i] != "" {
+			applications[i].Status = &statuses[i]
+		}
+	}
+	return applications
+}
+
+func TestRollUpGroupStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		members []entity.VisaApplication
+		want    string
+	}{
+		{"no members", nil, entity.VisaStatusPending},
+		{"same status", members(entity.VisaStatusSubmitted, entity.VisaStatusSubmitted), entity.VisaStatusSubmitted},
+		{"no status counts as pending", members("", entity.VisaStatusPending), entity.VisaStatusPending},
+		{"documents requested wins", members(entity.VisaStatusApproved, entity.VisaStatusDocumentsRequested), entity.VisaStatusDocumentsRequested},
+		{"all decided", members(entity.VisaStatusApproved, entity.VisaStatusRejected), usecase.VisaGroupStatusPartiallyApproved},
+		{"decided and open", members(entity.VisaStatusApproved, entity.VisaStatusSubmitted), usecase.VisaGroupStatusMixed},
+		{"slowest open", members(entity.VisaStatusSubmitted, entity.VisaStatusUnderReview, entity.VisaStatusSubmitted), entity.VisaStatusUnderReview},
+	}
+
+	for _, tt := range tests {
+		got, counts := usecase.RollUpGroupStatus(tt.members)
+		if got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+		total := 0
+		for _, count := range counts {
+			total += count
+		}
+		if total != len(tt.members) {
+			t.Errorf("%s: counted %d members, want %d", tt.name, total, len(tt.members))
+		}
+	}
+}
+
+func TestGroupTravelDependentDraft(t *testing.T) {
+	group := &entity.VisaApplicationGroup{
+		Destination:    "Canada",
+		VisaType:       "Work",
+		TravelDate:     time.Date(2027, 1, 15, 0, 0, 0, 0, time.UTC),
+		DurationOfStay: "2 years",
+		Purpose:        "Relocation",
+	}
+	dependent := request.DependentRequest{
+		Relationship: "spouse",
+		FullName:     "Jane Doe",
+		PersonalInfo: request.PersonalInfoRequest{
+			PassportNumber:  "B12345678",
+			PassportExpiry:  "2031-05-31",
+			ResidentialAddr: "123 Lagos Street, Abuja",
+			Nationality:     "Nigerian",
+			MaritalStatus:   "Married",
+			DateOfBirth:     "1994-02-10",
+		},
+	}
+
+	// Dependent drafts are stored like any draft, the request payload
+	form, err := json.Marshal(usecase.DependentFormInput(group, dependent))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	// The principal changes the trip
+	group.Destination = "Germany"
+	group.VisaType = "Student"
+	group.TravelDate = time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC)
+	form, err = usecase.GroupTravelForm(form, group, true)
+	if err != nil {
+		t.Fatalf("draft travel: %v", err)
+	}
+
+	var keys map[string]any
+	if err := json.Unmarshal(form, &keys); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	for _, key := range []string{"Destination", "VisaType", "TravelDate"} {
+		if _, ok := keys[key]; ok {
+			t.Errorf("draft has the stored form key %s: %s", key, form)
+		}
+	}
+
+	// The draft still submits, with the new trip
+	submission, err := usecase.DraftToSubmission(&entity.VisaApplication{VisaFormInput: form})
+	if err != nil {
+		t.Fatalf("submission: %v", err)
+	}
+	if err := validator.New().Struct(submission.VisaFormInput); err != nil {
+		t.Fatalf("dependent draft can't be submitted: %v", err)
+	}
+	input := submission.VisaFormInput
+	if input.Destination != "Germany" || input.VisaType != "Student" || input.TravelDate != "2027-03-01" || input.PersonalInfo.PassportNumber != "B12345678" {
+		t.Errorf("unexpected submission: %+v", input)
+	}
+}
+
+func TestGroupTravelSubmittedForm(t *testing.T) {
+	group := &entity.VisaApplicationGroup{
+		Destination:    "Germany",
+		VisaType:       "Student",
+		TravelDate:     time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC),
+		DurationOfStay: "1 year",
+		Purpose:        "Study",
+	}
+	stored, err := json.Marshal(entity.VisaFormInput{
+		Destination:  "Canada",
+		VisaType:     "Work",
+		TravelDate:   time.Date(2027, 1, 15, 0, 0, 0, 0, time.UTC),
+		PersonalInfo: entity.PersonalInfo{PassportNumber: "A12345678"},
+	})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	form, err := usecase.GroupTravelForm(stored, group, false)
+	if err != nil {
+		t.Fatalf("submitted travel: %v", err)
+	}
+	var got entity.VisaFormInput
+	if err := json.Unmarshal(form, &got); err != nil {
+		t.Fatalf("invalid form: %v", err)
+	}
+	if got.Destination != "Germany" || got.VisaType != "Student" || !got.TravelDate.Equal(group.TravelDate) ||
+		got.DurationOfStay != "1 year" || got.Purpose != "Study" || got.PersonalInfo.PassportNumber != "A12345678" {
+		t.Errorf("unexpected form: %+v", got)
+	}
+
+	// Uploaded forms have nothing to update
+	if form, err := usecase.GroupTravelForm([]byte("null"), group, false); err != nil || string(form) != "null" {
+		t.Errorf("uploaded form: got %s, %v", form, err)
+	}
+}
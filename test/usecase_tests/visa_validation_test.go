@@ -0,0 +1,90 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"japa/internal/app/http/dto/apperror"
+	"japa/internal/app/http/dto/request"
+	"japa/internal/config"
+	"japa/internal/domain/usecase"
+)
+
+var validationConfig = config.VisaValidationConfig{
+	PassportValidityMonths:        6,
+	PassportValidityByDestination: map[string]int{"germany": 3},
+	MaxTravelAheadMonths:          24,
+	MinApplicantAge:               0,
+	MaxApplicantAge:               100,
+}
+
+var now = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func visaForm(destination, travelDate, passportExpiry, dob string) *request.VisaFormInputRequest {
+	return &request.VisaFormInputRequest{
+		Destination: destination,
+		TravelDate:  travelDate,
+		PersonalInfo: request.PersonalInfoRequest{
+			PassportExpiry: passportExpiry,
+			DateOfBirth:    dob,
+		},
+	}
+}
+
+func fieldsOf(t *testing.T, err error) map[string]string {
+	t.Helper()
+	var fieldErrs apperror.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+	fields := make(map[string]string)
+	for _, fieldErr := range fieldErrs {
+		fields[fieldErr.Field] = fieldErr.Code
+	}
+	return fields
+}
+
+func TestValidateVisaDatesValid(t *testing.T) {
+	form := visaForm("Canada", "2025-06-01", "2026-01-01", "1995-05-20")
+	if err := usecase.ValidateVisaDates(validationConfig, form, "visa_form_input", now); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestValidateVisaDatesPassportMarginPerDestination(t *testing.T) {
+	// 4 months after travel: too short by default, enough for Germany
+	form := visaForm("Canada", "2025-06-01", "2025-10-01", "1995-05-20")
+	fields := fieldsOf(t, usecase.ValidateVisaDates(validationConfig, form, "visa_form_input", now))
+	if fields["visa_form_input.personal_info.passport_expiry"] != apperror.ErrCodeOutOfRange {
+		t.Fatalf("expected passport_expiry out of range, got %v", fields)
+	}
+
+	form.Destination = "Germany"
+	if err := usecase.ValidateVisaDates(validationConfig, form, "visa_form_input", now); err != nil {
+		t.Fatalf("expected no error for Germany, got %v", err)
+	}
+}
+
+func TestValidateVisaDatesCollectsEveryField(t *testing.T) {
+	form := visaForm("Canada", "2025-01-01", "", "2030-01-01")
+	fields := fieldsOf(t, usecase.ValidateVisaDates(validationConfig, form, "", now))
+
+	want := map[string]string{
+		"travel_date":                   apperror.ErrCodeOutOfRange,
+		"personal_info.passport_expiry": apperror.ErrCodeMissingField,
+		"personal_info.date_of_birth":   apperror.ErrCodeOutOfRange,
+	}
+	for field, code := range want {
+		if fields[field] != code {
+			t.Errorf("%s: expected %s, got %q", field, code, fields[field])
+		}
+	}
+}
+
+func TestValidateTravelDateFormat(t *testing.T) {
+	_, err := usecase.ValidateTravelDate(validationConfig, "travel.travel_date", "01/06/2025", now)
+	if fieldsOf(t, err)["travel.travel_date"] != apperror.ErrCodeInvalidFormat {
+		t.Fatalf("expected invalid format, got %v", err)
+	}
+}
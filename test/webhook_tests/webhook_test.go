@@ -0,0 +1,109 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"japa/internal/domain/usecase"
+	"japa/internal/infrastructure/webhook"
+	"japa/internal/pkg"
+)
+
+const secret = "whsec_test"
+
+func TestSenderSignsPayload(t *testing.T) {
+	payload := []byte(`{"type":"visa_application.created"}`)
+
+	var got http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender := webhook.NewSender(5*time.Second, "japa-test")
+	status, err := sender.Send(context.Background(), webhook.Message{
+		URL:        server.URL,
+		Secret:     secret,
+		DeliveryID: "01JXYZM4T8HR8PQKJS6E4X2C1Z",
+		EventType:  "visa_application.created",
+		Payload:    payload,
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if status != http.StatusNoContent {
+		t.Errorf("status = %d, want %d", status, http.StatusNoContent)
+	}
+	if got.Get("X-Japa-Event") != "visa_application.created" || got.Get("X-Japa-Delivery") != "01JXYZM4T8HR8PQKJS6E4X2C1Z" {
+		t.Errorf("unexpected event headers %v", got)
+	}
+	if string(body) != string(payload) {
+		t.Errorf("body = %s, want %s", body, payload)
+	}
+
+	header := got.Get(pkg.WebhookSignatureHeader)
+	if err := pkg.VerifyWebhookSignature(secret, header, body, 5*time.Minute, time.Now()); err != nil {
+		t.Errorf("VerifyWebhookSignature() error = %v", err)
+	}
+	if err := pkg.VerifyWebhookSignature("whsec_other", header, body, 5*time.Minute, time.Now()); !errors.Is(err, pkg.ErrSignatureMismatch) {
+		t.Errorf("wrong secret: error = %v, want %v", err, pkg.ErrSignatureMismatch)
+	}
+	if err := pkg.VerifyWebhookSignature(secret, header, []byte(`{"type":"tampered"}`), 5*time.Minute, time.Now()); !errors.Is(err, pkg.ErrSignatureMismatch) {
+		t.Errorf("tampered payload: error = %v, want %v", err, pkg.ErrSignatureMismatch)
+	}
+}
+
+func TestSenderRejectsNon2xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sender := webhook.NewSender(5*time.Second, "japa-test")
+	status, err := sender.Send(context.Background(), webhook.Message{URL: server.URL, Secret: secret, Payload: []byte(`{}`)})
+	if err == nil {
+		t.Fatal("Send() error = nil, want an error")
+	}
+	if status != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", status, http.StatusServiceUnavailable)
+	}
+}
+
+func TestVerifyWebhookSignatureExpired(t *testing.T) {
+	payload := []byte(`{}`)
+	signedAt := time.Now().Add(-time.Hour)
+	header := pkg.SignWebhook(secret, signedAt, payload)
+
+	if err := pkg.VerifyWebhookSignature(secret, header, payload, 5*time.Minute, time.Now()); !errors.Is(err, pkg.ErrSignatureExpired) {
+		t.Errorf("error = %v, want %v", err, pkg.ErrSignatureExpired)
+	}
+	if err := pkg.VerifyWebhookSignature(secret, "garbage", payload, 0, time.Now()); !errors.Is(err, pkg.ErrInvalidSignatureHeader) {
+		t.Errorf("error = %v, want %v", err, pkg.ErrInvalidSignatureHeader)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{20, time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := usecase.WebhookBackoff(tt.attempts, 30*time.Second, time.Hour); got != tt.want {
+			t.Errorf("WebhookBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}